@@ -160,6 +160,8 @@ func Bootstrap(templates embed.FS, diggerController controllers.DiggerController
 	}
 
 	r.POST("/github-app-webhook", diggerController.GithubAppWebHook)
+	// authless, plan pages are protected by a signed link
+	r.GET("/plans/:jobId", controllers.JobPlanOutputPage)
 
 	tenantActionsGroup := r.Group("/api/tenants")
 	tenantActionsGroup.Use(middleware.CORSMiddleware())
@@ -183,9 +185,11 @@ func Bootstrap(templates embed.FS, diggerController controllers.DiggerController
 	fronteggWebhookProcessor.Use(middleware.SecretCodeAuth())
 
 	authorized.GET("/repos/:repo/projects/:projectName/access-policy", controllers.FindAccessPolicy)
+	authorized.GET("/repos/:repo/access-policy", controllers.FindAccessPolicyForRepo)
 	authorized.GET("/orgs/:organisation/access-policy", controllers.FindAccessPolicyForOrg)
 
 	authorized.GET("/repos/:repo/projects/:projectName/plan-policy", controllers.FindPlanPolicy)
+	authorized.GET("/repos/:repo/plan-policy", controllers.FindPlanPolicyForRepo)
 	authorized.GET("/orgs/:organisation/plan-policy", controllers.FindPlanPolicyForOrg)
 
 	authorized.GET("/repos/:repo/projects/:projectName/drift-policy", controllers.FindDriftPolicy)
@@ -194,6 +198,12 @@ func Bootstrap(templates embed.FS, diggerController controllers.DiggerController
 	authorized.GET("/repos/:repo/projects/:projectName/runs", controllers.RunHistoryForProject)
 
 	authorized.POST("/repos/:repo/projects/:projectName/jobs/:jobId/set-status", diggerController.SetJobStatusForProject)
+	authorized.POST("/repos/:repo/projects/:projectName/jobs/:jobId/policy-override", diggerController.ReportPolicyOverrideForJob)
+	authorized.POST("/repos/:repo/projects/:projectName/jobs/:jobId/logs", controllers.UploadJobLogs)
+	authorized.GET("/jobs/:jobId/logs", controllers.GetJobLogs)
+	authorized.POST("/repos/:repo/projects/:projectName/jobs/:jobId/plan-output", controllers.UploadJobPlanOutput)
+	authorized.POST("/repos/:repo/projects/:projectName/jobs/:jobId/outputs", controllers.ReportJobOutputs)
+	authorized.GET("/jobs/:jobId/outputs", controllers.GetJobOutputs)
 
 	authorized.GET("/repos/:repo/projects", controllers.FindProjectsForRepo)
 	authorized.POST("/repos/:repo/report-projects", controllers.ReportProjectsForRepo)
@@ -201,9 +211,11 @@ func Bootstrap(templates embed.FS, diggerController controllers.DiggerController
 	authorized.GET("/orgs/:organisation/projects", controllers.FindProjectsForOrg)
 
 	admin.PUT("/repos/:repo/projects/:projectName/access-policy", controllers.UpsertAccessPolicyForRepoAndProject)
+	admin.PUT("/repos/:repo/access-policy", controllers.UpsertAccessPolicyForRepo)
 	admin.PUT("/orgs/:organisation/access-policy", controllers.UpsertAccessPolicyForOrg)
 
 	admin.PUT("/repos/:repo/projects/:projectName/plan-policy", controllers.UpsertPlanPolicyForRepoAndProject)
+	admin.PUT("/repos/:repo/plan-policy", controllers.UpsertPlanPolicyForRepo)
 	admin.PUT("/orgs/:organisation/plan-policy", controllers.UpsertPlanPolicyForOrg)
 
 	admin.PUT("/repos/:repo/projects/:projectName/drift-policy", controllers.UpsertDriftPolicyForRepoAndProject)
@@ -235,6 +247,10 @@ func Bootstrap(templates embed.FS, diggerController controllers.DiggerController
 		reposApiGroup.GET("/", controllers.ListReposApi)
 		reposApiGroup.GET("/:repo_id/jobs", controllers.GetJobsForRepoApi)
 
+		jobsApiGroup := apiGroup.Group("/jobs")
+		jobsApiGroup.GET("/:job_id/logs", controllers.GetJobLogsApi)
+		jobsApiGroup.GET("/:job_id/outputs", controllers.GetJobOutputsApi)
+
 		projectsApiGroup := apiGroup.Group("/projects")
 		projectsApiGroup.GET("/", controllers.ListProjectsApi)
 		projectsApiGroup.GET("/:project_id/", controllers.ProjectsDetailsApi)
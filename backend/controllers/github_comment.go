@@ -21,6 +21,7 @@ import (
 	"github.com/diggerhq/digger/libs/comment_utils/reporting"
 	"github.com/diggerhq/digger/libs/digger_config"
 	"github.com/diggerhq/digger/libs/locking"
+	"github.com/diggerhq/digger/libs/policy"
 	"github.com/diggerhq/digger/libs/scheduler"
 	"github.com/google/go-github/v61/github"
 	"github.com/samber/lo"
@@ -135,6 +136,25 @@ func handleIssueCommentEvent(gh utils.GithubClientProvider, payload *github.Issu
 	}
 
 	commentReporterManager := utils.InitCommentReporterManager(ghService, issueNumber)
+
+	// Overrides don't start jobs: the next plan or apply finds the comment and
+	// checks the access policy for its author
+	if policy.IsOverridePolicyComment(commentBody) {
+		override, err := policy.ParseOverridePolicyComment(commentBody)
+		if err != nil {
+			slog.Info("Invalid policy override comment", "issueNumber", issueNumber, "error", err)
+			commentReporterManager.UpdateComment(fmt.Sprintf(":x: %v", err))
+			return nil
+		}
+		slog.Info("Policy override comment received",
+			"issueNumber", issueNumber,
+			"project", override.Project,
+			"sha", override.Sha,
+			"actor", actor,
+		)
+		commentReporterManager.UpdateComment(fmt.Sprintf(":memo: Policy override for %v at commit %v noted. It applies to the next plan or apply of that commit if the access policy allows %v to override.", override.Project, override.Sha, actor))
+		return nil
+	}
 	if os.Getenv("DIGGER_REPORT_BEFORE_LOADING_CONFIG") == "1" {
 		_, err := commentReporterManager.UpdateComment(":construction_worker: Digger starting....")
 		if err != nil {
@@ -588,7 +588,8 @@ func setupSuite(tb testing.TB) (func(tb testing.TB), *models.Database) {
 	// migrate tables
 	err = gdb.AutoMigrate(&models.Policy{}, &models.Organisation{}, &models.Repo{}, &models.Project{}, &models.Token{},
 		&models.User{}, &models.ProjectRun{}, &models.GithubAppInstallation{}, &models.VCSConnection{}, &models.GithubAppInstallationLink{},
-		&models.GithubDiggerJobLink{}, &models.DiggerJob{}, &models.DiggerJobParentLink{}, &models.JobToken{})
+		&models.GithubDiggerJobLink{}, &models.DiggerBatch{}, &models.DiggerJob{}, &models.DiggerJobParentLink{}, &models.DiggerJobLogChunk{}, &models.DiggerJobPlanOutput{},
+		&models.JobToken{})
 	if err != nil {
 		panic(err)
 	}
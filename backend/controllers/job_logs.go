@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/diggerhq/digger/backend/middleware"
+	"github.com/diggerhq/digger/backend/models"
+	"github.com/diggerhq/digger/libs/backendapi"
+	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultJobLogMaxBytes  = 10 * 1024 * 1024
+	maxJobLogChunksPerPage = 500
+)
+
+type UploadJobLogsRequest struct {
+	Chunks   []backendapi.JobLogChunk `json:"chunks"`
+	Complete bool                     `json:"complete"`
+}
+
+func jobLogMaxBytes() int64 {
+	if value := os.Getenv("DIGGER_JOB_LOG_MAX_BYTES"); value != "" {
+		maxBytes, err := strconv.ParseInt(value, 10, 64)
+		if err == nil && maxBytes > 0 {
+			return maxBytes
+		}
+		slog.Warn("Invalid DIGGER_JOB_LOG_MAX_BYTES, using default", "value", value)
+	}
+	return defaultJobLogMaxBytes
+}
+
+// UploadJobLogs appends stdout and stderr chunks streamed by the CLI while a job runs
+func UploadJobLogs(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+
+	var request UploadJobLogsRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		slog.Error("Error binding JSON request", "jobId", jobId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding JSON"})
+		return
+	}
+
+	chunks := make([]models.DiggerJobLogChunk, 0, len(request.Chunks))
+	for _, chunk := range request.Chunks {
+		if len(chunk.Content) > backendapi.MaxJobLogChunkBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Log chunk too large"})
+			return
+		}
+		if chunk.Stream != backendapi.JobLogStdout && chunk.Stream != backendapi.JobLogStderr {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown log stream " + chunk.Stream})
+			return
+		}
+		chunks = append(chunks, models.DiggerJobLogChunk{
+			Seq:      chunk.Seq,
+			Stream:   chunk.Stream,
+			Content:  backendapi.RedactJobLog(chunk.Content, nil),
+			LoggedAt: chunk.Timestamp,
+		})
+	}
+
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+		return
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if _, err := models.DB.GetRepoByFullName(orgId, job.Batch.RepoFullName); err != nil {
+		slog.Warn("Job logs uploaded for a job outside the organisation", "jobId", jobId, "orgId", orgId)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	job, err = models.DB.AppendDiggerJobLogChunks(jobId, chunks, request.Complete, jobLogMaxBytes())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		slog.Error("Error storing job logs", "jobId", jobId, "orgId", orgId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error storing job logs"})
+		return
+	}
+
+	slog.Debug("Stored job log chunks",
+		"jobId", jobId,
+		"orgId", orgId,
+		"chunks", len(chunks),
+		"logBytes", job.LogBytes,
+		"truncated", job.LogTruncated,
+		"complete", job.LogComplete,
+	)
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// GetJobLogs returns a job's streamed logs for dgctl, see writeJobLogs for the query parameters
+func GetJobLogs(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+	writeJobLogs(c, jobId, orgId)
+}
+
+// GetJobLogsApi returns a job's streamed logs for the UI
+func GetJobLogsApi(c *gin.Context) {
+	organisationId := c.GetString(middleware.ORGANISATION_ID_KEY)
+	organisationSource := c.GetString(middleware.ORGANISATION_SOURCE_KEY)
+	jobId := c.Param("job_id")
+
+	var org models.Organisation
+	err := models.DB.GormDB.Where("external_id = ? AND external_source = ?", organisationId, organisationSource).First(&org).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Info("Organisation not found", "organisationId", organisationId, "source", organisationSource)
+			c.String(http.StatusNotFound, "Could not find organisation: "+organisationId)
+		} else {
+			slog.Error("Error fetching organisation", "organisationId", organisationId, "source", organisationSource, "error", err)
+			c.String(http.StatusInternalServerError, "Error fetching organisation")
+		}
+		return
+	}
+	writeJobLogs(c, jobId, org.ID)
+}
+
+// writeJobLogs responds with the chunks after the "after" sequence number, or the last "tail"
+// chunks. Clients follow a running job by polling with after set to the returned next_seq
+// until complete is true.
+func writeJobLogs(c *gin.Context, jobId string, orgId any) {
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+		return
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if _, err := models.DB.GetRepoByFullName(orgId, job.Batch.RepoFullName); err != nil {
+		slog.Warn("Job logs requested for a job outside the organisation", "jobId", jobId, "orgId", orgId)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	afterSeq, _ := strconv.Atoi(c.DefaultQuery("after", "0"))
+	tail, _ := strconv.Atoi(c.Query("tail"))
+
+	var chunks []models.DiggerJobLogChunk
+	if tail > 0 && afterSeq == 0 {
+		chunks, err = models.DB.GetDiggerJobLogTail(jobId, min(tail, maxJobLogChunksPerPage))
+	} else {
+		chunks, err = models.DB.GetDiggerJobLogChunks(jobId, afterSeq, maxJobLogChunksPerPage)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job logs"})
+		return
+	}
+
+	response := backendapi.JobLogs{
+		JobId:     job.DiggerJobID,
+		Status:    job.Status.ToString(),
+		Chunks:    make([]backendapi.JobLogChunk, 0, len(chunks)),
+		NextSeq:   afterSeq,
+		Truncated: job.LogTruncated,
+	}
+	for _, chunk := range chunks {
+		response.Chunks = append(response.Chunks, backendapi.JobLogChunk{
+			Seq:       chunk.Seq,
+			Stream:    chunk.Stream,
+			Content:   chunk.Content,
+			Timestamp: chunk.LoggedAt,
+		})
+		response.NextSeq = chunk.Seq
+	}
+	// complete once the CLI has sent its last chunk and the client has read everything,
+	// or once a job run by a CLI that does not stream logs has finished
+	jobFinished := job.Status == orchestrator_scheduler.DiggerJobFailed || job.Status == orchestrator_scheduler.DiggerJobSucceeded
+	response.Complete = (job.LogComplete && len(chunks) < maxJobLogChunksPerPage) || (jobFinished && job.LogBytes == 0)
+	c.JSON(http.StatusOK, response)
+}
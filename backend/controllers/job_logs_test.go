@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/backend/middleware"
+	"github.com/diggerhq/digger/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// createOrganisationJob creates a job in a repo of a new organisation and returns both
+func createOrganisationJob(t *testing.T, database *models.Database, orgName string, repoFullName string) (*models.Organisation, *models.DiggerJob) {
+	t.Helper()
+	org, err := database.CreateOrganisation(orgName, "test", uuid.NewString(), nil)
+	assert.NoError(t, err)
+	_, err = database.CreateRepo(strings.ReplaceAll(repoFullName, "/", "-"), repoFullName, "", "", "", org, "", 0, 0, "", "")
+	assert.NoError(t, err)
+	batch := models.DiggerBatch{ID: uuid.New(), RepoFullName: repoFullName}
+	assert.NoError(t, database.GormDB.Create(&batch).Error)
+	job, err := database.CreateDiggerJob(batch.ID, []byte("{}"), "digger_workflow.yml", nil, nil, "lazy", "dev")
+	assert.NoError(t, err)
+	return org, job
+}
+
+// jobRequest calls a job handler as a member of orgId
+func jobRequest(handler gin.HandlerFunc, orgId uint, jobId string, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "jobId", Value: jobId}}
+	c.Set(middleware.ORGANISATION_ID_KEY, orgId)
+	handler(c)
+	return w
+}
+
+func TestUploadJobLogsChecksOrganisation(t *testing.T) {
+	teardownSuite, database := setupSuite(t)
+	defer teardownSuite(t)
+
+	owner, job := createOrganisationJob(t, database, "owner", "owner/infra")
+	other, _ := createOrganisationJob(t, database, "other", "other/infra")
+	body := `{"chunks": [{"seq": 1, "stream": "stdout", "content": "forged\n"}]}`
+
+	w := jobRequest(UploadJobLogs, other.ID, job.DiggerJobID, body)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	chunks, err := database.GetDiggerJobLogChunks(job.DiggerJobID, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	w = jobRequest(UploadJobLogs, owner.ID, job.DiggerJobID, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	chunks, err = database.GetDiggerJobLogChunks(job.DiggerJobID, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+}
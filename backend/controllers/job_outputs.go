@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/diggerhq/digger/backend/middleware"
+	"github.com/diggerhq/digger/backend/models"
+	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportJobOutputsRequest struct {
+	Outputs orchestrator_scheduler.TerraformOutputs `json:"outputs"`
+}
+
+type JobOutputsResponse struct {
+	JobId       string                                  `json:"job_id"`
+	ProjectName string                                  `json:"project_name"`
+	Status      string                                  `json:"status"`
+	Outputs     orchestrator_scheduler.TerraformOutputs `json:"outputs"`
+}
+
+// ReportJobOutputs stores the outputs the CLI read after applying a job's project, they are
+// passed to the projects depending on it when the backend triggers their jobs
+func ReportJobOutputs(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+
+	var request ReportJobOutputsRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		slog.Error("Error binding JSON request", "jobId", jobId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding JSON"})
+		return
+	}
+
+	job, ok := getOrganisationJob(c, jobId, orgId)
+	if !ok {
+		return
+	}
+	if request.Outputs == nil {
+		request.Outputs = orchestrator_scheduler.TerraformOutputs{}
+	}
+	err = models.DB.SetDiggerJobOutputs(job, request.Outputs)
+	if err != nil {
+		slog.Error("Error storing job outputs", "jobId", jobId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error storing job outputs"})
+		return
+	}
+
+	slog.Info("Stored job outputs", "jobId", jobId, "orgId", orgId, "outputs", len(request.Outputs))
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// GetJobOutputs returns the outputs of a job for dgctl
+func GetJobOutputs(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+	writeJobOutputs(c, jobId, orgId)
+}
+
+// GetJobOutputsApi returns the outputs of a job for the UI
+func GetJobOutputsApi(c *gin.Context) {
+	organisationId := c.GetString(middleware.ORGANISATION_ID_KEY)
+	organisationSource := c.GetString(middleware.ORGANISATION_SOURCE_KEY)
+	jobId := c.Param("job_id")
+
+	var org models.Organisation
+	err := models.DB.GormDB.Where("external_id = ? AND external_source = ?", organisationId, organisationSource).First(&org).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Info("Organisation not found", "organisationId", organisationId, "source", organisationSource)
+			c.String(http.StatusNotFound, "Could not find organisation: "+organisationId)
+		} else {
+			slog.Error("Error fetching organisation", "organisationId", organisationId, "source", organisationSource, "error", err)
+			c.String(http.StatusInternalServerError, "Error fetching organisation")
+		}
+		return
+	}
+	writeJobOutputs(c, jobId, org.ID)
+}
+
+// writeJobOutputs responds with the outputs of the job, empty until it has been applied. Values
+// of sensitive outputs are never stored, only their names and types.
+func writeJobOutputs(c *gin.Context, jobId string, orgId any) {
+	job, ok := getOrganisationJob(c, jobId, orgId)
+	if !ok {
+		return
+	}
+	outputs, err := models.DB.GetDiggerJobOutputs(job)
+	if err != nil {
+		slog.Error("Error reading job outputs", "jobId", jobId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reading job outputs"})
+		return
+	}
+	if outputs == nil {
+		outputs = orchestrator_scheduler.TerraformOutputs{}
+	}
+	c.JSON(http.StatusOK, JobOutputsResponse{
+		JobId:       job.DiggerJobID,
+		ProjectName: job.ProjectName,
+		Status:      job.Status.ToString(),
+		Outputs:     outputs,
+	})
+}
+
+// getOrganisationJob fetches a job of the organisation, responding with an error when there is none
+func getOrganisationJob(c *gin.Context, jobId string, orgId any) (*models.DiggerJob, bool) {
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+		return nil, false
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return nil, false
+	}
+	if _, err := models.DB.GetRepoByFullName(orgId, job.Batch.RepoFullName); err != nil {
+		slog.Warn("Job outputs requested for a job outside the organisation", "jobId", jobId, "orgId", orgId)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return nil, false
+	}
+	return job, true
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diggerhq/digger/backend/middleware"
+	"github.com/diggerhq/digger/backend/models"
+	"github.com/diggerhq/digger/libs/backendapi"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// plan pages are linked from PR comments, which outlive most runs
+	defaultPlanLinkTTL = 30 * 24 * time.Hour
+	maxPlanOutputBytes = 20 * 1024 * 1024
+)
+
+type UploadJobPlanOutputRequest struct {
+	Output string `json:"output"`
+}
+
+// planLinkSecret signs plan links, hosting plans is disabled while it is not set
+func planLinkSecret() string {
+	return os.Getenv("DIGGER_PLAN_LINK_SECRET")
+}
+
+func planLinkTTL() time.Duration {
+	if value := os.Getenv("DIGGER_PLAN_LINK_TTL_HOURS"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err == nil && hours > 0 {
+			return time.Duration(hours) * time.Hour
+		}
+		slog.Warn("Invalid DIGGER_PLAN_LINK_TTL_HOURS, using default", "value", value)
+	}
+	return defaultPlanLinkTTL
+}
+
+func signPlanLink(secret string, jobId string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%v:%v", jobId, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PlanOutputUrl returns a link to the rendered plan of a job, valid until expires
+func PlanOutputUrl(hostname string, secret string, jobId string, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", signPlanLink(secret, jobId, expires.Unix()))
+	return fmt.Sprintf("%v/plans/%v?%v", strings.TrimSuffix(hostname, "/"), url.PathEscape(jobId), query.Encode())
+}
+
+func verifyPlanLink(secret string, jobId string, expiresParam string, signature string, now time.Time) bool {
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signPlanLink(secret, jobId, expires)))
+}
+
+// UploadJobPlanOutput stores a plan too long for a PR comment and responds with a signed link
+// to its rendered page, which the CLI comments instead of the plan
+func UploadJobPlanOutput(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+
+	secret := planLinkSecret()
+	hostname := os.Getenv("HOSTNAME")
+	if secret == "" || hostname == "" {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Hosted plans are not enabled"})
+		return
+	}
+
+	var request UploadJobPlanOutputRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		slog.Error("Error binding JSON request", "jobId", jobId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding JSON"})
+		return
+	}
+	if len(request.Output) > maxPlanOutputBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Plan output too large"})
+		return
+	}
+
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+		return
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if _, err := models.DB.GetRepoByFullName(orgId, job.Batch.RepoFullName); err != nil {
+		slog.Warn("Plan output uploaded for a job outside the organisation", "jobId", jobId, "orgId", orgId)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	output := backendapi.RedactJobLog(request.Output, nil)
+	err = models.DB.SetDiggerJobPlanOutput(job.DiggerJobID, output)
+	if err != nil {
+		slog.Error("Error storing plan output", "jobId", jobId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error storing plan output"})
+		return
+	}
+
+	slog.Info("Stored plan output", "jobId", jobId, "orgId", orgId, "bytes", len(output))
+	c.JSON(http.StatusOK, gin.H{"url": PlanOutputUrl(hostname, secret, jobId, time.Now().Add(planLinkTTL()))})
+}
+
+var planOutputPageTemplate = template.Must(template.New("plan").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Plan for {{.Project}}</title>
+  <style>
+    body { font-family: -apple-system, sans-serif; margin: 2rem; }
+    pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; font-size: 13px; }
+  </style>
+</head>
+<body>
+  <h2>Plan for {{.Project}}</h2>
+  <p>{{.Repo}}{{if .PrNumber}} #{{.PrNumber}}{{end}} &middot; {{.Status}} &middot;
+    {{.Created}} to add, {{.Updated}} to change, {{.Deleted}} to destroy</p>
+  <pre>{{.Output}}</pre>
+</body>
+</html>
+`))
+
+// JobPlanOutputPage renders the plan hosted by UploadJobPlanOutput for anyone with a valid link
+func JobPlanOutputPage(c *gin.Context) {
+	jobId := c.Param("jobId")
+	secret := planLinkSecret()
+	if secret == "" || !verifyPlanLink(secret, jobId, c.Query("expires"), c.Query("signature"), time.Now()) {
+		c.String(http.StatusForbidden, "This plan link is invalid or has expired")
+		return
+	}
+
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error fetching job")
+		return
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.String(http.StatusNotFound, "Plan not found")
+		return
+	}
+	planOutput, err := models.DB.GetDiggerJobPlanOutput(job.DiggerJobID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error fetching plan")
+		return
+	}
+	if planOutput == nil || planOutput.Output == "" {
+		c.String(http.StatusNotFound, "Plan not found")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	err = planOutputPageTemplate.Execute(c.Writer, gin.H{
+		"Project":  job.ProjectName,
+		"Repo":     job.Batch.RepoFullName,
+		"PrNumber": job.Batch.PrNumber,
+		"Status":   job.Status.ToString(),
+		"Created":  job.DiggerJobSummary.ResourcesCreated,
+		"Updated":  job.DiggerJobSummary.ResourcesUpdated,
+		"Deleted":  job.DiggerJobSummary.ResourcesDeleted,
+		"Output":   planOutput.Output,
+	})
+	if err != nil {
+		slog.Error("Error rendering plan page", "jobId", jobId, "error", err)
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanOutputUrlVerifies(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	link := PlanOutputUrl("https://digger.example.com/", "secret", "job-1", now.Add(time.Hour))
+
+	u, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "/plans/job-1", u.Path)
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	assert.True(t, verifyPlanLink("secret", "job-1", expires, signature, now))
+	assert.False(t, verifyPlanLink("secret", "job-1", expires, signature, now.Add(2*time.Hour)))
+	assert.False(t, verifyPlanLink("secret", "job-2", expires, signature, now))
+	assert.False(t, verifyPlanLink("other", "job-1", expires, signature, now))
+	assert.False(t, verifyPlanLink("secret", "job-1", "1700009999", signature, now))
+}
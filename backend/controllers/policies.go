@@ -71,6 +71,47 @@ func findPolicy(c *gin.Context, policyType string) {
 	c.String(http.StatusOK, policy.Policy)
 }
 
+func FindAccessPolicyForRepo(c *gin.Context) {
+	findPolicyForRepo(c, models.POLICY_TYPE_ACCESS)
+}
+
+func FindPlanPolicyForRepo(c *gin.Context) {
+	findPolicyForRepo(c, models.POLICY_TYPE_PLAN)
+}
+
+// findPolicyForRepo returns the repo level policy, which applies to every project in the repo
+func findPolicyForRepo(c *gin.Context, policyType string) {
+	repo := c.Param("repo")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+
+	if !exists {
+		slog.Warn("Organisation ID not found in context")
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+
+	var policy models.Policy
+	query := JoinedOrganisationRepoProjectQuery()
+
+	err := query.
+		Where("repos.name = ? AND projects.id IS NULL AND policies.organisation_id = ? AND policies.type = ?", repo, orgId, policyType).
+		First(&policy).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Debug("Policy not found for repo", "repo", repo, "policyType", policyType)
+			c.String(http.StatusNotFound, fmt.Sprintf("Could not find policy for repo %v", repo))
+		} else {
+			slog.Error("Error fetching policy for repo", "repo", repo, "policyType", policyType, "error", err)
+			c.String(http.StatusInternalServerError, "Unknown error occurred while fetching database")
+		}
+		return
+	}
+
+	slog.Debug("Repo policy found", "repo", repo, "policyType", policyType)
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.String(http.StatusOK, policy.Policy)
+}
+
 func FindAccessPolicyForOrg(c *gin.Context) {
 	findPolicyForOrg(c, models.POLICY_TYPE_ACCESS)
 }
@@ -196,6 +237,74 @@ func upsertPolicyForOrg(c *gin.Context, policyType string) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+func UpsertAccessPolicyForRepo(c *gin.Context) {
+	upsertPolicyForRepo(c, models.POLICY_TYPE_ACCESS)
+}
+
+func UpsertPlanPolicyForRepo(c *gin.Context) {
+	upsertPolicyForRepo(c, models.POLICY_TYPE_PLAN)
+}
+
+func upsertPolicyForRepo(c *gin.Context, policyType string) {
+	orgID := c.GetUint(middleware.ORGANISATION_ID_KEY)
+	if orgID == 0 {
+		slog.Warn("Organisation ID not found in context")
+		c.String(http.StatusUnauthorized, "Not authorized")
+		return
+	}
+
+	policyData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		slog.Error("Error reading request body", "error", err)
+		c.String(http.StatusInternalServerError, "Error reading request body")
+		return
+	}
+	repo := c.Param("repo")
+	repoModel := models.Repo{}
+	repoResult := models.DB.GormDB.Where("name = ? AND organisation_id = ?", repo, orgID).Take(&repoModel)
+	if repoResult.RowsAffected == 0 {
+		repoModel = models.Repo{
+			OrganisationID: orgID,
+			Name:           repo,
+		}
+		result := models.DB.GormDB.Create(&repoModel)
+		if result.Error != nil {
+			slog.Error("Error creating repo", "repo", repo, "error", result.Error)
+			c.String(http.StatusInternalServerError, "Error creating missing repo")
+			return
+		}
+		slog.Info("Created new repo", "repo", repo, "orgId", orgID)
+	}
+
+	var policy models.Policy
+	policyResult := models.DB.GormDB.Where("organisation_id = ? AND repo_id = ? AND project_id IS NULL AND type = ?", orgID, repoModel.ID, policyType).Take(&policy)
+
+	if policyResult.RowsAffected == 0 {
+		err := models.DB.GormDB.Create(&models.Policy{
+			OrganisationID: orgID,
+			RepoID:         &repoModel.ID,
+			Type:           policyType,
+			Policy:         string(policyData),
+		}).Error
+		if err != nil {
+			slog.Error("Error creating policy", "repo", repo, "policyType", policyType, "error", err)
+			c.String(http.StatusInternalServerError, "Error creating policy")
+			return
+		}
+		slog.Info("Created new policy for repo", "repo", repo, "policyType", policyType)
+	} else {
+		err := policyResult.Update("policy", string(policyData)).Error
+		if err != nil {
+			slog.Error("Error updating policy", "repo", repo, "policyType", policyType, "error", err)
+			c.String(http.StatusInternalServerError, "Error updating policy")
+			return
+		}
+		slog.Info("Updated existing policy for repo", "repo", repo, "policyType", policyType)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
 func UpsertAccessPolicyForRepoAndProject(c *gin.Context) {
 	upsertPolicyForRepoAndProject(c, models.POLICY_TYPE_ACCESS)
 }
@@ -615,6 +615,78 @@ func RunHistoryForProject(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+type PolicyOverrideRequest struct {
+	Project     string   `json:"project"`
+	User        string   `json:"user"`
+	Reason      string   `json:"reason"`
+	SoftDenials []string `json:"soft_denials"`
+}
+
+// ReportPolicyOverrideForJob records on a job who overrode its soft plan policy denials and why
+func (d DiggerController) ReportPolicyOverrideForJob(c *gin.Context) {
+	jobId := c.Param("jobId")
+	orgId, exists := c.Get(middleware.ORGANISATION_ID_KEY)
+	if !exists {
+		slog.Warn("Organisation ID not found in context", "jobId", jobId)
+		c.String(http.StatusForbidden, "Not allowed to access this resource")
+		return
+	}
+
+	var request PolicyOverrideRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		slog.Error("Error binding JSON request", "jobId", jobId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding JSON"})
+		return
+	}
+	if request.User == "" || request.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user and reason are required"})
+		return
+	}
+
+	job, err := models.DB.GetDiggerJob(jobId)
+	if err != nil {
+		slog.Error("Error fetching job", "jobId", jobId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching job"})
+		return
+	}
+	if job.ID == 0 || job.Batch == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if _, err := models.DB.GetRepoByFullName(orgId, job.Batch.RepoFullName); err != nil {
+		slog.Warn("Policy override reported for a job outside the organisation", "jobId", jobId, "orgId", orgId)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	softDenials, err := json.Marshal(request.SoftDenials)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid soft denials"})
+		return
+	}
+	now := time.Now()
+	job.PolicyOverrideBy = &request.User
+	job.PolicyOverrideReason = &request.Reason
+	job.PolicyOverrideSoftDenials = softDenials
+	job.PolicyOverriddenAt = &now
+	err = models.DB.UpdateDiggerJob(job)
+	if err != nil {
+		slog.Error("Error recording policy override", "jobId", jobId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error recording policy override"})
+		return
+	}
+
+	slog.Info("Plan policy override recorded",
+		"jobId", jobId,
+		"orgId", orgId,
+		"project", request.Project,
+		"user", request.User,
+		"reason", request.Reason,
+	)
+	c.JSON(http.StatusOK, gin.H{})
+}
+
 type SetJobStatusRequest struct {
 	Status          string                      `json:"status"`
 	Timestamp       time.Time                   `json:"timestamp"`
@@ -9,6 +9,7 @@ import (
 	"github.com/diggerhq/digger/backend/models"
 	"github.com/diggerhq/digger/backend/utils"
 	"github.com/diggerhq/digger/libs/ci/github"
+	"github.com/diggerhq/digger/libs/comment_utils/reporting"
 	"github.com/diggerhq/digger/libs/digger_config"
 	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
 )
@@ -186,6 +187,8 @@ func UpdateCheckRunForBatch(gh utils.GithubClientProvider, batch *models.DiggerB
 			"error", err)
 		return fmt.Errorf("error generating realtime comment message: %v", err)
 	}
+	// the summary is split over comments, the check run can only hold one part
+	message = reporting.TruncateCheckRunText(message)
 
 	summary, err := GenerateChecksSummaryForBatch(batch)
 	if err != nil {
@@ -112,3 +112,24 @@ func TestAutomergeWhenBatchIsSuccessfulStatus(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isMergeCalled)
 }
+
+func TestReportPolicyOverrideForJobChecksOrganisation(t *testing.T) {
+	teardownSuite, database := setupSuite(t)
+	defer teardownSuite(t)
+
+	owner, job := createOrganisationJob(t, database, "owner", "owner/infra")
+	other, _ := createOrganisationJob(t, database, "other", "other/infra")
+	body := `{"project": "dev", "user": "mallory", "reason": "forged", "soft_denials": ["no public buckets"]}`
+
+	w := jobRequest(DiggerController{}.ReportPolicyOverrideForJob, other.ID, job.DiggerJobID, body)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	stored, err := database.GetDiggerJob(job.DiggerJobID)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.PolicyOverrideBy)
+
+	w = jobRequest(DiggerController{}.ReportPolicyOverrideForJob, owner.ID, job.DiggerJobID, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, err = database.GetDiggerJob(job.DiggerJobID)
+	assert.NoError(t, err)
+	assert.Equal(t, "mallory", *stored.PolicyOverrideBy)
+}
@@ -0,0 +1,2 @@
+-- Modify "digger_jobs" table
+ALTER TABLE "public"."digger_jobs" ADD COLUMN "policy_override_by" text NULL, ADD COLUMN "policy_override_reason" text NULL, ADD COLUMN "policy_override_soft_denials" jsonb NULL, ADD COLUMN "policy_overridden_at" timestamptz NULL;
@@ -0,0 +1,19 @@
+-- Modify "digger_jobs" table
+ALTER TABLE "public"."digger_jobs" ADD COLUMN "log_bytes" bigint NULL, ADD COLUMN "log_truncated" boolean NULL, ADD COLUMN "log_complete" boolean NULL;
+-- Create "digger_job_log_chunks" table
+CREATE TABLE "public"."digger_job_log_chunks" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "deleted_at" timestamptz NULL,
+  "digger_job_id" character varying(50) NULL,
+  "seq" bigint NULL,
+  "stream" text NULL,
+  "content" text NULL,
+  "logged_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_digger_job_log_chunks_deleted_at" to table: "digger_job_log_chunks"
+CREATE INDEX "idx_digger_job_log_chunks_deleted_at" ON "public"."digger_job_log_chunks" ("deleted_at");
+-- Create index "idx_digger_job_log_chunks_job_seq" to table: "digger_job_log_chunks"
+CREATE UNIQUE INDEX "idx_digger_job_log_chunks_job_seq" ON "public"."digger_job_log_chunks" ("digger_job_id", "seq");
@@ -0,0 +1,14 @@
+-- Create "digger_job_plan_outputs" table
+CREATE TABLE "public"."digger_job_plan_outputs" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "deleted_at" timestamptz NULL,
+  "digger_job_id" character varying(50) NULL,
+  "output" text NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_digger_job_plan_outputs_deleted_at" to table: "digger_job_plan_outputs"
+CREATE INDEX "idx_digger_job_plan_outputs_deleted_at" ON "public"."digger_job_plan_outputs" ("deleted_at");
+-- Create index "idx_digger_job_plan_outputs_digger_job_id" to table: "digger_job_plan_outputs"
+CREATE UNIQUE INDEX "idx_digger_job_plan_outputs_digger_job_id" ON "public"."digger_job_plan_outputs" ("digger_job_id");
@@ -0,0 +1,2 @@
+-- Modify "digger_jobs" table
+ALTER TABLE "public"."digger_jobs" ADD COLUMN "terraform_outputs" jsonb NULL;
@@ -8,6 +8,7 @@ import (
 
 	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -92,7 +93,36 @@ type DiggerJob struct {
 	WorkflowFile    string
 	WorkflowRunUrl  *string
 	StatusUpdatedAt time.Time
-	ReporterType string `gorm:"default:'lazy'"` // temporary, to be replaced by SerializedReporterSpec
+	ReporterType    string `gorm:"default:'lazy'"` // temporary, to be replaced by SerializedReporterSpec
+	// set when the job ran despite soft plan policy denials, via a PR override comment
+	PolicyOverrideBy          *string
+	PolicyOverrideReason      *string
+	PolicyOverrideSoftDenials datatypes.JSON
+	PolicyOverriddenAt        *time.Time
+	// streamed job output, see DiggerJobLogChunk
+	LogBytes     int64
+	LogTruncated bool
+	LogComplete  bool
+	// outputs of the project read after apply, passed to the projects depending on it
+	TerraformOutputs datatypes.JSON
+}
+
+// DiggerJobLogChunk is a redacted piece of a job's stdout or stderr streamed by the CLI
+type DiggerJobLogChunk struct {
+	gorm.Model
+	DiggerJobID string `gorm:"size:50;uniqueIndex:idx_digger_job_log_chunks_job_seq"`
+	Seq         int    `gorm:"uniqueIndex:idx_digger_job_log_chunks_job_seq"`
+	Stream      string
+	Content     string
+	LoggedAt    time.Time
+}
+
+// DiggerJobPlanOutput is the full plan output of a job hosted by the backend when it is too
+// long for a PR comment. It is stored apart from the job so that loading jobs doesn't load it.
+type DiggerJobPlanOutput struct {
+	gorm.Model
+	DiggerJobID string `gorm:"size:50;uniqueIndex"`
+	Output      string
 }
 
 type DiggerJobSummary struct {
@@ -138,17 +168,19 @@ func (j *DiggerJob) MapToJsonStruct() (orchestrator_scheduler.SerializedJob, err
 	}
 
 	serialized := orchestrator_scheduler.SerializedJob{
-		DiggerJobId:      j.DiggerJobID,
-		Status:           j.Status,
-		JobString:        j.SerializedJobSpec,
-		PlanFootprint:    j.PlanFootprint,
-		ProjectName:      job.ProjectName,
-		ProjectAlias:     job.ProjectAlias,
-		WorkflowRunUrl:   j.WorkflowRunUrl,
-		PRCommentUrl:     j.PRCommentUrl,
-		ResourcesCreated: j.DiggerJobSummary.ResourcesCreated,
-		ResourcesUpdated: j.DiggerJobSummary.ResourcesUpdated,
-		ResourcesDeleted: j.DiggerJobSummary.ResourcesDeleted,
+		DiggerJobId:          j.DiggerJobID,
+		Status:               j.Status,
+		JobString:            j.SerializedJobSpec,
+		PlanFootprint:        j.PlanFootprint,
+		ProjectName:          job.ProjectName,
+		ProjectAlias:         job.ProjectAlias,
+		WorkflowRunUrl:       j.WorkflowRunUrl,
+		PRCommentUrl:         j.PRCommentUrl,
+		ResourcesCreated:     j.DiggerJobSummary.ResourcesCreated,
+		ResourcesUpdated:     j.DiggerJobSummary.ResourcesUpdated,
+		ResourcesDeleted:     j.DiggerJobSummary.ResourcesDeleted,
+		PolicyOverrideBy:     j.PolicyOverrideBy,
+		PolicyOverrideReason: j.PolicyOverrideReason,
 	}
 
 	slog.Debug("Mapped job to JSON struct",
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -32,7 +33,7 @@ func setupSuiteScheduler(tb testing.TB) (func(tb testing.TB), *Database) {
 	// migrate tables
 	err = gdb.AutoMigrate(&Policy{}, &Organisation{}, &Repo{}, &Project{}, &Token{},
 		&User{}, &ProjectRun{}, &GithubAppInstallation{}, &VCSConnection{}, &GithubAppInstallationLink{},
-		&GithubDiggerJobLink{}, &DiggerJob{}, &DiggerJobParentLink{})
+		&GithubDiggerJobLink{}, &DiggerBatch{}, &DiggerJob{}, &DiggerJobParentLink{}, &DiggerJobLogChunk{}, &DiggerJobPlanOutput{})
 	if err != nil {
 		panic(err)
 	}
@@ -123,3 +124,116 @@ func TestFindDiggerJobsByParentJobId(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(jobs))
 }
+
+func TestAppendDiggerJobLogChunks(t *testing.T) {
+	teardownSuite, database := setupSuiteScheduler(t)
+	defer teardownSuite(t)
+
+	batchId, _ := uuid.NewUUID()
+	job, err := database.CreateDiggerJob(batchId, []byte{100}, "digger_workflow.yml", nil, nil, "lazy", "")
+	assert.NoError(t, err)
+
+	chunks := []DiggerJobLogChunk{
+		{Seq: 1, Stream: "stdout", Content: "Initializing...\n"},
+		{Seq: 2, Stream: "stderr", Content: "Warning: deprecated\n"},
+	}
+	updated, err := database.AppendDiggerJobLogChunks(job.DiggerJobID, chunks, false, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30+len(JobLogTruncatedMessage)), updated.LogBytes)
+	assert.True(t, updated.LogTruncated)
+	assert.False(t, updated.LogComplete)
+
+	// retried and late chunks are ignored once stored or truncated
+	updated, err = database.AppendDiggerJobLogChunks(job.DiggerJobID, append(chunks, DiggerJobLogChunk{Seq: 3, Stream: "stdout", Content: "more\n"}), true, 30)
+	assert.NoError(t, err)
+	assert.True(t, updated.LogComplete)
+
+	stored, err := database.GetDiggerJobLogChunks(job.DiggerJobID, 0, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(stored))
+	assert.Equal(t, "Initializing...\n", stored[0].Content)
+	assert.Equal(t, "Warning: depre"+JobLogTruncatedMessage, stored[1].Content)
+
+	tail, err := database.GetDiggerJobLogTail(job.DiggerJobID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tail))
+	assert.Equal(t, 2, tail[0].Seq)
+
+	_, err = database.AppendDiggerJobLogChunks("missing", chunks, false, 30)
+	assert.Error(t, err)
+}
+
+func TestSetDiggerJobPlanOutput(t *testing.T) {
+	teardownSuite, database := setupSuiteScheduler(t)
+	defer teardownSuite(t)
+
+	planOutput, err := database.GetDiggerJobPlanOutput("job-1")
+	assert.NoError(t, err)
+	assert.Nil(t, planOutput)
+
+	assert.NoError(t, database.SetDiggerJobPlanOutput("job-1", "Plan: 1 to add"))
+	// a retried upload replaces the stored plan
+	assert.NoError(t, database.SetDiggerJobPlanOutput("job-1", "Plan: 2 to add"))
+
+	planOutput, err = database.GetDiggerJobPlanOutput("job-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Plan: 2 to add", planOutput.Output)
+}
+
+func TestGetLatestProjectOutputs(t *testing.T) {
+	teardownSuite, database := setupSuiteScheduler(t)
+	defer teardownSuite(t)
+
+	org := Organisation{Name: "org", ExternalSource: "test", ExternalId: "org"}
+	otherOrg := Organisation{Name: "other", ExternalSource: "test", ExternalId: "other"}
+	for _, o := range []*Organisation{&org, &otherOrg} {
+		assert.NoError(t, database.GormDB.Create(o).Error)
+	}
+	assert.NoError(t, database.GormDB.Create(&Repo{Name: "org-repo", RepoFullName: "org/repo", OrganisationID: org.ID, DefaultBranch: "main"}).Error)
+	assert.NoError(t, database.GormDB.Create(&Repo{Name: "org-other", RepoFullName: "org/other", OrganisationID: org.ID, DefaultBranch: "main"}).Error)
+
+	earlierBatch := DiggerBatch{ID: uuid.New(), RepoFullName: "org/repo", BranchName: "main", BatchType: orchestrator_scheduler.DiggerCommandApply}
+	otherPullRequestBatch := DiggerBatch{ID: uuid.New(), RepoFullName: "org/repo", BranchName: "feature", BatchType: orchestrator_scheduler.DiggerCommandApply}
+	batch := DiggerBatch{ID: uuid.New(), RepoFullName: "org/repo", BranchName: "change-app", BatchType: orchestrator_scheduler.DiggerCommandPlan}
+	otherRepoBatch := DiggerBatch{ID: uuid.New(), RepoFullName: "org/other"}
+	otherOrgBatch := DiggerBatch{ID: uuid.New(), RepoFullName: "org/repo"}
+	for _, b := range []*DiggerBatch{&earlierBatch, &otherPullRequestBatch, &batch, &otherRepoBatch, &otherOrgBatch} {
+		assert.NoError(t, database.GormDB.Create(b).Error)
+	}
+
+	earlier, err := database.CreateDiggerJob(earlierBatch.ID, []byte{100}, "digger_workflow.yml", nil, nil, "lazy", "network")
+	assert.NoError(t, err)
+	err = database.SetDiggerJobOutputs(earlier, orchestrator_scheduler.TerraformOutputs{
+		"vpc_id":   {Value: []byte(`"vpc-1"`)},
+		"password": {Sensitive: true, Value: []byte(`"hunter2"`)},
+	})
+	assert.NoError(t, err)
+
+	// applies of other pull requests are never used, even when they are more recent
+	unmerged, err := database.CreateDiggerJob(otherPullRequestBatch.ID, []byte{100}, "digger_workflow.yml", nil, nil, "lazy", "network")
+	assert.NoError(t, err)
+	assert.NoError(t, database.SetDiggerJobOutputs(unmerged, orchestrator_scheduler.TerraformOutputs{"vpc_id": {Value: []byte(`"vpc-unmerged"`)}}))
+
+	// outputs applied on the default branch are used until the project is applied in the batch
+	outputs, err := database.GetLatestProjectOutputs(batch.ID, org.ID, "org/repo", "network")
+	assert.NoError(t, err)
+	assert.Equal(t, `"vpc-1"`, string(outputs["vpc_id"].Value))
+	assert.True(t, outputs["password"].Sensitive)
+	assert.Nil(t, outputs["password"].Value)
+
+	// but not for a job of an organisation the repo doesn't belong to
+	outputs, err = database.GetLatestProjectOutputs(otherOrgBatch.ID, otherOrg.ID, "org/repo", "network")
+	assert.NoError(t, err)
+	assert.Nil(t, outputs)
+
+	job, err := database.CreateDiggerJob(batch.ID, []byte{100}, "digger_workflow.yml", nil, nil, "lazy", "network")
+	assert.NoError(t, err)
+	assert.NoError(t, database.SetDiggerJobOutputs(job, orchestrator_scheduler.TerraformOutputs{"vpc_id": {Value: []byte(`"vpc-2"`)}}))
+	outputs, err = database.GetLatestProjectOutputs(batch.ID, org.ID, "org/repo", "network")
+	assert.NoError(t, err)
+	assert.Equal(t, `"vpc-2"`, string(outputs["vpc_id"].Value))
+
+	outputs, err = database.GetLatestProjectOutputs(otherRepoBatch.ID, org.ID, "org/other", "network")
+	assert.NoError(t, err)
+	assert.Nil(t, outputs)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const JobLogTruncatedMessage = "\n[digger: log size limit reached, further output was dropped]\n"
+
+// AppendDiggerJobLogChunks stores streamed output for a job. Chunks whose sequence number is
+// already stored are ignored so that the CLI can retry uploads. Once the job's log reaches
+// maxBytes the rest of the output is dropped and the log is marked as truncated.
+func (db *Database) AppendDiggerJobLogChunks(diggerJobId string, chunks []DiggerJobLogChunk, complete bool, maxBytes int64) (*DiggerJob, error) {
+	var job DiggerJob
+	err := db.GormDB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("digger_job_id = ?", diggerJobId).First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		seqs := make([]int, 0, len(chunks))
+		for _, chunk := range chunks {
+			seqs = append(seqs, chunk.Seq)
+		}
+		var existing []int
+		if len(seqs) > 0 {
+			err = tx.Model(&DiggerJobLogChunk{}).Where("digger_job_id = ? AND seq IN ?", diggerJobId, seqs).Pluck("seq", &existing).Error
+			if err != nil {
+				return err
+			}
+		}
+		stored := make(map[int]bool, len(existing))
+		for _, seq := range existing {
+			stored[seq] = true
+		}
+
+		var newChunks []DiggerJobLogChunk
+		for _, chunk := range chunks {
+			if job.LogTruncated || stored[chunk.Seq] {
+				continue
+			}
+			stored[chunk.Seq] = true
+			if remaining := maxBytes - job.LogBytes; int64(len(chunk.Content)) > remaining {
+				chunk.Content = strings.ToValidUTF8(chunk.Content[:max(remaining, 0)], "") + JobLogTruncatedMessage
+				job.LogTruncated = true
+			}
+			chunk.DiggerJobID = diggerJobId
+			job.LogBytes += int64(len(chunk.Content))
+			newChunks = append(newChunks, chunk)
+		}
+
+		if len(newChunks) > 0 {
+			if err := tx.Create(&newChunks).Error; err != nil {
+				return err
+			}
+		}
+		job.LogComplete = job.LogComplete || complete
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"log_bytes":     job.LogBytes,
+			"log_truncated": job.LogTruncated,
+			"log_complete":  job.LogComplete,
+		}).Error
+	})
+	if err != nil {
+		slog.Error("failed to append job log chunks", "diggerJobId", diggerJobId, "chunks", len(chunks), "error", err)
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetDiggerJobLogChunks returns up to limit chunks of a job's log after the given sequence number
+func (db *Database) GetDiggerJobLogChunks(diggerJobId string, afterSeq int, limit int) ([]DiggerJobLogChunk, error) {
+	var chunks []DiggerJobLogChunk
+	err := db.GormDB.Where("digger_job_id = ? AND seq > ?", diggerJobId, afterSeq).Order("seq").Limit(limit).Find(&chunks).Error
+	if err != nil {
+		slog.Error("failed to fetch job log chunks", "diggerJobId", diggerJobId, "afterSeq", afterSeq, "error", err)
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// GetDiggerJobLogTail returns the last tail chunks of a job's log in order
+func (db *Database) GetDiggerJobLogTail(diggerJobId string, tail int) ([]DiggerJobLogChunk, error) {
+	var chunks []DiggerJobLogChunk
+	err := db.GormDB.Where("digger_job_id = ?", diggerJobId).Order("seq DESC").Limit(tail).Find(&chunks).Error
+	if err != nil {
+		slog.Error("failed to fetch job log tail", "diggerJobId", diggerJobId, "tail", tail, "error", err)
+		return nil, err
+	}
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	return chunks, nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/diggerhq/digger/libs/scheduler"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SetDiggerJobOutputs stores the outputs read after a job's apply. Values of sensitive outputs
+// are dropped again in case a client sent them.
+func (db *Database) SetDiggerJobOutputs(job *DiggerJob, outputs scheduler.TerraformOutputs) error {
+	for name, output := range outputs {
+		if output.Sensitive {
+			output.Value = nil
+			outputs[name] = output
+		}
+	}
+	serialized, err := json.Marshal(outputs)
+	if err != nil {
+		return err
+	}
+	job.TerraformOutputs = datatypes.JSON(serialized)
+	return db.GormDB.Model(job).Update("terraform_outputs", job.TerraformOutputs).Error
+}
+
+// GetDiggerJobOutputs returns the outputs stored for a job, nil when none were reported
+func (db *Database) GetDiggerJobOutputs(job *DiggerJob) (scheduler.TerraformOutputs, error) {
+	if len(job.TerraformOutputs) == 0 {
+		return nil, nil
+	}
+	var outputs scheduler.TerraformOutputs
+	if err := json.Unmarshal(job.TerraformOutputs, &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// GetLatestProjectOutputs returns the outputs of a project applied in the batch, or else the
+// latest outputs applied for the project on the default branch of the repo, as long as the repo
+// belongs to the organisation of the requesting job. Applies of other pull requests are never
+// used. It returns nil when there are none.
+func (db *Database) GetLatestProjectOutputs(batchId uuid.UUID, orgId uint, repoFullName string, projectName string) (scheduler.TerraformOutputs, error) {
+	var job DiggerJob
+	err := db.GormDB.
+		Where("batch_id = ? AND project_name = ? AND terraform_outputs IS NOT NULL", batchId, projectName).
+		Order("updated_at DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var repo Repo
+		err = db.GormDB.Where("organisation_id = ? AND repo_full_name = ? AND default_branch <> ''", orgId, repoFullName).First(&repo).Error
+		if err == nil {
+			defaultBranchApplies := db.GormDB.Model(&DiggerBatch{}).Select("id").
+				Where("repo_full_name = ? AND branch_name = ? AND batch_type = ?", repoFullName, repo.DefaultBranch, scheduler.DiggerCommandApply)
+			err = db.GormDB.
+				Where("project_name = ? AND terraform_outputs IS NOT NULL", projectName).
+				Where("batch_id IN (?)", defaultBranchApplies).
+				Order("updated_at DESC").
+				First(&job).Error
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("error fetching project outputs",
+			"batchId", batchId,
+			"orgId", orgId,
+			"repoFullName", repoFullName,
+			"projectName", projectName,
+			"error", err)
+		return nil, err
+	}
+	return db.GetDiggerJobOutputs(&job)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetDiggerJobPlanOutput stores the full plan output of a job, replacing a previous upload
+func (db *Database) SetDiggerJobPlanOutput(diggerJobId string, output string) error {
+	planOutput := DiggerJobPlanOutput{DiggerJobID: diggerJobId, Output: output}
+	err := db.GormDB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "digger_job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"output", "updated_at"}),
+	}).Create(&planOutput).Error
+	if err != nil {
+		slog.Error("failed to store plan output", "diggerJobId", diggerJobId, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetDiggerJobPlanOutput returns the plan output stored for a job, nil when none was uploaded
+func (db *Database) GetDiggerJobPlanOutput(diggerJobId string) (*DiggerJobPlanOutput, error) {
+	var planOutput DiggerJobPlanOutput
+	err := db.GormDB.Where("digger_job_id = ?", diggerJobId).First(&planOutput).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("failed to fetch plan output", "diggerJobId", diggerJobId, "error", err)
+		return nil, err
+	}
+	return &planOutput, nil
+}
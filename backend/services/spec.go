@@ -162,6 +162,7 @@ func GetSpecFromJob(job models.DiggerJob) (*spec.Spec, error) {
 	variablesSpec = append(variablesSpec, stateVariables...)
 	variablesSpec = append(variablesSpec, commandVariables...)
 	variablesSpec = append(variablesSpec, runVariables...)
+	variablesSpec = append(variablesSpec, getDependencyOutputVariables(job, jobSpec)...)
 
 	// check for duplicates in list of variablesSpec
 	justNames := lo.Map(variablesSpec, func(item spec.VariableSpec, i int) string {
@@ -229,3 +230,49 @@ func GetSpecFromJob(job models.DiggerJob) (*spec.Spec, error) {
 
 	return &spec, nil
 }
+
+// getDependencyOutputVariables passes the outputs the job takes from the projects it depends on,
+// from the jobs of the batch that applied them or else from the latest apply on the default branch
+// of the repo. Outputs that are not available are left out, the CLI fails the job with the list of
+// missing outputs.
+func getDependencyOutputVariables(job models.DiggerJob, jobSpec scheduler.JobJson) []spec.VariableSpec {
+	variablesSpec := make([]spec.VariableSpec, 0)
+	if len(jobSpec.DependencyOutputs) == 0 || job.Batch == nil {
+		return variablesSpec
+	}
+	// outputs of earlier batches are only taken from the repo in the organisation of the job
+	var orgId uint
+	jobToken, err := models.DB.GetJobToken(jobSpec.BackendJobToken)
+	if err != nil || jobToken == nil {
+		slog.Warn("Could not find the organisation of the job, only using outputs of its batch", "jobId", job.DiggerJobID, "error", err)
+	} else {
+		orgId = jobToken.OrganisationID
+	}
+	projectOutputs := make(map[string]scheduler.TerraformOutputs)
+	for _, dependencyOutput := range jobSpec.DependencyOutputs {
+		outputs, ok := projectOutputs[dependencyOutput.Project]
+		if !ok {
+			outputs, err = models.DB.GetLatestProjectOutputs(job.Batch.ID, orgId, job.Batch.RepoFullName, dependencyOutput.Project)
+			if err != nil {
+				slog.Warn("Could not fetch dependency outputs", "jobId", job.DiggerJobID, "project", dependencyOutput.Project, "error", err)
+			}
+			projectOutputs[dependencyOutput.Project] = outputs
+		}
+		if outputs == nil {
+			continue
+		}
+		name, value, err := outputs.DependencyVariable(dependencyOutput)
+		if err != nil {
+			slog.Warn("Could not pass dependency output", "jobId", job.DiggerJobID, "error", err)
+			continue
+		}
+		variablesSpec = append(variablesSpec, spec.VariableSpec{
+			Name:           name,
+			Value:          value,
+			IsSecret:       false,
+			IsInterpolated: false,
+		})
+	}
+	slog.Debug("Passing dependency outputs", "jobId", job.DiggerJobID, "variableCount", len(variablesSpec))
+	return variablesSpec
+}
@@ -6,9 +6,11 @@ import (
 	"log/slog"
 	"runtime/debug"
 	"strconv"
+	"strings"
 
 	"github.com/diggerhq/digger/backend/models"
 	"github.com/diggerhq/digger/libs/ci"
+	"github.com/diggerhq/digger/libs/comment_utils/reporting"
 	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -72,12 +74,22 @@ func UpdatePRCommentRealtime(gh GithubClientProvider, batch *models.DiggerBatch)
 		return fmt.Errorf("error generating comment message: %v", err)
 	}
 
+	// Summaries too long for one comment continue in further comments
+	parts := SplitSummaryMessage(message, reporting.MaxCommentLength(prService))
+
 	// Update or create the summary comment using fresh batch data
-	commentId, err := UpdateOrCreateSummaryComment(prService, freshBatch, message)
+	commentId, err := UpdateOrCreateSummaryComment(prService, freshBatch, parts[0])
 	if err != nil {
 		slog.Error("Error updating real-time summary comment", "batchId", freshBatch.ID, "error", err)
 		return fmt.Errorf("error updating summary comment: %v", err)
 	}
+	if len(parts) > 1 {
+		err = updateSummaryContinuations(prService, freshBatch, parts[1:])
+		if err != nil {
+			slog.Error("Error updating continuations of summary comment", "batchId", freshBatch.ID, "error", err)
+			return fmt.Errorf("error updating summary comment: %v", err)
+		}
+	}
 
 	// Update batch with comment ID if it was newly created (using fresh batch data)
 	if freshBatch.CommentId == nil && commentId != nil {
@@ -196,17 +208,69 @@ func GenerateRealtimeCommentMessage(jobs []models.DiggerJob, batchType orchestra
 	// Add instruction helpers (same as CLI)
 	message += "\n" + formatExampleCommands()
 
-	// Handle comment length limits
-	const GithubCommentMaxLength = 65536
-	if len(message) > GithubCommentMaxLength {
-		slog.Warn("Comment message too long, trimming", "originalLength", len(message), "maxLength", GithubCommentMaxLength)
-		const footer = "\n\n[Message truncated due to length limits]"
-		trimLength := len(message) - GithubCommentMaxLength + len(footer)
-		message = message[:len(message)-trimLength] + footer
-		slog.Debug("Trimmed comment message", "newLength", len(message))
+	return message, nil
+}
+
+// summaryCommentOverhead leaves room for the table header and part notes added to each part
+const summaryCommentOverhead = 1024
+
+// SplitSummaryMessage splits a summary longer than maxLength at table rows into parts that each
+// fit a comment, the table header is repeated in every part
+func SplitSummaryMessage(message string, maxLength int) []string {
+	lines := strings.SplitN(message, "\n", 3)
+	if len(message) <= maxLength || len(lines) < 3 {
+		return []string{message}
+	}
+	header := lines[0] + "\n" + lines[1] + "\n"
+	parts := reporting.SplitReport(lines[2], max(maxLength-len(header)-summaryCommentOverhead, summaryCommentOverhead))
+	for i := range parts {
+		if i == 0 || strings.HasPrefix(parts[i], "|") {
+			parts[i] = header + parts[i]
+		}
+		if i > 0 {
+			parts[i] = fmt.Sprintf("Continued from the previous comment (part %d of %d)\n\n", i+1, len(parts)) + parts[i]
+		}
+		if i < len(parts)-1 {
+			parts[i] += fmt.Sprintf("\n\nContinued in the next comment (part %d of %d)", i+1, len(parts))
+		}
 	}
+	slog.Info("Summary comment exceeds the comment length limit, splitting it", "length", len(message), "parts", len(parts))
+	return parts
+}
 
-	return message, nil
+// summaryPartMarker tags a continuation comment of a batch summary, so later updates of the
+// summary edit it instead of posting another
+func summaryPartMarker(batch *models.DiggerBatch, part int) string {
+	return fmt.Sprintf("<!-- digger summary %v part %d -->", batch.ID, part)
+}
+
+// updateSummaryContinuations edits the comments continuing the summary of batch, publishing the
+// ones that don't exist yet. parts are the parts after the summary comment itself.
+func updateSummaryContinuations(prService ci.PullRequestService, batch *models.DiggerBatch, parts []string) error {
+	comments, err := prService.GetComments(batch.PrNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %v", err)
+	}
+	for i, part := range parts {
+		marker := summaryPartMarker(batch, i+2)
+		body := part + "\n" + marker
+		existingId := ""
+		for _, comment := range comments {
+			if comment.Body != nil && strings.Contains(*comment.Body, marker) {
+				existingId = comment.Id
+				break
+			}
+		}
+		if existingId != "" {
+			err = prService.EditComment(batch.PrNumber, existingId, body)
+		} else {
+			_, err = prService.PublishComment(batch.PrNumber, body)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update part %d of the summary: %v", i+2, err)
+		}
+	}
+	return nil
 }
 
 // formatExampleCommands creates a collapsible markdown section with example commands
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitSummaryMessageRepeatsTableHeader(t *testing.T) {
+	header := "| Project | Status | Plan | + | ~ | - |\n|---------|--------|------|---|---|---|\n"
+	message := header
+	for i := 0; i < 200; i++ {
+		message += fmt.Sprintf("|✅ **project-%d** |<a href='#'>succeeded</a> | <a href='#'>Plan</a> | 1 | 0 | 0|\n", i)
+	}
+	message += "\n" + formatExampleCommands()
+
+	if parts := SplitSummaryMessage(message, len(message)); len(parts) != 1 || parts[0] != message {
+		t.Fatalf("expected a summary within the limit to be left alone, got %d parts", len(parts))
+	}
+
+	const maxLength = 8000
+	parts := SplitSummaryMessage(message, maxLength)
+	if len(parts) < 2 {
+		t.Fatalf("expected the summary to be split, got %d parts", len(parts))
+	}
+	rows := 0
+	for i, part := range parts {
+		if len(part) > maxLength {
+			t.Errorf("part %d is %d bytes, longer than %d", i+1, len(part), maxLength)
+		}
+		if !strings.Contains(part, header) {
+			t.Errorf("part %d does not repeat the table header", i+1)
+		}
+		rows += strings.Count(part, "**project-")
+	}
+	if rows != 200 {
+		t.Errorf("expected all 200 rows across the parts, got %d", rows)
+	}
+	if !strings.Contains(parts[len(parts)-1], "digger apply") {
+		t.Error("expected the instructions in the last part")
+	}
+}
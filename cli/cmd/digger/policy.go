@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	core_policy "github.com/diggerhq/digger/libs/policy"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Work with OPA policies",
+	// policy commands run offline so skip the CI and backend setup done by PreRun
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test [flags] <suite.yaml>...",
+	Short: "Run policy test suites against fixture inputs",
+	Long: `Run access, plan and drift policies against fixture inputs and assert their decisions.
+Policies are read from the files passed with --access-policy, --plan-policy and --drift-policy,
+or looked up in --policy-dir, a checkout laid out like the management repository.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		accessPolicy, _ := flags.GetString("access-policy")
+		planPolicy, _ := flags.GetString("plan-policy")
+		driftPolicy, _ := flags.GetString("drift-policy")
+		policyDir, _ := flags.GetString("policy-dir")
+		junitOutput, _ := flags.GetString("junit-output")
+		bundleSources, _ := flags.GetStringSlice("bundle")
+
+		bundles, err := core_policy.NewBundleLoaderFromEnv().LoadAll(bundleSources)
+		if err != nil {
+			return err
+		}
+
+		checker := core_policy.DiggerPolicyChecker{
+			PolicyProvider: core_policy.LocalPolicyProvider{
+				AccessPolicyPath: accessPolicy,
+				PlanPolicyPath:   planPolicy,
+				DriftPolicyPath:  driftPolicy,
+				RepoDir:          policyDir,
+			},
+			Bundles: bundles,
+		}
+
+		var suiteResults []core_policy.PolicyTestSuiteResult
+		total, failed := 0, 0
+		for _, suitePath := range args {
+			suite, err := core_policy.LoadPolicyTestSuite(suitePath)
+			if err != nil {
+				return err
+			}
+			suiteResult := core_policy.RunPolicyTestSuite(checker, *suite)
+			for _, result := range suiteResult.Results {
+				total++
+				switch {
+				case result.Err != nil:
+					failed++
+					fmt.Printf("ERROR %v/%v: %v\n", suiteResult.Name, result.Name, result.Err)
+				case len(result.Failures) > 0:
+					failed++
+					fmt.Printf("FAIL  %v/%v\n", suiteResult.Name, result.Name)
+					for _, failure := range result.Failures {
+						fmt.Printf("      %v\n", failure)
+					}
+				default:
+					fmt.Printf("PASS  %v/%v\n", suiteResult.Name, result.Name)
+				}
+			}
+			suiteResults = append(suiteResults, suiteResult)
+		}
+
+		if junitOutput != "" {
+			f, err := os.Create(junitOutput)
+			if err != nil {
+				return fmt.Errorf("could not create junit report: %v", err)
+			}
+			defer f.Close()
+			if err := core_policy.WritePolicyTestJUnit(f, suiteResults); err != nil {
+				return fmt.Errorf("could not write junit report: %v", err)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%v of %v policy tests failed", failed, total)
+		}
+		fmt.Printf("%v policy tests passed\n", total)
+		return nil
+	},
+}
+
+func init() {
+	policyTestCmd.Flags().String("access-policy", "", "path to the access policy to test")
+	policyTestCmd.Flags().String("plan-policy", "", "path to the plan policy to test")
+	policyTestCmd.Flags().String("drift-policy", "", "path to the drift policy to test")
+	policyTestCmd.Flags().String("policy-dir", "", "local checkout of a management repository to look up policies in")
+	policyTestCmd.Flags().String("junit-output", "", "path to write a JUnit XML report to")
+	policyTestCmd.Flags().StringSlice("bundle", nil, "policy bundle URL or oci:// reference to load alongside the policies, can be repeated")
+
+	policyCmd.AddCommand(policyTestCmd)
+	rootCmd.AddCommand(policyCmd)
+}
@@ -51,7 +51,7 @@ func (r *RunConfig) GetServices() (*ci.PullRequestService, *ci.OrgService, *repo
 			RepoName:      repositoryName,
 		}
 		orgService = bitbucket.BitbucketAPI{
-			AuthToken:     "",
+			AuthToken:     r.BitbucketToken,
 			HttpClient:    http.Client{},
 			RepoWorkspace: repoOwner,
 			RepoName:      repositoryName,
@@ -100,6 +100,8 @@ func PreRun(cmd *cobra.Command, args []string) {
 		ReportStrategy = &reporting.LatestRunCommentStrategy{
 			TimeOfRun: time.Now(),
 		}
+	} else if os.Getenv("REPORTING_STRATEGY") == "check_run" {
+		ReportStrategy = &reporting.CheckRunStrategy{}
 	} else {
 		ReportStrategy = &reporting.MultipleCommentsStrategy{}
 	}
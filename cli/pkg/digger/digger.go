@@ -66,48 +66,26 @@ func DetectCI() CIName {
 }
 
 func RunJobs(jobs []orchestrator.Job, prService ci.PullRequestService, orgService ci.OrgService, lock locking2.Lock, reporter reporting.Reporter, planStorage storage.PlanStorage, policyChecker policy.Checker, commentUpdater comment_updater.CommentUpdater, backendApi backendapi.Api, jobId string, reportFinalStatusToBackend bool, reportTerraformOutput bool, prCommentId string, workingDir string) (bool, bool, error) {
+	return runJobs(jobs, nil, 1, prService, orgService, lock, reporter, planStorage, policyChecker, commentUpdater, backendApi, jobId, reportFinalStatusToBackend, reportTerraformOutput, prCommentId, workingDir)
+}
+
+func runJobs(jobs []orchestrator.Job, dependencyGraph *graph.Graph[string, config.Project], maxParallelJobs int, prService ci.PullRequestService, orgService ci.OrgService, lock locking2.Lock, reporter reporting.Reporter, planStorage storage.PlanStorage, policyChecker policy.Checker, commentUpdater comment_updater.CommentUpdater, backendApi backendapi.Api, jobId string, reportFinalStatusToBackend bool, reportTerraformOutput bool, prCommentId string, workingDir string) (bool, bool, error) {
 	defer reporter.Flush()
 
 	slog.Debug("Variable info", "TF_PLUGIN_CACHE_DIR", os.Getenv("TF_PLUGIN_CACHE_DIR"))
 	slog.Debug("Variable info", "TG_PROVIDER_CACHE_DIR", os.Getenv("TG_PROVIDER_CACHE_DIR"))
 	slog.Debug("Variable info", "TERRAGRUNT_PROVIDER_CACHE_DIR", os.Getenv("TERRAGRUNT_PROVIDER_CACHE_DIR"))
 
-	exectorResults := make([]execution.DiggerExecutorResult, len(jobs))
-	appliesPerProject := make(map[string]bool)
-
-	for i, job := range jobs {
-		splits := strings.Split(job.Namespace, "/")
-		SCMOrganisation := splits[0]
-		SCMrepository := splits[1]
-
-		for _, command := range job.Commands {
-			allowedToPerformCommand, err := policyChecker.CheckAccessPolicy(orgService, &prService, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, command, job.PullRequestNumber, job.RequestedBy, []string{})
-
-			if err != nil {
-				return false, false, fmt.Errorf("error checking policy: %v", err)
-			}
-
-			if !allowedToPerformCommand {
-				msg := reportPolicyError(job.ProjectName, command, job.RequestedBy, reporter)
-				slog.Warn("Skipping command ... %v for project %v", command, job.ProjectName)
-				slog.Warn("Received policy error", "message", msg)
-				appliesPerProject[job.ProjectName] = false
-				continue
-			}
-
-			executorResult, _, err := run(command, job, policyChecker, orgService, SCMOrganisation, SCMrepository, job.PullRequestNumber, job.RequestedBy, reporter, lock, prService, job.Namespace, workingDir, planStorage, appliesPerProject)
-			if err != nil {
-				slog.Error("error while running command for project", "command", command, "projectname", job.ProjectName, "error", err)
-				appliesPerProject[job.ProjectName] = false
-				if executorResult != nil {
-					exectorResults[i] = *executorResult
-				}
-				slog.Error("Project command failed, skipping job", "project name", job.ProjectName, "command", command)
-				break
-			}
-			exectorResults[i] = *executorResult
-
-		}
+	pluginCache := execution.NewPluginCacheFromEnv(planStorage)
+	outputs := newProjectOutputs()
+	exectorResults, appliesPerProject, err := executeJobs(jobs, dependencyGraph, maxParallelJobs, reporter, func(job orchestrator.Job, reporter reporting.Reporter, appliesPerProject map[string]bool) (execution.DiggerExecutorResult, error) {
+		return runJobCommands(job, prService, orgService, lock, reporter, planStorage, pluginCache, outputs, policyChecker, backendApi, jobId, workingDir, appliesPerProject)
+	})
+	if pluginCache != nil {
+		reportPluginCacheSummary(pluginCache)
+	}
+	if err != nil {
+		return false, false, err
 	}
 
 	allAppliesSuccess := true
@@ -166,8 +144,104 @@ func RunJobs(jobs []orchestrator.Job, prService ci.PullRequestService, orgServic
 	return allAppliesSuccess, atLeastOneApply, nil
 }
 
-func reportPolicyError(projectName string, command string, requestedBy string, reporter reporting.Reporter) string {
-	msg := fmt.Sprintf("User %s is not allowed to perform action: %s. Check your policies :x:", requestedBy, command)
+// reportPluginCacheSummary logs how the plugin cache did and adds it to the job summary on GitHub Actions
+func reportPluginCacheSummary(pluginCache *execution.PluginCache) {
+	summary := pluginCache.Summary()
+	if summary == "" {
+		return
+	}
+	slog.Info("Plugin cache summary", "summary", summary)
+	stepSummary := os.Getenv("GITHUB_STEP_SUMMARY")
+	if stepSummary == "" {
+		return
+	}
+	file, err := os.OpenFile(stepSummary, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Warn("could not open job summary", "error", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.WriteString(summary + "\n"); err != nil {
+		slog.Warn("could not write job summary", "error", err)
+	}
+}
+
+// runJobCommands runs the commands of a single job, skipping the rest of them once one fails
+func runJobCommands(job orchestrator.Job, prService ci.PullRequestService, orgService ci.OrgService, lock locking2.Lock, reporter reporting.Reporter, planStorage storage.PlanStorage, pluginCache *execution.PluginCache, outputs *projectOutputs, policyChecker policy.Checker, backendApi backendapi.Api, jobId string, workingDir string, appliesPerProject map[string]bool) (execution.DiggerExecutorResult, error) {
+	var result execution.DiggerExecutorResult
+	splits := strings.Split(job.Namespace, "/")
+	SCMOrganisation := splits[0]
+	SCMrepository := splits[1]
+
+	job, err := withDependencyOutputs(job, outputs)
+	if err != nil {
+		msg := fmt.Sprintf("Could not pass dependency outputs to project %v: %v", job.ProjectName, err)
+		slog.Error(msg)
+		_, _, reportErr := reporter.Report(msg, reporting.AsComment("Error passing dependency outputs"))
+		if reportErr != nil {
+			slog.Error("Error publishing comment.", "error", reportErr)
+		}
+		appliesPerProject[job.ProjectName] = false
+		return result, nil
+	}
+
+	for _, command := range job.Commands {
+		allowedToPerformCommand, err := policyChecker.CheckAccessPolicy(orgService, &prService, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, command, job.PullRequestNumber, job.RequestedBy, []string{})
+
+		if err != nil && !policy.IsAccessDenied(err) {
+			return result, fmt.Errorf("error checking policy: %v", err)
+		}
+
+		if !allowedToPerformCommand {
+			msg := reportPolicyError(job.ProjectName, command, job.RequestedBy, err, reporter)
+			slog.Warn("Skipping command ... %v for project %v", command, job.ProjectName)
+			slog.Warn("Received policy error", "message", msg)
+			appliesPerProject[job.ProjectName] = false
+			continue
+		}
+
+		executorResult, _, err := run(command, job, policyChecker, orgService, SCMOrganisation, SCMrepository, job.PullRequestNumber, job.RequestedBy, reporter, lock, prService, job.Namespace, workingDir, planStorage, pluginCache, appliesPerProject, backendApi, jobId)
+		if err != nil {
+			slog.Error("error while running command for project", "command", command, "projectname", job.ProjectName, "error", err)
+			appliesPerProject[job.ProjectName] = false
+			if executorResult != nil {
+				result = *executorResult
+			}
+			slog.Error("Project command failed, skipping job", "project name", job.ProjectName, "command", command)
+			break
+		}
+		result = *executorResult
+
+		if executorResult.ApplyResult != nil && executorResult.ApplyResult.Outputs != nil {
+			outputs.set(job.ProjectName, executorResult.ApplyResult.Outputs)
+			err = backendApi.ReportJobOutputs(job.Namespace, job.ProjectName, jobId, executorResult.ApplyResult.Outputs)
+			if err != nil {
+				slog.Error("error reporting job outputs", "project", job.ProjectName, "error", err)
+			}
+		}
+
+		if executorResult.PolicyOverride != nil {
+			err = backendApi.ReportPolicyOverride(job.Namespace, job.ProjectName, jobId, *executorResult.PolicyOverride)
+			if err != nil {
+				slog.Error("error reporting policy override", "project", job.ProjectName, "error", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// policyErrorMessage tells requestedBy that command was denied, naming the policy layer that denied
+// it when the policy checker reported one
+func policyErrorMessage(command string, requestedBy string, denial error) string {
+	msg := fmt.Sprintf("User %s is not allowed to perform action: %s", requestedBy, command)
+	if denial != nil {
+		msg += fmt.Sprintf(" (%v)", denial)
+	}
+	return msg + ". Check your policies"
+}
+
+func reportPolicyError(projectName string, command string, requestedBy string, denial error, reporter reporting.Reporter) string {
+	msg := policyErrorMessage(command, requestedBy, denial) + " :x:"
 	if reporter.SupportsMarkdown() {
 		_, _, err := reporter.Report(msg, reporting.AsCollapsibleComment(fmt.Sprintf("Policy violation for <b>%v - %v</b>", projectName, command), false))
 		if err != nil {
@@ -182,17 +256,133 @@ func reportPolicyError(projectName string, command string, requestedBy string, r
 	return msg
 }
 
-func run(command string, job orchestrator.Job, policyChecker policy.Checker, orgService ci.OrgService, SCMOrganisation string, SCMrepository string, PRNumber *int, requestedBy string, reporter reporting.Reporter, lock locking2.Lock, prService ci.PullRequestService, projectNamespace string, workingDir string, planStorage storage.PlanStorage, appliesPerProject map[string]bool) (*execution.DiggerExecutorResult, string, error) {
+// pullRequestHeadSha returns the head commit of the job's pull request, or "" if it is unknown
+func pullRequestHeadSha(prService ci.PullRequestService, job orchestrator.Job) string {
+	if job.PullRequestNumber == nil {
+		return ""
+	}
+	_, headSha, _, _, err := prService.GetBranchName(*job.PullRequestNumber)
+	if err != nil {
+		slog.Warn("Failed to get head commit of pull request", "prNumber", *job.PullRequestNumber, "error", err)
+		return ""
+	}
+	return headSha
+}
+
+// findPolicyOverride looks for an authorised override of the soft plan policy
+// denials at headSha on the job's pull request. Lookup failures are treated as
+// no override.
+func findPolicyOverride(policyChecker policy.Checker, orgService ci.OrgService, prService ci.PullRequestService, SCMOrganisation string, SCMrepository string, job orchestrator.Job, headSha string, planPolicyResult policy.PlanPolicyResult) *policy.PolicyOverride {
+	if job.PullRequestNumber == nil {
+		return nil
+	}
+	override, err := policy.FindPolicyOverride(policyChecker, orgService, prService, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, *job.PullRequestNumber, headSha, planPolicyResult.SoftDenials)
+	if err != nil {
+		slog.Error("Failed to look up plan policy override", "project", job.ProjectName, "error", err)
+		return nil
+	}
+	return override
+}
+
+// buildPolicyContext collects the input.context passed to plan and drift policies.
+// Pull request details are looked up only when a policy is evaluated, and are best
+// effort: lookups that fail are logged and left empty.
+func buildPolicyContext(job orchestrator.Job, prService ci.PullRequestService, SCMOrganisation string, SCMrepository string, requestedBy string) policy.PolicyContext {
+	policyContext := policy.PolicyContext{
+		Organisation: SCMOrganisation,
+		Repository:   SCMrepository,
+		Project: policy.Project{
+			Name:      job.ProjectName,
+			Dir:       job.ProjectDir,
+			Workspace: job.ProjectWorkspace,
+		},
+		User: requestedBy,
+	}
+	if job.PullRequestNumber == nil || prService == nil {
+		return policyContext
+	}
+	prNumber := *job.PullRequestNumber
+	return policyContext.WithPullRequestLoader(func() *policy.PullRequest {
+		return loadPolicyPullRequest(prService, prNumber)
+	})
+}
+
+// loadPolicyPullRequest looks up the pull request details passed to policies
+func loadPolicyPullRequest(prService ci.PullRequestService, prNumber int) *policy.PullRequest {
+	pullRequest := &policy.PullRequest{Number: prNumber}
+	if changedFiles, err := prService.GetChangedFiles(prNumber); err != nil {
+		slog.Warn("Failed to get changed files for policy input", "prNumber", prNumber, "error", err)
+	} else {
+		pullRequest.ChangedFiles = changedFiles
+	}
+	if approvals, err := prService.GetApprovals(prNumber); err != nil {
+		slog.Warn("Failed to get approvals for policy input", "prNumber", prNumber, "error", err)
+	} else {
+		pullRequest.Approvals = approvals
+	}
+	if infoService, ok := prService.(ci.PullRequestInfoService); ok {
+		if info, err := infoService.GetPullRequestInfo(prNumber); err != nil {
+			slog.Warn("Failed to get pull request info for policy input", "prNumber", prNumber, "error", err)
+		} else {
+			pullRequest.Author = info.Author
+			pullRequest.Labels = info.Labels
+			pullRequest.TargetBranch = info.TargetBranch
+		}
+	}
+	if pullRequest.TargetBranch == "" {
+		if _, _, targetBranch, _, err := prService.GetBranchName(prNumber); err != nil {
+			slog.Warn("Failed to get target branch for policy input", "prNumber", prNumber, "error", err)
+		} else {
+			pullRequest.TargetBranch = targetBranch
+		}
+	}
+	return pullRequest
+}
+
+// formatPlanPolicyReport renders the plan policy result for the PR comment. Soft
+// denials can be overridden for headSha.
+func formatPlanPolicyReport(projectName string, headSha string, result policy.PlanPolicyResult, override *policy.PolicyOverride) string {
+	preformatted := func(messages []string) string {
+		lines := make([]string, 0, len(messages))
+		for _, message := range messages {
+			// name the policy layers that produced the message when policies are layered
+			if layers := result.LayersOf(message); len(layers) > 0 {
+				message = fmt.Sprintf("[%v] %v", strings.Join(layers, ", "), message)
+			}
+			lines = append(lines, fmt.Sprintf("    %v", message))
+		}
+		return strings.Join(lines, "<br>")
+	}
+
+	var report string
+	switch {
+	case len(result.Denials) > 0 || (len(result.SoftDenials) > 0 && override == nil):
+		report = "Terraform plan failed validation checks :x:<br>" + preformatted(result.Violations(false))
+		if len(result.Denials) == 0 && headSha != "" {
+			report += fmt.Sprintf("<br>These checks can be overridden by commenting `%v -p %v --sha %v --reason \"...\"`", policy.OverridePolicyCommand, projectName, headSha[:min(len(headSha), 12)])
+		}
+	case override != nil:
+		report = fmt.Sprintf("Terraform plan validation checks overridden by %v :warning:<br>Reason: %v<br>", override.User, override.Reason) + preformatted(result.SoftDenials)
+	default:
+		report = "Terraform plan validation checks succeeded :white_check_mark:"
+	}
+	if len(result.Warnings) > 0 {
+		report += "<br>Warnings :warning:<br>" + preformatted(result.Warnings)
+	}
+	return report
+}
+
+func run(command string, job orchestrator.Job, policyChecker policy.Checker, orgService ci.OrgService, SCMOrganisation string, SCMrepository string, PRNumber *int, requestedBy string, reporter reporting.Reporter, lock locking2.Lock, prService ci.PullRequestService, projectNamespace string, workingDir string, planStorage storage.PlanStorage, pluginCache *execution.PluginCache, appliesPerProject map[string]bool, backendApi backendapi.Api, jobId string) (*execution.DiggerExecutorResult, string, error) {
 	slog.Info("Running command for project", "command", command, "project name", job.ProjectName, "project workflow", job.ProjectWorkflow)
 
 	allowedToPerformCommand, err := policyChecker.CheckAccessPolicy(orgService, &prService, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, command, job.PullRequestNumber, requestedBy, []string{})
 
-	if err != nil {
+	if err != nil && !policy.IsAccessDenied(err) {
 		return nil, "error checking policy", fmt.Errorf("error checking policy: %v", err)
 	}
 
 	if !allowedToPerformCommand {
-		msg := reportPolicyError(job.ProjectName, command, requestedBy, reporter)
+		msg := reportPolicyError(job.ProjectName, command, requestedBy, err, reporter)
 		slog.Error(msg)
 		return nil, msg, errors.New(msg)
 	}
@@ -212,22 +402,36 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 		PrNumber:         *job.PullRequestNumber,
 	}
 
+	toolBinary, err := execution.ResolveJobToolBinary(job, workingDir)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to install the pinned terraform version for project %v: %v", job.ProjectName, err)
+		slog.Error(msg)
+		_, _, reportErr := reporter.Report(msg, reporting.AsComment("Error installing terraform"))
+		if reportErr != nil {
+			slog.Error("Error publishing comment.", "error", reportErr)
+		}
+		return nil, msg, fmt.Errorf("failed to install pinned terraform version: %v", err)
+	}
+
 	var terraformExecutor execution.TerraformExecutor
 	var iacUtils iac_utils.IacUtils
 	projectPath := path.Join(workingDir, job.ProjectDir)
 	if job.Terragrunt {
-		terraformExecutor = execution.Terragrunt{WorkingDir: projectPath}
+		terraformExecutor = execution.Terragrunt{WorkingDir: projectPath, TerraformBinary: toolBinary}
 		iacUtils = iac_utils.TerraformUtils{}
 	} else if job.OpenTofu {
-		terraformExecutor = execution.OpenTofu{WorkingDir: projectPath, Workspace: job.ProjectWorkspace}
+		terraformExecutor = execution.OpenTofu{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
 		iacUtils = iac_utils.TerraformUtils{}
 	} else if job.Pulumi {
 		terraformExecutor = execution.Pulumi{WorkingDir: projectPath, Stack: job.ProjectWorkspace}
 		iacUtils = iac_utils.PulumiUtils{}
 	} else {
-		terraformExecutor = execution.Terraform{WorkingDir: projectPath, Workspace: job.ProjectWorkspace}
+		terraformExecutor = execution.Terraform{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
 		iacUtils = iac_utils.TerraformUtils{}
 	}
+	if pluginCache != nil && !job.Pulumi {
+		terraformExecutor = execution.PluginCachingExecutor{TerraformExecutor: terraformExecutor, Cache: pluginCache, WorkingDir: projectPath}
+	}
 
 	commandRunner := execution.CommandRunner{}
 	planPathProvider := execution.ProjectPathProvider{
@@ -273,14 +477,21 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 
 			return nil, msg, fmt.Errorf("%s", msg)
 		} else if planPerformed {
+			var policyOverride *policy.PolicyOverride
 			if isNonEmptyPlan {
-				reportTerraformPlanOutput(reporter, projectLock.LockId(), plan)
-				planIsAllowed, messages, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, planJsonOutput)
+				reportPlanOutputOrLink(reporter, prService, backendApi, job, jobId, projectLock.LockId(), plan, planSummary)
+				planIsAllowed, planPolicyResult, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, planJsonOutput, buildPolicyContext(job, prService, SCMOrganisation, SCMrepository, requestedBy))
 				if err != nil {
 					msg := fmt.Sprintf("Failed to validate plan. %v", err)
 					slog.Error("Failed to validate plan.", "error", err)
 					return nil, msg, fmt.Errorf("%s", msg)
 				}
+				headSha := ""
+				if !planIsAllowed && len(planPolicyResult.Denials) == 0 {
+					headSha = pullRequestHeadSha(prService, job)
+					policyOverride = findPolicyOverride(policyChecker, orgService, prService, SCMOrganisation, SCMrepository, job, headSha, planPolicyResult)
+					planIsAllowed = policyOverride != nil
+				}
 				var planPolicyFormatter func(report string) string
 				summary := fmt.Sprintf("Terraform plan validation check (%v)", job.ProjectName)
 				if reporter.SupportsMarkdown() {
@@ -295,12 +506,7 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 				}
 
 				if !planIsAllowed {
-					planReportMessage := "Terraform plan failed validation checks :x:<br>"
-					preformattedMessaged := make([]string, 0)
-					for _, message := range messages {
-						preformattedMessaged = append(preformattedMessaged, fmt.Sprintf("    %v", message))
-					}
-					planReportMessage = planReportMessage + strings.Join(preformattedMessaged, "<br>")
+					planReportMessage := formatPlanPolicyReport(job.ProjectName, headSha, planPolicyResult, nil)
 					_, _, err = reporter.Report(planReportMessage, planPolicyFormatter)
 
 					if err != nil {
@@ -310,7 +516,7 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 					slog.Error(msg)
 					return nil, msg, fmt.Errorf("%s", msg)
 				} else {
-					_, _, err := reporter.Report("Terraform plan validation checks succeeded :white_check_mark:", planPolicyFormatter)
+					_, _, err := reporter.Report(formatPlanPolicyReport(job.ProjectName, headSha, planPolicyResult, policyOverride), planPolicyFormatter)
 					if err != nil {
 						slog.Error("Failed to report plan.", "error", err)
 					}
@@ -331,6 +537,7 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 					PlanSummary:   *planSummary,
 					TerraformJson: planJsonOutput,
 				},
+				PolicyOverride: policyOverride,
 			}
 			return &result, plan, nil
 		}
@@ -362,6 +569,7 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 
 			// checking policies (plan, access)
 			var planPolicyViolations []string
+			var policyOverride *policy.PolicyOverride
 
 			if os.Getenv("PLAN_UPLOAD_DESTINATION") != "" {
 				terraformPlanJsonStr, err := executor.RetrievePlanJson()
@@ -371,26 +579,29 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 					return nil, msg, fmt.Errorf("%s", msg)
 				}
 
-				_, violations, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, terraformPlanJsonStr)
+				_, planPolicyResult, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, terraformPlanJsonStr, buildPolicyContext(job, prService, SCMOrganisation, SCMrepository, requestedBy))
 				if err != nil {
 					msg := fmt.Sprintf("Failed to check plan policy. %v", err)
 					slog.Error("Failed to check plan policy.", "error", err)
 					return nil, msg, fmt.Errorf("%s", msg)
 				}
-				planPolicyViolations = violations
+				if len(planPolicyResult.Denials) == 0 && len(planPolicyResult.SoftDenials) > 0 {
+					policyOverride = findPolicyOverride(policyChecker, orgService, prService, SCMOrganisation, SCMrepository, job, pullRequestHeadSha(prService, job), planPolicyResult)
+				}
+				planPolicyViolations = planPolicyResult.Violations(policyOverride != nil)
 			} else {
 				slog.Info("Skipping plan policy checks because plan storage is not configured.")
 				planPolicyViolations = []string{}
 			}
 
 			allowedToApply, err := policyChecker.CheckAccessPolicy(orgService, &prService, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, command, job.PullRequestNumber, requestedBy, planPolicyViolations)
-			if err != nil {
+			if err != nil && !policy.IsAccessDenied(err) {
 				msg := fmt.Sprintf("Failed to run plan policy check before apply. %v", err)
 				slog.Error("Failed to run plan policy check before apply", "error", err)
 				return nil, msg, fmt.Errorf("%s", msg)
 			}
 			if !allowedToApply {
-				msg := reportPolicyError(job.ProjectName, command, requestedBy, reporter)
+				msg := reportPolicyError(job.ProjectName, command, requestedBy, err, reporter)
 				slog.Error(msg)
 				return nil, msg, errors.New(msg)
 			}
@@ -404,15 +615,22 @@ func run(command string, job orchestrator.Job, policyChecker policy.Checker, org
 
 				msg := fmt.Sprintf("Failed to run digger apply command. %v", err)
 				return nil, msg, fmt.Errorf("%s", msg)
-			} else if applyPerformed {
+			}
+			var outputs orchestrator.TerraformOutputs
+			if applyPerformed {
 				appliesPerProject[job.ProjectName] = true
+				if !job.Pulumi {
+					outputs = readOutputs(terraformExecutor, job)
+				}
 			}
 			result := execution.DiggerExecutorResult{
 				OperationType:   execution.DiggerOparationTypeApply,
 				TerraformOutput: output,
 				ApplyResult: &execution.DiggerExecutorApplyResult{
 					ApplySummary: *applySummary,
+					Outputs:      outputs,
 				},
+				PolicyOverride: policyOverride,
 			}
 			return &result, output, nil
 		}
@@ -499,6 +717,37 @@ func reportTerraformPlanOutput(reporter reporting.Reporter, projectId string, pl
 	}
 }
 
+// reportPlanOutputOrLink comments the plan, or a link to the plan hosted by the backend when it
+// is too long for a comment. Without hosting the reporter splits the plan over several comments.
+func reportPlanOutputOrLink(reporter reporting.Reporter, prService ci.PullRequestService, backendApi backendapi.Api, job orchestrator.Job, jobId string, projectId string, plan string, summary *iac_utils.IacSummary) {
+	if len(plan) <= reporting.MaxCommentLength(prService) || backendApi == nil || jobId == "" {
+		reportTerraformPlanOutput(reporter, projectId, plan)
+		return
+	}
+
+	planUrl, err := backendApi.UploadJobPlanOutput(job.Namespace, job.ProjectName, jobId, plan)
+	if err != nil || planUrl == "" {
+		slog.Info("Plan output is not hosted by the backend, commenting it in parts", "project", job.ProjectName, "error", err)
+		reportTerraformPlanOutput(reporter, projectId, plan)
+		return
+	}
+
+	report := fmt.Sprintf("The plan is too long for a comment, [view the full plan](%v).", planUrl)
+	if summary != nil {
+		report = fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy.\n\n", summary.ResourcesCreated, summary.ResourcesUpdated, summary.ResourcesDeleted) + report
+	}
+	var formatter func(string) string
+	if reporter.SupportsMarkdown() {
+		formatter = reporting.AsCollapsibleComment("Plan output", true)
+	} else {
+		formatter = reporting.AsComment("Plan output")
+	}
+	_, _, err = reporter.Report(report, formatter)
+	if err != nil {
+		slog.Error("Failed to report plan.", "error", err)
+	}
+}
+
 func reportPlanSummary(reporter reporting.Reporter, summary string) {
 	var formatter func(string) string
 
@@ -544,12 +793,12 @@ func RunJob(
 
 		allowedToPerformCommand, err := policyChecker.CheckAccessPolicy(orgService, nil, SCMOrganisation, SCMrepository, job.ProjectName, job.ProjectDir, command, nil, requestedBy, []string{})
 
-		if err != nil {
+		if err != nil && !policy.IsAccessDenied(err) {
 			return fmt.Errorf("error checking policy: %v", err)
 		}
 
 		if !allowedToPerformCommand {
-			msg := fmt.Sprintf("User %s is not allowed to perform action: %s. Check your policies", requestedBy, command)
+			msg := policyErrorMessage(command, requestedBy, err)
 			if err != nil {
 				slog.Error("Error publishing comment.", "error", err)
 			}
@@ -562,20 +811,25 @@ func RunJob(
 			log.Fatalf("failed to fetch AWS keys, %v", err)
 		}
 
+		toolBinary, err := execution.ResolveJobToolBinary(job, workingDir)
+		if err != nil {
+			return fmt.Errorf("failed to install pinned terraform version: %v", err)
+		}
+
 		var terraformExecutor execution.TerraformExecutor
 		var iacUtils iac_utils.IacUtils
 		projectPath := path.Join(workingDir, job.ProjectDir)
 		if job.Terragrunt {
-			terraformExecutor = execution.Terragrunt{WorkingDir: projectPath}
+			terraformExecutor = execution.Terragrunt{WorkingDir: projectPath, TerraformBinary: toolBinary}
 			iacUtils = iac_utils.TerraformUtils{}
 		} else if job.OpenTofu {
-			terraformExecutor = execution.OpenTofu{WorkingDir: projectPath, Workspace: job.ProjectWorkspace}
+			terraformExecutor = execution.OpenTofu{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
 			iacUtils = iac_utils.TerraformUtils{}
 		} else if job.Pulumi {
 			terraformExecutor = execution.Pulumi{WorkingDir: projectPath, Stack: job.ProjectWorkspace}
 			iacUtils = iac_utils.PulumiUtils{}
 		} else {
-			terraformExecutor = execution.Terraform{WorkingDir: projectPath, Workspace: job.ProjectWorkspace}
+			terraformExecutor = execution.Terraform{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
 			iacUtils = iac_utils.TerraformUtils{}
 		}
 
@@ -617,8 +871,8 @@ func RunJob(
 				slog.Error(msg)
 				return fmt.Errorf("%s", msg)
 			}
-			planIsAllowed, messages, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, planJsonOutput)
-			slog.Info(strings.Join(messages, "\n"))
+			planIsAllowed, planPolicyResult, err := policyChecker.CheckPlanPolicy(SCMrepository, SCMOrganisation, job.ProjectName, job.ProjectDir, planJsonOutput, buildPolicyContext(job, nil, SCMOrganisation, SCMrepository, requestedBy))
+			slog.Info(strings.Join(append(planPolicyResult.Violations(false), planPolicyResult.Warnings...), "\n"))
 			if err != nil {
 				msg := fmt.Sprintf("Failed to validate plan %v", err)
 				slog.Error(msg)
@@ -654,7 +908,7 @@ func RunJob(
 			}
 
 		case "digger drift-detect":
-			_, err = runDriftDetection(policyChecker, SCMOrganisation, SCMrepository, job.ProjectName, requestedBy, job.EventName, diggerExecutor, driftNotification)
+			_, err = runDriftDetection(policyChecker, SCMOrganisation, SCMrepository, job, requestedBy, diggerExecutor, driftNotification)
 			if err != nil {
 				return fmt.Errorf("failed to Run digger drift-detect command. %v", err)
 			}
@@ -664,12 +918,13 @@ func RunJob(
 	return nil
 }
 
-func runDriftDetection(policyChecker policy.Checker, SCMOrganisation string, SCMrepository string, projectName string, requestedBy string, eventName string, diggerExecutor execution.Executor, notification *core_drift.Notification) (string, error) {
-	err := usage.SendUsageRecord(requestedBy, eventName, "drift-detect")
+func runDriftDetection(policyChecker policy.Checker, SCMOrganisation string, SCMrepository string, job orchestrator.Job, requestedBy string, diggerExecutor execution.Executor, notification *core_drift.Notification) (string, error) {
+	projectName := job.ProjectName
+	err := usage.SendUsageRecord(requestedBy, job.EventName, "drift-detect")
 	if err != nil {
 		slog.Error("Failed to send usage report.", "error", err)
 	}
-	policyEnabled, err := policyChecker.CheckDriftPolicy(SCMOrganisation, SCMrepository, projectName)
+	policyEnabled, err := policyChecker.CheckDriftPolicy(SCMOrganisation, SCMrepository, projectName, buildPolicyContext(job, nil, SCMOrganisation, SCMrepository, requestedBy))
 	if err != nil {
 		msg := fmt.Sprintf("failed to check drift policy. %v", err)
 		slog.Error(msg)
@@ -6,9 +6,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/diggerhq/digger/libs/backendapi"
 	"github.com/diggerhq/digger/libs/ci"
 	"github.com/diggerhq/digger/libs/execution"
 	"github.com/diggerhq/digger/libs/iac_utils"
@@ -63,6 +65,11 @@ func (m *MockTerraformExecutor) Show(params []string, envs map[string]string, pl
 	return nonEmptyTerraformPlanJson, "", nil
 }
 
+func (m *MockTerraformExecutor) Output(params []string, envs map[string]string) (string, string, error) {
+	m.Commands = append(m.Commands, RunInfo{"Output", strings.Join(params, " "), time.Now()})
+	return "{}", "", nil
+}
+
 func (m *MockTerraformExecutor) Plan(params []string, envs map[string]string, planJsonFilePath string, s *string) (bool, string, string, error) {
 	m.Commands = append(m.Commands, RunInfo{"Plan", strings.Join(params, " "), time.Now()})
 	return true, "", "", nil
@@ -486,6 +493,167 @@ func TestSortedCommandByDependency(t *testing.T) {
 
 }
 
+func TestJobDependencies(t *testing.T) {
+	jobs := []orchestrator.Job{
+		{ProjectName: "network", ProjectDir: "network"},
+		{ProjectName: "dns", ProjectDir: "dns"},
+		{ProjectName: "app", ProjectDir: "app"},
+		{ProjectName: "app-staging", ProjectDir: "app/"},
+		{ProjectName: "monitoring", ProjectDir: "monitoring", Layer: 1},
+	}
+
+	projectHash := func(p configuration.Project) string {
+		return p.Name
+	}
+	dependencyGraph := graph.New(projectHash, graph.PreventCycles(), graph.Directed())
+	for _, name := range []string{"network", "vpc", "dns", "app", "app-staging", "monitoring"} {
+		dependencyGraph.AddVertex(configuration.Project{Name: name})
+	}
+	// app depends on network through vpc, which is not part of this run
+	dependencyGraph.AddEdge("network", "vpc")
+	dependencyGraph.AddEdge("vpc", "app")
+
+	dependencies := jobDependencies(jobs, &dependencyGraph)
+	assert.Equal(t, [][]int{nil, nil, {0}, {2}, {0, 1, 2, 3}}, dependencies)
+}
+
+func TestExecuteJobsInParallelKeepsReportOrder(t *testing.T) {
+	jobs := []orchestrator.Job{
+		{ProjectName: "slow", ProjectDir: "slow"},
+		{ProjectName: "fast", ProjectDir: "fast"},
+		{ProjectName: "dependent", ProjectDir: "dependent"},
+	}
+	projectHash := func(p configuration.Project) string {
+		return p.Name
+	}
+	dependencyGraph := graph.New(projectHash, graph.PreventCycles(), graph.Directed())
+	for _, job := range jobs {
+		dependencyGraph.AddVertex(configuration.Project{Name: job.ProjectName})
+	}
+	dependencyGraph.AddEdge("fast", "dependent")
+
+	var mu sync.Mutex
+	var started []string
+	running, maxRunning := 0, 0
+	reporter := &bufferedReporter{}
+	results, applies, err := executeJobs(jobs, &dependencyGraph, 2, reporter, func(job orchestrator.Job, reporter reporting.Reporter, appliesPerProject map[string]bool) (execution.DiggerExecutorResult, error) {
+		mu.Lock()
+		started = append(started, job.ProjectName)
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		if job.ProjectName == "slow" {
+			time.Sleep(200 * time.Millisecond)
+		} else {
+			time.Sleep(20 * time.Millisecond)
+		}
+		reporter.Report("plan for "+job.ProjectName, reporting.AsComment(job.ProjectName))
+		appliesPerProject[job.ProjectName] = job.ProjectName != "fast"
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return execution.DiggerExecutorResult{TerraformOutput: job.ProjectName}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, maxRunning)
+	assert.Equal(t, "dependent", started[2])
+	assert.Equal(t, "dependent", results[2].TerraformOutput)
+	assert.Equal(t, map[string]bool{"slow": true, "fast": false, "dependent": true}, applies)
+	assert.Equal(t, []string{"plan for slow", "plan for fast", "plan for dependent"}, reporter.reports)
+}
+
+// planHostingApi is a backend that hosts plan output when url is set
+type planHostingApi struct {
+	backendapi.MockBackendApi
+	url      string
+	uploaded []string
+}
+
+func (a *planHostingApi) UploadJobPlanOutput(repo string, projectName string, jobId string, output string) (string, error) {
+	if a.url == "" {
+		return "", fmt.Errorf("hosted plans are not enabled")
+	}
+	a.uploaded = append(a.uploaded, output)
+	return a.url, nil
+}
+
+func TestReportPlanOutputOrLink(t *testing.T) {
+	job := orchestrator.Job{ProjectName: "dev", Namespace: "acme/infra"}
+	longPlan := strings.Repeat("  + resource\n", 10000)
+	summary := &iac_utils.IacSummary{ResourcesCreated: 10000}
+
+	// short plans are commented as they are
+	api := &planHostingApi{url: "https://digger.example.com/plans/1?signature=abc"}
+	reporter := &bufferedReporter{}
+	reportPlanOutputOrLink(reporter, nil, api, job, "1", "acme/infra#dev", "short plan", summary)
+	assert.Equal(t, []string{"short plan"}, reporter.reports)
+	assert.Empty(t, api.uploaded)
+
+	// long plans are replaced by a summary and a link when the backend hosts them
+	reporter = &bufferedReporter{}
+	reportPlanOutputOrLink(reporter, nil, api, job, "1", "acme/infra#dev", longPlan, summary)
+	assert.Equal(t, []string{longPlan}, api.uploaded)
+	assert.Equal(t, []string{"Plan: 10000 to add, 0 to change, 0 to destroy.\n\nThe plan is too long for a comment, [view the full plan](https://digger.example.com/plans/1?signature=abc)."}, reporter.reports)
+
+	// and commented in full, for the reporter to split, when it does not
+	reporter = &bufferedReporter{}
+	reportPlanOutputOrLink(reporter, nil, &planHostingApi{}, job, "1", "acme/infra#dev", longPlan, summary)
+	assert.Equal(t, []string{longPlan}, reporter.reports)
+}
+
+func TestWithDependencyOutputs(t *testing.T) {
+	outputs := newProjectOutputs()
+	outputs.set("network", orchestrator.TerraformOutputs{
+		"vpc_id":      {Value: []byte(`"vpc-123"`)},
+		"subnet_ids":  {Value: []byte(`["subnet-a","subnet-b"]`)},
+		"db_password": {Sensitive: true},
+	})
+	job := orchestrator.Job{
+		ProjectName: "app",
+		Commands:    []string{"digger apply"},
+		DependencyOutputs: []configuration.DependencyOutput{
+			{Project: "network", Output: "vpc_id", Variable: "vpc_id"},
+			{Project: "network", Output: "subnet_ids", Variable: "subnets"},
+		},
+		CommandEnvVars: map[string]string{"AWS_REGION": "us-east-1"},
+	}
+
+	withOutputs, err := withDependencyOutputs(job, outputs)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"AWS_REGION": "us-east-1", "TF_VAR_vpc_id": "vpc-123", "TF_VAR_subnets": `["subnet-a","subnet-b"]`}, withOutputs.CommandEnvVars)
+	assert.Equal(t, "vpc-123", withOutputs.RunEnvVars["TF_VAR_vpc_id"])
+	assert.NotContains(t, job.CommandEnvVars, "TF_VAR_vpc_id")
+
+	// outputs of projects applied by other runs are passed by the backend with the job spec
+	job.DependencyOutputs = []configuration.DependencyOutput{{Project: "dns", Output: "zone_id", Variable: "zone_id"}}
+	_, err = withDependencyOutputs(job, outputs)
+	assert.ErrorContains(t, err, "zone_id of project dns")
+	job.CommandEnvVars = map[string]string{"TF_VAR_zone_id": "Z123"}
+	withOutputs, err = withDependencyOutputs(job, outputs)
+	assert.NoError(t, err)
+	assert.Equal(t, "Z123", withOutputs.CommandEnvVars["TF_VAR_zone_id"])
+
+	job.DependencyOutputs = []configuration.DependencyOutput{{Project: "network", Output: "db_password", Variable: "db_password"}}
+	_, err = withDependencyOutputs(job, outputs)
+	assert.ErrorContains(t, err, "sensitive")
+
+	// commands not running terraform don't need the outputs
+	job.Commands = []string{"digger unlock"}
+	_, err = withDependencyOutputs(job, outputs)
+	assert.NoError(t, err)
+}
+
+func TestReadOutputs(t *testing.T) {
+	terraformExecutor := &MockTerraformExecutor{}
+	outputs := readOutputs(terraformExecutor, orchestrator.Job{ProjectName: "network"})
+	assert.NotNil(t, outputs)
+	assert.Empty(t, outputs)
+	assert.Equal(t, "Output", terraformExecutor.Commands[0].Command)
+}
+
 func TestParseWorkspace(t *testing.T) {
 	var commentTests = []struct {
 		in  string
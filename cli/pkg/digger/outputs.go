@@ -0,0 +1,87 @@
+package digger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/diggerhq/digger/libs/execution"
+	orchestrator "github.com/diggerhq/digger/libs/scheduler"
+	"github.com/samber/lo"
+)
+
+// projectOutputs keeps the outputs of the projects applied during the run for the jobs depending on them
+type projectOutputs struct {
+	mutex   sync.Mutex
+	outputs map[string]orchestrator.TerraformOutputs
+}
+
+func newProjectOutputs() *projectOutputs {
+	return &projectOutputs{outputs: make(map[string]orchestrator.TerraformOutputs)}
+}
+
+func (p *projectOutputs) set(projectName string, outputs orchestrator.TerraformOutputs) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.outputs[projectName] = outputs
+}
+
+func (p *projectOutputs) get(projectName string) (orchestrator.TerraformOutputs, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	outputs, ok := p.outputs[projectName]
+	return outputs, ok
+}
+
+// commandsTakingVariables are the commands running terraform with the variables of the project
+var commandsTakingVariables = []string{"digger plan", "digger apply", "digger destroy"}
+
+// withDependencyOutputs passes the outputs the job takes from the projects it depends on as TF_VAR_
+// variables. Outputs of projects applied earlier in the run come first, then the variables the
+// backend passed along with the job spec for projects applied by other runs.
+func withDependencyOutputs(job orchestrator.Job, outputs *projectOutputs) (orchestrator.Job, error) {
+	if len(job.DependencyOutputs) == 0 || !lo.Some(job.Commands, commandsTakingVariables) {
+		return job, nil
+	}
+	variables := make(map[string]string)
+	var missing []string
+	for _, dependencyOutput := range job.DependencyOutputs {
+		if dependencyOutputs, ok := outputs.get(dependencyOutput.Project); ok {
+			name, value, err := dependencyOutputs.DependencyVariable(dependencyOutput)
+			if err != nil {
+				return job, err
+			}
+			variables[name] = value
+			continue
+		}
+		name := "TF_VAR_" + dependencyOutput.Variable
+		if _, ok := job.CommandEnvVars[name]; !ok {
+			missing = append(missing, fmt.Sprintf("%v of project %v", dependencyOutput.Output, dependencyOutput.Project))
+		}
+	}
+	if len(missing) > 0 {
+		return job, fmt.Errorf("outputs %v are not available, they are recorded once the projects have been applied by digger", strings.Join(missing, ", "))
+	}
+
+	slog.Info("passing dependency outputs to project", "project", job.ProjectName, "variables", lo.Keys(variables))
+	job.CommandEnvVars = lo.Assign(job.CommandEnvVars, variables)
+	job.RunEnvVars = lo.Assign(job.RunEnvVars, variables)
+	return job, nil
+}
+
+// readOutputs reads the outputs of a project once it has been applied. Failing to read them doesn't
+// fail the apply, only the jobs taking outputs from the project.
+func readOutputs(terraformExecutor execution.TerraformExecutor, job orchestrator.Job) orchestrator.TerraformOutputs {
+	stdout, stderr, err := terraformExecutor.Output(nil, job.CommandEnvVars)
+	if err != nil {
+		slog.Warn("could not read outputs of project", "project", job.ProjectName, "error", err, "stderr", stderr)
+		return nil
+	}
+	outputs, err := orchestrator.ParseTerraformOutputs(stdout)
+	if err != nil {
+		slog.Warn("could not read outputs of project", "project", job.ProjectName, "error", err)
+		return nil
+	}
+	return outputs
+}
@@ -0,0 +1,189 @@
+package digger
+
+import (
+	"log/slog"
+	"path/filepath"
+	"sync"
+
+	"github.com/diggerhq/digger/libs/backendapi"
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/diggerhq/digger/libs/comment_utils/reporting"
+	comment_updater "github.com/diggerhq/digger/libs/comment_utils/summary"
+	config "github.com/diggerhq/digger/libs/digger_config"
+	"github.com/diggerhq/digger/libs/execution"
+	locking2 "github.com/diggerhq/digger/libs/locking"
+	"github.com/diggerhq/digger/libs/policy"
+	orchestrator "github.com/diggerhq/digger/libs/scheduler"
+	"github.com/diggerhq/digger/libs/storage"
+	"github.com/dominikbraun/graph"
+)
+
+// RunJobsInParallel is RunJobs with up to maxParallelJobs jobs running at the same time.
+// jobs must be sorted with SortedCommandsByDependency. A job only starts once the jobs of the
+// projects it depends on, of lower layers and of the same project directory have finished.
+func RunJobsInParallel(jobs []orchestrator.Job, dependencyGraph *graph.Graph[string, config.Project], maxParallelJobs int, prService ci.PullRequestService, orgService ci.OrgService, lock locking2.Lock, reporter reporting.Reporter, planStorage storage.PlanStorage, policyChecker policy.Checker, commentUpdater comment_updater.CommentUpdater, backendApi backendapi.Api, jobId string, reportFinalStatusToBackend bool, reportTerraformOutput bool, prCommentId string, workingDir string) (bool, bool, error) {
+	return runJobs(jobs, dependencyGraph, maxParallelJobs, prService, orgService, lock, reporter, planStorage, policyChecker, commentUpdater, backendApi, jobId, reportFinalStatusToBackend, reportTerraformOutput, prCommentId, workingDir)
+}
+
+type jobRunner func(job orchestrator.Job, reporter reporting.Reporter, appliesPerProject map[string]bool) (execution.DiggerExecutorResult, error)
+
+// executeJobs runs the jobs one by one, or on a pool of maxParallelJobs workers. In parallel mode
+// each job reports to its own buffer which is passed on to reporter in job order, so comments of
+// a project are never interleaved with those of another one.
+func executeJobs(jobs []orchestrator.Job, dependencyGraph *graph.Graph[string, config.Project], maxParallelJobs int, reporter reporting.Reporter, runJob jobRunner) ([]execution.DiggerExecutorResult, map[string]bool, error) {
+	results := make([]execution.DiggerExecutorResult, len(jobs))
+	appliesPerProject := make(map[string]bool)
+
+	if maxParallelJobs <= 1 || len(jobs) <= 1 {
+		for i, job := range jobs {
+			result, err := runJob(job, reporting.ForProject(reporter, job.ProjectName, job.ProjectDir), appliesPerProject)
+			if err != nil {
+				return nil, nil, err
+			}
+			results[i] = result
+		}
+		return results, appliesPerProject, nil
+	}
+
+	slog.Info("running jobs in parallel", "jobs", len(jobs), "maxParallelJobs", maxParallelJobs)
+	dependencies := jobDependencies(jobs, dependencyGraph)
+	supportsMarkdown := reporter.SupportsMarkdown()
+
+	done := make([]chan struct{}, len(jobs))
+	buffers := make([]*bufferedReporter, len(jobs))
+	applies := make([]map[string]bool, len(jobs))
+	errs := make([]error, len(jobs))
+	for i := range jobs {
+		done[i] = make(chan struct{})
+		buffers[i] = &bufferedReporter{supportsMarkdown: supportsMarkdown}
+		applies[i] = make(map[string]bool)
+	}
+
+	workers := make(chan struct{}, maxParallelJobs)
+	var publishMu sync.Mutex
+	finished := make([]bool, len(jobs))
+	nextToPublish := 0
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dependency := range dependencies[i] {
+				<-done[dependency]
+			}
+
+			workers <- struct{}{}
+			slog.Info("starting job", "project", job.ProjectName, "dependencies", len(dependencies[i]))
+			results[i], errs[i] = runJob(job, buffers[i], applies[i])
+			<-workers
+
+			publishMu.Lock()
+			defer publishMu.Unlock()
+			finished[i] = true
+			for nextToPublish < len(jobs) && finished[nextToPublish] {
+				published := jobs[nextToPublish]
+				buffers[nextToPublish].replay(reporting.ForProject(reporter, published.ProjectName, published.ProjectDir))
+				nextToPublish++
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range jobs {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		for projectName, success := range applies[i] {
+			appliesPerProject[projectName] = success
+		}
+	}
+	return results, appliesPerProject, nil
+}
+
+// jobDependencies returns for every job the indexes of the earlier jobs it has to wait for:
+// jobs of projects it depends on (directly or not), jobs of lower layers and jobs sharing its
+// directory, since those share the .terraform directory and state lock
+func jobDependencies(jobs []orchestrator.Job, dependencyGraph *graph.Graph[string, config.Project]) [][]int {
+	var predecessors map[string]map[string]graph.Edge[string]
+	if dependencyGraph != nil {
+		var err error
+		predecessors, err = (*dependencyGraph).PredecessorMap()
+		if err != nil {
+			slog.Warn("could not read project dependency graph, jobs of dependent projects may run concurrently", "error", err)
+		}
+	}
+
+	dependencies := make([][]int, len(jobs))
+	for j, job := range jobs {
+		ancestors := projectAncestors(job.ProjectName, predecessors)
+		for i := 0; i < j; i++ {
+			earlier := jobs[i]
+			if ancestors[earlier.ProjectName] ||
+				earlier.ProjectName == job.ProjectName ||
+				earlier.Layer < job.Layer ||
+				filepath.Clean(earlier.ProjectDir) == filepath.Clean(job.ProjectDir) {
+				dependencies[j] = append(dependencies[j], i)
+			}
+		}
+	}
+	return dependencies
+}
+
+func projectAncestors(projectName string, predecessors map[string]map[string]graph.Edge[string]) map[string]bool {
+	ancestors := make(map[string]bool)
+	pending := []string{projectName}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for parent := range predecessors[current] {
+			if !ancestors[parent] {
+				ancestors[parent] = true
+				pending = append(pending, parent)
+			}
+		}
+	}
+	return ancestors
+}
+
+// bufferedReporter collects the reports of one job while it runs in parallel with others
+type bufferedReporter struct {
+	supportsMarkdown bool
+	suppressed       bool
+	reports          []string
+	formatters       []func(report string) string
+}
+
+func (r *bufferedReporter) Report(report string, reportFormatting func(report string) string) (string, string, error) {
+	r.reports = append(r.reports, report)
+	r.formatters = append(r.formatters, reportFormatting)
+	return "", "", nil
+}
+
+func (r *bufferedReporter) Flush() (string, string, error) {
+	return "", "", nil
+}
+
+func (r *bufferedReporter) Suppress() error {
+	r.suppressed = true
+	return nil
+}
+
+func (r *bufferedReporter) SupportsMarkdown() bool {
+	return r.supportsMarkdown
+}
+
+func (r *bufferedReporter) replay(reporter reporting.Reporter) {
+	for i, report := range r.reports {
+		_, _, err := reporter.Report(report, r.formatters[i])
+		if err != nil {
+			slog.Error("Error publishing comment", "error", err)
+		}
+	}
+	if r.suppressed {
+		if err := reporter.Suppress(); err != nil {
+			slog.Error("Error suppressing reporter", "error", err)
+		}
+	}
+}
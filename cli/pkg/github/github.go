@@ -310,6 +310,10 @@ func GitHubCI(lock core_locking.Lock, policyCheckerProvider core_policy.PolicyCh
 			requestedBy := *commentEvent.Sender.Login
 			commentBody := *commentEvent.Comment.Body
 
+			if core_policy.IsOverridePolicyComment(commentBody) {
+				usage.ReportErrorAndExit(githubActor, "Policy override comment noted, it applies to the next plan or apply", 0)
+			}
+
 			var impactedProjectsForEvent []digger_config.Project
 			if requestedProject != nil {
 				impactedProjectsForEvent = []digger_config.Project{*requestedProject}
@@ -346,7 +350,7 @@ func GitHubCI(lock core_locking.Lock, policyCheckerProvider core_policy.PolicyCh
 
 		jobs = digger.SortedCommandsByDependency(jobs, &dependencyGraph)
 
-		allAppliesSuccessful, atLeastOneApply, err := digger.RunJobs(jobs, &githubPrService, &githubPrService, lock, reporter, planStorage, policyChecker, comment_updater.NoopCommentUpdater{}, backendApi, "", false, false, "0", currentDir)
+		allAppliesSuccessful, atLeastOneApply, err := digger.RunJobsInParallel(jobs, &dependencyGraph, diggerConfig.MaxParallelJobs, &githubPrService, &githubPrService, lock, reporter, planStorage, policyChecker, comment_updater.NoopCommentUpdater{}, backendApi, "", false, false, "0", currentDir)
 		if !allAppliesSuccessful || err != nil {
 			// aggregate status checks: failure
 			if scheduler.IsPlanJobs(jobs) {
@@ -0,0 +1,79 @@
+package spec
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	backend2 "github.com/diggerhq/digger/libs/backendapi"
+	"github.com/diggerhq/digger/libs/spec"
+)
+
+// streamJobLogs tees the process stdout and stderr to the backend while the job runs,
+// so that logs can be followed without opening the CI provider.
+// The returned function restores the original streams and sends what is left.
+func streamJobLogs(backendApi backend2.Api, spec spec.Spec) func() {
+	if os.Getenv("DIGGER_DISABLE_LOG_STREAMING") == "true" || spec.JobId == "" {
+		return func() {}
+	}
+
+	streamer := backend2.NewJobLogStreamer(backendApi, spec.VCS.RepoName, spec.Job.ProjectName, spec.JobId)
+	restoreStdout, err := teeFile(&os.Stdout, streamer.Writer(backend2.JobLogStdout))
+	if err != nil {
+		slog.Warn("could not capture stdout for log streaming", "error", err)
+		return func() {}
+	}
+	restoreStderr, err := teeFile(&os.Stderr, streamer.Writer(backend2.JobLogStderr))
+	if err != nil {
+		restoreStdout()
+		slog.Warn("could not capture stderr for log streaming", "error", err)
+		return func() {}
+	}
+
+	// the default logger was created with the original stdout, point it at the pipe
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel(logger)})))
+
+	streamer.Start()
+	return func() {
+		slog.SetDefault(logger)
+		restoreStderr()
+		restoreStdout()
+		if err := streamer.Close(); err != nil {
+			slog.Warn("could not send remaining job logs to backend", "jobId", spec.JobId, "error", err)
+		}
+	}
+}
+
+// teeFile replaces *target with a pipe whose contents are copied to the original file and w
+func teeFile(target **os.File, w io.Writer) (func(), error) {
+	original := *target
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	*target = writer
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(io.MultiWriter(original, w), reader)
+	}()
+
+	return func() {
+		*target = original
+		writer.Close()
+		<-done
+		reader.Close()
+	}, nil
+}
+
+func logLevel(logger *slog.Logger) slog.Level {
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn} {
+		if logger.Enabled(context.Background(), level) {
+			return level
+		}
+	}
+	return slog.LevelError
+}
@@ -42,6 +42,7 @@ func RunSpec(
 		slog.Error("could not get backend api", "error", err)
 		usage.ReportErrorAndExit(spec.VCS.Actor, fmt.Sprintf("could not get backend api: %v", err), 1)
 	}
+	usage.OnExit(streamJobLogs(backendApi, spec))
 
 	// for additional output reporting
 	diggerOutPath := os.Getenv("DIGGER_OUT")
@@ -112,6 +112,13 @@ func init() {
 	}
 }
 
+var exitHooks []func()
+
+// OnExit registers a function that ReportErrorAndExit runs before exiting, most recent first
+func OnExit(hook func()) {
+	exitHooks = append(exitHooks, hook)
+}
+
 func ReportErrorAndExit(repoOwner string, message string, exitCode int) {
 	if exitCode == 0 {
 		slog.Info(message)
@@ -122,5 +129,10 @@ func ReportErrorAndExit(repoOwner string, message string, exitCode int) {
 	if err != nil {
 		slog.Error("Failed to send log record.", "error", err)
 	}
+	hooks := exitHooks
+	exitHooks = nil
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
 	os.Exit(exitCode)
 }
@@ -18,6 +18,7 @@ var availableCommands = []Command{
 	{"digger show-projects", "Show the impacted projects"},
 	{"digger lock", "Lock Terraform project"},
 	{"digger unlock", "Unlock the Terraform project"},
+	{"digger override-policy", "Override soft plan policy failures of a commit: -p <project> --sha <commit> --reason \"...\""},
 }
 
 func DisplayCommands() {
@@ -32,8 +32,11 @@ import (
 var viperExec *viper.Viper
 
 type execConfig struct {
-	Project string `mapstructure:"project"`
-	Command string `mapstructure:"command"`
+	Project     string `mapstructure:"project"`
+	Command     string `mapstructure:"command"`
+	Local       bool   `mapstructure:"local"`
+	Lock        bool   `mapstructure:"lock"`
+	AutoApprove bool   `mapstructure:"auto-approve"`
 }
 
 func getRepoUsername() (string, error) {
@@ -61,6 +64,9 @@ func getRepoFullname() (string, error) {
 		parts := strings.Split(originURL, ":")
 		repoFullname = parts[1]
 		repoFullname = strings.ReplaceAll(repoFullname, ".git", "")
+	} else if u, err := url.Parse(originURL); err == nil && u.Host != "" {
+		// Format: https://github.com/orgName/repoName.git
+		repoFullname = strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
 	}
 
 	return repoFullname, nil
@@ -186,14 +192,51 @@ func cleanupDiggerOutput(output string) string {
 
 // validateCmd represents the validate command
 var execCmd = &cobra.Command{
-	Use:   "exec [flags]",
+	Use:   "exec [flags] [plan|apply]",
 	Short: "Execute a command on a project",
-	Long:  `Execute a command on a project`,
+	Long: `Execute a command on a project.
+
+By default the command is dispatched to the CI workflow through the digger backend. With --local
+the job is built from digger.yml and run on this machine with the workflow steps, env vars and
+role assumption of the project, reporting to stdout:
+
+  dgctl exec --local -p <project> plan
+  dgctl exec --local --lock -p <project> apply
+
+--lock takes the same project lock as CI for the duration of the run.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var execConfig execConfig
 		viperExec.Unmarshal(&execConfig)
+		if len(args) > 0 {
+			execConfig.Command = args[0]
+		}
 		log.Printf("%v - %v ", execConfig.Project, execConfig.Command)
 
+		if execConfig.Project == "" || execConfig.Command == "" {
+			log.Printf("ERROR: a project and a command are required")
+			os.Exit(1)
+		}
+
+		if execConfig.Local {
+			command, err := localCommand(execConfig.Command)
+			if err != nil {
+				log.Printf("ERROR: %v", err)
+				os.Exit(1)
+			}
+			repoRoot, err := git(".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				log.Printf("could not find git repository: %v", err)
+				os.Exit(1)
+			}
+			err = runLocalJob(repoRoot, execConfig.Project, command, execConfig.Lock, execConfig.AutoApprove)
+			if err != nil {
+				log.Printf("ERROR: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		if execConfig.Command != "digger plan" {
 			log.Printf("ERROR: currently only 'digger plan' supported with exec command")
 			os.Exit(1)
@@ -344,8 +387,13 @@ var execCmd = &cobra.Command{
 
 func init() {
 	flags := []pflag.Flag{
-		{Name: "project", Usage: "the project to run command on"},
-		{Name: "command", Usage: "the command to run"},
+		{Name: "project", Shorthand: "p", Usage: "the project to run command on"},
+		{Name: "command", Usage: "the command to run, can also be passed as argument"},
+	}
+	boolFlags := []pflag.Flag{
+		{Name: "local", Usage: "run the job on this machine instead of in CI"},
+		{Name: "lock", Usage: "take the project lock for the duration of a local run"},
+		{Name: "auto-approve", Usage: "skip the confirmation of a local apply"},
 	}
 
 	viperExec = viper.New()
@@ -354,8 +402,11 @@ func init() {
 	viperExec.AutomaticEnv()
 
 	for _, flag := range flags {
-		execCmd.Flags().String(flag.Name, "", flag.Usage)
-		execCmd.MarkFlagRequired(flag.Name)
+		execCmd.Flags().StringP(flag.Name, flag.Shorthand, "", flag.Usage)
+		viperExec.BindPFlag(flag.Name, execCmd.Flags().Lookup(flag.Name))
+	}
+	for _, flag := range boolFlags {
+		execCmd.Flags().Bool(flag.Name, false, flag.Usage)
 		viperExec.BindPFlag(flag.Name, execCmd.Flags().Lookup(flag.Name))
 	}
 
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diggerhq/digger/libs/ci/generic"
+	"github.com/diggerhq/digger/libs/digger_config"
+	"github.com/diggerhq/digger/libs/execution"
+	"github.com/diggerhq/digger/libs/iac_utils"
+	"github.com/diggerhq/digger/libs/locking"
+	orchestrator_scheduler "github.com/diggerhq/digger/libs/scheduler"
+)
+
+// stdoutReporter prints the reports a job would comment on a pull request
+type stdoutReporter struct{}
+
+func (r stdoutReporter) Report(report string, reportFormatting func(report string) string) (string, string, error) {
+	if reportFormatting != nil {
+		report = reportFormatting(report)
+	}
+	fmt.Println(report)
+	return "", "", nil
+}
+
+func (r stdoutReporter) Flush() (string, string, error) {
+	return "", "", nil
+}
+
+func (r stdoutReporter) SupportsMarkdown() bool {
+	return false
+}
+
+func (r stdoutReporter) Suppress() error {
+	return nil
+}
+
+// localProjectLock takes the project lock CI uses for the duration of a local run. Unlike
+// PullRequestLock it never waits on or releases locks of pull requests, it fails if one is held.
+type localProjectLock struct {
+	InternalLock     locking.Lock
+	ProjectNamespace string
+	ProjectName      string
+	acquired         bool
+}
+
+// localLockTransactionId marks locks taken by a local run, no pull request has this number
+const localLockTransactionId = 0
+
+func (l *localProjectLock) Lock() (bool, error) {
+	existing, err := l.InternalLock.GetLock(l.LockId())
+	if err != nil {
+		return false, fmt.Errorf("could not read lock %v: %v", l.LockId(), err)
+	}
+	if existing != nil {
+		if *existing == localLockTransactionId {
+			return false, fmt.Errorf("project %v is locked by another local run", l.LockId())
+		}
+		return false, fmt.Errorf("project %v is locked by PR #%v", l.LockId(), *existing)
+	}
+	acquired, err := l.InternalLock.Lock(localLockTransactionId, l.LockId())
+	if err != nil {
+		return false, err
+	}
+	l.acquired = acquired
+	return acquired, nil
+}
+
+func (l *localProjectLock) Unlock() (bool, error) {
+	if !l.acquired {
+		return false, nil
+	}
+	released, err := l.InternalLock.Unlock(l.LockId())
+	if err != nil {
+		return false, err
+	}
+	l.acquired = false
+	return released, nil
+}
+
+func (l *localProjectLock) ForceUnlock() error {
+	_, err := l.InternalLock.Unlock(l.LockId())
+	return err
+}
+
+func (l *localProjectLock) LockId() string {
+	return l.ProjectNamespace + "#" + l.ProjectName
+}
+
+// localCommand turns plan, apply or digger plan into the digger command it stands for
+func localCommand(command string) (string, error) {
+	command = strings.TrimSpace(strings.TrimPrefix(command, "digger "))
+	switch command {
+	case "plan", "apply":
+		return "digger " + command, nil
+	default:
+		return "", fmt.Errorf("unsupported command %q, expected plan or apply", command)
+	}
+}
+
+// runLocalJob runs a plan or apply of the project on this machine, the way a CI job would,
+// without a backend. The cloud lock is only taken when useLock is set.
+func runLocalJob(repoRoot string, projectName string, command string, useLock bool, autoApprove bool) error {
+	config, _, _, _, err := digger_config.LoadDiggerConfig(repoRoot, true, nil, nil)
+	if err != nil {
+		return fmt.Errorf("invalid digger config file: %v", err)
+	}
+	project := config.GetProject(projectName)
+	if project == nil {
+		return fmt.Errorf("project %v not found in config, does it exist?", projectName)
+	}
+
+	actor, _ := getRepoUsername()
+	repoFullname, err := getRepoFullname()
+	if err != nil || repoFullname == "" {
+		repoFullname = filepath.Base(repoRoot)
+	}
+	jobs, _, err := orchestrator_scheduler.ConvertProjectsToJobs(actor, repoFullname, command, 0, []digger_config.Project{*project}, project, config.Workflows)
+	if err != nil {
+		return err
+	}
+	job := jobs[0]
+	// there is no pull request, the current branch stands in for it
+	branch, err := git(repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return fmt.Errorf("could not read current branch: %v", err)
+	}
+	job.RunEnvVars = generic.GetRunEnvVars(defaultBranch(repoRoot, "main"), branch, project.Name, project.Dir)
+
+	if err := job.PopulateAwsCredentialsEnvVarsForJob(); err != nil {
+		return fmt.Errorf("could not assume aws roles of project: %v", err)
+	}
+
+	if command == "digger apply" && !autoApprove {
+		fmt.Printf("Apply project %v in %v? Only 'yes' will be accepted: ", project.Name, project.Dir)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return fmt.Errorf("apply cancelled")
+		}
+	}
+
+	toolBinary, err := execution.ResolveJobToolBinary(job, repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to install pinned terraform version: %v", err)
+	}
+
+	projectPath := filepath.Join(repoRoot, job.ProjectDir)
+	var terraformExecutor execution.TerraformExecutor
+	var iacUtils iac_utils.IacUtils = iac_utils.TerraformUtils{}
+	if job.Terragrunt {
+		terraformExecutor = execution.Terragrunt{WorkingDir: projectPath, TerraformBinary: toolBinary}
+	} else if job.OpenTofu {
+		terraformExecutor = execution.OpenTofu{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
+	} else if job.Pulumi {
+		terraformExecutor = execution.Pulumi{WorkingDir: projectPath, Stack: job.ProjectWorkspace}
+		iacUtils = iac_utils.PulumiUtils{}
+	} else {
+		terraformExecutor = execution.Terraform{WorkingDir: projectPath, Workspace: job.ProjectWorkspace, Binary: toolBinary}
+	}
+
+	reporter := stdoutReporter{}
+	var executor execution.Executor = execution.DiggerExecutor{
+		ProjectNamespace:  job.Namespace,
+		ProjectName:       job.ProjectName,
+		ProjectPath:       projectPath,
+		StateEnvVars:      job.StateEnvVars,
+		RunEnvVars:        job.RunEnvVars,
+		CommandEnvVars:    job.CommandEnvVars,
+		ApplyStage:        job.ApplyStage,
+		PlanStage:         job.PlanStage,
+		CommandRunner:     execution.CommandRunner{},
+		TerraformExecutor: terraformExecutor,
+		Reporter:          reporter,
+		PlanPathProvider: execution.ProjectPathProvider{
+			ProjectPath:      projectPath,
+			ProjectNamespace: job.Namespace,
+			ProjectName:      job.ProjectName,
+		},
+		IacUtils: iacUtils,
+	}
+
+	if useLock {
+		lock, err := locking.GetLock()
+		if err != nil {
+			return fmt.Errorf("could not get lock provider: %v", err)
+		}
+		projectLock := &localProjectLock{InternalLock: lock, ProjectNamespace: job.Namespace, ProjectName: job.ProjectName}
+		defer func() {
+			if _, err := projectLock.Unlock(); err != nil {
+				fmt.Fprintf(os.Stderr, "could not release lock %v: %v\n", projectLock.LockId(), err)
+			}
+		}()
+		executor = execution.LockingExecutorWrapper{ProjectLock: projectLock, Executor: executor}
+	}
+
+	switch command {
+	case "digger plan":
+		summary, _, isNonEmptyPlan, _, _, err := executor.Plan()
+		if err != nil {
+			return fmt.Errorf("plan failed: %v", err)
+		}
+		if !isNonEmptyPlan {
+			fmt.Println("No changes, the infrastructure matches the configuration.")
+		} else if summary != nil {
+			fmt.Printf("Plan: %d to add, %d to change, %d to destroy.\n", summary.ResourcesCreated, summary.ResourcesUpdated, summary.ResourcesDeleted)
+		}
+	case "digger apply":
+		summary, applyPerformed, _, err := executor.Apply()
+		if err != nil {
+			return fmt.Errorf("apply failed: %v", err)
+		}
+		if applyPerformed && summary != nil {
+			fmt.Printf("Apply complete: %d added, %d changed, %d destroyed.\n", summary.ResourcesCreated, summary.ResourcesUpdated, summary.ResourcesDeleted)
+		}
+	}
+	return nil
+}
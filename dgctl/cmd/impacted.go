@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/diggerhq/digger/libs/ci/generic"
+	"github.com/diggerhq/digger/libs/digger_config"
+	"github.com/spf13/cobra"
+)
+
+var impactedBase string
+var impactedOutput string
+
+type impactedProject struct {
+	Name         string   `json:"name"`
+	Dir          string   `json:"dir"`
+	Workspace    string   `json:"workspace"`
+	Layer        uint     `json:"layer"`
+	Workflow     string   `json:"workflow"`
+	Terragrunt   bool     `json:"terragrunt"`
+	OpenTofu     bool     `json:"opentofu"`
+	Pulumi       bool     `json:"pulumi"`
+	ChangedFiles []string `json:"changed_files"`
+	Modules      []string `json:"modules"`
+	// ImpactingLocations are the directories the backend records in the detection run source mapping
+	ImpactingLocations []string `json:"impacting_locations"`
+	// DependencyOf lists the impacted projects this one depends on, when it was only added as a dependent
+	DependencyOf []string `json:"dependency_of,omitempty"`
+}
+
+type impactedReport struct {
+	Base         string            `json:"base"`
+	ChangedFiles []string          `json:"changed_files"`
+	Projects     []impactedProject `json:"projects"`
+}
+
+// impactedCmd represents the impacted command
+var impactedCmd = &cobra.Command{
+	Use:   "impacted",
+	Short: "List the projects a branch will plan",
+	Long: `Diff the working tree against a base ref and list the projects digger will plan for it,
+along with the changed files and local modules that caused each one to be picked.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if impactedOutput != "tree" && impactedOutput != "json" {
+			log.Printf("unknown output format %v, expected tree or json", impactedOutput)
+			os.Exit(1)
+		}
+		// keep stdout for the report
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+		repoRoot, err := git(".", "rev-parse", "--show-toplevel")
+		if err != nil {
+			log.Printf("could not find git repository: %v", err)
+			os.Exit(1)
+		}
+		changedFiles, err := changedFilesSince(repoRoot, impactedBase)
+		if err != nil {
+			log.Printf("could not diff against %v: %v", impactedBase, err)
+			os.Exit(1)
+		}
+
+		config, _, dependencyGraph, _, err := digger_config.LoadDiggerConfig(repoRoot, true, changedFiles, nil)
+		if err != nil {
+			log.Printf("Invalid digger config file: %v. Exiting.", err)
+			os.Exit(1)
+		}
+
+		projects, sourceMapping := config.GetModifiedProjects(changedFiles)
+		targetBranch := branchName(impactedBase)
+		projects = generic.FilterTargetBranchForImpactedProjects(projects, defaultBranch(repoRoot, targetBranch), targetBranch)
+		directlyImpacted := map[string]bool{}
+		for _, project := range projects {
+			directlyImpacted[project.Name] = true
+		}
+
+		if config.DependencyConfiguration.Mode == digger_config.DependencyConfigurationHard {
+			projects, err = generic.FindAllProjectsDependantOnImpactedProjects(projects, dependencyGraph)
+			if err != nil {
+				log.Printf("could not find projects depending on impacted projects: %v", err)
+				os.Exit(1)
+			}
+		}
+		predecessors, err := dependencyGraph.PredecessorMap()
+		if err != nil {
+			log.Printf("could not read project dependency graph: %v", err)
+			os.Exit(1)
+		}
+
+		impacted := map[string]bool{}
+		for _, project := range projects {
+			impacted[project.Name] = true
+		}
+
+		report := impactedReport{Base: impactedBase, ChangedFiles: changedFiles, Projects: []impactedProject{}}
+		for _, project := range projects {
+			// dependants come from the dependency graph, use the loaded project for its local modules
+			if loadedProject := config.GetProject(project.Name); loadedProject != nil {
+				project = *loadedProject
+			}
+			item := impactedProject{
+				Name:               project.Name,
+				Dir:                project.Dir,
+				Workspace:          project.Workspace,
+				Layer:              project.Layer,
+				Workflow:           project.Workflow,
+				Terragrunt:         project.Terragrunt,
+				OpenTofu:           project.OpenTofu,
+				Pulumi:             project.Pulumi,
+				ChangedFiles:       []string{},
+				Modules:            []string{},
+				ImpactingLocations: sourceMapping[project.Name].ImpactingLocations,
+			}
+			if item.ImpactingLocations == nil {
+				item.ImpactingLocations = []string{}
+			}
+			for _, location := range item.ImpactingLocations {
+				if contains(project.LocalModuleDirs, location) {
+					item.Modules = append(item.Modules, location)
+				}
+			}
+			if directlyImpacted[project.Name] {
+				item.ChangedFiles = filesIn(changedFiles, item.ImpactingLocations)
+			} else {
+				for parent := range predecessors[project.Name] {
+					if impacted[parent] {
+						item.DependencyOf = append(item.DependencyOf, parent)
+					}
+				}
+				sort.Strings(item.DependencyOf)
+			}
+			report.Projects = append(report.Projects, item)
+		}
+
+		if impactedOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				log.Printf("could not encode report: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
+		printImpactedTree(os.Stdout, report)
+	},
+}
+
+func printImpactedTree(w io.Writer, report impactedReport) {
+	fmt.Fprintf(w, "%d changed files against %s, %d impacted projects\n", len(report.ChangedFiles), report.Base, len(report.Projects))
+	for i, project := range report.Projects {
+		branch, indent := "├── ", "│   "
+		if i == len(report.Projects)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s (dir: %s, layer: %d)\n", branch, project.Name, project.Dir, project.Layer)
+
+		var reasons []string
+		for _, module := range project.Modules {
+			reasons = append(reasons, "module "+module)
+		}
+		for _, file := range project.ChangedFiles {
+			reasons = append(reasons, file)
+		}
+		for _, parent := range project.DependencyOf {
+			reasons = append(reasons, "depends on "+parent)
+		}
+		for j, reason := range reasons {
+			if j == len(reasons)-1 {
+				fmt.Fprintf(w, "%s└── %s\n", indent, reason)
+			} else {
+				fmt.Fprintf(w, "%s├── %s\n", indent, reason)
+			}
+		}
+	}
+}
+
+// changedFilesSince returns the files changed in the working tree, including uncommitted and
+// untracked ones, since the merge base of base and HEAD
+func changedFilesSince(repoRoot string, base string) ([]string, error) {
+	mergeBase, err := git(repoRoot, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(repoRoot, "diff", "--name-only", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(repoRoot, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(diff+"\n"+untracked, "\n") {
+		if file != "" && !contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// defaultBranch returns the branch origin/HEAD points at, falling back to fallback
+func defaultBranch(repoRoot string, fallback string) string {
+	ref, err := git(repoRoot, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return fallback
+	}
+	return branchName(ref)
+}
+
+// branchName strips the remote from refs such as origin/main
+func branchName(ref string) string {
+	remotes, err := git(".", "remote")
+	if err != nil {
+		return ref
+	}
+	for _, remote := range strings.Fields(remotes) {
+		if strings.HasPrefix(ref, remote+"/") {
+			return strings.TrimPrefix(ref, remote+"/")
+		}
+	}
+	return ref
+}
+
+func filesIn(files []string, dirs []string) []string {
+	matched := []string{}
+	for _, file := range files {
+		for _, dir := range dirs {
+			if filepath.Dir(file) == dir || strings.HasPrefix(file, dir+"/") {
+				matched = append(matched, file)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %v: %v", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func init() {
+	impactedCmd.Flags().StringVar(&impactedBase, "base", "origin/main", "git ref to diff the working tree against")
+	impactedCmd.Flags().StringVarP(&impactedOutput, "output", "o", "tree", "output format, tree or json")
+	rootCmd.AddCommand(impactedCmd)
+}
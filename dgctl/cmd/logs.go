@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/diggerhq/digger/libs/backendapi"
+	"github.com/spf13/cobra"
+)
+
+var logsFollow bool
+var logsTail int
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs <job-id>",
+	Short: "Show the logs of a digger job",
+	Long: `Show the stdout and stderr a digger job streamed to the backend. Use --follow to keep
+printing output until the job finishes. Requires DIGGER_BACKEND_URL and DIGGER_TOKEN to be set.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		diggerHostname := os.Getenv("DIGGER_BACKEND_URL")
+		token := os.Getenv("DIGGER_TOKEN")
+		if diggerHostname == "" || token == "" {
+			log.Printf("missing variable: DIGGER_BACKEND_URL and DIGGER_TOKEN are required")
+			os.Exit(1)
+		}
+		backendApi := backendapi.DiggerApi{DiggerHost: diggerHostname, AuthToken: token, HttpClient: http.DefaultClient}
+
+		jobId := args[0]
+		afterSeq := 0
+		tail := logsTail
+		for {
+			logs, err := backendApi.GetJobLogs(jobId, afterSeq, tail)
+			if err != nil {
+				log.Printf("could not fetch job logs: %v", err)
+				os.Exit(1)
+			}
+			tail = 0
+			for _, chunk := range logs.Chunks {
+				if chunk.Stream == backendapi.JobLogStderr {
+					fmt.Fprint(os.Stderr, chunk.Content)
+				} else {
+					fmt.Fprint(os.Stdout, chunk.Content)
+				}
+			}
+			afterSeq = logs.NextSeq
+
+			if logs.Complete || (!logsFollow && len(logs.Chunks) == 0) {
+				if logs.Truncated {
+					log.Printf("job logs were truncated by the backend")
+				}
+				return
+			}
+			if len(logs.Chunks) == 0 {
+				time.Sleep(2 * time.Second)
+			}
+		}
+	},
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep printing logs until the job finishes")
+	logsCmd.Flags().IntVar(&logsTail, "tail", 0, "only show the last N log chunks")
+	rootCmd.AddCommand(logsCmd)
+}
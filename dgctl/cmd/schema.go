@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/diggerhq/digger/libs/digger_config"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of digger.yml",
+	Long: `Print a JSON Schema of digger.yml, for editors to validate and autocomplete the file, e.g.
+
+  dgctl schema > digger.schema.json
+
+and reference it from the yaml language server with
+  # yaml-language-server: $schema=./digger.schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := digger_config.DiggerConfigJsonSchema()
+		if err != nil {
+			log.Printf("could not generate schema: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(schema))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
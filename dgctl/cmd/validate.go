@@ -17,21 +17,42 @@ import (
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate a digger.yml file",
-	Long:  `Validate the structure and contents of a digger.yml file.`,
+	Long: `Validate the structure and contents of a digger.yml file. Besides loading the file, validate
+lints it for project dirs that do not exist, patterns matching no files, undefined or unused
+workflows, duplicate project dirs, unknown dependencies and terragrunt blocks finding no modules.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configPath := "./"
 		log.Printf("Starting validation of digger.yml in path: %s", configPath)
 
 		// Load the Digger config file
-		_, configYaml, _, _, err := digger_config.LoadDiggerConfig(configPath, true, nil, nil)
+		config, configYaml, _, _, loadErr := digger_config.LoadDiggerConfig(configPath, true, nil, nil)
+		if loadErr != nil {
+			log.Printf("Error loading digger.yml: %v", loadErr)
+			config = nil
+		}
+
+		issues, err := digger_config.LintDiggerConfig(configPath, config)
 		if err != nil {
-			log.Printf("Error loading digger.yml: %v", err)
 			fmt.Fprintf(os.Stderr, "Invalid digger config file: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Log success message
-		log.Printf("digger.yml loaded successfully.")
+		errorCount := 0
+		for _, issue := range issues {
+			if issue.Severity == digger_config.LintError {
+				errorCount++
+			}
+			fmt.Println(issue.String())
+		}
+		if loadErr != nil {
+			fmt.Fprintf(os.Stderr, "Invalid digger config file: %v\n", loadErr)
+			os.Exit(1)
+		}
+		if errorCount > 0 {
+			fmt.Fprintf(os.Stderr, "%d errors, %d warnings\n", errorCount, len(issues)-errorCount)
+			os.Exit(1)
+		}
+		log.Printf("digger.yml loaded successfully, %d warnings.", len(issues))
 
 		// Display the configuration in a pretty JSON format
 		prettyConfig, err := json.MarshalIndent(configYaml, "", "\t")
@@ -2,37 +2,16 @@ package policy
 
 import (
 	"github.com/diggerhq/digger/libs/git_utils"
-	"github.com/samber/lo"
-	"os"
-	"path"
-	"path/filepath"
-	"slices"
-	"strings"
+	"github.com/diggerhq/digger/libs/policy"
 )
 
-const DefaultAccessPolicy = `
-package digger
-default allow = true
-allow = (count(input.planPolicyViolations) == 0)
-`
+const DefaultAccessPolicy = policy.DefaultAccessPolicy
 
 type DiggerRepoPolicyProvider struct {
 	ManagementRepoUrl string
 	GitToken          string
 }
 
-func getContents(filePath string) (string, error) {
-	if _, err := os.Stat(filePath); err != nil {
-		return "", err
-	}
-
-	contents, err := os.ReadFile(filePath)
-	if err != nil {
-		return "", err
-	}
-	return string(contents), nil
-}
-
 // GetPrefixesForPath
 // @path is the total path example /dev/vpc/subnets
 // @filename is the name of the file to search for example access.rego
@@ -41,53 +20,15 @@ func getContents(filePath string) (string, error) {
 // /dev/vpc/access.rego
 // /dev/access.rego
 func GetPrefixesForPath(path string, fileName string) []string {
-	var prefixes []string
-	parts := strings.Split(filepath.Clean(path), string(filepath.Separator))
-	for i := range parts {
-		prefixes = append(prefixes, filepath.Join(parts[:i+1]...))
-	}
-
-	slices.Reverse(prefixes)
-	prefixes = lo.FilterMap(prefixes, func(item string, index int) (string, bool) {
-		// if input path was absolute then result should be absolute and ignore last item ""
-		if parts[0] == "" {
-			return string(filepath.Separator) + item + string(filepath.Separator) + fileName, index < len(prefixes)-1
-		} else {
-			return item + string(filepath.Separator) + fileName, index < len(prefixes)
-		}
-	})
-
-	return prefixes
+	return policy.GetPrefixesForPath(path, fileName)
 }
 
 func (p DiggerRepoPolicyProvider) getPolicyFileContents(repo string, projectName string, projectDir string, fileName string) (string, error) {
 	var contents string
 	err := git_utils.CloneGitRepoAndDoAction(p.ManagementRepoUrl, "main", "", p.GitToken, "", func(basePath string) error {
-		// we start with the project directory path prefixes as the highest priority
-		prefixes := GetPrefixesForPath(path.Join(basePath, projectDir), fileName)
-
-		// we also add a known location as a least priority item
-		orgAccesspath := path.Join(basePath, "policies", fileName)
-		repoAccesspath := path.Join(basePath, "policies", repo, fileName)
-		projectAccessPath := path.Join(basePath, "policies", repo, projectName, fileName)
-		prefixes = append(prefixes, projectAccessPath)
-		prefixes = append(prefixes, repoAccesspath)
-		prefixes = append(prefixes, orgAccesspath)
-
-		for _, pathPrefix := range prefixes {
-			var err error
-			contents, err = getContents(pathPrefix)
-			if err == nil {
-				return nil
-			}
-			if os.IsNotExist(err) {
-				continue
-			} else {
-				return err
-			}
-		}
-
-		return nil
+		var err error
+		contents, err = policy.FindRepoPolicyFile(basePath, repo, projectName, projectDir, fileName)
+		return err
 	})
 	if err != nil {
 		return "", err
@@ -115,6 +56,35 @@ func (p DiggerRepoPolicyProvider) GetPlanPolicy(organisation string, repository
 	return policy, nil
 }
 
+func (p DiggerRepoPolicyProvider) getPolicyLayers(repo string, projectName string, projectDir string, fileName string) ([]policy.PolicyLayer, error) {
+	var layers []policy.PolicyLayer
+	err := git_utils.CloneGitRepoAndDoAction(p.ManagementRepoUrl, "main", "", p.GitToken, "", func(basePath string) error {
+		var err error
+		layers, err = policy.FindRepoPolicyLayers(basePath, repo, projectName, projectDir, fileName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+// GetAccessPolicyLayers returns every access policy that applies to the project, or the default policy if there are none
+func (p DiggerRepoPolicyProvider) GetAccessPolicyLayers(organisation string, repo string, projectName string, projectDir string) ([]policy.PolicyLayer, error) {
+	layers, err := p.getPolicyLayers(repo, projectName, projectDir, "access.rego")
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return []policy.PolicyLayer{{Name: policy.DefaultPolicyLayer, Policy: DefaultAccessPolicy}}, nil
+	}
+	return layers, nil
+}
+
+func (p DiggerRepoPolicyProvider) GetPlanPolicyLayers(organisation string, repository string, projectname string, projectDir string) ([]policy.PolicyLayer, error) {
+	return p.getPolicyLayers(repository, projectname, projectDir, "plan.rego")
+}
+
 func (p DiggerRepoPolicyProvider) GetDriftPolicy() (string, error) {
 	return "", nil
 
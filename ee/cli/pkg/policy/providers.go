@@ -31,11 +31,16 @@ func (p AdvancedPolicyProvider) GetPolicyProvider(policySpec lib_spec.PolicySpec
 		if token == "" {
 			return nil, fmt.Errorf("failed to get managent repo policy provider: %v not specified", tokenName)
 		}
+		bundles, err := policy.LoadPolicyBundlesFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy bundles: %v", err)
+		}
 		return policy.DiggerPolicyChecker{
 			PolicyProvider: DiggerRepoPolicyProvider{
 				ManagementRepoUrl: managementRepo,
 				GitToken:          token,
 			},
+			Bundles: bundles,
 		}, nil
 	}
 
@@ -52,11 +57,16 @@ func (p PolicyCheckerProviderAdvanced) Get(hostname string, organisationName str
 		if token == "" {
 			return nil, fmt.Errorf("failed to get managent repo policy provider: GITHUB_TOKEN not specified")
 		}
+		bundles, err := policy.LoadPolicyBundlesFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy bundles: %v", err)
+		}
 		return policy.DiggerPolicyChecker{
 			PolicyProvider: DiggerRepoPolicyProvider{
 				ManagementRepoUrl: managementRepo,
 				GitToken:          token,
 			},
+			Bundles: bundles,
 		}, nil
 	}
 	return policy.PolicyCheckerProviderBasic{}.Get(hostname, organisationName, authToken)
@@ -2,6 +2,7 @@ package backendapi
 
 import (
 	"github.com/diggerhq/digger/libs/iac_utils"
+	"github.com/diggerhq/digger/libs/policy"
 	"github.com/diggerhq/digger/libs/scheduler"
 	"time"
 )
@@ -9,6 +10,10 @@ import (
 type Api interface {
 	ReportProject(repo string, projectName string, configuration string) error
 	ReportProjectJobStatus(repo string, projectName string, jobId string, status string, timestamp time.Time, summary *iac_utils.IacSummary, planJson string, PrCommentUrl string, PrCommentId string, terraformOutput string, iacUtils iac_utils.IacUtils) (*scheduler.SerializedBatch, error)
+	ReportPolicyOverride(repo string, projectName string, jobId string, override policy.PolicyOverride) error
+	ReportJobOutputs(repo string, projectName string, jobId string, outputs scheduler.TerraformOutputs) error
+	UploadJobLogs(repo string, projectName string, jobId string, chunks []JobLogChunk, complete bool) error
+	UploadJobPlanOutput(repo string, projectName string, jobId string, output string) (string, error)
 	UploadJobArtefact(zipLocation string) (*int, *string, error)
 	DownloadJobArtefact(downloadTo string) (*string, error)
 }
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/diggerhq/digger/libs/iac_utils"
+	"github.com/diggerhq/digger/libs/policy"
 	"github.com/diggerhq/digger/libs/scheduler"
 )
 
@@ -32,6 +33,22 @@ func (n NoopApi) ReportProjectJobStatus(repo string, projectName string, jobId s
 	return nil, nil
 }
 
+func (n NoopApi) ReportPolicyOverride(repo string, projectName string, jobId string, override policy.PolicyOverride) error {
+	return nil
+}
+
+func (n NoopApi) ReportJobOutputs(repo string, projectName string, jobId string, outputs scheduler.TerraformOutputs) error {
+	return nil
+}
+
+func (n NoopApi) UploadJobLogs(repo string, projectName string, jobId string, chunks []JobLogChunk, complete bool) error {
+	return nil
+}
+
+func (n NoopApi) UploadJobPlanOutput(repo string, projectName string, jobId string, output string) (string, error) {
+	return "", nil
+}
+
 func (n NoopApi) UploadJobArtefact(zipLocation string) (*int, *string, error) {
 	return nil, nil, nil
 }
@@ -161,6 +178,195 @@ func (d DiggerApi) ReportProjectJobStatus(repo string, projectName string, jobId
 	return &response, nil
 }
 
+// ReportPolicyOverride records on the job that its soft plan policy denials were overridden
+func (d DiggerApi) ReportPolicyOverride(repo string, projectName string, jobId string, override policy.PolicyOverride) error {
+	repoNameForBackendReporting := strings.ReplaceAll(repo, "/", "-")
+	u, err := url.Parse(d.DiggerHost)
+	if err != nil {
+		slog.Error("not able to parse digger cloud url", "error", err)
+		return fmt.Errorf("not able to parse digger cloud url: %v", err)
+	}
+	u.Path = filepath.Join(u.Path, "repos", repoNameForBackendReporting, "projects", projectName, "jobs", jobId, "policy-override")
+
+	jsonData, err := json.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("not able to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error while creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", d.AuthToken))
+
+	resp, err := d.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status when reporting a policy override: %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// UploadJobLogs appends output chunks to a job's log; complete marks the end of the log
+func (d DiggerApi) UploadJobLogs(repo string, projectName string, jobId string, chunks []JobLogChunk, complete bool) error {
+	repoNameForBackendReporting := strings.ReplaceAll(repo, "/", "-")
+	u, err := url.Parse(d.DiggerHost)
+	if err != nil {
+		return fmt.Errorf("not able to parse digger cloud url: %v", err)
+	}
+	u.Path = filepath.Join(u.Path, "repos", repoNameForBackendReporting, "projects", projectName, "jobs", jobId, "logs")
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"chunks":   chunks,
+		"complete": complete,
+	})
+	if err != nil {
+		return fmt.Errorf("not able to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error while creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", d.AuthToken))
+
+	resp, err := d.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status when uploading job logs: %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// ReportJobOutputs records the outputs of an applied project on the job, for the jobs depending on it
+func (d DiggerApi) ReportJobOutputs(repo string, projectName string, jobId string, outputs scheduler.TerraformOutputs) error {
+	repoNameForBackendReporting := strings.ReplaceAll(repo, "/", "-")
+	u, err := url.Parse(d.DiggerHost)
+	if err != nil {
+		return fmt.Errorf("not able to parse digger cloud url: %v", err)
+	}
+	u.Path = filepath.Join(u.Path, "repos", repoNameForBackendReporting, "projects", projectName, "jobs", jobId, "outputs")
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"outputs": outputs,
+	})
+	if err != nil {
+		return fmt.Errorf("not able to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error while creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", d.AuthToken))
+
+	resp, err := d.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status when reporting job outputs: %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// UploadJobPlanOutput hosts a plan too long for a PR comment on the backend and returns a signed
+// link to it, or an error when the backend does not host plans
+func (d DiggerApi) UploadJobPlanOutput(repo string, projectName string, jobId string, output string) (string, error) {
+	repoNameForBackendReporting := strings.ReplaceAll(repo, "/", "-")
+	u, err := url.Parse(d.DiggerHost)
+	if err != nil {
+		return "", fmt.Errorf("not able to parse digger cloud url: %v", err)
+	}
+	u.Path = filepath.Join(u.Path, "repos", repoNameForBackendReporting, "projects", projectName, "jobs", jobId, "plan-output")
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"output": output,
+	})
+	if err != nil {
+		return "", fmt.Errorf("not able to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error while creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", d.AuthToken))
+
+	resp, err := d.HttpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status when uploading plan output: %v", resp.StatusCode)
+	}
+
+	var response struct {
+		Url string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("could not decode plan output response: %v", err)
+	}
+	return response.Url, nil
+}
+
+// GetJobLogs fetches the chunks of a job's log after afterSeq, or only the last tail chunks when tail is set
+func (d DiggerApi) GetJobLogs(jobId string, afterSeq int, tail int) (*JobLogs, error) {
+	u, err := url.Parse(d.DiggerHost)
+	if err != nil {
+		return nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
+	}
+	u.Path = filepath.Join(u.Path, "jobs", jobId, "logs")
+	query := u.Query()
+	query.Set("after", fmt.Sprintf("%v", afterSeq))
+	if tail > 0 {
+		query.Set("tail", fmt.Sprintf("%v", tail))
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating request: %v", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", d.AuthToken))
+
+	resp, err := d.HttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status when fetching job logs: %v", resp.StatusCode)
+	}
+
+	var logs JobLogs
+	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
+		return nil, fmt.Errorf("could not parse job logs: %v", err)
+	}
+	return &logs, nil
+}
+
 func (d DiggerApi) UploadJobArtefact(zipLocation string) (*int, *string, error) {
 	u, err := url.Parse(d.DiggerHost)
 	if err != nil {
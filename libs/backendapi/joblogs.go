@@ -0,0 +1,255 @@
+package backendapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JobLogStdout = "stdout"
+	JobLogStderr = "stderr"
+
+	// MaxJobLogChunkBytes caps the content of a single chunk, longer output is split
+	MaxJobLogChunkBytes = 64 * 1024
+	// maxPendingJobLogChunks bounds what is kept in memory while the backend is unreachable
+	maxPendingJobLogChunks = 256
+)
+
+// JobLogChunk is a piece of a job's stdout or stderr, numbered in the order it was written
+type JobLogChunk struct {
+	Seq       int       `json:"seq"`
+	Stream    string    `json:"stream"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JobLogs is a page of a job's logs as returned by the backend
+type JobLogs struct {
+	JobId     string        `json:"job_id"`
+	Status    string        `json:"status"`
+	Chunks    []JobLogChunk `json:"chunks"`
+	NextSeq   int           `json:"next_seq"`
+	Truncated bool          `json:"truncated"`
+	Complete  bool          `json:"complete"`
+}
+
+const redacted = "<REDACTED>"
+
+// jobLogSecretPatterns match credentials commonly printed by terraform and CI tooling.
+// The first group is kept so that the key name stays readable.
+var jobLogSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`()\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`()\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`()\bglpat-[A-Za-z0-9_\-]{20,}\b`),
+	regexp.MustCompile(`(?s)()-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|token)\s+)[^\s"']+`),
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api_key|apikey|access_key|secret_key)["']?\s*[=:]\s*["']?)[^\s"',;]+`),
+}
+
+var secretEnvName = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|PRIVATE_KEY|ACCESS_KEY|CREDENTIALS|API_KEY)`)
+
+// SecretValuesFromEnv returns the values of environment variables whose names look like credentials
+func SecretValuesFromEnv() []string {
+	var secrets []string
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if len(value) >= 8 && secretEnvName.MatchString(name) {
+			secrets = append(secrets, value)
+		}
+	}
+	// replace longer values first so a secret containing another is fully redacted
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// RedactJobLog masks the given secret values and anything matching known credential patterns
+func RedactJobLog(content string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			content = strings.ReplaceAll(content, secret, redacted)
+		}
+	}
+	for _, pattern := range jobLogSecretPatterns {
+		content = pattern.ReplaceAllString(content, "${1}"+redacted)
+	}
+	return content
+}
+
+// JobLogStreamer buffers a job's output and ships it to the backend in redacted chunks.
+// Output is cut on line boundaries so a secret is never split across two chunks.
+type JobLogStreamer struct {
+	FlushInterval time.Duration
+
+	api         Api
+	repo        string
+	projectName string
+	jobId       string
+	secrets     []string
+
+	mu      sync.Mutex
+	partial map[string]*bytes.Buffer
+	pending []JobLogChunk
+	seq     int
+	// the marker chunk replacing dropped chunks and how many it stands for
+	markerSeq     int
+	markerDropped int
+
+	flushMu sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func NewJobLogStreamer(api Api, repo string, projectName string, jobId string) *JobLogStreamer {
+	return &JobLogStreamer{
+		FlushInterval: 5 * time.Second,
+		api:           api,
+		repo:          repo,
+		projectName:   projectName,
+		jobId:         jobId,
+		secrets:       SecretValuesFromEnv(),
+		partial:       map[string]*bytes.Buffer{},
+	}
+}
+
+type jobLogWriter struct {
+	streamer *JobLogStreamer
+	stream   string
+}
+
+func (w jobLogWriter) Write(p []byte) (int, error) {
+	w.streamer.write(w.stream, p)
+	return len(p), nil
+}
+
+// Writer returns a writer that appends to the given stream, stdout or stderr
+func (s *JobLogStreamer) Writer(stream string) io.Writer {
+	return jobLogWriter{streamer: s, stream: stream}
+}
+
+func (s *JobLogStreamer) write(stream string, p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.partial[stream]
+	if !ok {
+		buf = &bytes.Buffer{}
+		s.partial[stream] = buf
+	}
+	buf.Write(p)
+
+	end := bytes.LastIndexByte(buf.Bytes(), '\n') + 1
+	if end == 0 && buf.Len() < MaxJobLogChunkBytes {
+		return
+	}
+	if end == 0 {
+		end = buf.Len()
+	}
+	s.appendLocked(stream, string(buf.Next(end)))
+}
+
+// appendLocked adds complete lines to the pending chunks, merging with the last chunk of the same stream
+func (s *JobLogStreamer) appendLocked(stream string, content string) {
+	content = RedactJobLog(content, s.secrets)
+	for len(content) > 0 {
+		if n := len(s.pending); n > 0 && s.pending[n-1].Stream == stream && len(s.pending[n-1].Content) < MaxJobLogChunkBytes {
+			last := &s.pending[n-1]
+			take := min(len(content), MaxJobLogChunkBytes-len(last.Content))
+			last.Content += content[:take]
+			content = content[take:]
+			continue
+		}
+		take := min(len(content), MaxJobLogChunkBytes)
+		s.seq++
+		s.pending = append(s.pending, JobLogChunk{Seq: s.seq, Stream: stream, Content: content[:take], Timestamp: time.Now()})
+		content = content[take:]
+	}
+	s.trimLocked()
+}
+
+// trimLocked bounds the pending chunks by replacing the oldest ones with a marker chunk
+// saying how many were dropped, so that the gap is visible in the stored log
+func (s *JobLogStreamer) trimLocked() {
+	excess := len(s.pending) - maxPendingJobLogChunks
+	if excess <= 0 {
+		return
+	}
+	// drop one more chunk to make room for the marker
+	dropped := s.pending[:excess+1]
+	count := len(dropped)
+	if dropped[0].Seq == s.markerSeq {
+		// the oldest chunk is the marker of an earlier drop that was not sent yet
+		count += s.markerDropped - 1
+	}
+	last := dropped[len(dropped)-1]
+	s.markerSeq, s.markerDropped = last.Seq, count
+	marker := JobLogChunk{Seq: last.Seq, Stream: JobLogStderr, Content: fmt.Sprintf("[%d log chunks dropped]\n", count), Timestamp: last.Timestamp}
+	s.pending = append([]JobLogChunk{marker}, s.pending[excess+1:]...)
+}
+
+// Start flushes buffered output every FlushInterval until Close is called
+func (s *JobLogStreamer) Start() {
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go func() {
+		defer close(s.stopped)
+		ticker := time.NewTicker(s.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.flush(false)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close sends everything still buffered, including unterminated lines, and marks the log complete
+func (s *JobLogStreamer) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.stopped
+		s.stop = nil
+	}
+	s.mu.Lock()
+	for _, stream := range []string{JobLogStdout, JobLogStderr} {
+		if buf, ok := s.partial[stream]; ok && buf.Len() > 0 {
+			s.appendLocked(stream, buf.String())
+			buf.Reset()
+		}
+	}
+	s.mu.Unlock()
+	return s.flush(true)
+}
+
+func (s *JobLogStreamer) flush(complete bool) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	chunks := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(chunks) == 0 && !complete {
+		return nil
+	}
+
+	err := s.api.UploadJobLogs(s.repo, s.projectName, s.jobId, chunks, complete)
+	if err != nil {
+		// keep the chunks for the next attempt, the backend ignores sequence numbers it already has
+		s.mu.Lock()
+		s.pending = append(chunks, s.pending...)
+		s.trimLocked()
+		s.mu.Unlock()
+		slog.Warn("could not stream job logs to backend", "jobId", s.jobId, "error", err)
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package backendapi
 
 import (
 	"github.com/diggerhq/digger/libs/iac_utils"
+	"github.com/diggerhq/digger/libs/policy"
 	"github.com/diggerhq/digger/libs/scheduler"
 	"time"
 )
@@ -17,6 +18,22 @@ func (t MockBackendApi) ReportProjectJobStatus(repo string, projectName string,
 	return nil, nil
 }
 
+func (t MockBackendApi) ReportPolicyOverride(repo string, projectName string, jobId string, override policy.PolicyOverride) error {
+	return nil
+}
+
+func (t MockBackendApi) ReportJobOutputs(repo string, projectName string, jobId string, outputs scheduler.TerraformOutputs) error {
+	return nil
+}
+
+func (t MockBackendApi) UploadJobLogs(repo string, projectName string, jobId string, chunks []JobLogChunk, complete bool) error {
+	return nil
+}
+
+func (t MockBackendApi) UploadJobPlanOutput(repo string, projectName string, jobId string, output string) (string, error) {
+	return "", nil
+}
+
 func (t MockBackendApi) UploadJobArtefact(zipLocation string) (*int, *string, error) {
 	return nil, nil, nil
 }
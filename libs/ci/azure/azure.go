@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"github.com/diggerhq/digger/libs/ci"
 	"github.com/diggerhq/digger/libs/scheduler"
+	"html"
 	"strconv"
 	"strings"
 
 	digger_config2 "github.com/diggerhq/digger/libs/digger_config"
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/workitemtracking"
 )
 
 const (
@@ -117,27 +121,120 @@ func (a *Azure) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func NewAzureReposService(patToken string, baseUrl string, projectName string, repositoryId string) (*AzureReposService, error) {
-	client, err := git.NewClient(context.Background(), azuredevops.NewPatConnection(baseUrl, patToken))
+// Reviewer votes in Azure DevOps: 10 approved, 5 approved with suggestions,
+// 0 no vote, -5 waiting for author, -10 rejected
+const azureVoteApprovedWithSuggestions = 5
+
+// azurePageSize is the page size used when listing teams and team members
+const azurePageSize = 100
+
+// azureWorkItemBatchSize is the maximum number of work items fetched per request
+const azureWorkItemBatchSize = 200
+
+// DefaultWorkItemType is the work item type used for issues when none is configured
+const DefaultWorkItemType = "Issue"
 
+func NewAzureReposService(patToken string, baseUrl string, projectName string, repositoryId string) (*AzureReposService, error) {
+	connection := azuredevops.NewPatConnection(baseUrl, patToken)
+	client, err := git.NewClient(context.Background(), connection)
+	if err != nil {
+		return nil, err
+	}
+	coreClient, err := core.NewClient(context.Background(), connection)
+	if err != nil {
+		return nil, err
+	}
+	workItemClient, err := workitemtracking.NewClient(context.Background(), connection)
 	if err != nil {
 		return nil, err
 	}
 	return &AzureReposService{
-		Client:       client,
-		ProjectName:  projectName,
-		RepositoryId: repositoryId,
+		Client:         client,
+		CoreClient:     coreClient,
+		WorkItemClient: workItemClient,
+		ProjectName:    projectName,
+		RepositoryId:   repositoryId,
 	}, nil
 }
 
 type AzureReposService struct {
-	Client       git.Client
-	ProjectName  string
-	RepositoryId string
+	Client         git.Client
+	CoreClient     core.Client
+	WorkItemClient workitemtracking.Client
+	ProjectName    string
+	RepositoryId   string
+	// WorkItemType is the type of work item created for issues, DefaultWorkItemType if empty
+	WorkItemType string
+}
+
+// GetUserTeams returns the names of the project teams the user is a member of.
+// Azure DevOps teams are scoped to a project, so organisation is not used;
+// user is matched against the member's unique name (usually the email) or ID.
+func (a *AzureReposService) GetUserTeams(organisation string, user string) ([]string, error) {
+	teamNames := make([]string, 0)
+	for skip := 0; ; skip += azurePageSize {
+		top := azurePageSize
+		teams, err := a.CoreClient.GetTeams(context.Background(), core.GetTeamsArgs{
+			ProjectId: &a.ProjectName,
+			Top:       &top,
+			Skip:      &skip,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing teams for project %v: %v", a.ProjectName, err)
+		}
+		if teams == nil {
+			break
+		}
+		for _, team := range *teams {
+			if team.Id == nil || team.Name == nil {
+				continue
+			}
+			isMember, err := a.isTeamMember(team.Id.String(), user)
+			if err != nil {
+				return nil, err
+			}
+			if isMember {
+				teamNames = append(teamNames, *team.Name)
+			}
+		}
+		if len(*teams) < azurePageSize {
+			break
+		}
+	}
+	return teamNames, nil
 }
 
-func (a *AzureReposService) GetUserTeams(organisation string, user string) ([]string, error) {
-	return make([]string, 0), nil
+func (a *AzureReposService) isTeamMember(teamId string, user string) (bool, error) {
+	for skip := 0; ; skip += azurePageSize {
+		top := azurePageSize
+		members, err := a.CoreClient.GetTeamMembersWithExtendedProperties(context.Background(), core.GetTeamMembersWithExtendedPropertiesArgs{
+			ProjectId: &a.ProjectName,
+			TeamId:    &teamId,
+			Top:       &top,
+			Skip:      &skip,
+		})
+		if err != nil {
+			return false, fmt.Errorf("error listing members of team %v: %v", teamId, err)
+		}
+		if members == nil {
+			return false, nil
+		}
+		for _, member := range *members {
+			identity := member.Identity
+			if identity == nil {
+				continue
+			}
+			if identity.UniqueName != nil && strings.EqualFold(*identity.UniqueName, user) {
+				return true, nil
+			}
+			if identity.Id != nil && strings.EqualFold(*identity.Id, user) {
+				return true, nil
+			}
+		}
+		if len(*members) < azurePageSize {
+			return false, nil
+		}
+	}
 }
 
 func (a *AzureReposService) GetChangedFiles(prNumber int) ([]string, error) {
@@ -188,16 +285,129 @@ func (a *AzureReposService) PublishComment(prNumber int, comment string) (*ci.Co
 	return nil, err
 }
 
+// ListIssues returns the open work items of the project. Work items are used
+// as issues since Azure Repos has no issue tracker of its own.
 func (svc *AzureReposService) ListIssues() ([]*ci.Issue, error) {
-	return nil, fmt.Errorf("implement me")
+	query := "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project " +
+		"AND [System.State] NOT IN ('Closed', 'Done', 'Removed', 'Resolved') ORDER BY [System.Id]"
+	result, err := svc.WorkItemClient.QueryByWiql(context.Background(), workitemtracking.QueryByWiqlArgs{
+		Wiql:    &workitemtracking.Wiql{Query: &query},
+		Project: &svc.ProjectName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying work items: %v", err)
+	}
+	issues := make([]*ci.Issue, 0)
+	if result == nil || result.WorkItems == nil {
+		return issues, nil
+	}
+
+	ids := make([]int, 0, len(*result.WorkItems))
+	for _, ref := range *result.WorkItems {
+		if ref.Id != nil {
+			ids = append(ids, *ref.Id)
+		}
+	}
+
+	fields := []string{"System.Title", "System.Description"}
+	for start := 0; start < len(ids); start += azureWorkItemBatchSize {
+		end := min(start+azureWorkItemBatchSize, len(ids))
+		batch := ids[start:end]
+		workItems, err := svc.WorkItemClient.GetWorkItems(context.Background(), workitemtracking.GetWorkItemsArgs{
+			Ids:     &batch,
+			Project: &svc.ProjectName,
+			Fields:  &fields,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching work items: %v", err)
+		}
+		if workItems == nil {
+			continue
+		}
+		for _, workItem := range *workItems {
+			if workItem.Id == nil {
+				continue
+			}
+			issue := &ci.Issue{ID: int64(*workItem.Id)}
+			if workItem.Fields != nil {
+				if title, ok := (*workItem.Fields)["System.Title"].(string); ok {
+					issue.Title = title
+				}
+				if description, ok := (*workItem.Fields)["System.Description"].(string); ok {
+					issue.Body = description
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
 }
 
+// PublishIssue creates a work item and returns its ID. Labels become work item tags.
 func (svc *AzureReposService) PublishIssue(title string, body string, labels *[]string) (int64, error) {
-	return 0, fmt.Errorf("implement me")
+	document := workItemDocument(title, body)
+	if labels != nil && len(*labels) > 0 {
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr("/fields/System.Tags"),
+			Value: strings.Join(*labels, "; "),
+		})
+	}
+	workItemType := svc.WorkItemType
+	if workItemType == "" {
+		workItemType = DefaultWorkItemType
+	}
+	workItem, err := svc.WorkItemClient.CreateWorkItem(context.Background(), workitemtracking.CreateWorkItemArgs{
+		Document: &document,
+		Project:  &svc.ProjectName,
+		Type:     &workItemType,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error creating work item: %v", err)
+	}
+	if workItem == nil || workItem.Id == nil {
+		return 0, fmt.Errorf("work item created without an ID")
+	}
+	return int64(*workItem.Id), nil
 }
 
+// UpdateIssue replaces the title and description of a work item
 func (svc *AzureReposService) UpdateIssue(ID int64, title string, body string) (int64, error) {
-	return 0, fmt.Errorf("implement me")
+	document := workItemDocument(title, body)
+	id := int(ID)
+	workItem, err := svc.WorkItemClient.UpdateWorkItem(context.Background(), workitemtracking.UpdateWorkItemArgs{
+		Document: &document,
+		Id:       &id,
+		Project:  &svc.ProjectName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error updating work item %v: %v", ID, err)
+	}
+	if workItem == nil || workItem.Id == nil {
+		return ID, nil
+	}
+	return int64(*workItem.Id), nil
+}
+
+// workItemDocument builds the patch setting a work item's title and description.
+// The description field is HTML, so the body is escaped and kept preformatted.
+func workItemDocument(title string, body string) []webapi.JsonPatchOperation {
+	return []webapi.JsonPatchOperation{
+		{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr("/fields/System.Title"),
+			Value: title,
+		},
+		{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr("/fields/System.Description"),
+			Value: "<pre>" + html.EscapeString(body) + "</pre>",
+		},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
 }
 
 func (a *AzureReposService) SetStatus(prNumber int, status string, statusContext string) error {
@@ -315,9 +525,26 @@ func (a *AzureReposService) IsClosed(prNumber int) (bool, error) {
 	return *pullRequest.Status == git.PullRequestStatusValues.Abandoned, nil
 }
 
-// TODO implement me
+// IsDivergedFromBranch reports whether sourceBranch is both ahead of and behind targetBranch
 func (a *AzureReposService) IsDivergedFromBranch(sourceBranch string, targetBranch string) (bool, error) {
-	return false, nil
+	sourceBranch = strings.TrimPrefix(sourceBranch, "refs/heads/")
+	targetBranch = strings.TrimPrefix(targetBranch, "refs/heads/")
+	stats, err := a.Client.GetBranch(context.Background(), git.GetBranchArgs{
+		RepositoryId: &a.RepositoryId,
+		Name:         &sourceBranch,
+		Project:      &a.ProjectName,
+		BaseVersionDescriptor: &git.GitVersionDescriptor{
+			Version:     &targetBranch,
+			VersionType: &git.GitVersionTypeValues.Branch,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error comparing branch %v with %v: %v", sourceBranch, targetBranch, err)
+	}
+	if stats == nil || stats.AheadCount == nil || stats.BehindCount == nil {
+		return false, nil
+	}
+	return *stats.AheadCount > 0 && *stats.BehindCount > 0, nil
 }
 
 func (a *AzureReposService) IsMerged(prNumber int) (bool, error) {
@@ -331,6 +558,31 @@ func (a *AzureReposService) IsMerged(prNumber int) (bool, error) {
 	return *pullRequest.Status == git.PullRequestStatusValues.Completed, nil
 }
 
+func (a *AzureReposService) GetPullRequestInfo(prNumber int) (*ci.PullRequestInfo, error) {
+	pullRequest, err := a.Client.GetPullRequestById(context.Background(), git.GetPullRequestByIdArgs{
+		Project:       &a.ProjectName,
+		PullRequestId: &prNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := &ci.PullRequestInfo{Labels: make([]string, 0)}
+	if pullRequest.CreatedBy != nil && pullRequest.CreatedBy.UniqueName != nil {
+		info.Author = *pullRequest.CreatedBy.UniqueName
+	}
+	if pullRequest.Labels != nil {
+		for _, label := range *pullRequest.Labels {
+			if label.Name != nil {
+				info.Labels = append(info.Labels, *label.Name)
+			}
+		}
+	}
+	if pullRequest.TargetRefName != nil {
+		info.TargetBranch = strings.TrimPrefix(*pullRequest.TargetRefName, "refs/heads/")
+	}
+	return info, nil
+}
+
 func (a *AzureReposService) EditComment(prNumber int, id string, comment string) error {
 	threadId, err := strconv.Atoi(id)
 	if err != nil {
@@ -382,18 +634,45 @@ func (a *AzureReposService) GetComments(prNumber int) ([]ci.Comment, error) {
 	}
 	var result []ci.Comment
 	for _, comment := range *comments {
+		var author string
+		if comment.Author != nil && comment.Author.UniqueName != nil {
+			author = *comment.Author.UniqueName
+		}
 		result = append(result, ci.Comment{
-			Id:   strconv.Itoa(*comment.Id),
-			Body: comment.Content,
+			Id:     strconv.Itoa(*comment.Id),
+			Body:   comment.Content,
+			Author: author,
 		})
 	}
 	return result, nil
 
 }
 
+// GetApprovals returns the unique names of reviewers who approved the pull
+// request, with or without suggestions. Group reviewers are skipped since
+// their vote only reflects the votes of their members.
 func (svc *AzureReposService) GetApprovals(prNumber int) ([]string, error) {
 	approvals := make([]string, 0)
-	// TODO: implement me
+	reviewers, err := svc.Client.GetPullRequestReviewers(context.Background(), git.GetPullRequestReviewersArgs{
+		RepositoryId:  &svc.RepositoryId,
+		PullRequestId: &prNumber,
+		Project:       &svc.ProjectName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing reviewers of pull request %v: %v", prNumber, err)
+	}
+	if reviewers == nil {
+		return approvals, nil
+	}
+	for _, reviewer := range *reviewers {
+		if reviewer.IsContainer != nil && *reviewer.IsContainer {
+			continue
+		}
+		if reviewer.Vote == nil || *reviewer.Vote < azureVoteApprovedWithSuggestions || reviewer.UniqueName == nil {
+			continue
+		}
+		approvals = append(approvals, *reviewer.UniqueName)
+	}
 	return approvals, nil
 }
 
@@ -467,6 +746,9 @@ func ConvertAzureEventToCommands(parseAzureContext Azure, impactedProjects []dig
 				ProjectWorkspace:   project.Workspace,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestPushed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -506,6 +788,9 @@ func ConvertAzureEventToCommands(parseAzureContext Azure, impactedProjects []dig
 				ProjectWorkspace:   project.Workspace,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestClosed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -546,6 +831,9 @@ func ConvertAzureEventToCommands(parseAzureContext Azure, impactedProjects []dig
 					ProjectWorkspace:   project.Workspace,
 					Terragrunt:         project.Terragrunt,
 					OpenTofu:           project.OpenTofu,
+					TerraformVersion:   project.PinnedTerraformVersion(workflow),
+					OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+					DependencyOutputs:  project.DependencyOutputs,
 					Pulumi:             project.Pulumi,
 					Commands:           workflow.Configuration.OnCommitToDefault,
 					ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -611,6 +899,9 @@ func ConvertAzureEventToCommands(parseAzureContext Azure, impactedProjects []dig
 						ProjectWorkspace:   workspace,
 						Terragrunt:         project.Terragrunt,
 						OpenTofu:           project.OpenTofu,
+						TerraformVersion:   project.PinnedTerraformVersion(workflow),
+						OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+						DependencyOutputs:  project.DependencyOutputs,
 						Pulumi:             project.Pulumi,
 						Commands:           []string{command},
 						ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -1,8 +1,15 @@
 package azure
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/workitemtracking"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetAzureReposContext(t *testing.T) {
@@ -12,3 +19,158 @@ func TestGetAzureReposContext(t *testing.T) {
 	az, _ := GetAzureReposContext(context)
 	assert.Equal(t, "digger plan", az.Event.(AzureCommentEvent).Resource.Comment.Content)
 }
+
+type fakeGitClient struct {
+	git.Client
+	reviewers   []git.IdentityRefWithVote
+	branchStats git.GitBranchStats
+	branchArgs  git.GetBranchArgs
+}
+
+func (f *fakeGitClient) GetPullRequestReviewers(ctx context.Context, args git.GetPullRequestReviewersArgs) (*[]git.IdentityRefWithVote, error) {
+	return &f.reviewers, nil
+}
+
+func (f *fakeGitClient) GetBranch(ctx context.Context, args git.GetBranchArgs) (*git.GitBranchStats, error) {
+	f.branchArgs = args
+	return &f.branchStats, nil
+}
+
+type fakeCoreClient struct {
+	core.Client
+	teams   []core.WebApiTeam
+	members map[string][]webapi.TeamMember
+}
+
+func (f *fakeCoreClient) GetTeams(ctx context.Context, args core.GetTeamsArgs) (*[]core.WebApiTeam, error) {
+	return &f.teams, nil
+}
+
+func (f *fakeCoreClient) GetTeamMembersWithExtendedProperties(ctx context.Context, args core.GetTeamMembersWithExtendedPropertiesArgs) (*[]webapi.TeamMember, error) {
+	members := f.members[*args.TeamId]
+	return &members, nil
+}
+
+type fakeWorkItemClient struct {
+	workitemtracking.Client
+	workItems map[int]map[string]interface{}
+	created   *workitemtracking.CreateWorkItemArgs
+	updated   *workitemtracking.UpdateWorkItemArgs
+}
+
+func (f *fakeWorkItemClient) QueryByWiql(ctx context.Context, args workitemtracking.QueryByWiqlArgs) (*workitemtracking.WorkItemQueryResult, error) {
+	refs := make([]workitemtracking.WorkItemReference, 0)
+	for id := range f.workItems {
+		refs = append(refs, workitemtracking.WorkItemReference{Id: intPtr(id)})
+	}
+	return &workitemtracking.WorkItemQueryResult{WorkItems: &refs}, nil
+}
+
+func (f *fakeWorkItemClient) GetWorkItems(ctx context.Context, args workitemtracking.GetWorkItemsArgs) (*[]workitemtracking.WorkItem, error) {
+	workItems := make([]workitemtracking.WorkItem, 0)
+	for _, id := range *args.Ids {
+		fields := f.workItems[id]
+		workItems = append(workItems, workitemtracking.WorkItem{Id: intPtr(id), Fields: &fields})
+	}
+	return &workItems, nil
+}
+
+func (f *fakeWorkItemClient) CreateWorkItem(ctx context.Context, args workitemtracking.CreateWorkItemArgs) (*workitemtracking.WorkItem, error) {
+	f.created = &args
+	return &workitemtracking.WorkItem{Id: intPtr(42)}, nil
+}
+
+func (f *fakeWorkItemClient) UpdateWorkItem(ctx context.Context, args workitemtracking.UpdateWorkItemArgs) (*workitemtracking.WorkItem, error) {
+	f.updated = &args
+	return &workitemtracking.WorkItem{Id: args.Id}, nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestGetApprovals(t *testing.T) {
+	client := &fakeGitClient{reviewers: []git.IdentityRefWithVote{
+		{UniqueName: stringPtr("alice@example.com"), Vote: intPtr(10)},
+		{UniqueName: stringPtr("bob@example.com"), Vote: intPtr(5)},
+		{UniqueName: stringPtr("carol@example.com"), Vote: intPtr(0)},
+		{UniqueName: stringPtr("dave@example.com"), Vote: intPtr(-10)},
+		{UniqueName: stringPtr("[project]\\Reviewers"), Vote: intPtr(10), IsContainer: boolPtr(true)},
+	}}
+	svc := &AzureReposService{Client: client, ProjectName: "project", RepositoryId: "repo"}
+
+	approvals, err := svc.GetApprovals(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, approvals)
+}
+
+func TestGetUserTeams(t *testing.T) {
+	platform, apps := uuid.New(), uuid.New()
+	client := &fakeCoreClient{
+		teams: []core.WebApiTeam{
+			{Id: &platform, Name: stringPtr("platform")},
+			{Id: &apps, Name: stringPtr("apps")},
+		},
+		members: map[string][]webapi.TeamMember{
+			platform.String(): {{Identity: &webapi.IdentityRef{UniqueName: stringPtr("Alice@example.com")}}},
+			apps.String():     {{Identity: &webapi.IdentityRef{UniqueName: stringPtr("bob@example.com")}}},
+		},
+	}
+	svc := &AzureReposService{CoreClient: client, ProjectName: "project"}
+
+	teams, err := svc.GetUserTeams("org", "alice@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, teams)
+
+	teams, err = svc.GetUserTeams("org", "eve@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, teams)
+}
+
+func TestIsDivergedFromBranch(t *testing.T) {
+	client := &fakeGitClient{}
+	svc := &AzureReposService{Client: client, ProjectName: "project", RepositoryId: "repo"}
+
+	client.branchStats = git.GitBranchStats{AheadCount: intPtr(2), BehindCount: intPtr(0)}
+	diverged, err := svc.IsDivergedFromBranch("feature", "refs/heads/main")
+	assert.NoError(t, err)
+	assert.False(t, diverged)
+	assert.Equal(t, "feature", *client.branchArgs.Name)
+	assert.Equal(t, "main", *client.branchArgs.BaseVersionDescriptor.Version)
+
+	client.branchStats = git.GitBranchStats{AheadCount: intPtr(2), BehindCount: intPtr(3)}
+	diverged, err = svc.IsDivergedFromBranch("feature", "main")
+	assert.NoError(t, err)
+	assert.True(t, diverged)
+}
+
+func TestWorkItemIssues(t *testing.T) {
+	client := &fakeWorkItemClient{workItems: map[int]map[string]interface{}{
+		7: {"System.Title": "Drift detected", "System.Description": "<pre>changes</pre>"},
+	}}
+	svc := &AzureReposService{WorkItemClient: client, ProjectName: "project"}
+
+	issues, err := svc.ListIssues()
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, int64(7), issues[0].ID)
+	assert.Equal(t, "Drift detected", issues[0].Title)
+
+	id, err := svc.PublishIssue("Drift detected", "a < b", &[]string{"digger", "drift"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.Equal(t, DefaultWorkItemType, *client.created.Type)
+	document := *client.created.Document
+	assert.Len(t, document, 3)
+	assert.Equal(t, "<pre>a &lt; b</pre>", document[1].Value)
+	assert.Equal(t, "digger; drift", document[2].Value)
+
+	id, err = svc.UpdateIssue(7, "Drift detected", "updated")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+	assert.Equal(t, 7, *client.updated.Id)
+}
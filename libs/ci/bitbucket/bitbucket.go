@@ -8,13 +8,18 @@ import (
 	configuration "github.com/diggerhq/digger/libs/digger_config"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Define the base URL for the Bitbucket API.
 const bitbucketBaseURL = "https://api.bitbucket.org/2.0"
 
+// Group membership is only exposed by the 1.0 API.
+const bitbucketBaseURLV1 = "https://api.bitbucket.org/1.0"
+
 // BitbucketAPI is a struct that holds the required authentication information.
 type BitbucketAPI struct {
 	AuthToken     string
@@ -24,7 +29,7 @@ type BitbucketAPI struct {
 }
 
 func (b BitbucketAPI) sendRequest(method, url string, body []byte) (*http.Response, error) {
-	client := &http.Client{}
+	client := &b.HttpClient
 	req, err := http.NewRequest(method, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
@@ -99,6 +104,11 @@ type BitbucketCommentResponse struct {
 	} `json:"user"`
 }
 
+// MaxCommentLength keeps comments within what Bitbucket renders reliably
+func (b BitbucketAPI) MaxCommentLength() int {
+	return 32768
+}
+
 func (b BitbucketAPI) PublishComment(prNumber int, comment string) (*ci.Comment, error) {
 	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d/comments", bitbucketBaseURL, b.RepoWorkspace, b.RepoName, prNumber)
 
@@ -203,6 +213,7 @@ type Comment struct {
 		Content struct {
 			Raw string `json:"raw"`
 		}
+		User BitbucketUser `json:"user"`
 	} `json:"values"`
 }
 
@@ -230,8 +241,9 @@ func (b BitbucketAPI) GetComments(prNumber int) ([]ci.Comment, error) {
 
 	for _, v := range commentResponse.Values {
 		comments = append(comments, ci.Comment{
-			Id:   strconv.Itoa(v.Id),
-			Body: &v.Content.Raw,
+			Id:     strconv.Itoa(v.Id),
+			Body:   &v.Content.Raw,
+			Author: v.User.Nickname,
 		})
 	}
 
@@ -239,9 +251,55 @@ func (b BitbucketAPI) GetComments(prNumber int) ([]ci.Comment, error) {
 
 }
 
+// BitbucketUser is a user as returned in participants, permissions and group members
+type BitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	UUID        string `json:"uuid"`
+	AccountId   string `json:"account_id"`
+}
+
+// matches reports whether user refers to this account by nickname, account ID or UUID
+func (u BitbucketUser) matches(user string) bool {
+	for _, id := range []string{u.Nickname, u.AccountId, u.UUID} {
+		if id != "" && strings.EqualFold(id, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetApprovals returns the nicknames of pull request participants who approved it
 func (svc BitbucketAPI) GetApprovals(prNumber int) ([]string, error) {
+	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d", bitbucketBaseURL, svc.RepoWorkspace, svc.RepoName, prNumber)
+
+	resp, err := svc.sendRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get pull request. Status code: %d", resp.StatusCode)
+	}
+
+	var pullRequest struct {
+		Participants []struct {
+			User     BitbucketUser `json:"user"`
+			Approved bool          `json:"approved"`
+		} `json:"participants"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&pullRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	approvals := make([]string, 0)
-	// TODO: implement me
+	for _, participant := range pullRequest.Participants {
+		if participant.Approved {
+			approvals = append(approvals, participant.User.Nickname)
+		}
+	}
 	return approvals, nil
 }
 
@@ -477,9 +535,47 @@ func (b BitbucketAPI) IsClosed(prNumber int) (bool, error) {
 	return pullRequest.State != "OPEN", nil
 }
 
-// TODO implement me
+// IsDivergedFromBranch reports whether sourceBranch has commits that are not on
+// targetBranch and targetBranch has commits that are not on sourceBranch
 func (b BitbucketAPI) IsDivergedFromBranch(sourceBranch string, targetBranch string) (bool, error) {
-	return false, nil
+	ahead, err := b.hasCommitsNotIn(sourceBranch, targetBranch)
+	if err != nil {
+		return false, err
+	}
+	if !ahead {
+		return false, nil
+	}
+	return b.hasCommitsNotIn(targetBranch, sourceBranch)
+}
+
+// hasCommitsNotIn reports whether any commit reachable from branch is not reachable from exclude
+func (b BitbucketAPI) hasCommitsNotIn(branch string, exclude string) (bool, error) {
+	query := url.Values{}
+	query.Set("include", branch)
+	query.Set("exclude", exclude)
+	query.Set("pagelen", "1")
+	commitsUrl := fmt.Sprintf("%s/repositories/%s/%s/commits?%s", bitbucketBaseURL, b.RepoWorkspace, b.RepoName, query.Encode())
+
+	resp, err := b.sendRequest("GET", commitsUrl, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to compare %s with %s. Status code: %d", branch, exclude, resp.StatusCode)
+	}
+
+	var commits struct {
+		Values []struct {
+			Hash string `json:"hash"`
+		} `json:"values"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&commits)
+	if err != nil {
+		return false, err
+	}
+	return len(commits.Values) > 0, nil
 }
 
 func (b BitbucketAPI) GetBranchName(prNumber int) (string, string, string, string, error) {
@@ -511,6 +607,28 @@ func (b BitbucketAPI) GetBranchName(prNumber int) (string, string, string, strin
 	return pullRequest.Source.Branch.Name, "", "", "", nil
 }
 
+// GetPullRequestInfo returns the author and target branch. Bitbucket pull requests have no labels.
+func (b BitbucketAPI) GetPullRequestInfo(prNumber int) (*ci.PullRequestInfo, error) {
+	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d", bitbucketBaseURL, b.RepoWorkspace, b.RepoName, prNumber)
+
+	var pullRequest struct {
+		Author      BitbucketUser `json:"author"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+	}
+	if err := b.getJSON(url, &pullRequest); err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %v", err)
+	}
+	return &ci.PullRequestInfo{
+		Author:       pullRequest.Author.Nickname,
+		Labels:       []string{},
+		TargetBranch: pullRequest.Destination.Branch.Name,
+	}, nil
+}
+
 func (svc BitbucketAPI) SetOutput(prNumber int, key string, value string) error {
 	//TODO implement me
 	return nil
@@ -518,8 +636,102 @@ func (svc BitbucketAPI) SetOutput(prNumber int, key string, value string) error
 
 // Implement the OrgService interface.
 
+// GetUserTeams returns the slugs of the workspace groups that have permission on
+// the repository, directly or through its project, and that the user belongs
+// to. organisation is the workspace and defaults to the repository's workspace.
 func (b BitbucketAPI) GetUserTeams(organisation string, user string) ([]string, error) {
-	return nil, fmt.Errorf("not implemented")
+	workspace := organisation
+	if workspace == "" {
+		workspace = b.RepoWorkspace
+	}
+
+	groups, err := b.listRepositoryGroups(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]string, 0)
+	for _, group := range groups {
+		members, err := b.listGroupMembers(workspace, group)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member.matches(user) {
+				teams = append(teams, group)
+				break
+			}
+		}
+	}
+	return teams, nil
+}
+
+// listRepositoryGroups returns the slugs of groups with explicit repository
+// permissions followed by those inherited from the repository's project
+func (b BitbucketAPI) listRepositoryGroups(workspace string) ([]string, error) {
+	var repository struct {
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+	}
+	repoUrl := fmt.Sprintf("%s/repositories/%s/%s", bitbucketBaseURL, workspace, b.RepoName)
+	if err := b.getJSON(repoUrl, &repository); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %v", err)
+	}
+
+	urls := []string{fmt.Sprintf("%s/repositories/%s/%s/permissions-config/groups", bitbucketBaseURL, workspace, b.RepoName)}
+	if repository.Project.Key != "" {
+		urls = append(urls, fmt.Sprintf("%s/workspaces/%s/projects/%s/permissions-config/groups", bitbucketBaseURL, workspace, repository.Project.Key))
+	}
+
+	seen := make(map[string]bool)
+	groups := make([]string, 0)
+	for _, next := range urls {
+		for next != "" {
+			var page struct {
+				Values []struct {
+					Group struct {
+						Slug string `json:"slug"`
+					} `json:"group"`
+				} `json:"values"`
+				Next string `json:"next"`
+			}
+			if err := b.getJSON(next, &page); err != nil {
+				return nil, fmt.Errorf("failed to get group permissions: %v", err)
+			}
+			for _, v := range page.Values {
+				if v.Group.Slug != "" && !seen[v.Group.Slug] {
+					seen[v.Group.Slug] = true
+					groups = append(groups, v.Group.Slug)
+				}
+			}
+			next = page.Next
+		}
+	}
+	return groups, nil
+}
+
+func (b BitbucketAPI) listGroupMembers(workspace string, group string) ([]BitbucketUser, error) {
+	membersUrl := fmt.Sprintf("%s/groups/%s/%s/members", bitbucketBaseURLV1, workspace, group)
+	var members []BitbucketUser
+	if err := b.getJSON(membersUrl, &members); err != nil {
+		return nil, fmt.Errorf("failed to get members of group %s: %v", group, err)
+	}
+	return members, nil
+}
+
+// getJSON sends a GET request and decodes a 200 response into v
+func (b BitbucketAPI) getJSON(requestUrl string, v interface{}) error {
+	resp, err := b.sendRequest("GET", requestUrl, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
 }
 
 type PipelineResponse struct {
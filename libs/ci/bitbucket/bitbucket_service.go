@@ -132,6 +132,9 @@ func ConvertBitbucketEventToCommands(event BitbucketEventType, context *Bitbucke
 				ProjectWorkspace:   project.Workspace,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestPushed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -215,6 +218,9 @@ func ConvertBitbucketEventToCommands(event BitbucketEventType, context *Bitbucke
 						ProjectWorkspace:   workspace,
 						Terragrunt:         project.Terragrunt,
 						OpenTofu:           project.OpenTofu,
+						TerraformVersion:   project.PinnedTerraformVersion(workflow),
+						OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+						DependencyOutputs:  project.DependencyOutputs,
 						Pulumi:             project.Pulumi,
 						Commands:           []string{command},
 						ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -0,0 +1,142 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+)
+
+// rewriteTransport sends every request to the test server, keeping the path and query
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestBitbucketAPI(t *testing.T, mux *http.ServeMux) BitbucketAPI {
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return BitbucketAPI{
+		AuthToken:     "token",
+		HttpClient:    http.Client{Transport: rewriteTransport{target: target}},
+		RepoWorkspace: "acme",
+		RepoName:      "infra",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestGetApprovals(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/acme/infra/pullrequests/3", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"participants": []map[string]interface{}{
+				{"user": map[string]string{"nickname": "alice"}, "approved": true},
+				{"user": map[string]string{"nickname": "bob"}, "approved": false},
+			},
+		})
+	})
+	api := newTestBitbucketAPI(t, mux)
+
+	approvals, err := api.GetApprovals(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, approvals)
+}
+
+func TestGetUserTeams(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/acme/infra", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"project": map[string]string{"key": "OPS"}})
+	})
+	mux.HandleFunc("/2.0/repositories/acme/infra/permissions-config/groups", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			writeJSON(w, map[string]interface{}{
+				"values": []map[string]interface{}{{"group": map[string]string{"slug": "developers"}}},
+				"next":   "https://api.bitbucket.org/2.0/repositories/acme/infra/permissions-config/groups?page=2",
+			})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"values": []map[string]interface{}{{"group": map[string]string{"slug": "platform"}}},
+		})
+	})
+	mux.HandleFunc("/2.0/workspaces/acme/projects/OPS/permissions-config/groups", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"group": map[string]string{"slug": "platform"}},
+				{"group": map[string]string{"slug": "sre"}},
+			},
+		})
+	})
+	members := map[string][]BitbucketUser{
+		"developers": {{Nickname: "bob"}},
+		"platform":   {{Nickname: "Alice"}, {Nickname: "bob"}},
+		"sre":        {{AccountId: "557058:alice"}},
+	}
+	for group, users := range members {
+		users := users
+		mux.HandleFunc("/1.0/groups/acme/"+group+"/members", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, users)
+		})
+	}
+	api := newTestBitbucketAPI(t, mux)
+
+	teams, err := api.GetUserTeams("", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, teams)
+
+	teams, err = api.GetUserTeams("acme", "557058:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sre"}, teams)
+}
+
+func TestIsDivergedFromBranch(t *testing.T) {
+	// commits on each branch that the other branch lacks
+	unique := map[string]bool{"feature": true, "main": false}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/acme/infra/commits", func(w http.ResponseWriter, r *http.Request) {
+		values := []map[string]string{}
+		if unique[r.URL.Query().Get("include")] {
+			values = append(values, map[string]string{"hash": "abc"})
+		}
+		writeJSON(w, map[string]interface{}{"values": values})
+	})
+	api := newTestBitbucketAPI(t, mux)
+
+	diverged, err := api.IsDivergedFromBranch("feature", "main")
+	assert.NoError(t, err)
+	assert.False(t, diverged)
+
+	unique["main"] = true
+	diverged, err = api.IsDivergedFromBranch("feature", "main")
+	assert.NoError(t, err)
+	assert.True(t, diverged)
+}
+
+func TestGetPullRequestInfo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/acme/infra/pullrequests/3", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"author":      map[string]string{"nickname": "alice"},
+			"destination": map[string]interface{}{"branch": map[string]string{"name": "main"}},
+		})
+	})
+	api := newTestBitbucketAPI(t, mux)
+
+	info, err := api.GetPullRequestInfo(3)
+	assert.NoError(t, err)
+	assert.Equal(t, &ci.PullRequestInfo{Author: "alice", Labels: []string{}, TargetBranch: "main"}, info)
+}
@@ -28,6 +28,51 @@ type PullRequestService interface {
 	SetOutput(prNumber int, key string, value string) error
 }
 
+// PullRequestInfo is pull request metadata that is not covered by PullRequestService
+type PullRequestInfo struct {
+	Author       string
+	Labels       []string
+	TargetBranch string
+}
+
+// PullRequestInfoService is implemented by services that can look up the
+// author and labels of a pull request
+type PullRequestInfoService interface {
+	GetPullRequestInfo(prNumber int) (*PullRequestInfo, error)
+}
+
+// CommentLengthLimiter is implemented by services that limit the length of
+// a pull request comment
+type CommentLengthLimiter interface {
+	MaxCommentLength() int
+}
+
+// CheckRunAnnotation points a check run message at a line range of a file in the repository
+type CheckRunAnnotation struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	// Level is one of notice, warning or failure
+	Level   string
+	Title   string
+	Message string
+}
+
+type CheckRunOutput struct {
+	Title       string
+	Summary     string
+	Text        string
+	Annotations []CheckRunAnnotation
+}
+
+// CheckRunService is implemented by services that can publish check runs on
+// the head commit of a pull request. Annotations passed to an update are
+// added to those of the check run.
+type CheckRunService interface {
+	CreatePullRequestCheckRun(prNumber int, name string, conclusion string, output CheckRunOutput) (id string, url string, err error)
+	UpdatePullRequestCheckRun(id string, name string, conclusion string, output CheckRunOutput) (url string, err error)
+}
+
 type OrgService interface {
 	GetUserTeams(organisation string, user string) ([]string, error)
 }
@@ -43,6 +88,7 @@ type Comment struct {
 	DiscussionId string // gitlab only
 	Body         *string
 	Url          string
+	Author       string // login of the commenter, where the VCS reports it
 }
 
 func (c Comment) GetIdAsInt() (int, error) {
@@ -177,6 +177,9 @@ func CreateJobsForProjects(projects []digger_config.Project, command string, eve
 			ProjectWorkflow:    project.Workflow,
 			Terragrunt:         project.Terragrunt,
 			OpenTofu:           project.OpenTofu,
+			TerraformVersion:   project.PinnedTerraformVersion(workflow),
+			OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+			DependencyOutputs:  project.DependencyOutputs,
 			Pulumi:             project.Pulumi,
 			Commands:           []string{command},
 			ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
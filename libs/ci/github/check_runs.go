@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/google/go-github/v61/github"
+)
+
+// GitHub accepts at most this many annotations per check run request
+const maxAnnotationsPerRequest = 50
+
+func toGithubCheckRunOutput(output ci.CheckRunOutput, annotations []ci.CheckRunAnnotation) *github.CheckRunOutput {
+	githubOutput := &github.CheckRunOutput{
+		Title:   github.String(output.Title),
+		Summary: github.String(output.Summary),
+		Text:    github.String(output.Text),
+	}
+	for _, annotation := range annotations {
+		endLine := max(annotation.EndLine, annotation.StartLine)
+		githubOutput.Annotations = append(githubOutput.Annotations, &github.CheckRunAnnotation{
+			Path:            github.String(annotation.Path),
+			StartLine:       github.Int(annotation.StartLine),
+			EndLine:         github.Int(endLine),
+			AnnotationLevel: github.String(annotation.Level),
+			Title:           github.String(annotation.Title),
+			Message:         github.String(annotation.Message),
+		})
+	}
+	return githubOutput
+}
+
+// CreatePullRequestCheckRun creates a completed check run on the head commit of the pull request
+func (svc GithubService) CreatePullRequestCheckRun(prNumber int, name string, conclusion string, output ci.CheckRunOutput) (string, string, error) {
+	pr, _, err := svc.Client.PullRequests.Get(context.Background(), svc.Owner, svc.RepoName, prNumber)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting pull request: %v", err)
+	}
+
+	annotations := output.Annotations
+	firstBatch := annotations[:min(len(annotations), maxAnnotationsPerRequest)]
+	checkRun, _, err := svc.Client.Checks.CreateCheckRun(context.Background(), svc.Owner, svc.RepoName, github.CreateCheckRunOptions{
+		Name:       name,
+		HeadSHA:    pr.Head.GetSHA(),
+		Status:     github.String("completed"),
+		Conclusion: github.String(conclusion),
+		Output:     toGithubCheckRunOutput(output, firstBatch),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("could not create check run: %v", err)
+	}
+
+	id := strconv.FormatInt(checkRun.GetID(), 10)
+	if len(annotations) > len(firstBatch) {
+		output.Annotations = annotations[len(firstBatch):]
+		if _, err := svc.UpdatePullRequestCheckRun(id, name, conclusion, output); err != nil {
+			return id, checkRun.GetHTMLURL(), err
+		}
+	}
+	return id, checkRun.GetHTMLURL(), nil
+}
+
+// UpdatePullRequestCheckRun replaces the output of a check run and adds the given annotations,
+// sending them in batches GitHub accepts
+func (svc GithubService) UpdatePullRequestCheckRun(id string, name string, conclusion string, output ci.CheckRunOutput) (string, error) {
+	checkRunId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("could not convert id %v to i64: %v", id, err)
+	}
+
+	var url string
+	annotations := output.Annotations
+	for first := true; first || len(annotations) > 0; first = false {
+		batch := annotations[:min(len(annotations), maxAnnotationsPerRequest)]
+		annotations = annotations[len(batch):]
+		checkRun, _, err := svc.Client.Checks.UpdateCheckRun(context.Background(), svc.Owner, svc.RepoName, checkRunId, github.UpdateCheckRunOptions{
+			Name:       name,
+			Status:     github.String("completed"),
+			Conclusion: github.String(conclusion),
+			Output:     toGithubCheckRunOutput(output, batch),
+		})
+		if err != nil {
+			return "", fmt.Errorf("could not update check run %v: %v", id, err)
+		}
+		url = checkRun.GetHTMLURL()
+	}
+	return url, nil
+}
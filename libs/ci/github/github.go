@@ -155,6 +155,11 @@ func (svc GithubService) UpdateIssue(ID int64, title string, body string) (int64
 	return *githubissue.ID, err
 }
 
+// MaxCommentLength is the most characters GitHub accepts in a comment body
+func (svc GithubService) MaxCommentLength() int {
+	return 65536
+}
+
 func (svc GithubService) PublishComment(prNumber int, comment string) (*ci.Comment, error) {
 	githubComment, _, err := svc.Client.Issues.CreateComment(context.Background(), svc.Owner, svc.RepoName, prNumber, &github.IssueComment{Body: &comment})
 	if err != nil {
@@ -198,9 +203,10 @@ func (svc GithubService) GetComments(prNumber int) ([]ci.Comment, error) {
 			}
 
 			allComments = append(allComments, ci.Comment{
-				Id:   commentId,
-				Body: commentBody,
-				Url:  commentUrl,
+				Id:     commentId,
+				Body:   commentBody,
+				Url:    commentUrl,
+				Author: comment.GetUser().GetLogin(),
 			})
 		}
 
@@ -618,6 +624,24 @@ func (svc GithubService) GetBranchName(prNumber int) (string, string, string, st
 	return pr.Head.GetRef(), pr.Head.GetSHA(), targetBranch, targetSha, nil
 }
 
+func (svc GithubService) GetPullRequestInfo(prNumber int) (*ci.PullRequestInfo, error) {
+	pr, _, err := svc.Client.PullRequests.Get(context.Background(), svc.Owner, svc.RepoName, prNumber)
+	if err != nil {
+		slog.Error("error getting pull request", "error", err, "prNumber", prNumber)
+		return nil, fmt.Errorf("error getting pull request: %v", err)
+	}
+
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return &ci.PullRequestInfo{
+		Author:       pr.GetUser().GetLogin(),
+		Labels:       labels,
+		TargetBranch: pr.Base.GetRef(),
+	}, nil
+}
+
 func (svc GithubService) GetHeadCommitFromBranch(branch string) (string, string, error) {
 	branchInfo, _, err := svc.Client.Repositories.GetBranch(context.Background(), svc.Owner, svc.RepoName, branch, 0)
 	if err != nil {
@@ -698,6 +722,9 @@ func ConvertGithubPullRequestEventToJobs(payload *github.PullRequestEvent, impac
 				Layer:              project.Layer,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnCommitToDefault,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -731,6 +758,9 @@ func ConvertGithubPullRequestEventToJobs(payload *github.PullRequestEvent, impac
 				Layer:              project.Layer,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestPushed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -763,6 +793,9 @@ func ConvertGithubPullRequestEventToJobs(payload *github.PullRequestEvent, impac
 				Layer:              project.Layer,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestClosed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -803,6 +836,9 @@ func ConvertGithubPullRequestEventToJobs(payload *github.PullRequestEvent, impac
 				Layer:              project.Layer,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           commands,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -179,8 +179,72 @@ func (gitlabService GitLabService) GetChangedFiles(mergeRequestId int) ([]string
 	return fileNames, nil
 }
 
+// GetUserTeams returns the subgroups of organisation that user is a member of, directly
+// or through a parent group. Like GitHub team names they are relative to organisation,
+// e.g. "platform/oncall" for the group acme/platform/oncall. Only group and member
+// listings are used, so a token of any member of the group is enough.
 func (gitlabService GitLabService) GetUserTeams(organisation string, user string) ([]string, error) {
-	return make([]string, 0), nil
+	slog.Debug("getting user groups", "organisation", organisation, "user", user)
+
+	root, _, err := gitlabService.Client.Groups.GetGroup(organisation, &go_gitlab.GetGroupOptions{})
+	if err != nil {
+		slog.Error("failed to get GitLab group", "error", err, "organisation", organisation)
+		return nil, fmt.Errorf("failed to get gitlab group %v: %v", organisation, err)
+	}
+	var subgroups []*go_gitlab.Group
+	opt := &go_gitlab.ListDescendantGroupsOptions{ListOptions: go_gitlab.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := gitlabService.Client.Groups.ListDescendantGroups(root.ID, opt)
+		if err != nil {
+			slog.Error("failed to list GitLab subgroups", "error", err, "organisation", organisation)
+			return nil, fmt.Errorf("failed to list subgroups of %v: %v", organisation, err)
+		}
+		subgroups = append(subgroups, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	teams := make([]string, 0)
+	for _, group := range subgroups {
+		isMember, err := gitlabService.isGroupMember(group.ID, user)
+		if err != nil {
+			slog.Error("failed to list GitLab group members", "error", err, "group", group.FullPath)
+			return nil, fmt.Errorf("failed to list members of group %v: %v", group.FullPath, err)
+		}
+		if isMember {
+			teams = append(teams, strings.TrimPrefix(group.FullPath, root.FullPath+"/"))
+		}
+	}
+
+	slog.Debug("found user groups", "user", user, "count", len(teams))
+	return teams, nil
+}
+
+// isGroupMember checks direct and inherited membership of a group
+func (gitlabService GitLabService) isGroupMember(groupID int, user string) (bool, error) {
+	opt := &go_gitlab.ListGroupMembersOptions{Query: &user, ListOptions: go_gitlab.ListOptions{PerPage: 100}}
+	for {
+		members, resp, err := gitlabService.Client.Groups.ListAllGroupMembers(groupID, opt)
+		if err != nil {
+			return false, err
+		}
+		for _, member := range members {
+			if member.Username == user {
+				return true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// MaxCommentLength is the most characters GitLab accepts in a note
+func (gitlabService GitLabService) MaxCommentLength() int {
+	return 1000000
 }
 
 func (gitlabService GitLabService) PublishComment(prNumber int, comment string) (*ci.Comment, error) {
@@ -214,16 +278,57 @@ func (gitlabService GitLabService) PublishComment(prNumber int, comment string)
 	}
 }
 
+// ListIssues returns the open issues of the project. Issue IDs are project
+// IIDs, which is what PublishIssue returns and UpdateIssue expects.
 func (svc GitLabService) ListIssues() ([]*ci.Issue, error) {
-	return nil, fmt.Errorf("implement me")
+	projectId := *svc.Context.ProjectId
+	state := "opened"
+	opt := &go_gitlab.ListProjectIssuesOptions{State: &state, ListOptions: go_gitlab.ListOptions{PerPage: 100}}
+
+	allIssues := make([]*ci.Issue, 0)
+	for {
+		issues, resp, err := svc.Client.Issues.ListProjectIssues(projectId, opt)
+		if err != nil {
+			slog.Error("error getting issues", "error", err, "projectId", projectId)
+			return nil, fmt.Errorf("error getting issues: %v", err)
+		}
+		for _, issue := range issues {
+			allIssues = append(allIssues, &ci.Issue{ID: int64(issue.IID), Title: issue.Title, Body: issue.Description})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return allIssues, nil
 }
 
 func (svc GitLabService) PublishIssue(title string, body string, labels *[]string) (int64, error) {
-	return 0, fmt.Errorf("implement me")
+	projectId := *svc.Context.ProjectId
+	opt := &go_gitlab.CreateIssueOptions{Title: &title, Description: &body}
+	if labels != nil {
+		labelOpts := go_gitlab.LabelOptions(*labels)
+		opt.Labels = &labelOpts
+	}
+
+	issue, _, err := svc.Client.Issues.CreateIssue(projectId, opt)
+	if err != nil {
+		slog.Error("could not publish issue", "error", err, "projectId", projectId)
+		return 0, fmt.Errorf("could not publish issue: %v", err)
+	}
+	return int64(issue.IID), nil
 }
 
 func (svc GitLabService) UpdateIssue(ID int64, title string, body string) (int64, error) {
-	return 0, fmt.Errorf("implement me")
+	projectId := *svc.Context.ProjectId
+	opt := &go_gitlab.UpdateIssueOptions{Title: &title, Description: &body}
+
+	issue, _, err := svc.Client.Issues.UpdateIssue(projectId, int(ID), opt)
+	if err != nil {
+		slog.Error("could not edit issue", "error", err, "projectId", projectId, "issueIID", ID)
+		return 0, fmt.Errorf("could not edit issue: %v", err)
+	}
+	return int64(issue.IID), nil
 }
 
 // SetStatus GitLab implementation is using https://docs.gitlab.com/15.11/ee/api/status_checks.html (external status checks)
@@ -343,14 +448,54 @@ func (gitlabService GitLabService) CreateCommentReaction(id string, reaction str
 	return nil
 }
 
+// GetComments returns the comments of the merge request, oldest first. Notes GitLab adds for
+// events such as pushes or approvals are left out.
 func (gitlabService GitLabService) GetComments(prNumber int) ([]ci.Comment, error) {
-	//TODO implement me
-	return nil, nil
+	projectId := *gitlabService.Context.ProjectId
+	orderBy, sort := "created_at", "asc"
+	opt := &go_gitlab.ListMergeRequestNotesOptions{OrderBy: &orderBy, Sort: &sort, ListOptions: go_gitlab.ListOptions{PerPage: 100}}
+
+	comments := make([]ci.Comment, 0)
+	for {
+		notes, resp, err := gitlabService.Client.Notes.ListMergeRequestNotes(projectId, prNumber, opt)
+		if err != nil {
+			slog.Error("error getting merge request notes", "error", err, "mergeRequestIID", prNumber)
+			return nil, fmt.Errorf("error getting merge request notes: %v", err)
+		}
+		for _, note := range notes {
+			if note.System {
+				continue
+			}
+			body := note.Body
+			comments = append(comments, ci.Comment{
+				Id:     strconv.Itoa(note.ID),
+				Body:   &body,
+				Author: note.Author.Username,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return comments, nil
 }
 
+// GetApprovals returns the usernames that approved the merge request
 func (gitlabService GitLabService) GetApprovals(prNumber int) ([]string, error) {
+	projectId := *gitlabService.Context.ProjectId
 	approvals := make([]string, 0)
-	// TODO: implement me
+
+	mergeRequestApprovals, _, err := gitlabService.Client.MergeRequestApprovals.GetConfiguration(projectId, prNumber)
+	if err != nil {
+		slog.Error("could not get merge request approvals", "error", err, "mergeRequestIID", prNumber)
+		return approvals, fmt.Errorf("could not get merge request approvals: %v", err)
+	}
+	for _, approver := range mergeRequestApprovals.ApprovedBy {
+		if approver.User != nil {
+			approvals = append(approvals, approver.User.Username)
+		}
+	}
 	return approvals, nil
 }
 
@@ -368,6 +513,24 @@ func (gitlabService GitLabService) GetBranchName(prNumber int) (string, string,
 	return pr.SourceBranch, pr.SHA, "", "", nil
 }
 
+func (gitlabService GitLabService) GetPullRequestInfo(prNumber int) (*ci.PullRequestInfo, error) {
+	projectId := *gitlabService.Context.ProjectId
+	mergeRequest, _, err := gitlabService.Client.MergeRequests.GetMergeRequest(projectId, prNumber, &go_gitlab.GetMergeRequestsOptions{})
+	if err != nil {
+		slog.Error("could not get merge request", "error", err, "mergeRequestIID", prNumber)
+		return nil, fmt.Errorf("could not get merge request: %v", err)
+	}
+
+	info := &ci.PullRequestInfo{
+		Labels:       append([]string{}, mergeRequest.Labels...),
+		TargetBranch: mergeRequest.TargetBranch,
+	}
+	if mergeRequest.Author != nil {
+		info.Author = mergeRequest.Author.Username
+	}
+	return info, nil
+}
+
 func (gitlabService GitLabService) CheckBranchExists(branchName string) (bool, error) {
 	projectId := *gitlabService.Context.ProjectId
 	slog.Debug("checking if branch exists", "branchName", branchName, "projectId", projectId)
@@ -461,6 +624,9 @@ func ConvertGitLabEventToCommands(event GitLabEvent, gitLabContext *GitLabContex
 				ProjectWorkspace:   project.Workspace,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestPushed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -515,6 +681,9 @@ func ConvertGitLabEventToCommands(event GitLabEvent, gitLabContext *GitLabContex
 				ProjectWorkspace:   project.Workspace,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestClosed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -597,6 +766,9 @@ func ConvertGitLabEventToCommands(event GitLabEvent, gitLabContext *GitLabContex
 						ProjectWorkspace:   workspace,
 						Terragrunt:         project.Terragrunt,
 						OpenTofu:           project.OpenTofu,
+						TerraformVersion:   project.PinnedTerraformVersion(workflow),
+						OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+						DependencyOutputs:  project.DependencyOutputs,
 						Pulumi:             project.Pulumi,
 						Commands:           []string{command},
 						ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
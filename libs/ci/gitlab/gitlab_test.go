@@ -1,8 +1,16 @@
 package gitlab
 
 import (
-	"github.com/stretchr/testify/assert"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+	go_gitlab "github.com/xanzy/go-gitlab"
 )
 
 func TestParseGitLabContext(t *testing.T) {
@@ -34,3 +42,164 @@ func TestOpenMergeRequestEvent(t *testing.T) {
 	assert.Nil(t, context.MergeRequestId)
 	assert.Nil(t, context.MergeRequestIId)
 }
+
+// newTestGitLabService returns a service talking to a local stand-in for the
+// GitLab API; handler paths are relative to /api/v4
+func newTestGitLabService(t *testing.T, mux *http.ServeMux) *GitLabService {
+	server := httptest.NewServer(http.StripPrefix("/api/v4", mux))
+	t.Cleanup(server.Close)
+
+	client, err := go_gitlab.NewClient("token", go_gitlab.WithBaseURL(server.URL))
+	assert.NoError(t, err)
+	projectId := 42
+	mergeRequestIId := 7
+	return &GitLabService{
+		Client:  client,
+		Context: &GitLabContext{ProjectId: &projectId, MergeRequestIId: &mergeRequestIId},
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	assert.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func TestGetApprovals(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/42/merge_requests/7/approvals", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{
+			"approved_by": []map[string]interface{}{
+				{"user": map[string]interface{}{"username": "alice"}},
+				{"user": map[string]interface{}{"username": "bob"}},
+			},
+		})
+	})
+	service := newTestGitLabService(t, mux)
+
+	approvals, err := service.GetApprovals(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, approvals)
+}
+
+func TestGetUserTeams(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/groups/acme", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"id": 1, "full_path": "acme"})
+	})
+	mux.HandleFunc("/groups/1/descendant_groups", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(t, w, []map[string]interface{}{{"id": 3, "full_path": "acme/platform/oncall"}})
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		writeJSON(t, w, []map[string]interface{}{{"id": 2, "full_path": "acme/platform"}, {"id": 4, "full_path": "acme/data"}})
+	})
+	// the members listings include members inherited from parent groups
+	members := map[string][]map[string]interface{}{
+		"2": {{"id": 10, "username": "alice"}},
+		"3": {{"id": 11, "username": "alice2"}, {"id": 10, "username": "alice"}},
+		"4": {{"id": 11, "username": "alice2"}},
+	}
+	mux.HandleFunc("/groups/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 4 || parts[2] != "members" || parts[3] != "all" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "alice", r.URL.Query().Get("query"))
+		writeJSON(t, w, members[parts[1]])
+	})
+	service := newTestGitLabService(t, mux)
+
+	teams, err := service.GetUserTeams("acme", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"platform", "platform/oncall"}, teams)
+}
+
+func TestGetComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/42/merge_requests/7/notes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "asc", r.URL.Query().Get("sort"))
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(t, w, []map[string]interface{}{
+				{"id": 3, "body": "digger override-policy reason: hotfix", "author": map[string]interface{}{"username": "bob"}},
+			})
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		writeJSON(t, w, []map[string]interface{}{
+			{"id": 1, "body": "digger plan", "author": map[string]interface{}{"username": "alice"}},
+			{"id": 2, "body": "approved this merge request", "system": true, "author": map[string]interface{}{"username": "carol"}},
+		})
+	})
+	service := newTestGitLabService(t, mux)
+
+	comments, err := service.GetComments(7)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, "1", comments[0].Id)
+	assert.Equal(t, "alice", comments[0].Author)
+	assert.Equal(t, "digger plan", *comments[0].Body)
+	assert.Equal(t, "bob", comments[1].Author)
+}
+
+func TestIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/42/issues", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "opened", r.URL.Query().Get("state"))
+			writeJSON(t, w, []map[string]interface{}{
+				{"id": 1001, "iid": 3, "title": "Drift detected in project: prod", "description": "old plan"},
+			})
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			var req map[string]interface{}
+			assert.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, "Drift detected in project: dev", req["title"])
+			assert.Equal(t, "digger", req["labels"])
+			writeJSON(t, w, map[string]interface{}{"id": 1002, "iid": 4})
+		}
+	})
+	mux.HandleFunc("/projects/42/issues/3", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, "new plan", req["description"])
+		writeJSON(t, w, map[string]interface{}{"id": 1001, "iid": 3})
+	})
+	service := newTestGitLabService(t, mux)
+
+	issues, err := service.ListIssues()
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, int64(3), issues[0].ID)
+	assert.Equal(t, "old plan", issues[0].Body)
+
+	id, err := service.UpdateIssue(issues[0].ID, issues[0].Title, "new plan")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+
+	labels := []string{"digger"}
+	id, err = service.PublishIssue("Drift detected in project: dev", "plan", &labels)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), id)
+}
+
+func TestGetPullRequestInfo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/42/merge_requests/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{
+			"iid":           7,
+			"author":        map[string]interface{}{"username": "alice"},
+			"labels":        []string{"freeze", "infra"},
+			"target_branch": "main",
+		})
+	})
+	service := newTestGitLabService(t, mux)
+
+	info, err := service.GetPullRequestInfo(7)
+	assert.NoError(t, err)
+	assert.Equal(t, &ci.PullRequestInfo{Author: "alice", Labels: []string{"freeze", "infra"}, TargetBranch: "main"}, info)
+}
@@ -88,6 +88,9 @@ func ConvertGithubPullRequestEventToJobs(payload *gitlab.MergeEvent, impactedPro
 				ProjectWorkflow:    project.Workflow,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestPushed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -111,6 +114,9 @@ func ConvertGithubPullRequestEventToJobs(payload *gitlab.MergeEvent, impactedPro
 				ProjectWorkflow:    project.Workflow,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           workflow.Configuration.OnPullRequestClosed,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
@@ -142,6 +148,9 @@ func ConvertGithubPullRequestEventToJobs(payload *gitlab.MergeEvent, impactedPro
 				ProjectWorkflow:    project.Workflow,
 				Terragrunt:         project.Terragrunt,
 				OpenTofu:           project.OpenTofu,
+				TerraformVersion:   project.PinnedTerraformVersion(workflow),
+				OpenTofuVersion:    project.PinnedOpenTofuVersion(workflow),
+				DependencyOutputs:  project.DependencyOutputs,
 				Pulumi:             project.Pulumi,
 				Commands:           commands,
 				ApplyStage:         scheduler.ToConfigStage(workflow.Apply),
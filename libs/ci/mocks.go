@@ -6,6 +6,7 @@ type MockPullRequestManager struct {
 	ChangedFiles []string
 	Teams        []string
 	Approvals    []string
+	Comments     []Comment
 }
 
 func (t MockPullRequestManager) GetUserTeams(organisation string, user string) ([]string, error) {
@@ -69,7 +70,10 @@ func (t MockPullRequestManager) IsDivergedFromBranch(sourceBranch string, target
 }
 
 func (t MockPullRequestManager) GetComments(prNumber int) ([]Comment, error) {
-	return []Comment{}, nil
+	if t.Comments == nil {
+		return []Comment{}, nil
+	}
+	return t.Comments, nil
 }
 
 func (t MockPullRequestManager) EditComment(prNumber int, id string, comment string) error {
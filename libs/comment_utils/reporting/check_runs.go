@@ -0,0 +1,254 @@
+package reporting
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"gopkg.in/yaml.v3"
+)
+
+// GitHub rejects check run output text longer than this
+const checkRunTextMaxLength = 65535
+
+var (
+	diagnosticStartPattern  = regexp.MustCompile(`^(Error|Warning): (.+)$`)
+	diagnosticRangePattern  = regexp.MustCompile(`^on (\S+) line (\d+)`)
+	diagnosticSourcePattern = regexp.MustCompile(`^\d+:`)
+)
+
+// CheckRunStrategy publishes the reports of a project into a check run on the head commit of the
+// pull request instead of comments. Terraform diagnostics with a range and plan policy violations
+// become annotations of the check run. Services without check runs get a comment per report.
+type CheckRunStrategy struct {
+	// Title names the check run
+	Title string
+	// ProjectDir resolves the files terraform diagnostics point at
+	ProjectDir string
+	// ConfigFile is annotated with policy violations, they have no position in the code
+	ConfigFile string
+
+	// configLine is the line of the project block in the config file, 0 when it was not found
+	configLine int
+	checkRunId string
+	text       string
+	errors     int
+	warnings   int
+}
+
+// ForProject returns a strategy publishing to a check run of its own for the project. Policy
+// violations of the project point at its block in the config file.
+func (strategy *CheckRunStrategy) ForProject(projectName string, projectDir string) ReportStrategy {
+	configFile := strategy.ConfigFile
+	candidates := []string{configFile}
+	if configFile == "" {
+		candidates = []string{"digger.yml", "digger.yaml"}
+	}
+	configLine := 0
+	for _, candidate := range candidates {
+		content, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		configFile = candidate
+		configLine = projectBlockLine(content, projectName)
+		break
+	}
+	return &CheckRunStrategy{
+		Title:      "digger/" + projectName,
+		ProjectDir: projectDir,
+		ConfigFile: configFile,
+		configLine: configLine,
+	}
+}
+
+// projectBlockLine returns the line the block of the project starts at in a digger config,
+// or 0 when the project is not declared in it
+func projectBlockLine(config []byte, projectName string) int {
+	var document yaml.Node
+	if err := yaml.Unmarshal(config, &document); err != nil || len(document.Content) == 0 {
+		return 0
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "projects" || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, project := range root.Content[i+1].Content {
+			if project.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(project.Content); j += 2 {
+				if project.Content[j].Value == "name" && project.Content[j+1].Value == projectName {
+					return project.Line
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func (strategy *CheckRunStrategy) Report(ciService ci.PullRequestService, PrNumber int, report string, reportFormatter func(report string) string, supportsCollapsibleComment bool) (string, string, error) {
+	checkRunService, ok := ciService.(ci.CheckRunService)
+	if !ok {
+		return MultipleCommentsStrategy{}.Report(ciService, PrNumber, report, reportFormatter, supportsCollapsibleComment)
+	}
+
+	name := strategy.Title
+	if name == "" {
+		name = "digger"
+	}
+	annotations := TerraformDiagnosticAnnotations(report, strategy.ProjectDir)
+	violations := strategy.policyAnnotations(report)
+	for _, annotation := range append(annotations, violations...) {
+		if annotation.Level == "failure" {
+			strategy.errors++
+		} else {
+			strategy.warnings++
+		}
+	}
+	// violations of a project missing from the config file have no line to point at, they
+	// still count but are only part of the text
+	if strategy.configLine > 0 {
+		annotations = append(annotations, violations...)
+	}
+
+	if strategy.text != "" {
+		strategy.text += "\n\n"
+	}
+	strategy.text += reportFormatter(report)
+	text := TruncateCheckRunText(strategy.text)
+
+	conclusion := "success"
+	if strategy.errors > 0 {
+		conclusion = "failure"
+	}
+	output := ci.CheckRunOutput{
+		Title:       name,
+		Summary:     fmt.Sprintf("%d errors, %d warnings", strategy.errors, strategy.warnings),
+		Text:        text,
+		Annotations: annotations,
+	}
+
+	if strategy.checkRunId == "" {
+		id, url, err := checkRunService.CreatePullRequestCheckRun(PrNumber, name, conclusion, output)
+		if err != nil {
+			slog.Error("error creating check run", "error", err, "prNumber", PrNumber, "name", name)
+			return "", "", fmt.Errorf("error creating check run: %v", err)
+		}
+		strategy.checkRunId = id
+		return id, url, nil
+	}
+	url, err := checkRunService.UpdatePullRequestCheckRun(strategy.checkRunId, name, conclusion, output)
+	if err != nil {
+		slog.Error("error updating check run", "error", err, "prNumber", PrNumber, "checkRunId", strategy.checkRunId)
+		return "", "", fmt.Errorf("error updating check run: %v", err)
+	}
+	return strategy.checkRunId, url, nil
+}
+
+// policyAnnotations turns the messages of a plan policy report into annotations of the project
+// block in the config file
+func (strategy *CheckRunStrategy) policyAnnotations(report string) []ci.CheckRunAnnotation {
+	var annotations []ci.CheckRunAnnotation
+	level := ""
+	for _, line := range strings.Split(report, "<br>") {
+		switch {
+		case strings.Contains(line, "failed validation checks"):
+			level = "failure"
+		case strings.Contains(line, "validation checks overridden"), strings.HasPrefix(line, "Warnings"):
+			level = "warning"
+		case level != "" && strings.HasPrefix(line, "    "):
+			annotations = append(annotations, ci.CheckRunAnnotation{
+				Path:      strategy.ConfigFile,
+				StartLine: strategy.configLine,
+				EndLine:   strategy.configLine,
+				Level:     level,
+				Title:     "Plan policy violation in " + strings.TrimPrefix(strategy.Title, "digger/"),
+				Message:   strings.TrimSpace(line),
+			})
+		default:
+			level = ""
+		}
+	}
+	return annotations
+}
+
+// TerraformDiagnosticAnnotations finds the errors and warnings in terraform output that point at
+// a file of the repository. Paths are resolved against projectDir, the directory terraform ran in.
+func TerraformDiagnosticAnnotations(output string, projectDir string) []ci.CheckRunAnnotation {
+	var annotations []ci.CheckRunAnnotation
+	var current *ci.CheckRunAnnotation
+	var message []string
+	flush := func() {
+		if current != nil && current.Path != "" {
+			current.Message = strings.TrimSpace(strings.Join(message, "\n"))
+			if current.Message == "" {
+				current.Message = current.Title
+			}
+			annotations = append(annotations, *current)
+		}
+		current = nil
+		message = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		// diagnostics are boxed by ╷ and ╵ unless colors are disabled
+		if strings.HasPrefix(strings.TrimSpace(line), "╵") {
+			flush()
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "│╷ \t"))
+		if match := diagnosticStartPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			level := "failure"
+			if match[1] == "Warning" {
+				level = "warning"
+			}
+			current = &ci.CheckRunAnnotation{Level: level, Title: match[2]}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if match := diagnosticRangePattern.FindStringSubmatch(line); match != nil && current.Path == "" {
+			file := path.Clean(path.Join(projectDir, match[1]))
+			if strings.HasPrefix(file, "../") || path.IsAbs(file) {
+				continue
+			}
+			lineNumber, _ := strconv.Atoi(match[2])
+			current.Path = file
+			current.StartLine = lineNumber
+			current.EndLine = lineNumber
+			continue
+		}
+		if diagnosticSourcePattern.MatchString(line) || strings.HasPrefix(line, "├") {
+			continue
+		}
+		message = append(message, line)
+	}
+	flush()
+	return annotations
+}
+
+// TruncateCheckRunText cuts text to the length GitHub accepts as check run output
+func TruncateCheckRunText(text string) string {
+	if len(text) <= checkRunTextMaxLength {
+		return text
+	}
+	const footer = "\n\n[Output truncated due to length limits, see the job logs for the rest]"
+	cut := checkRunTextMaxLength - len(footer)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + footer
+}
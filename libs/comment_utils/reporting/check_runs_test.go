@@ -0,0 +1,174 @@
+package reporting
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+)
+
+type checkRun struct {
+	name       string
+	conclusion string
+	output     ci.CheckRunOutput
+}
+
+// checkRunCiService is a MockCiService that records the check runs created and updated
+type checkRunCiService struct {
+	MockCiService
+	checkRuns map[string]*checkRun
+}
+
+func (t checkRunCiService) CreatePullRequestCheckRun(prNumber int, name string, conclusion string, output ci.CheckRunOutput) (string, string, error) {
+	id := strconv.Itoa(len(t.checkRuns) + 1)
+	t.checkRuns[id] = &checkRun{name: name, conclusion: conclusion, output: output}
+	return id, "https://github.com/owner/repo/runs/" + id, nil
+}
+
+func (t checkRunCiService) UpdatePullRequestCheckRun(id string, name string, conclusion string, output ci.CheckRunOutput) (string, error) {
+	t.checkRuns[id] = &checkRun{name: name, conclusion: conclusion, output: output}
+	return "https://github.com/owner/repo/runs/" + id, nil
+}
+
+func TestTerraformDiagnosticAnnotations(t *testing.T) {
+	output := `
+╷
+│ Error: Unsupported argument
+│
+│   on main.tf line 12, in resource "aws_s3_bucket" "b":
+│   12:   buckt = "my-bucket"
+│
+│ An argument named "buckt" is not expected here.
+╵
+╷
+│ Warning: Deprecated attribute
+│
+│   on ../modules/vpc/main.tf line 3, in module "vpc":
+│    3:   enable_classiclink = true
+│
+│ The attribute "enable_classiclink" is deprecated.
+╵
+Error: Missing required argument
+
+  on variables.tf line 4:
+   4: variable "region" {
+
+The argument "type" is required.
+
+Error: No configuration files
+`
+	annotations := TerraformDiagnosticAnnotations(output, "prod/app")
+	assert.Equal(t, []ci.CheckRunAnnotation{
+		{Path: "prod/app/main.tf", StartLine: 12, EndLine: 12, Level: "failure", Title: "Unsupported argument", Message: `An argument named "buckt" is not expected here.`},
+		{Path: "prod/modules/vpc/main.tf", StartLine: 3, EndLine: 3, Level: "warning", Title: "Deprecated attribute", Message: `The attribute "enable_classiclink" is deprecated.`},
+		{Path: "prod/app/variables.tf", StartLine: 4, EndLine: 4, Level: "failure", Title: "Missing required argument", Message: `The argument "type" is required.`},
+	}, annotations)
+
+	// files outside of the repository can't be annotated
+	assert.Empty(t, TerraformDiagnosticAnnotations("Error: Bad\n\n  on ../../main.tf line 1:\n", "app"))
+}
+
+func TestCheckRunStrategyCreatesThenUpdatesCheckRun(t *testing.T) {
+	service := checkRunCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, checkRuns: map[string]*checkRun{}}
+	reporter := ForProject(&CiReporter{CiService: service, PrNumber: 1, IsSupportMarkdown: true, ReportStrategy: &CheckRunStrategy{}}, "app", "app")
+
+	id, url, err := reporter.Report("Plan: 1 to add, 0 to change, 0 to destroy.", AsComment("plan"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", id)
+	assert.Equal(t, "https://github.com/owner/repo/runs/1", url)
+	assert.Equal(t, "success", service.checkRuns["1"].conclusion)
+
+	_, _, err = reporter.Report("Error: Invalid reference\n\n  on main.tf line 2:\n   2: foo\n\nA reference must be an attribute.", AsComment("apply"))
+	assert.NoError(t, err)
+	assert.Len(t, service.checkRuns, 1)
+	run := service.checkRuns["1"]
+	assert.Equal(t, "digger/app", run.name)
+	assert.Equal(t, "failure", run.conclusion)
+	assert.Equal(t, "1 errors, 0 warnings", run.output.Summary)
+	assert.Contains(t, run.output.Text, "Plan: 1 to add")
+	assert.Contains(t, run.output.Text, "Invalid reference")
+	assert.Equal(t, []ci.CheckRunAnnotation{
+		{Path: "app/main.tf", StartLine: 2, EndLine: 2, Level: "failure", Title: "Invalid reference", Message: "A reference must be an attribute."},
+	}, run.output.Annotations)
+	assert.Empty(t, service.CommentsPerPr[1])
+
+	// long plans are truncated in the check run rather than split over comments
+	_, _, err = reporter.Report(strings.Repeat("  + resource\n", 10000), AsComment("plan"))
+	assert.NoError(t, err)
+	assert.Empty(t, service.CommentsPerPr[1])
+	assert.LessOrEqual(t, len(service.checkRuns["1"].output.Text), checkRunTextMaxLength)
+
+	// every project gets a check run of its own
+	_, _, err = ForProject(&CiReporter{CiService: service, PrNumber: 1, ReportStrategy: &CheckRunStrategy{}}, "db", "db").Report("ok", AsComment("plan"))
+	assert.NoError(t, err)
+	assert.Equal(t, "digger/db", service.checkRuns["2"].name)
+}
+
+func TestCheckRunStrategyAnnotatesPolicyViolations(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := "projects:\n- name: db\n  dir: db\n- name: app\n  dir: app\n"
+	assert.NoError(t, os.WriteFile("digger.yml", []byte(config), 0644))
+
+	service := checkRunCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, checkRuns: map[string]*checkRun{}}
+	strategy := (&CheckRunStrategy{}).ForProject("app", "app")
+	report := "Plan policy checks failed validation checks:<br>    no public buckets allowed<br>    instances must be tagged<br>"
+
+	_, _, err := strategy.Report(service, 1, report, AsComment("policy"), true)
+	assert.NoError(t, err)
+	run := service.checkRuns["1"]
+	assert.Equal(t, "failure", run.conclusion)
+	assert.Len(t, run.output.Annotations, 2)
+	assert.Equal(t, "digger.yml", run.output.Annotations[0].Path)
+	assert.Equal(t, 4, run.output.Annotations[0].StartLine)
+	assert.Equal(t, "no public buckets allowed", run.output.Annotations[0].Message)
+	assert.Equal(t, "Plan policy violation in app", run.output.Annotations[0].Title)
+
+	// a project missing from the config has no block to point at
+	_, _, err = (&CheckRunStrategy{}).ForProject("generated", "generated").Report(service, 1, report, AsComment("policy"), true)
+	assert.NoError(t, err)
+	assert.Empty(t, service.checkRuns["2"].output.Annotations)
+	assert.Equal(t, "failure", service.checkRuns["2"].conclusion)
+	assert.Contains(t, service.checkRuns["2"].output.Text, "no public buckets allowed")
+}
+
+func TestProjectBlockLine(t *testing.T) {
+	config := []byte("generate_projects:\n  include: \"**\"\nprojects:\n  - name: app\n    dir: app\n\n  - dir: db\n    name: db\n")
+	assert.Equal(t, 4, projectBlockLine(config, "app"))
+	assert.Equal(t, 7, projectBlockLine(config, "db"))
+	assert.Equal(t, 0, projectBlockLine(config, "web"))
+	assert.Equal(t, 0, projectBlockLine([]byte("projects: ["), "app"))
+}
+
+func TestForProjectLeavesLazyReporterUnchanged(t *testing.T) {
+	service := checkRunCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, checkRuns: map[string]*checkRun{}}
+	strategy := &CheckRunStrategy{}
+	lazy := NewCiReporterLazy(CiReporter{CiService: service, PrNumber: 1, ReportStrategy: strategy})
+
+	_, _, err := ForProject(lazy, "app", "app").Report("app plan", AsComment("plan"))
+	assert.NoError(t, err)
+	_, _, err = ForProject(lazy, "db", "db").Report("db plan", AsComment("plan"))
+	assert.NoError(t, err)
+	assert.Same(t, strategy, lazy.CiReporter.ReportStrategy)
+	assert.Empty(t, service.checkRuns)
+
+	// every report is published into the check run of its own project on flush
+	_, _, err = lazy.Flush()
+	assert.NoError(t, err)
+	assert.Len(t, service.checkRuns, 2)
+	assert.Equal(t, "digger/app", service.checkRuns["1"].name)
+	assert.Contains(t, service.checkRuns["1"].output.Text, "app plan")
+	assert.Equal(t, "digger/db", service.checkRuns["2"].name)
+	assert.Contains(t, service.checkRuns["2"].output.Text, "db plan")
+}
+
+func TestCheckRunStrategyFallsBackToComments(t *testing.T) {
+	service := limitedCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, maxLength: DefaultCommentMaxLength}
+	reporter := CiReporter{CiService: service, PrNumber: 1, ReportStrategy: &CheckRunStrategy{}}
+
+	_, _, err := reporter.Report("plan output", AsComment("plan"))
+	assert.NoError(t, err)
+	assert.Len(t, service.CommentsPerPr[1], 1)
+}
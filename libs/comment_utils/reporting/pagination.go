@@ -0,0 +1,100 @@
+package reporting
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/diggerhq/digger/libs/ci"
+)
+
+// DefaultCommentMaxLength applies to services that do not report their limit, it is GitHub's
+const DefaultCommentMaxLength = 65536
+
+// commentOverhead leaves room for the titles and markers added around a report part
+const commentOverhead = 1024
+
+// MaxCommentLength returns the longest comment ciService accepts
+func MaxCommentLength(ciService ci.PullRequestService) int {
+	if limiter, ok := ciService.(ci.CommentLengthLimiter); ok {
+		return limiter.MaxCommentLength()
+	}
+	return DefaultCommentMaxLength
+}
+
+// SplitReport splits report at line breaks into parts of at most maxLength bytes.
+// Lines longer than maxLength are cut.
+func SplitReport(report string, maxLength int) []string {
+	if len(report) <= maxLength {
+		return []string{report}
+	}
+
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.Split(report, "\n") {
+		for len(line) > maxLength {
+			cut := maxLength
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			parts = append(parts, line[:cut])
+			line = line[cut:]
+		}
+		if current.Len() > 0 && current.Len()+1+len(line) > maxLength {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// reportInParts reports through strategy, splitting reports too long for a single comment.
+// The first part goes through strategy, the rest follow as ordered continuation comments.
+func reportInParts(strategy ReportStrategy, ciService ci.PullRequestService, prNumber int, report string, reportFormatter func(report string) string, supportsCollapsibleComment bool) (string, string, error) {
+	// check runs are not comments, they truncate their own output
+	if _, ok := strategy.(*CheckRunStrategy); ok {
+		if _, ok := ciService.(ci.CheckRunService); ok {
+			return strategy.Report(ciService, prNumber, report, reportFormatter, supportsCollapsibleComment)
+		}
+	}
+
+	maxLength := max(MaxCommentLength(ciService)-len(reportFormatter(""))-commentOverhead, commentOverhead)
+	parts := SplitReport(report, maxLength)
+	if len(parts) == 1 {
+		return strategy.Report(ciService, prNumber, report, reportFormatter, supportsCollapsibleComment)
+	}
+
+	slog.Info("report exceeds the comment length limit, splitting it",
+		"prNumber", prNumber,
+		"length", len(report),
+		"parts", len(parts))
+
+	firstFormatter := func(report string) string {
+		return reportFormatter(report) + fmt.Sprintf("\n\nContinued in the next comment (part 1 of %d)", len(parts))
+	}
+	commentId, commentUrl, err := strategy.Report(ciService, prNumber, parts[0], firstFormatter, supportsCollapsibleComment)
+	if err != nil {
+		return "", "", err
+	}
+	for i, part := range parts[1:] {
+		header := fmt.Sprintf("Continued from the previous comment (part %d of %d)\n\n", i+2, len(parts))
+		_, err := ciService.PublishComment(prNumber, header+reportFormatter(part))
+		if err != nil {
+			slog.Error("error publishing continuation comment", "error", err, "prNumber", prNumber, "part", i+2)
+			return commentId, commentUrl, fmt.Errorf("error publishing continuation comment: %v", err)
+		}
+	}
+	return commentId, commentUrl, nil
+}
@@ -0,0 +1,75 @@
+package reporting
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+)
+
+// limitedCiService is a MockCiService with a small comment limit that returns published comments
+type limitedCiService struct {
+	MockCiService
+	maxLength int
+}
+
+func (t limitedCiService) MaxCommentLength() int {
+	return t.maxLength
+}
+
+func (t limitedCiService) PublishComment(prNumber int, comment string) (*ci.Comment, error) {
+	id := strconv.Itoa(len(t.CommentsPerPr[prNumber]) + 1)
+	t.CommentsPerPr[prNumber] = append(t.CommentsPerPr[prNumber], &ci.Comment{Id: id, Body: &comment})
+	return &ci.Comment{Id: id, Body: &comment}, nil
+}
+
+func TestSplitReport(t *testing.T) {
+	assert.Equal(t, []string{"short"}, SplitReport("short", 10))
+	assert.Equal(t, []string{"aaa\nbbb", "ccc"}, SplitReport("aaa\nbbb\nccc", 8))
+	assert.Equal(t, []string{"aaaaa", "aaaaa", "bb"}, SplitReport("aaaaaaaaaa\nbb", 5))
+	// multi byte characters are not cut in half
+	assert.Equal(t, []string{"abé", "éé"}, SplitReport("abééé", 4))
+}
+
+func TestCiReporterSplitsLongReports(t *testing.T) {
+	service := limitedCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, maxLength: 3000}
+	reporter := CiReporter{CiService: service, PrNumber: 1, ReportStrategy: MultipleCommentsStrategy{}}
+
+	var lines []string
+	for i := 0; i < 400; i++ {
+		lines = append(lines, "  + resource line "+strconv.Itoa(i))
+	}
+	_, _, err := reporter.Report(strings.Join(lines, "\n"), GetTerraformOutputAsComment("Plan output"))
+	assert.NoError(t, err)
+
+	comments := service.CommentsPerPr[1]
+	assert.Greater(t, len(comments), 2)
+	var joined []string
+	for i, comment := range comments {
+		assert.LessOrEqual(t, len(*comment.Body), 3000)
+		assert.Contains(t, *comment.Body, "```terraform")
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(*comment.Body, "Continued from the previous comment (part "+strconv.Itoa(i+1)+" of "))
+		}
+		body := *comment.Body
+		body = body[strings.Index(body, "```terraform\n")+len("```terraform\n") : strings.LastIndex(body, "\n```")]
+		joined = append(joined, body)
+	}
+	assert.Equal(t, strings.Join(lines, "\n"), strings.Join(joined, "\n"))
+}
+
+func TestUpsertCommentStartsNewCommentWhenFull(t *testing.T) {
+	service := limitedCiService{MockCiService: MockCiService{CommentsPerPr: map[int][]*ci.Comment{}}, maxLength: 3000}
+	reporter := CiReporter{CiService: service, PrNumber: 1, IsSupportMarkdown: true, ReportStrategy: CommentPerRunStrategy{Title: "Run", TimeOfRun: time.Now()}}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := reporter.Report(strings.Repeat("x", 900), AsComment("report"))
+		assert.NoError(t, err)
+	}
+	assert.Len(t, service.CommentsPerPr[1], 2)
+	assert.Equal(t, 2, strings.Count(*service.CommentsPerPr[1][0].Body, "report\n"))
+	assert.Equal(t, 1, strings.Count(*service.CommentsPerPr[1][1].Body, "report\n"))
+}
@@ -17,7 +17,7 @@ type CiReporter struct {
 }
 
 func (ciReporter CiReporter) Report(report string, reportFormatting func(report string) string) (string, string, error) {
-	commentId, commentUrl, err := ciReporter.ReportStrategy.Report(ciReporter.CiService, ciReporter.PrNumber, report, reportFormatting, ciReporter.SupportsMarkdown())
+	commentId, commentUrl, err := reportInParts(ciReporter.ReportStrategy, ciReporter.CiService, ciReporter.PrNumber, report, reportFormatting, ciReporter.SupportsMarkdown())
 	return commentId, commentUrl, err
 }
 
@@ -38,6 +38,7 @@ type CiReporterLazy struct {
 	isSuppressed bool
 	reports      []string
 	formatters   []func(report string) string
+	strategies   []ReportStrategy
 }
 
 func NewCiReporterLazy(ciReporter CiReporter) *CiReporterLazy {
@@ -46,12 +47,19 @@ func NewCiReporterLazy(ciReporter CiReporter) *CiReporterLazy {
 		isSuppressed: false,
 		reports:      []string{},
 		formatters:   []func(report string) string{},
+		strategies:   []ReportStrategy{},
 	}
 }
 
 func (lazyReporter *CiReporterLazy) Report(report string, reportFormatting func(report string) string) (string, string, error) {
+	return lazyReporter.report(report, reportFormatting, lazyReporter.CiReporter.ReportStrategy)
+}
+
+// report buffers a report that is published with strategy on the next flush
+func (lazyReporter *CiReporterLazy) report(report string, reportFormatting func(report string) string, strategy ReportStrategy) (string, string, error) {
 	lazyReporter.reports = append(lazyReporter.reports, report)
 	lazyReporter.formatters = append(lazyReporter.formatters, reportFormatting)
+	lazyReporter.strategies = append(lazyReporter.strategies, strategy)
 	return "", "", nil
 }
 
@@ -63,7 +71,7 @@ func (lazyReporter *CiReporterLazy) Flush() (string, string, error) {
 	var commentId, commentUrl string
 	for i := range lazyReporter.formatters {
 		var err error
-		commentId, commentUrl, err = lazyReporter.CiReporter.ReportStrategy.Report(lazyReporter.CiReporter.CiService, lazyReporter.CiReporter.PrNumber, lazyReporter.reports[i], lazyReporter.formatters[i], lazyReporter.SupportsMarkdown())
+		commentId, commentUrl, err = reportInParts(lazyReporter.strategies[i], lazyReporter.CiReporter.CiService, lazyReporter.CiReporter.PrNumber, lazyReporter.reports[i], lazyReporter.formatters[i], lazyReporter.SupportsMarkdown())
 		if err != nil {
 			slog.Error("failed to report strategy", "error", err)
 			return "", "", err
@@ -72,6 +80,7 @@ func (lazyReporter *CiReporterLazy) Flush() (string, string, error) {
 	// clear the buffers
 	lazyReporter.formatters = []func(comment string) string{}
 	lazyReporter.reports = []string{}
+	lazyReporter.strategies = []ReportStrategy{}
 	return commentId, commentUrl, nil
 }
 
@@ -107,6 +116,45 @@ type ReportStrategy interface {
 	Report(ciService ci.PullRequestService, PrNumber int, report string, reportFormatter func(report string) string, supportsCollapsibleComment bool) (commentId string, commentUrl string, error error)
 }
 
+// ProjectReportStrategy is implemented by strategies that report each project separately
+type ProjectReportStrategy interface {
+	ForProject(projectName string, projectDir string) ReportStrategy
+}
+
+// projectLazyReporter buffers the reports of one project in a shared lazy reporter, so that the
+// caller still flushes them, and publishes them with the strategy of the project
+type projectLazyReporter struct {
+	*CiReporterLazy
+	strategy ReportStrategy
+}
+
+func (reporter projectLazyReporter) Report(report string, reportFormatting func(report string) string) (string, string, error) {
+	return reporter.report(report, reportFormatting, reporter.strategy)
+}
+
+// ForProject returns the reporter for the reports of one project. The given reporter is left
+// unchanged, reports of a lazy reporter are still published by its Flush.
+func ForProject(reporter Reporter, projectName string, projectDir string) Reporter {
+	switch r := reporter.(type) {
+	case *CiReporter:
+		if strategy, ok := r.ReportStrategy.(ProjectReportStrategy); ok {
+			scoped := *r
+			scoped.ReportStrategy = strategy.ForProject(projectName, projectDir)
+			return &scoped
+		}
+	case CiReporter:
+		if strategy, ok := r.ReportStrategy.(ProjectReportStrategy); ok {
+			r.ReportStrategy = strategy.ForProject(projectName, projectDir)
+			return r
+		}
+	case *CiReporterLazy:
+		if strategy, ok := r.CiReporter.ReportStrategy.(ProjectReportStrategy); ok {
+			return projectLazyReporter{CiReporterLazy: r, strategy: strategy.ForProject(projectName, projectDir)}
+		}
+	}
+	return reporter
+}
+
 type CommentPerRunStrategy struct {
 	Title     string
 	TimeOfRun time.Time
@@ -134,8 +182,10 @@ func upsertComment(ciService ci.PullRequestService, PrNumber int, report string,
 	commentIdForThisRun := ""
 	var commentBody string
 	var commentUrl string
-	for _, comment := range comments {
-		if strings.Contains(*comment.Body, reportTitle) {
+	// the latest comment of the run, earlier ones are full when reports overflowed into a new one
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if comment.Body != nil && strings.Contains(*comment.Body, reportTitle) {
 			commentIdForThisRun = comment.Id
 			commentBody = *comment.Body
 			commentUrl = comment.Url
@@ -143,7 +193,7 @@ func upsertComment(ciService ci.PullRequestService, PrNumber int, report string,
 		}
 	}
 
-	if commentIdForThisRun == "" {
+	if commentIdForThisRun == "" || len(commentBody)+len(report) > MaxCommentLength(ciService)-commentOverhead {
 		var commentMessage string
 		if !supportsCollapsible {
 			commentMessage = AsComment(reportTitle)(report)
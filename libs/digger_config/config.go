@@ -30,6 +30,7 @@ type DiggerConfig struct {
 	TraverseToNestedProjects      bool
 	Reporting                     ReporterConfig
 	ReportTerraformOutputs        bool
+	MaxParallelJobs               int
 }
 
 type ReporterConfig struct {
@@ -69,6 +70,37 @@ type Project struct {
 	AwsCognitoOidcConfig *AwsCognitoOidcConfig
 	Generated            bool
 	PulumiStack          string
+	// TerraformVersion and OpenTofuVersion pin the binary the project runs with
+	TerraformVersion string
+	OpenTofuVersion  string
+	// DependencyOutputs are the outputs of projects in DependencyProjects passed to the project as TF_VAR_ variables
+	DependencyOutputs []DependencyOutput
+	// LocalModuleDirs are the repo relative directories of local modules the project
+	// calls, directly or through other modules. They act as implicit include patterns.
+	LocalModuleDirs []string
+}
+
+// DependencyOutput passes the output of a project the project depends on as the TF_VAR_ variable Variable
+type DependencyOutput struct {
+	Project  string `json:"project"`
+	Output   string `json:"output"`
+	Variable string `json:"variable"`
+}
+
+// PinnedTerraformVersion returns the terraform version of the project, or of its workflow when the project pins none
+func (p Project) PinnedTerraformVersion(workflow Workflow) string {
+	if p.TerraformVersion != "" || p.OpenTofuVersion != "" {
+		return p.TerraformVersion
+	}
+	return workflow.TerraformVersion
+}
+
+// PinnedOpenTofuVersion returns the opentofu version of the project, or of its workflow when the project pins none
+func (p Project) PinnedOpenTofuVersion(workflow Workflow) string {
+	if p.TerraformVersion != "" || p.OpenTofuVersion != "" {
+		return p.OpenTofuVersion
+	}
+	return workflow.OpenTofuVersion
 }
 
 type Workflow struct {
@@ -76,6 +108,9 @@ type Workflow struct {
 	Plan          *Stage
 	Apply         *Stage
 	Configuration *WorkflowConfiguration
+	// TerraformVersion and OpenTofuVersion apply to the projects of the workflow that don't pin a version
+	TerraformVersion string
+	OpenTofuVersion  string
 }
 
 type WorkflowConfiguration struct {
@@ -104,12 +104,32 @@ func copyProjects(projects []*ProjectYaml) []Project {
 			awsCognitoOidc,
 			p.Generated,
 			workspace,
+			p.TerraformVersion,
+			p.OpenTofuVersion,
+			copyDependencyOutputs(p.DependencyOutputs),
+			nil,
 		}
 		result[i] = item
 	}
 	return result
 }
 
+// copyDependencyOutputs defaults the variable of an output to the name of the output
+func copyDependencyOutputs(dependencyOutputs []DependencyOutputYaml) []DependencyOutput {
+	if len(dependencyOutputs) == 0 {
+		return nil
+	}
+	result := make([]DependencyOutput, len(dependencyOutputs))
+	for i, o := range dependencyOutputs {
+		variable := o.Variable
+		if variable == "" {
+			variable = o.Output
+		}
+		result[i] = DependencyOutput{Project: o.Project, Output: o.Output, Variable: variable}
+	}
+	return result
+}
+
 func copyTerraformEnvConfig(terraformEnvConfig *TerraformEnvConfigYaml) *TerraformEnvConfig {
 	if terraformEnvConfig == nil {
 		return &TerraformEnvConfig{}
@@ -183,6 +203,8 @@ func copyWorkflows(workflows map[string]*WorkflowYaml) map[string]Workflow {
 				plan,
 				apply,
 				configuration,
+				w.TerraformVersion,
+				w.OpenTofuVersion,
 			}
 			result[i] = item
 		}
@@ -290,6 +312,15 @@ func ConvertDiggerYamlToConfig(diggerYaml *DiggerConfigYaml) (*DiggerConfig, gra
 		diggerConfig.PrLocks = true
 	}
 
+	if diggerYaml.MaxParallelJobs != nil {
+		if *diggerYaml.MaxParallelJobs < 1 {
+			return nil, nil, fmt.Errorf("max_parallel_jobs must be at least 1, got %v", *diggerYaml.MaxParallelJobs)
+		}
+		diggerConfig.MaxParallelJobs = *diggerYaml.MaxParallelJobs
+	} else {
+		diggerConfig.MaxParallelJobs = 1
+	}
+
 	if diggerYaml.Telemetry != nil {
 		diggerConfig.Telemetry = *diggerYaml.Telemetry
 	} else {
@@ -228,6 +228,8 @@ func LoadDiggerConfig(workingDir string, generateProjects bool, changedFiles []s
 		return nil, nil, nil, nil, err
 	}
 
+	resolveLocalModuleDirs(workingDir, config)
+
 	err = ValidateDiggerConfig(config)
 	if err != nil {
 		slog.Warn("digger config validation failed", "error", err)
@@ -665,6 +667,66 @@ func validatePulumiProject(project *Project) error {
 	return nil
 }
 
+// toolVersionPattern matches the exact versions terraform_version and opentofu_version accept
+var toolVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$`)
+
+// generatedToolVersion normalizes the terraform version terragrunt gives a generated project, such as
+// v1.5.7 or = 1.5.7. Versions that are not exact are left for terraform to check.
+func generatedToolVersion(projectName string, version string) string {
+	normalized := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(version), "=")), "v")
+	if normalized == "" {
+		return ""
+	}
+	if !toolVersionPattern.MatchString(normalized) {
+		slog.Warn("ignoring terraform version of generated project, it is not an exact version",
+			"projectName", projectName,
+			"version", version)
+		return ""
+	}
+	return normalized
+}
+
+func validateToolVersions(project *Project) error {
+	if project.TerraformVersion != "" && project.OpenTofuVersion != "" {
+		return fmt.Errorf("project %v pins both terraform_version and opentofu_version, please specify one", project.Name)
+	}
+	if project.TerraformVersion != "" && (project.OpenTofu || project.Pulumi) {
+		return fmt.Errorf("terraform_version can't be used for project %v, it doesn't run terraform", project.Name)
+	}
+	if project.OpenTofuVersion != "" && !project.OpenTofu && !project.Terragrunt {
+		return fmt.Errorf("opentofu_version can only be used for opentofu or terragrunt projects, project %v is neither", project.Name)
+	}
+	for _, version := range []string{project.TerraformVersion, project.OpenTofuVersion} {
+		if version != "" && !toolVersionPattern.MatchString(version) {
+			return fmt.Errorf("invalid version %v for project %v, expecting an exact version such as 1.9.8", version, project.Name)
+		}
+	}
+	return nil
+}
+
+// variableNamePattern matches the terraform variable names dependency outputs can be passed as
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func validateDependencyOutputs(project *Project) error {
+	variables := make(map[string]bool)
+	for _, dependencyOutput := range project.DependencyOutputs {
+		if !lo.Contains(project.DependencyProjects, dependencyOutput.Project) {
+			return fmt.Errorf("project %v takes output %v from %v, which is not in its depends_on", project.Name, dependencyOutput.Output, dependencyOutput.Project)
+		}
+		if dependencyOutput.Output == "" {
+			return fmt.Errorf("project %v has a dependency output from %v without an output name", project.Name, dependencyOutput.Project)
+		}
+		if !variableNamePattern.MatchString(dependencyOutput.Variable) {
+			return fmt.Errorf("invalid variable name %v for dependency output of project %v", dependencyOutput.Variable, project.Name)
+		}
+		if variables[dependencyOutput.Variable] {
+			return fmt.Errorf("project %v takes more than one dependency output as variable %v", project.Name, dependencyOutput.Variable)
+		}
+		variables[dependencyOutput.Variable] = true
+	}
+	return nil
+}
+
 func ValidateProjects(config *DiggerConfig) error {
 	slog.Debug("validating projects configuration", "projectCount", len(config.Projects))
 
@@ -679,6 +741,16 @@ func ValidateProjects(config *DiggerConfig) error {
 		if err != nil {
 			return err
 		}
+
+		err = validateToolVersions(&project)
+		if err != nil {
+			return err
+		}
+
+		err = validateDependencyOutputs(&project)
+		if err != nil {
+			return err
+		}
 	}
 
 	slog.Debug("projects validation successful")
@@ -747,6 +819,11 @@ func ValidateDiggerConfig(config *DiggerConfig) error {
 	}
 
 	for name, w := range config.Workflows {
+		for _, version := range []string{w.TerraformVersion, w.OpenTofuVersion} {
+			if version != "" && !toolVersionPattern.MatchString(version) {
+				return fmt.Errorf("invalid version %v for workflow %v, expecting an exact version such as 1.9.8", version, name)
+			}
+		}
 		for i, s := range w.Plan.Steps {
 			if s.Action == "" {
 				slog.Error("empty action in plan step",
@@ -916,6 +993,7 @@ func hydrateDiggerConfigYamlWithTerragrunt(configYaml *DiggerConfigYaml, parsing
 			Generated:            true,
 			AwsRoleToAssume:      parsingConfig.AwsRoleToAssume,
 			AwsCognitoOidcConfig: parsingConfig.AwsCognitoOidcConfig,
+			TerraformVersion:     generatedToolVersion(atlantisProject.Name, atlantisProject.TerraformVersion),
 		}
 
 		if parsingConfig.DependsOnOrdering != nil && *parsingConfig.DependsOnOrdering {
@@ -994,6 +1072,9 @@ func (c *DiggerConfig) GetModifiedProjects(changedFiles []string) ([]Project, ma
 		} else {
 			includePatterns = append(includePatterns, filepath.Join(project.Dir, "*"))
 		}
+		for _, moduleDir := range project.LocalModuleDirs {
+			includePatterns = append(includePatterns, filepath.Join(moduleDir, "**", "*"))
+		}
 
 		for _, changedFile := range changedFiles {
 			// all our patterns are the globale dir pattern + the include patterns specified by user
@@ -1006,7 +1087,11 @@ func (c *DiggerConfig) GetModifiedProjects(changedFiles []string) ([]Project, ma
 						"dir", project.Dir)
 				}
 
+				// a change in a local module is reported against the module so it is clear why the project was picked
 				changedDir := filepath.Dir(changedFile)
+				if moduleDir, ok := project.localModuleForFile(changedFile); ok {
+					changedDir = moduleDir
+				}
 				if !lo.Contains(sourceChangesForProject, changedDir) {
 					sourceChangesForProject = append(sourceChangesForProject, changedDir)
 					slog.Debug("adding source change directory for project",
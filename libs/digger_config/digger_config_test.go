@@ -542,6 +542,94 @@ workflows:
 	assert.Equal(t, "myregex", *r)
 }
 
+func TestDiggerConfigToolVersions(t *testing.T) {
+	tempDir, teardown := setUp()
+	defer teardown()
+
+	diggerCfg := `
+projects:
+- name: pinned
+  dir: pinned
+  workflow: versioned
+  terraform_version: 1.5.7
+- name: inherited
+  dir: inherited
+  workflow: versioned
+- name: tofu
+  dir: tofu
+  opentofu: true
+  workflow: versioned
+workflows:
+  versioned:
+    terraform_version: 1.9.8
+    opentofu_version: 1.8.3
+`
+	deleteFile := createFile(path.Join(tempDir, "digger.yaml"), diggerCfg)
+	defer deleteFile()
+
+	dg, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+	assert.NoError(t, err)
+	workflow := dg.Workflows["versioned"]
+	assert.Equal(t, "1.5.7", dg.GetProject("pinned").PinnedTerraformVersion(workflow))
+	assert.Equal(t, "", dg.GetProject("pinned").PinnedOpenTofuVersion(workflow))
+	assert.Equal(t, "1.9.8", dg.GetProject("inherited").PinnedTerraformVersion(workflow))
+	assert.Equal(t, "1.8.3", dg.GetProject("tofu").PinnedOpenTofuVersion(workflow))
+
+	for _, invalid := range []string{
+		"projects:\n- name: app\n  dir: app\n  terraform_version: \">= 1.5\"\n",
+		"projects:\n- name: app\n  dir: app\n  opentofu_version: 1.8.3\n",
+		"projects:\n- name: app\n  dir: app\n  opentofu: true\n  terraform_version: 1.5.7\n",
+		"projects:\n- name: app\n  dir: app\n  workflow: w\nworkflows:\n  w:\n    terraform_version: latest\n",
+	} {
+		deleteFile := createFile(path.Join(tempDir, "digger.yaml"), invalid)
+		_, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+		assert.Error(t, err, invalid)
+		deleteFile()
+	}
+}
+
+func TestDiggerConfigDependencyOutputs(t *testing.T) {
+	tempDir, teardown := setUp()
+	defer teardown()
+
+	diggerCfg := `
+projects:
+- name: network
+  dir: network
+- name: app
+  dir: app
+  depends_on: [network]
+  dependency_outputs:
+  - project: network
+    output: vpc_id
+  - project: network
+    output: private_subnet_ids
+    variable: subnet_ids
+`
+	deleteFile := createFile(path.Join(tempDir, "digger.yaml"), diggerCfg)
+	defer deleteFile()
+
+	dg, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []DependencyOutput{
+		{Project: "network", Output: "vpc_id", Variable: "vpc_id"},
+		{Project: "network", Output: "private_subnet_ids", Variable: "subnet_ids"},
+	}, dg.GetProject("app").DependencyOutputs)
+	assert.Empty(t, dg.GetProject("network").DependencyOutputs)
+
+	for _, invalid := range []string{
+		"projects:\n- name: network\n  dir: network\n- name: app\n  dir: app\n  dependency_outputs:\n  - project: network\n    output: vpc_id\n",
+		"projects:\n- name: network\n  dir: network\n- name: app\n  dir: app\n  depends_on: [network]\n  dependency_outputs:\n  - project: network\n",
+		"projects:\n- name: network\n  dir: network\n- name: app\n  dir: app\n  depends_on: [network]\n  dependency_outputs:\n  - project: network\n    output: vpc_id\n    variable: \"vpc id\"\n",
+		"projects:\n- name: network\n  dir: network\n- name: app\n  dir: app\n  depends_on: [network]\n  dependency_outputs:\n  - project: network\n    output: vpc_id\n  - project: network\n    output: id\n    variable: vpc_id\n",
+	} {
+		deleteFile := createFile(path.Join(tempDir, "digger.yaml"), invalid)
+		_, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+		assert.Error(t, err, invalid)
+		deleteFile()
+	}
+}
+
 func TestDiggerConfigCustomWorkflowMissingParams(t *testing.T) {
 	tempDir, teardown := setUp()
 	defer teardown()
@@ -1223,6 +1311,35 @@ generate_projects:
 	print(config)
 }
 
+func TestDiggerTerragruntProjectGenerationTerraformVersion(t *testing.T) {
+	tempDir, teardown := setUp()
+	defer teardown()
+
+	diggerCfg := `
+generate_projects:
+  terragrunt: true
+  terragrunt_parsing:
+    createProjectName: true
+    defaultWorkflow: default
+`
+	defer createFile(path.Join(tempDir, "digger.yml"), diggerCfg)()
+	for dir, version := range map[string]string{"prefixed": "v1.5.7", "range": ">= 1.5"} {
+		assert.NoError(t, os.MkdirAll(path.Join(tempDir, dir), os.ModePerm))
+		defer createFile(path.Join(tempDir, dir, "terragrunt.hcl"), fmt.Sprintf("locals {\n  atlantis_terraform_version = %q\n}\n\n%v", version, hclFile))()
+	}
+
+	config, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, config.Projects, 2)
+	versions := map[string]string{}
+	for _, project := range config.Projects {
+		versions[project.Dir] = project.TerraformVersion
+	}
+	assert.Equal(t, "1.5.7", versions["prefixed"])
+	// version ranges are left for terraform to check
+	assert.Equal(t, "", versions["range"])
+}
+
 func TestDiggerTerragruntInfrastructureLiveExample(t *testing.T) {
 	tempDir, teardown := setUp()
 	defer teardown()
@@ -1449,6 +1566,56 @@ func TestGetModifiedProjectsReturnsCorrectSourceMappingWithRelativePaths(t *test
 	assert.Equal(t, expectedImpactingLocations["prod"].ImpactingLocations, projectSourceMapping["prod"].ImpactingLocations)
 }
 
+func TestGetModifiedProjectsDetectsLocalModules(t *testing.T) {
+	tempDir, teardown := setUp()
+	defer teardown()
+
+	for _, dir := range []string{"dev", "prod", "modules/vpc", "modules/subnets", "modules/unused"} {
+		assert.NoError(t, os.MkdirAll(path.Join(tempDir, dir), 0755))
+	}
+	defer createFile(path.Join(tempDir, "digger.yml"), `
+projects:
+- name: dev
+  dir: dev
+- name: prod
+  dir: prod
+`)()
+	defer createFile(path.Join(tempDir, "dev", "main.tf"), `
+module "vpc" {
+  source = "../modules/vpc"
+}
+module "registry" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+`)()
+	defer createFile(path.Join(tempDir, "prod", "main.tf"), `
+module "outside" {
+  source = "../../outside"
+}
+`)()
+	defer createFile(path.Join(tempDir, "modules", "vpc", "main.tf"), `
+module "subnets" {
+  source = "../subnets"
+}
+`)()
+	defer createFile(path.Join(tempDir, "modules", "subnets", "main.tf"), `
+module "vpc" {
+  source = "../vpc"
+}
+`)()
+
+	dg, _, _, _, err := LoadDiggerConfig(tempDir, true, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"modules/subnets", "modules/vpc"}, dg.GetProject("dev").LocalModuleDirs)
+	assert.Empty(t, dg.GetProject("prod").LocalModuleDirs)
+
+	impactedProjects, projectSourceMapping := dg.GetModifiedProjects([]string{"modules/subnets/templates/cidr.tpl", "modules/unused/main.tf"})
+	assert.Equal(t, 1, len(impactedProjects))
+	assert.Equal(t, "dev", impactedProjects[0].Name)
+	assert.Equal(t, []string{"modules/subnets"}, projectSourceMapping["dev"].ImpactingLocations)
+	assert.Empty(t, projectSourceMapping["prod"].ImpactingLocations)
+}
+
 func TestCognitoTokenSetFromMinConfig(t *testing.T) {
 	diggerCfg := `
 projects:
@@ -0,0 +1,295 @@
+package digger_config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a problem found in digger.yml, positioned at the yaml node it was found at
+type LintIssue struct {
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Column   int          `json:"column"`
+	Severity LintSeverity `json:"severity"`
+	Rule     string       `json:"rule"`
+	Message  string       `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", i.File, i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+type configLinter struct {
+	workingDir string
+	fileName   string
+	issues     []LintIssue
+	files      []string
+}
+
+// LintDiggerConfig checks the digger.yml in workingDir for mistakes that load fine but do not do
+// what was intended. config is the loaded configuration, including generated projects, and may
+// be nil when loading failed; projects are then taken from the yaml alone.
+func LintDiggerConfig(workingDir string, config *DiggerConfig) ([]LintIssue, error) {
+	fileName, err := retrieveConfigFile(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if fileName == "" {
+		return nil, errors.New("could not find digger.yml or digger.yaml in root of repository")
+	}
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %v: %v", fileName, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return nil, fmt.Errorf("error parsing yaml: %v", err)
+	}
+	var configYaml DiggerConfigYaml
+	if err := root.Decode(&configYaml); err != nil {
+		return nil, fmt.Errorf("error parsing yaml: %v", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	document := root.Content[0]
+
+	linter := &configLinter{workingDir: workingDir, fileName: filepath.Base(fileName)}
+	linter.lintProjects(document, &configYaml, config)
+	linter.lintWorkflows(document, &configYaml, config)
+	linter.lintGenerateProjects(document, &configYaml)
+
+	sort.SliceStable(linter.issues, func(i, j int) bool {
+		if linter.issues[i].Line != linter.issues[j].Line {
+			return linter.issues[i].Line < linter.issues[j].Line
+		}
+		return linter.issues[i].Column < linter.issues[j].Column
+	})
+	return linter.issues, nil
+}
+
+func (l *configLinter) report(node *yaml.Node, severity LintSeverity, rule string, format string, args ...any) {
+	issue := LintIssue{File: l.fileName, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	l.issues = append(l.issues, issue)
+}
+
+func (l *configLinter) lintProjects(document *yaml.Node, configYaml *DiggerConfigYaml, config *DiggerConfig) {
+	projectNodes := sequenceItems(mappingValue(document, "projects"))
+
+	projectNames := map[string]bool{}
+	for _, project := range configYaml.Projects {
+		if project != nil {
+			projectNames[project.Name] = true
+		}
+	}
+	if config != nil {
+		for _, project := range config.Projects {
+			projectNames[project.Name] = true
+		}
+	}
+
+	seenDirs := map[string]string{}
+	for i, project := range configYaml.Projects {
+		if i >= len(projectNodes) || project == nil {
+			break
+		}
+		node := projectNodes[i]
+
+		dirNode := mappingValue(node, "dir")
+		if info, err := os.Stat(filepath.Join(l.workingDir, project.Dir)); err != nil || !info.IsDir() {
+			l.report(orNode(dirNode, node), LintError, "project-dir-missing", "dir %q of project %q does not exist", project.Dir, project.Name)
+		}
+
+		key := filepath.Clean(project.Dir) + "\x00" + project.Workspace
+		if other, ok := seenDirs[key]; ok {
+			l.report(orNode(dirNode, node), LintError, "duplicate-project-dir", "projects %q and %q both use dir %q with workspace %q", other, project.Name, project.Dir, project.Workspace)
+		} else {
+			seenDirs[key] = project.Name
+		}
+
+		l.lintPatterns(project, mappingValue(node, "include_patterns"), "include")
+		l.lintPatterns(project, mappingValue(node, "exclude_patterns"), "exclude")
+
+		if _, ok := configYaml.Workflows[project.Workflow]; !ok && project.Workflow != "default" {
+			l.report(orNode(mappingValue(node, "workflow"), node), LintError, "undefined-workflow", "project %q uses workflow %q which is not defined", project.Name, project.Workflow)
+		}
+
+		for _, dependencyNode := range sequenceItems(mappingValue(node, "depends_on")) {
+			if !projectNames[dependencyNode.Value] {
+				l.report(dependencyNode, LintError, "unknown-dependency", "project %q depends on unknown project %q", project.Name, dependencyNode.Value)
+			}
+		}
+	}
+}
+
+// lintPatterns flags patterns that match no file in the repository, resolving them the same
+// way as GetModifiedProjects does
+func (l *configLinter) lintPatterns(project *ProjectYaml, patternsNode *yaml.Node, kind string) {
+	for _, patternNode := range sequenceItems(patternsNode) {
+		pattern := patternNode.Value
+		if strings.HasPrefix(pattern, ".") {
+			pattern = filepath.Join(project.Dir, pattern)
+		}
+		matched := false
+		for _, file := range l.repoFiles() {
+			if MatchIncludeExcludePatternsToFile(file, []string{pattern}, nil) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			l.report(patternNode, LintWarning, "pattern-matches-nothing", "%s pattern %q of project %q matches no files", kind, patternNode.Value, project.Name)
+		}
+	}
+}
+
+func (l *configLinter) lintWorkflows(document *yaml.Node, configYaml *DiggerConfigYaml, config *DiggerConfig) {
+	used := map[string]bool{}
+	for _, project := range configYaml.Projects {
+		if project != nil {
+			used[project.Workflow] = true
+		}
+	}
+	if configYaml.GenerateProjectsConfig != nil {
+		for _, block := range configYaml.GenerateProjectsConfig.Blocks {
+			used[block.Workflow] = true
+		}
+		if parsing := configYaml.GenerateProjectsConfig.TerragruntParsingConfig; parsing != nil {
+			used[parsing.DefaultWorkflow] = true
+		}
+	}
+	if config != nil {
+		for _, project := range config.Projects {
+			used[project.Workflow] = true
+		}
+	}
+
+	workflowsNode := mappingValue(document, "workflows")
+	if workflowsNode == nil || workflowsNode.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(workflowsNode.Content); i += 2 {
+		keyNode := workflowsNode.Content[i]
+		// blocks without a workflow use the default one
+		if !used[keyNode.Value] && !(keyNode.Value == "default" && used[""]) {
+			l.report(keyNode, LintWarning, "unused-workflow", "workflow %q is not used by any project", keyNode.Value)
+		}
+	}
+}
+
+func (l *configLinter) lintGenerateProjects(document *yaml.Node, configYaml *DiggerConfigYaml) {
+	generateNode := mappingValue(document, "generate_projects")
+	generate := configYaml.GenerateProjectsConfig
+	if generate == nil || generateNode == nil {
+		return
+	}
+
+	if generate.Terragrunt && !l.hasTerragruntModules(l.workingDir) {
+		l.report(orNode(mappingValue(generateNode, "terragrunt"), generateNode), LintWarning, "terragrunt-no-matches", "terragrunt project generation finds no terragrunt.hcl files")
+	}
+
+	blockNodes := sequenceItems(mappingValue(generateNode, "blocks"))
+	for i, block := range generate.Blocks {
+		if !block.Terragrunt || i >= len(blockNodes) {
+			continue
+		}
+		rootDir := l.workingDir
+		if block.RootDir != nil {
+			rootDir = filepath.Join(l.workingDir, *block.RootDir)
+		}
+		if !l.hasTerragruntModules(rootDir) {
+			name := block.BlockName
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			l.report(orNode(mappingValue(blockNodes[i], "root_dir"), blockNodes[i]), LintWarning, "terragrunt-no-matches", "terragrunt block %s finds no terragrunt.hcl files", name)
+		}
+	}
+}
+
+func (l *configLinter) hasTerragruntModules(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == ".terragrunt-cache") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == "terragrunt.hcl" {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// repoFiles lists the files of the repository relative to its root, the form changed files come in
+func (l *configLinter) repoFiles() []string {
+	if l.files != nil {
+		return l.files
+	}
+	l.files = []string{}
+	filepath.WalkDir(l.workingDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if relPath, err := filepath.Rel(l.workingDir, path); err == nil {
+			l.files = append(l.files, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	return l.files
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func orNode(node *yaml.Node, fallback *yaml.Node) *yaml.Node {
+	if node != nil {
+		return node
+	}
+	return fallback
+}
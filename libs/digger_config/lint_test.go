@@ -0,0 +1,75 @@
+package digger_config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintDiggerConfig(t *testing.T) {
+	tempDir, teardown := setUp()
+	defer teardown()
+
+	assert.NoError(t, os.MkdirAll(path.Join(tempDir, "dev"), 0755))
+	assert.NoError(t, os.MkdirAll(path.Join(tempDir, "modules", "vpc"), 0755))
+	defer createFile(path.Join(tempDir, "dev", "main.tf"), "")()
+	defer createFile(path.Join(tempDir, "modules", "vpc", "main.tf"), "")()
+	defer createFile(path.Join(tempDir, "digger.yml"), `projects:
+- name: dev
+  dir: dev
+  include_patterns: ["../modules/**", "../shared/**"]
+- name: dev-again
+  dir: ./dev
+  workflow: custom
+- name: prod
+  dir: prod
+  depends_on: [dev, staging]
+workflows:
+  unused:
+    plan:
+      steps: [init, plan]
+generate_projects:
+  blocks:
+  - block_name: live
+    terragrunt: true
+    root_dir: live
+`)()
+
+	issues, err := LintDiggerConfig(tempDir, nil)
+	assert.NoError(t, err)
+
+	var found []string
+	for _, issue := range issues {
+		found = append(found, issue.String())
+	}
+	assert.Equal(t, []string{
+		`digger.yml:4:39: warning: include pattern "../shared/**" of project "dev" matches no files (pattern-matches-nothing)`,
+		`digger.yml:6:8: error: projects "dev" and "dev-again" both use dir "./dev" with workspace "default" (duplicate-project-dir)`,
+		`digger.yml:7:13: error: project "dev-again" uses workflow "custom" which is not defined (undefined-workflow)`,
+		`digger.yml:9:8: error: dir "prod" of project "prod" does not exist (project-dir-missing)`,
+		`digger.yml:10:21: error: project "prod" depends on unknown project "staging" (unknown-dependency)`,
+		`digger.yml:12:3: warning: workflow "unused" is not used by any project (unused-workflow)`,
+		`digger.yml:19:15: warning: terragrunt block live finds no terragrunt.hcl files (terragrunt-no-matches)`,
+	}, found)
+}
+
+func TestDiggerConfigJsonSchema(t *testing.T) {
+	contents, err := DiggerConfigJsonSchema()
+	assert.NoError(t, err)
+
+	var schema map[string]any
+	assert.NoError(t, json.Unmarshal(contents, &schema))
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/definitions/ProjectYaml"}}, properties["projects"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, properties["pr_locks"])
+
+	definitions := schema["definitions"].(map[string]any)
+	project := definitions["ProjectYaml"].(map[string]any)
+	assert.Equal(t, []any{"name", "dir"}, project["required"])
+	assert.Contains(t, project["properties"], "include_patterns")
+	assert.Contains(t, definitions["StageYaml"].(map[string]any)["properties"].(map[string]any)["steps"].(map[string]any)["items"], "oneOf")
+	assert.NotContains(t, definitions, "DiggerConfigYaml")
+}
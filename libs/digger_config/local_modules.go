@@ -0,0 +1,97 @@
+package digger_config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+var localModuleSourcePrefixes = []string{"./", "../", ".\\", "..\\"}
+
+func isLocalModuleSource(source string) bool {
+	for _, prefix := range localModuleSourcePrefixes {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findLocalModuleDirs returns the repo relative directories of all local modules called from dir,
+// following module calls recursively. Remote sources and modules outside of the repo are ignored.
+func findLocalModuleDirs(workingDir string, dir string) []string {
+	visited := map[string]bool{filepath.Clean(dir): true}
+	var moduleDirs []string
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		module, diags := tfconfig.LoadModule(filepath.Join(workingDir, dir))
+		if diags.HasErrors() {
+			slog.Warn("could not fully parse terraform module, local module detection may be incomplete",
+				"dir", dir,
+				"error", diags.Error())
+		}
+		if module == nil {
+			return
+		}
+
+		for _, call := range module.ModuleCalls {
+			if !isLocalModuleSource(call.Source) {
+				continue
+			}
+			moduleDir := filepath.Clean(filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(call.Source, "\\", "/"))))
+			if moduleDir == ".." || strings.HasPrefix(moduleDir, ".."+string(filepath.Separator)) {
+				slog.Debug("ignoring local module outside of the repository", "dir", dir, "source", call.Source)
+				continue
+			}
+			if visited[moduleDir] {
+				continue
+			}
+			visited[moduleDir] = true
+
+			if info, err := os.Stat(filepath.Join(workingDir, moduleDir)); err != nil || !info.IsDir() {
+				slog.Debug("local module directory not found", "dir", dir, "source", call.Source)
+				continue
+			}
+			moduleDirs = append(moduleDirs, moduleDir)
+			walk(moduleDir)
+		}
+	}
+	walk(filepath.Clean(dir))
+
+	sort.Strings(moduleDirs)
+	return moduleDirs
+}
+
+// resolveLocalModuleDirs records the local modules used by each plain terraform project so that
+// changes to them mark the project as modified without having to maintain include_patterns
+func resolveLocalModuleDirs(workingDir string, config *DiggerConfig) {
+	for i := range config.Projects {
+		project := &config.Projects[i]
+		if project.Terragrunt || project.Pulumi {
+			continue
+		}
+		project.LocalModuleDirs = findLocalModuleDirs(workingDir, project.Dir)
+		if len(project.LocalModuleDirs) > 0 {
+			slog.Debug("found local modules for project",
+				"projectName", project.Name,
+				"moduleDirs", project.LocalModuleDirs)
+		}
+	}
+}
+
+// localModuleForFile returns the innermost local module of the project that contains changedFile
+func (p *Project) localModuleForFile(changedFile string) (string, bool) {
+	changedFile = filepath.Clean(changedFile)
+	match := ""
+	for _, moduleDir := range p.LocalModuleDirs {
+		if strings.HasPrefix(changedFile, moduleDir+string(filepath.Separator)) && len(moduleDir) > len(match) {
+			match = moduleDir
+		}
+	}
+	return match, match != ""
+}
@@ -0,0 +1,124 @@
+package digger_config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const diggerConfigSchemaId = "https://digger.dev/schemas/digger.yml.json"
+
+// schemaOverrides covers types whose yaml form differs from their go fields
+var schemaOverrides = map[reflect.Type]map[string]any{
+	// a step is either an action name or a map with the action as key, see StepYaml.UnmarshalYAML
+	reflect.TypeOf(StepYaml{}): {
+		"oneOf": []any{
+			map[string]any{"type": "string", "enum": []string{"init", "plan", "apply"}},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"init":       map[string]any{"type": "string"},
+					"plan":       map[string]any{"type": "string"},
+					"apply":      map[string]any{"type": "string"},
+					"run":        map[string]any{"type": "string"},
+					"shell":      map[string]any{"type": "string"},
+					"extra_args": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+				"additionalProperties": false,
+			},
+		},
+	},
+}
+
+// schemaRequired lists the keys that have to be set for a block to be valid
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(ProjectYaml{}): {"name", "dir"},
+	reflect.TypeOf(EnvVarYaml{}):  {"name"},
+}
+
+// DiggerConfigJsonSchema returns a JSON Schema of digger.yml generated from DiggerConfigYaml,
+// for editors to validate and autocomplete the file
+func DiggerConfigJsonSchema() ([]byte, error) {
+	definitions := map[string]any{}
+	schema := map[string]any{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id":     diggerConfigSchemaId,
+		"title":   "digger.yml",
+	}
+	for key, value := range typeSchema(reflect.TypeOf(DiggerConfigYaml{}), definitions) {
+		schema[key] = value
+	}
+	delete(definitions, "DiggerConfigYaml")
+	schema["definitions"] = definitions
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(t reflect.Type, definitions map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if override, ok := schemaOverrides[t]; ok {
+		return override
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), definitions)}
+	case reflect.Struct:
+		return structSchema(t, definitions)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema adds the struct to definitions and returns a reference to it
+func structSchema(t reflect.Type, definitions map[string]any) map[string]any {
+	ref := map[string]any{"$ref": "#/definitions/" + t.Name()}
+	if _, ok := definitions[t.Name()]; ok {
+		return ref
+	}
+	// reserve the name first so that recursive types terminate
+	definitions[t.Name()] = map[string]any{}
+
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = typeSchema(field.Type, definitions)
+	}
+
+	definition := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required, ok := schemaRequired[t]; ok {
+		definition["required"] = required
+	}
+	definitions[t.Name()] = definition
+	if t == reflect.TypeOf(DiggerConfigYaml{}) {
+		return definition
+	}
+	return ref
+}
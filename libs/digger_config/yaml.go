@@ -27,6 +27,7 @@ type DiggerConfigYaml struct {
 	MentionDriftedProjectsInPR    *bool                        `yaml:"mention_drifted_projects_in_pr"`
 	ReportTerraformOutputs        *bool                        `yaml:"report_terraform_outputs"`
 	Reporting                     *ReportingConfigYaml         `yaml:"reporting"`
+	MaxParallelJobs               *int                         `yaml:"max_parallel_jobs,omitempty"`
 }
 
 type ReportingConfigYaml struct {
@@ -64,13 +65,24 @@ type ProjectYaml struct {
 	Generated            bool                        `yaml:"generated"`
 	AwsCognitoOidcConfig *AwsCognitoOidcConfig       `yaml:"aws_cognito_oidc,omitempty"`
 	PulumiStack          string                      `yaml:"pulumi_stack"`
+	TerraformVersion     string                      `yaml:"terraform_version,omitempty"`
+	OpenTofuVersion      string                      `yaml:"opentofu_version,omitempty"`
+	DependencyOutputs    []DependencyOutputYaml      `yaml:"dependency_outputs,omitempty"`
+}
+
+type DependencyOutputYaml struct {
+	Project  string `yaml:"project"`
+	Output   string `yaml:"output"`
+	Variable string `yaml:"variable,omitempty"`
 }
 
 type WorkflowYaml struct {
-	EnvVars       *TerraformEnvConfigYaml    `yaml:"env_vars"`
-	Plan          *StageYaml                 `yaml:"plan,omitempty"`
-	Apply         *StageYaml                 `yaml:"apply,omitempty"`
-	Configuration *WorkflowConfigurationYaml `yaml:"workflow_configuration"`
+	EnvVars          *TerraformEnvConfigYaml    `yaml:"env_vars"`
+	Plan             *StageYaml                 `yaml:"plan,omitempty"`
+	Apply            *StageYaml                 `yaml:"apply,omitempty"`
+	Configuration    *WorkflowConfigurationYaml `yaml:"workflow_configuration"`
+	TerraformVersion string                     `yaml:"terraform_version,omitempty"`
+	OpenTofuVersion  string                     `yaml:"opentofu_version,omitempty"`
 }
 
 type WorkflowConfigurationYaml struct {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/diggerhq/digger/libs/iac_utils"
 	"github.com/diggerhq/digger/libs/locking"
+	"github.com/diggerhq/digger/libs/policy"
 	"github.com/diggerhq/digger/libs/scheduler"
 	"github.com/diggerhq/digger/libs/storage"
 	"github.com/samber/lo"
@@ -114,10 +115,14 @@ type DiggerExecutorResult struct {
 	TerraformOutput string
 	PlanResult      *DiggerExecutorPlanResult
 	ApplyResult     *DiggerExecutorApplyResult
+	// PolicyOverride is set when soft plan policy denials were overridden for this run
+	PolicyOverride *policy.PolicyOverride
 }
 
 type DiggerExecutorApplyResult struct {
 	ApplySummary iac_utils.IacSummary
+	// Outputs are the outputs of the project after the apply, nil when they could not be read
+	Outputs scheduler.TerraformOutputs
 }
 
 type DiggerExecutorPlanResult struct {
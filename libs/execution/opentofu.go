@@ -13,6 +13,15 @@ import (
 type OpenTofu struct {
 	WorkingDir string
 	Workspace  string
+	// Binary is the path of a pinned opentofu version, tofu on PATH is used when empty
+	Binary string
+}
+
+func (tf OpenTofu) binary() string {
+	if tf.Binary != "" {
+		return tf.Binary
+	}
+	return "tofu"
 }
 
 func (tf OpenTofu) Init(params []string, envs map[string]string) (string, string, error) {
@@ -76,6 +85,15 @@ func (tf OpenTofu) Show(params []string, envs map[string]string, planArtefactFil
 	return stdout, stderr, nil
 }
 
+func (tf OpenTofu) Output(params []string, envs map[string]string) (string, string, error) {
+	params = append(append(params, "-no-color"), "-json")
+	stdout, stderr, _, err := tf.runOpentofuCommand("output", false, envs, nil, params...)
+	if err != nil {
+		return "", stderr, err
+	}
+	return stdout, stderr, nil
+}
+
 func (tf OpenTofu) Destroy(params []string, envs map[string]string) (string, string, error) {
 	if tf.Workspace != "default" {
 		err := tf.switchToWorkspace(envs)
@@ -141,10 +159,10 @@ func (tf OpenTofu) runOpentofuCommand(command string, printOutputToStdout bool,
 		mwerr = NewFilteringWriter(nil, &stderr, regEx)
 	}
 
-	cmd := exec.Command("tofu", expandedArgs...)
+	cmd := exec.Command(tf.binary(), expandedArgs...)
 	slog.Info("Running OpenTofu command",
 		slog.Group("command",
-			"binary", "tofu",
+			"binary", tf.binary(),
 			"args", expandedArgs,
 			"workingDir", tf.WorkingDir,
 		),
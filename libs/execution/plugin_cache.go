@@ -0,0 +1,338 @@
+package execution
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diggerhq/digger/libs/storage"
+)
+
+// DIGGER_PLUGIN_CACHE_DIR turns on the plugin cache, DIGGER_PLUGIN_CACHE_PERSIST=true also keeps the
+// cached providers in the plan storage between runs
+const (
+	pluginCacheDirEnv     = "DIGGER_PLUGIN_CACHE_DIR"
+	pluginCachePersistEnv = "DIGGER_PLUGIN_CACHE_PERSIST"
+)
+
+const (
+	lockFileName        = ".terraform.lock.hcl"
+	pluginCacheComplete = ".digger-complete"
+	pluginCacheLockWait = 500 * time.Millisecond
+	// a lock older than this was left behind by a runner that died during init
+	pluginCacheStaleLock = 30 * time.Minute
+)
+
+// PluginCache shares the providers terraform downloads during init between the projects of a runner.
+// Providers are cached per lock file, so projects with the same .terraform.lock.hcl reuse a cache
+// directory while projects with other lock files don't touch it. Inits of the same lock file run
+// one after the other, terraform doesn't support writing to a plugin cache concurrently.
+type PluginCache struct {
+	Dir string
+	// Storage keeps the cache between runs when set
+	Storage storage.PlanStorage
+
+	mutex    sync.Mutex
+	hits     int
+	restored int
+	misses   int
+	uncached int
+}
+
+// NewPluginCacheFromEnv returns the plugin cache configured for the runner, or nil when it is off
+func NewPluginCacheFromEnv(planStorage storage.PlanStorage) *PluginCache {
+	dir := os.Getenv(pluginCacheDirEnv)
+	if dir == "" {
+		return nil
+	}
+	cache := &PluginCache{Dir: dir}
+	if os.Getenv(pluginCachePersistEnv) == "true" {
+		if planStorage == nil {
+			slog.Warn("plugin cache persistence needs plan storage, keeping the cache local")
+		} else {
+			cache.Storage = planStorage
+		}
+	}
+	return cache
+}
+
+// pluginCacheKey is derived from the lock file of the project and the platform the providers are for
+func pluginCacheKey(workingDir string) (string, bool) {
+	lockFile, err := os.ReadFile(filepath.Join(workingDir, lockFileName))
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.Sum256(append([]byte(runtime.GOOS+"_"+runtime.GOARCH+"\n"), lockFile...))
+	return hex.EncodeToString(hash[:16]), true
+}
+
+// Init runs init of the project in workingDir with the cache directory of its lock file
+func (c *PluginCache) Init(workingDir string, envs map[string]string, init func(envs map[string]string) (string, string, error)) (string, string, error) {
+	key, ok := pluginCacheKey(workingDir)
+	if !ok {
+		slog.Info("no lock file, running init without the plugin cache", "workingDir", workingDir)
+		c.count(&c.uncached)
+		return init(envs)
+	}
+
+	unlock, err := c.lock(key)
+	if err != nil {
+		slog.Warn("could not lock plugin cache, running init without it", "key", key, "error", err)
+		c.count(&c.uncached)
+		return init(envs)
+	}
+	defer unlock()
+
+	keyDir := filepath.Join(c.Dir, key)
+	complete := filepath.Join(keyDir, pluginCacheComplete)
+	hit := false
+	if _, err := os.Stat(complete); err == nil {
+		hit = true
+		c.count(&c.hits)
+	} else if c.restore(key) {
+		hit = true
+		c.count(&c.restored)
+	} else {
+		c.count(&c.misses)
+	}
+	slog.Info("running init with plugin cache", "workingDir", workingDir, "key", key, "hit", hit)
+
+	if err := os.MkdirAll(keyDir, 0755); err != nil {
+		return "", "", fmt.Errorf("could not create plugin cache directory: %v", err)
+	}
+	cacheEnvs := make(map[string]string, len(envs)+3)
+	for k, v := range envs {
+		cacheEnvs[k] = v
+	}
+	cacheEnvs["TF_PLUGIN_CACHE_DIR"] = keyDir
+	cacheEnvs["TG_PROVIDER_CACHE_DIR"] = keyDir
+	cacheEnvs["TERRAGRUNT_PROVIDER_CACHE_DIR"] = keyDir
+
+	stdout, stderr, err := init(cacheEnvs)
+	if err != nil || hit {
+		return stdout, stderr, err
+	}
+	if err := os.WriteFile(complete, []byte(time.Now().UTC().Format(time.RFC3339)), 0644); err != nil {
+		slog.Warn("could not mark plugin cache complete", "key", key, "error", err)
+		return stdout, stderr, nil
+	}
+	c.persist(key)
+	return stdout, stderr, nil
+}
+
+func (c *PluginCache) count(counter *int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	*counter++
+}
+
+// lock takes the lock of a cache key. Locks are directories so that runners sharing the cache
+// directory exclude each other as well.
+func (c *PluginCache) lock(key string) (func(), error) {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return nil, err
+	}
+	lockDir := filepath.Join(c.Dir, key+".lock")
+	for {
+		err := os.Mkdir(lockDir, 0755)
+		if err == nil {
+			return func() { os.Remove(lockDir) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockDir); statErr == nil && time.Since(info.ModTime()) > pluginCacheStaleLock {
+			slog.Warn("removing stale plugin cache lock", "lock", lockDir)
+			os.Remove(lockDir)
+			continue
+		}
+		time.Sleep(pluginCacheLockWait)
+	}
+}
+
+func pluginCacheArtifact(key string) (string, string) {
+	return "plugin-cache-" + key, "plugin-cache/" + key + ".tar.gz"
+}
+
+// restore fetches the cache of a key from the storage, it reports whether the cache was restored
+func (c *PluginCache) restore(key string) bool {
+	if c.Storage == nil {
+		return false
+	}
+	artifactName, storedPath := pluginCacheArtifact(key)
+	exists, err := c.Storage.PlanExists(artifactName, storedPath)
+	if err != nil || !exists {
+		return false
+	}
+
+	archive, err := os.CreateTemp("", "digger-plugin-cache-*.tar.gz")
+	if err != nil {
+		slog.Warn("could not restore plugin cache", "key", key, "error", err)
+		return false
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+
+	retrieved, err := c.Storage.RetrievePlan(archive.Name(), artifactName, storedPath)
+	if err != nil || retrieved == nil {
+		slog.Warn("could not restore plugin cache", "key", key, "error", err)
+		return false
+	}
+	keyDir := filepath.Join(c.Dir, key)
+	if err := extractTarGz(*retrieved, keyDir); err != nil {
+		slog.Warn("could not restore plugin cache", "key", key, "error", err)
+		os.RemoveAll(keyDir)
+		return false
+	}
+	slog.Info("restored plugin cache from storage", "key", key)
+	return true
+}
+
+// persist stores the cache of a key, failures only cost the next run a cold init
+func (c *PluginCache) persist(key string) {
+	if c.Storage == nil {
+		return
+	}
+	archive, err := createTarGz(filepath.Join(c.Dir, key))
+	if err != nil {
+		slog.Warn("could not archive plugin cache", "key", key, "error", err)
+		return
+	}
+	artifactName, storedPath := pluginCacheArtifact(key)
+	if err := c.Storage.StorePlanFile(archive, artifactName, storedPath); err != nil {
+		slog.Warn("could not store plugin cache", "key", key, "error", err)
+		return
+	}
+	slog.Info("stored plugin cache", "key", key, "size", len(archive))
+}
+
+// Summary describes how the cache did for the jobs of the run, empty when no init used it
+func (c *PluginCache) Summary() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.hits+c.restored+c.misses+c.uncached == 0 {
+		return ""
+	}
+	return fmt.Sprintf("### Provider plugin cache\n\n| Hits | Restored from storage | Misses | Without lock file |\n| --- | --- | --- | --- |\n| %d | %d | %d | %d |\n",
+		c.hits, c.restored, c.misses, c.uncached)
+}
+
+// PluginCachingExecutor runs init of the wrapped executor with the plugin cache
+type PluginCachingExecutor struct {
+	TerraformExecutor
+	Cache      *PluginCache
+	WorkingDir string
+}
+
+func (e PluginCachingExecutor) Init(params []string, envs map[string]string) (string, string, error) {
+	return e.Cache.Init(e.WorkingDir, envs, func(envs map[string]string) (string, string, error) {
+		return e.TerraformExecutor.Init(params, envs)
+	})
+}
+
+func createTarGz(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil || name == "." {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func extractTarGz(archivePath string, dir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if target != dir && !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in plugin cache archive: %v", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tarReader); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package execution
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryPlanStorage keeps stored files in memory
+type memoryPlanStorage struct {
+	files map[string][]byte
+}
+
+func (s *memoryPlanStorage) StorePlanFile(fileContents []byte, artifactName string, storedPlanFilePath string) error {
+	s.files[storedPlanFilePath] = fileContents
+	return nil
+}
+
+func (s *memoryPlanStorage) RetrievePlan(localPlanFilePath string, artifactName string, storedPlanFilePath string) (*string, error) {
+	if err := os.WriteFile(localPlanFilePath, s.files[storedPlanFilePath], 0644); err != nil {
+		return nil, err
+	}
+	return &localPlanFilePath, nil
+}
+
+func (s *memoryPlanStorage) DeleteStoredPlan(artifactName string, storedPlanFilePath string) error {
+	delete(s.files, storedPlanFilePath)
+	return nil
+}
+
+func (s *memoryPlanStorage) PlanExists(artifactName string, storedPlanFilePath string) (bool, error) {
+	_, ok := s.files[storedPlanFilePath]
+	return ok, nil
+}
+
+func pluginCacheProject(t *testing.T, lockFile string) string {
+	dir := t.TempDir()
+	if lockFile != "" {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, lockFileName), []byte(lockFile), 0644))
+	}
+	return dir
+}
+
+// fakeInit installs a provider into the plugin cache unless it is there already
+func fakeInit(t *testing.T, downloads *int, mutex *sync.Mutex) func(envs map[string]string) (string, string, error) {
+	return func(envs map[string]string) (string, string, error) {
+		cacheDir := envs["TF_PLUGIN_CACHE_DIR"]
+		if cacheDir == "" {
+			return "", "", nil
+		}
+		provider := filepath.Join(cacheDir, "registry.terraform.io", "hashicorp", "aws", "5.0.0", "terraform-provider-aws")
+		if _, err := os.Stat(provider); err == nil {
+			return "", "", nil
+		}
+		mutex.Lock()
+		*downloads++
+		mutex.Unlock()
+		assert.NoError(t, os.MkdirAll(filepath.Dir(provider), 0755))
+		return "", "", os.WriteFile(provider, []byte("provider"), 0755)
+	}
+}
+
+func TestPluginCacheSharesProvidersPerLockFile(t *testing.T) {
+	cache := &PluginCache{Dir: t.TempDir()}
+	downloads, mutex := 0, &sync.Mutex{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		project := pluginCacheProject(t, `provider "registry.terraform.io/hashicorp/aws" { version = "5.0.0" }`)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := cache.Init(project, map[string]string{"TF_VAR_x": "1"}, fakeInit(t, &downloads, mutex))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	// concurrent inits of the same lock file download the providers once
+	assert.Equal(t, 1, downloads)
+
+	other := pluginCacheProject(t, `provider "registry.terraform.io/hashicorp/aws" { version = "5.1.0" }`)
+	_, _, err := cache.Init(other, map[string]string{}, fakeInit(t, &downloads, mutex))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, downloads)
+
+	withoutLockFile := pluginCacheProject(t, "")
+	var envs map[string]string
+	_, _, err = cache.Init(withoutLockFile, map[string]string{}, func(e map[string]string) (string, string, error) {
+		envs = e
+		return "", "", nil
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, envs, "TF_PLUGIN_CACHE_DIR")
+
+	assert.Equal(t, 3, cache.hits)
+	assert.Equal(t, 2, cache.misses)
+	assert.Equal(t, 1, cache.uncached)
+	assert.Contains(t, cache.Summary(), "| 3 | 0 | 2 | 1 |")
+}
+
+func TestPluginCacheDoesNotCacheFailedInit(t *testing.T) {
+	cache := &PluginCache{Dir: t.TempDir()}
+	project := pluginCacheProject(t, "lock")
+
+	_, _, err := cache.Init(project, map[string]string{}, func(envs map[string]string) (string, string, error) {
+		return "", "", os.ErrPermission
+	})
+	assert.Error(t, err)
+	_, _, err = cache.Init(project, map[string]string{}, func(envs map[string]string) (string, string, error) {
+		return "", "", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.hits)
+	assert.Equal(t, 2, cache.misses)
+}
+
+func TestPluginCachePersistsToStorage(t *testing.T) {
+	planStorage := &memoryPlanStorage{files: map[string][]byte{}}
+	downloads, mutex := 0, &sync.Mutex{}
+	lockFile := `provider "registry.terraform.io/hashicorp/aws" { version = "5.0.0" }`
+
+	first := &PluginCache{Dir: t.TempDir(), Storage: planStorage}
+	_, _, err := first.Init(pluginCacheProject(t, lockFile), map[string]string{}, fakeInit(t, &downloads, mutex))
+	assert.NoError(t, err)
+	assert.Len(t, planStorage.files, 1)
+
+	// a later run on a fresh runner restores the providers instead of downloading them
+	second := &PluginCache{Dir: t.TempDir(), Storage: planStorage}
+	_, _, err = second.Init(pluginCacheProject(t, lockFile), map[string]string{}, fakeInit(t, &downloads, mutex))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, downloads)
+	assert.Equal(t, 1, second.restored)
+
+	key, _ := pluginCacheKey(pluginCacheProject(t, lockFile))
+	info, err := os.Stat(filepath.Join(second.Dir, key, "registry.terraform.io", "hashicorp", "aws", "5.0.0", "terraform-provider-aws"))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100)
+}
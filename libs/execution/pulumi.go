@@ -59,6 +59,10 @@ func (pl Pulumi) Show(params []string, envs map[string]string, planArtefactFileP
 	return stdout, stderr, nil
 }
 
+func (pl Pulumi) Output(params []string, envs map[string]string) (string, string, error) {
+	return "", "", fmt.Errorf("outputs of pulumi stacks can't be passed to other projects")
+}
+
 func (pl Pulumi) Destroy(params []string, envs map[string]string) (string, string, error) {
 	pl.selectStack()
 	params = append(params, "--yes")
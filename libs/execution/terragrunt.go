@@ -12,6 +12,8 @@ import (
 
 type Terragrunt struct {
 	WorkingDir string
+	// TerraformBinary is the path of a pinned terraform or opentofu version for terragrunt to run
+	TerraformBinary string
 }
 
 func (terragrunt Terragrunt) Init(params []string, envs map[string]string) (string, string, error) {
@@ -74,6 +76,16 @@ func (terragrunt Terragrunt) Show(params []string, envs map[string]string, planA
 	return stdout, stderr, err
 }
 
+func (terragrunt Terragrunt) Output(params []string, envs map[string]string) (string, string, error) {
+	params = append(append(params, "-no-color"), "-json")
+	stdout, stderr, exitCode, err := terragrunt.runTerragruntCommand("output", false, envs, nil, params...)
+	if exitCode != 0 {
+		logCommandFail(exitCode, err)
+	}
+
+	return stdout, stderr, err
+}
+
 func (terragrunt Terragrunt) runTerragruntCommand(command string, printOutputToStdout bool, envs map[string]string, filterRegex *string, arg ...string) (stdOut string, stdErr string, exitCode int, err error) {
 	args := []string{command}
 	args = append(args, arg...)
@@ -122,6 +134,10 @@ func (terragrunt Terragrunt) runTerragruntCommand(command string, printOutputToS
 	env = append(env, "TG_NO_COLOR=true")
 	env = append(env, "TG_NON_INTERACTIVE=true")
 	env = append(env, "TG_TF_FORWARD_STDOUT=true")
+	if terragrunt.TerraformBinary != "" {
+		env = append(env, "TERRAGRUNT_TFPATH="+terragrunt.TerraformBinary)
+		env = append(env, "TG_TF_PATH="+terragrunt.TerraformBinary)
+	}
 
 	for k, v := range envs {
 		env = append(env, fmt.Sprintf("%s=%s", k, v))
@@ -17,11 +17,21 @@ type TerraformExecutor interface {
 	Destroy([]string, map[string]string) (string, string, error)
 	Plan([]string, map[string]string, string, *string) (bool, string, string, error)
 	Show([]string, map[string]string, string, bool) (string, string, error)
+	Output([]string, map[string]string) (string, string, error)
 }
 
 type Terraform struct {
 	WorkingDir string
 	Workspace  string
+	// Binary is the path of a pinned terraform version, terraform on PATH is used when empty
+	Binary string
+}
+
+func (tf Terraform) binary() string {
+	if tf.Binary != "" {
+		return tf.Binary
+	}
+	return "terraform"
 }
 
 func (tf Terraform) Init(params []string, envs map[string]string) (string, string, error) {
@@ -110,10 +120,10 @@ func (tf Terraform) runTerraformCommand(command string, printOutputToStdout bool
 		mwerr = NewFilteringWriter(nil, &stderr, regEx)
 	}
 
-	cmd := exec.Command("terraform", expandedArgs...)
+	cmd := exec.Command(tf.binary(), expandedArgs...)
 	slog.Info("Running Terraform command",
 		slog.Group("command",
-			"binary", "terraform",
+			"binary", tf.binary(),
 			"args", RedactSecrets(expandedArgs),
 			"workingDir", tf.WorkingDir,
 		),
@@ -180,6 +190,17 @@ func (tf Terraform) Show(params []string, envs map[string]string, planArtefactFi
 	return stdout, stderr, nil
 }
 
+// Output prints the outputs of the state as json. Sensitive values are in there too, so they are
+// kept out of the job output.
+func (tf Terraform) Output(params []string, envs map[string]string) (string, string, error) {
+	params = append(append(params, "-no-color"), "-json")
+	stdout, stderr, _, err := tf.runTerraformCommand("output", false, envs, nil, params...)
+	if err != nil {
+		return "", stderr, err
+	}
+	return stdout, stderr, nil
+}
+
 func RedactSecret(s string) string {
 	exps := []*regexp.Regexp{
 		regexp.MustCompile(`\-backend\-config\=access\_key\=(.*)`),
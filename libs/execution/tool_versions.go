@@ -0,0 +1,259 @@
+package execution
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diggerhq/digger/libs/scheduler"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+const (
+	ToolTerraform = "terraform"
+	ToolOpenTofu  = "tofu"
+)
+
+// DIGGER_TOOLS_CACHE_DIR is shared by the jobs of a runner, DIGGER_TOOLS_MIRROR_DIR replaces the
+// release downloads on air-gapped runners. The mirror has the release files of each version in
+// <mirror>/<terraform|tofu>/<version>/, the zip archives along with their SHA256SUMS file.
+const (
+	toolsCacheDirEnv  = "DIGGER_TOOLS_CACHE_DIR"
+	toolsMirrorDirEnv = "DIGGER_TOOLS_MIRROR_DIR"
+)
+
+// toolReleaseUrls are formatted with the version and the name of the release file
+var toolReleaseUrls = map[string]string{
+	ToolTerraform: "https://releases.hashicorp.com/terraform/%[1]s/%[2]s",
+	ToolOpenTofu:  "https://github.com/opentofu/opentofu/releases/download/v%[1]s/%[2]s",
+}
+
+var (
+	exactVersionPattern    = regexp.MustCompile(`^v?(\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?)$`)
+	requiredVersionPattern = regexp.MustCompile(`^=?\s*v?(\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?)$`)
+	toolVersionFiles       = map[string][]string{ToolTerraform: {".terraform-version"}, ToolOpenTofu: {".opentofu-version", ".terraform-version"}}
+	toolDownloadClient     = &http.Client{Timeout: 10 * time.Minute}
+	toolInstallMutex       sync.Mutex
+)
+
+// ResolveJobToolBinary installs the terraform or opentofu version the job runs with and returns the
+// path of the binary. The version pinned in digger.yml comes first, then an exact required_version
+// and the version files of the project. An empty path means nothing is pinned and the binary on
+// PATH is used.
+func ResolveJobToolBinary(job scheduler.Job, repoRoot string) (string, error) {
+	if job.Pulumi {
+		return "", nil
+	}
+	tool, version := ToolTerraform, job.TerraformVersion
+	if job.OpenTofu || job.OpenTofuVersion != "" {
+		tool, version = ToolOpenTofu, job.OpenTofuVersion
+	}
+	if version == "" {
+		version = DetectToolVersion(tool, repoRoot, job.ProjectDir)
+	}
+	if version == "" {
+		return "", nil
+	}
+	return InstallTool(tool, version)
+}
+
+// DetectToolVersion reads the version of the project from the required_version of its terraform block
+// when it is an exact version, otherwise from a version file in the project directory or one of its
+// parents within the repository. Version ranges are left for terraform to check.
+func DetectToolVersion(tool string, repoRoot string, projectDir string) string {
+	module, diags := tfconfig.LoadModule(filepath.Join(repoRoot, projectDir))
+	if diags.HasErrors() {
+		slog.Debug("could not load module for required_version", "projectDir", projectDir, "error", diags.Err())
+	} else {
+		for _, constraint := range module.RequiredCore {
+			for _, part := range strings.Split(constraint, ",") {
+				if match := requiredVersionPattern.FindStringSubmatch(strings.TrimSpace(part)); match != nil {
+					return match[1]
+				}
+			}
+		}
+	}
+
+	dir := filepath.Clean(filepath.Join(repoRoot, projectDir))
+	root := filepath.Clean(repoRoot)
+	for {
+		for _, name := range toolVersionFiles[tool] {
+			version, ok := readVersionFile(filepath.Join(dir, name))
+			if ok {
+				slog.Debug("found version file", "tool", tool, "file", filepath.Join(dir, name), "version", version)
+				return version
+			}
+		}
+		if dir == root || !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func readVersionFile(path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if match := exactVersionPattern.FindStringSubmatch(line); match != nil {
+			return match[1], true
+		}
+		slog.Warn("ignoring version file without an exact version", "file", path, "version", line)
+		return "", false
+	}
+	return "", false
+}
+
+// ToolsCacheDir is the directory the installed versions are kept in
+func ToolsCacheDir() string {
+	if dir := os.Getenv(toolsCacheDirEnv); dir != "" {
+		return dir
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "digger", "tools")
+	}
+	return filepath.Join(cacheDir, "digger", "tools")
+}
+
+// InstallTool installs a version of terraform or opentofu into the tools cache, unless it is there
+// already, and returns the path of the binary. Release archives are verified against the checksums
+// published with them.
+func InstallTool(tool string, version string) (string, error) {
+	if _, ok := toolReleaseUrls[tool]; !ok {
+		return "", fmt.Errorf("unknown tool %v", tool)
+	}
+	binaryName := tool
+	if runtime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	installDir := filepath.Join(ToolsCacheDir(), tool, version)
+	binary := filepath.Join(installDir, binaryName)
+
+	toolInstallMutex.Lock()
+	defer toolInstallMutex.Unlock()
+	if _, err := os.Stat(binary); err == nil {
+		slog.Debug("using cached tool", "tool", tool, "version", version, "binary", binary)
+		return binary, nil
+	}
+
+	archiveName := fmt.Sprintf("%v_%v_%v_%v.zip", tool, version, runtime.GOOS, runtime.GOARCH)
+	sumsName := fmt.Sprintf("%v_%v_SHA256SUMS", tool, version)
+	slog.Info("installing tool", "tool", tool, "version", version, "installDir", installDir)
+
+	sums, err := fetchToolReleaseFile(tool, version, sumsName)
+	if err != nil {
+		return "", err
+	}
+	archive, err := fetchToolReleaseFile(tool, version, archiveName)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(archive, sums, archiveName); err != nil {
+		return "", err
+	}
+	if err := extractToolBinary(archive, binaryName, installDir); err != nil {
+		return "", fmt.Errorf("could not extract %v %v: %v", tool, version, err)
+	}
+	return binary, nil
+}
+
+func fetchToolReleaseFile(tool string, version string, name string) ([]byte, error) {
+	if mirror := os.Getenv(toolsMirrorDirEnv); mirror != "" {
+		content, err := os.ReadFile(filepath.Join(mirror, tool, version, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not read %v from the tools mirror: %v", name, err)
+		}
+		return content, nil
+	}
+
+	url := fmt.Sprintf(toolReleaseUrls[tool], version, name)
+	resp, err := toolDownloadClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %v: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %v: unexpected status %v", url, resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %v: %v", url, err)
+	}
+	return content, nil
+}
+
+func verifyChecksum(archive []byte, sums []byte, archiveName string) error {
+	sum := sha256.Sum256(archive)
+	actual := hex.EncodeToString(sum[:])
+	for _, line := range strings.Split(string(sums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == archiveName {
+			if !strings.EqualFold(fields[0], actual) {
+				return fmt.Errorf("checksum mismatch for %v, expected %v got %v", archiveName, fields[0], actual)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no checksum published for %v", archiveName)
+}
+
+// extractToolBinary writes the binary from the release archive into dir. It is renamed into place
+// so that no other runner sharing the cache sees a partially written binary.
+func extractToolBinary(archive []byte, binaryName string, dir string) error {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+	for _, file := range reader.File {
+		if file.Name != binaryName {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		content, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		tmp, err := os.CreateTemp(dir, binaryName+".tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		if _, err := io.Copy(tmp, content); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		if err := os.Chmod(tmp.Name(), 0755); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), filepath.Join(dir, binaryName))
+	}
+	return fmt.Errorf("%v not found in the release archive", binaryName)
+}
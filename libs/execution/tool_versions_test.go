@@ -0,0 +1,196 @@
+package execution
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/diggerhq/digger/libs/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func toolReleaseArchive(t *testing.T, binaryName string, content string) []byte {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, err := writer.Create(binaryName)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return archive.Bytes()
+}
+
+func toolReleaseSums(archive []byte, archiveName string) []byte {
+	sum := sha256.Sum256(archive)
+	return []byte(fmt.Sprintf("%v  %v\n", hex.EncodeToString(sum[:]), archiveName))
+}
+
+func serveToolRelease(t *testing.T, tool string, files map[string][]byte) *int {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		downloads++
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	releaseUrl := toolReleaseUrls[tool]
+	toolReleaseUrls[tool] = server.URL + "/%[1]s/%[2]s"
+	t.Cleanup(func() { toolReleaseUrls[tool] = releaseUrl })
+	return &downloads
+}
+
+func TestInstallToolVerifiesChecksum(t *testing.T) {
+	t.Setenv(toolsCacheDirEnv, t.TempDir())
+	archiveName := fmt.Sprintf("terraform_1.5.7_%v_%v.zip", runtime.GOOS, runtime.GOARCH)
+	archive := toolReleaseArchive(t, "terraform", "#!/bin/sh\necho 1.5.7\n")
+	downloads := serveToolRelease(t, ToolTerraform, map[string][]byte{
+		"/1.5.7/" + archiveName:             archive,
+		"/1.5.7/terraform_1.5.7_SHA256SUMS": toolReleaseSums(archive, archiveName),
+		"/1.6.0/terraform_1.6.0_SHA256SUMS": toolReleaseSums(archive, "terraform_1.6.0_other_arch.zip"),
+	})
+
+	binary, err := InstallTool(ToolTerraform, "1.5.7")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(os.Getenv(toolsCacheDirEnv), "terraform", "1.5.7", "terraform"), binary)
+	content, err := os.ReadFile(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho 1.5.7\n", string(content))
+	info, err := os.Stat(binary)
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100)
+
+	// installed versions are reused
+	assert.Equal(t, 2, *downloads)
+	_, err = InstallTool(ToolTerraform, "1.5.7")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *downloads)
+
+	_, err = InstallTool(ToolTerraform, "1.6.0")
+	assert.ErrorContains(t, err, "could not download")
+}
+
+func TestInstallToolRejectsChecksumMismatch(t *testing.T) {
+	t.Setenv(toolsCacheDirEnv, t.TempDir())
+	archiveName := fmt.Sprintf("tofu_1.8.0_%v_%v.zip", runtime.GOOS, runtime.GOARCH)
+	archive := toolReleaseArchive(t, "tofu", "tampered")
+	serveToolRelease(t, ToolOpenTofu, map[string][]byte{
+		"/1.8.0/" + archiveName:        archive,
+		"/1.8.0/tofu_1.8.0_SHA256SUMS": toolReleaseSums([]byte("original"), archiveName),
+	})
+
+	_, err := InstallTool(ToolOpenTofu, "1.8.0")
+	assert.ErrorContains(t, err, "checksum mismatch")
+	_, err = os.Stat(filepath.Join(os.Getenv(toolsCacheDirEnv), "tofu", "1.8.0", "tofu"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInstallToolFromMirror(t *testing.T) {
+	t.Setenv(toolsCacheDirEnv, t.TempDir())
+	mirror := t.TempDir()
+	t.Setenv(toolsMirrorDirEnv, mirror)
+	// the mirror replaces downloads entirely
+	downloads := serveToolRelease(t, ToolTerraform, map[string][]byte{})
+
+	archiveName := fmt.Sprintf("terraform_1.9.8_%v_%v.zip", runtime.GOOS, runtime.GOARCH)
+	archive := toolReleaseArchive(t, "terraform", "mirrored")
+	assert.NoError(t, os.MkdirAll(filepath.Join(mirror, "terraform", "1.9.8"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(mirror, "terraform", "1.9.8", archiveName), archive, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(mirror, "terraform", "1.9.8", "terraform_1.9.8_SHA256SUMS"), toolReleaseSums(archive, archiveName), 0644))
+
+	binary, err := InstallTool(ToolTerraform, "1.9.8")
+	assert.NoError(t, err)
+	content, err := os.ReadFile(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, "mirrored", string(content))
+	assert.Equal(t, 0, *downloads)
+
+	_, err = InstallTool(ToolTerraform, "1.9.9")
+	assert.ErrorContains(t, err, "tools mirror")
+}
+
+func TestDetectToolVersion(t *testing.T) {
+	repo := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "envs", "prod"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "modules", "app"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "ranged"), 0755))
+
+	// no version pinned anywhere
+	assert.Equal(t, "", DetectToolVersion(ToolTerraform, repo, "envs/prod"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "modules", "app", "versions.tf"), []byte(`
+terraform {
+  required_version = "= 1.5.7"
+}
+`), 0644))
+	assert.Equal(t, "1.5.7", DetectToolVersion(ToolTerraform, repo, "modules/app"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "ranged", "versions.tf"), []byte(`
+terraform {
+  required_version = ">= 1.3, < 2.0"
+}
+`), 0644))
+	assert.Equal(t, "", DetectToolVersion(ToolTerraform, repo, "ranged"))
+
+	// version files are looked up in the parents of the project
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ".terraform-version"), []byte("1.9.8\n"), 0644))
+	assert.Equal(t, "1.9.8", DetectToolVersion(ToolTerraform, repo, "envs/prod"))
+	assert.Equal(t, "1.5.7", DetectToolVersion(ToolTerraform, repo, "modules/app"))
+	assert.Equal(t, "1.9.8", DetectToolVersion(ToolTerraform, repo, "ranged"))
+	assert.Equal(t, "1.9.8", DetectToolVersion(ToolOpenTofu, repo, "envs/prod"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "envs", ".opentofu-version"), []byte("v1.8.3\n"), 0644))
+	assert.Equal(t, "1.8.3", DetectToolVersion(ToolOpenTofu, repo, "envs/prod"))
+	assert.Equal(t, "1.9.8", DetectToolVersion(ToolTerraform, repo, "envs/prod"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "envs", "prod", ".terraform-version"), []byte("latest\n"), 0644))
+	assert.Equal(t, "1.9.8", DetectToolVersion(ToolTerraform, repo, "envs/prod"))
+}
+
+func TestResolveJobToolBinaryWithoutPinnedVersion(t *testing.T) {
+	binary, err := ResolveJobToolBinary(scheduler.Job{ProjectDir: "."}, t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, "", binary)
+
+	binary, err = ResolveJobToolBinary(scheduler.Job{ProjectDir: ".", Pulumi: true, TerraformVersion: "1.5.7"}, t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, "", binary)
+}
+
+func TestResolveJobToolBinaryInstallsDetectedVersion(t *testing.T) {
+	t.Setenv(toolsCacheDirEnv, t.TempDir())
+	files := map[string][]byte{}
+	for _, version := range []string{"1.5.7", "1.6.2"} {
+		archiveName := fmt.Sprintf("terraform_%v_%v_%v.zip", version, runtime.GOOS, runtime.GOARCH)
+		archive := toolReleaseArchive(t, "terraform", "#!/bin/sh\necho "+version+"\n")
+		files["/"+version+"/"+archiveName] = archive
+		files["/"+version+"/terraform_"+version+"_SHA256SUMS"] = toolReleaseSums(archive, archiveName)
+	}
+	downloads := serveToolRelease(t, ToolTerraform, files)
+	repo := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ".terraform-version"), []byte("1.5.7\n"), 0644))
+
+	// without a pin in digger.yml the version file of the project is installed
+	binary, err := ResolveJobToolBinary(scheduler.Job{ProjectDir: "."}, repo)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", binary)
+	assert.Equal(t, 2, *downloads)
+
+	// a pin in digger.yml wins over the version file
+	pinned, err := ResolveJobToolBinary(scheduler.Job{ProjectDir: ".", TerraformVersion: "1.6.2"}, repo)
+	assert.NoError(t, err)
+	assert.NotEqual(t, binary, pinned)
+	assert.Equal(t, 4, *downloads)
+}
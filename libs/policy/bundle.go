@@ -0,0 +1,369 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/keys"
+)
+
+const (
+	// DefaultBundleSigningAlg is the algorithm bundle signatures are verified with unless configured otherwise
+	DefaultBundleSigningAlg = "RS256"
+
+	bundleVerificationKeyId = "digger"
+	ociScheme               = "oci://"
+	ociManifestMediaTypes   = "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json"
+	ociBundleLayerMediaType = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// PolicyBundle is an OPA bundle whose rego modules and data documents are
+// available to every policy the checker evaluates
+type PolicyBundle struct {
+	Source string
+	Bundle *bundle.Bundle
+}
+
+// BundleLoader downloads policy bundles, tar.gz files of rego and data.json,
+// from an HTTP(S) URL or an OCI registry (oci://registry/repository:tag).
+// Downloads are cached in CacheDir and revalidated with their ETag, and the
+// cached copy is used if the source cannot be reached.
+type BundleLoader struct {
+	CacheDir   string
+	HttpClient *http.Client
+	// Token is sent as a bearer token to the bundle server or registry
+	Token string
+	// PublicKey is a PEM encoded public key, or a path to one. When it is set
+	// bundles have to be signed with the matching private key.
+	PublicKey  string
+	SigningAlg string
+}
+
+// NewBundleLoaderFromEnv configures a loader from the DIGGER_POLICY_BUNDLE_* variables
+func NewBundleLoaderFromEnv() BundleLoader {
+	cacheDir := os.Getenv("DIGGER_POLICY_BUNDLE_CACHE_DIR")
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			userCacheDir = os.TempDir()
+		}
+		cacheDir = filepath.Join(userCacheDir, "digger", "policy-bundles")
+	}
+	return BundleLoader{
+		CacheDir:   cacheDir,
+		HttpClient: http.DefaultClient,
+		Token:      os.Getenv("DIGGER_POLICY_BUNDLE_TOKEN"),
+		PublicKey:  os.Getenv("DIGGER_POLICY_BUNDLE_PUBLIC_KEY"),
+		SigningAlg: os.Getenv("DIGGER_POLICY_BUNDLE_SIGNING_ALG"),
+	}
+}
+
+// LoadPolicyBundlesFromEnv loads the comma separated bundle sources in DIGGER_POLICY_BUNDLES
+func LoadPolicyBundlesFromEnv() ([]PolicyBundle, error) {
+	sources := os.Getenv("DIGGER_POLICY_BUNDLES")
+	if sources == "" {
+		return nil, nil
+	}
+	return NewBundleLoaderFromEnv().LoadAll(strings.Split(sources, ","))
+}
+
+func (l BundleLoader) LoadAll(sources []string) ([]PolicyBundle, error) {
+	var bundles []PolicyBundle
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		b, err := l.Load(source)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, PolicyBundle{Source: source, Bundle: b})
+	}
+	return bundles, nil
+}
+
+// Load downloads the bundle at source, or reuses the cached copy, and verifies its signature if a public key is set
+func (l BundleLoader) Load(source string) (*bundle.Bundle, error) {
+	cacheDir := filepath.Join(l.CacheDir, bundleCacheKey(source))
+	cachedEtag, _ := os.ReadFile(filepath.Join(cacheDir, "etag"))
+
+	var contents []byte
+	etag, data, err := l.download(source, string(cachedEtag))
+	switch {
+	case err != nil:
+		cached, cacheErr := os.ReadFile(filepath.Join(cacheDir, "bundle.tar.gz"))
+		if cacheErr != nil {
+			return nil, fmt.Errorf("failed to download policy bundle %v: %v", source, err)
+		}
+		slog.Warn("Failed to download policy bundle, using cached copy", "source", source, "error", err)
+		contents, etag = cached, string(cachedEtag)
+	case data == nil:
+		slog.Debug("Policy bundle not modified, using cached copy", "source", source, "etag", etag)
+		contents, err = os.ReadFile(filepath.Join(cacheDir, "bundle.tar.gz"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached policy bundle %v: %v", source, err)
+		}
+	default:
+		contents = data
+		if err := writeBundleCache(cacheDir, etag, data); err != nil {
+			slog.Warn("Failed to cache policy bundle", "source", source, "error", err)
+		}
+	}
+
+	reader := bundle.NewCustomReader(bundle.NewTarballLoaderWithBaseURL(bytes.NewReader(contents), source)).
+		WithBundleName(source).
+		WithBundleEtag(etag)
+	if l.PublicKey != "" {
+		alg := l.SigningAlg
+		if alg == "" {
+			alg = DefaultBundleSigningAlg
+		}
+		keyConfig, err := bundleKeyConfig(l.PublicKey, alg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy bundle public key: %v", err)
+		}
+		reader = reader.WithBundleVerificationConfig(bundle.NewVerificationConfig(map[string]*bundle.KeyConfig{bundleVerificationKeyId: keyConfig}, bundleVerificationKeyId, "", nil))
+	} else {
+		reader = reader.WithSkipBundleVerification(true)
+	}
+
+	b, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy bundle %v: %v", source, err)
+	}
+	slog.Info("Loaded policy bundle", "source", source, "modules", len(b.Modules), "etag", etag)
+	return &b, nil
+}
+
+// bundleKeyConfig returns the verification key for publicKey. PEM keys are used as they are,
+// keys.NewKeyConfig would first stat them as a path, which fails when a line of base64 between
+// two slashes is longer than a file name can be.
+func bundleKeyConfig(publicKey string, alg string) (*keys.Config, error) {
+	if strings.Contains(publicKey, "-----BEGIN") {
+		return &keys.Config{Key: publicKey, Algorithm: alg}, nil
+	}
+	return keys.NewKeyConfig(publicKey, alg, "")
+}
+
+// download fetches the bundle and its ETag. data is nil when the cached ETag is still current.
+func (l BundleLoader) download(source string, cachedEtag string) (etag string, data []byte, err error) {
+	if strings.HasPrefix(source, ociScheme) {
+		return l.downloadOci(strings.TrimPrefix(source, ociScheme), cachedEtag)
+	}
+	resp, err := l.get(source, cachedEtag, "")
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return cachedEtag, nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Header.Get("ETag"), data, nil
+}
+
+// downloadOci fetches the bundle layer of an OCI artifact. The manifest is
+// revalidated with its ETag and the layer is checked against its digest.
+func (l BundleLoader) downloadOci(reference string, cachedEtag string) (string, []byte, error) {
+	registry, repository, ref, err := parseOciReference(reference)
+	if err != nil {
+		return "", nil, err
+	}
+	baseUrl := fmt.Sprintf("https://%v/v2/%v", registry, repository)
+
+	resp, err := l.get(baseUrl+"/manifests/"+ref, cachedEtag, ociManifestMediaTypes)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return cachedEtag, nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status %v fetching manifest", resp.Status)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		etag = resp.Header.Get("Docker-Content-Digest")
+	}
+
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return "", nil, fmt.Errorf("failed to decode manifest: %v", err)
+	}
+	digest := ""
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == ociBundleLayerMediaType {
+			digest = layer.Digest
+			break
+		}
+	}
+	if digest == "" {
+		return "", nil, fmt.Errorf("manifest has no %v layer", ociBundleLayerMediaType)
+	}
+
+	blobResp, err := l.get(baseUrl+"/blobs/"+digest, "", "")
+	if err != nil {
+		return "", nil, err
+	}
+	defer blobResp.Body.Close()
+	if blobResp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status %v fetching layer %v", blobResp.Status, digest)
+	}
+	data, err := io.ReadAll(blobResp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(data)
+	if actual := "sha256:" + hex.EncodeToString(sum[:]); actual != digest {
+		return "", nil, fmt.Errorf("layer digest mismatch: expected %v, got %v", digest, actual)
+	}
+	return etag, data, nil
+}
+
+// get sends a GET request, exchanging an anonymous registry token if the
+// server asks for one and no token is configured
+func (l BundleLoader) get(requestUrl string, etag string, accept string) (*http.Response, error) {
+	client := l.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	newRequest := func(token string) (*http.Request, error) {
+		req, err := http.NewRequest("GET", requestUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	}
+
+	req, err := newRequest(l.Token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || l.Token != "" {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	token, err := l.registryToken(client, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry: %v", err)
+	}
+	req, err = newRequest(token)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// registryToken requests an anonymous token for a Bearer WWW-Authenticate challenge
+func (l BundleLoader) registryToken(client *http.Client, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found {
+			params[key] = strings.Trim(value, `"`)
+		}
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge has no realm")
+	}
+	tokenUrl, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := tokenUrl.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenUrl.RawQuery = query.Encode()
+
+	resp, err := client.Get(tokenUrl.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
+
+// parseOciReference splits registry/repository:tag or registry/repository@digest
+func parseOciReference(reference string) (registry string, repository string, ref string, err error) {
+	registry, rest, found := strings.Cut(reference, "/")
+	if !found || rest == "" {
+		return "", "", "", fmt.Errorf("invalid oci reference %q, expected registry/repository:tag", reference)
+	}
+	if repository, ref, found = strings.Cut(rest, "@"); found {
+		return registry, repository, ref, nil
+	}
+	if i := strings.LastIndex(rest, ":"); i > 0 {
+		return registry, rest[:i], rest[i+1:], nil
+	}
+	return registry, rest, "latest", nil
+}
+
+func bundleCacheKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeBundleCache(cacheDir string, etag string, data []byte) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "bundle.tar.gz"), data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, "etag"), []byte(etag), 0o644)
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/stretchr/testify/assert"
+)
+
+const helpersModule = "package lib.regions\n\nallowed(region) { data.regions.allowed[_] == region }\n"
+
+// buildTestBundle returns a tar.gz bundle with a helper module and data, signed if signingKey is set
+func buildTestBundle(t *testing.T, signingKey string) []byte {
+	module, err := ast.ParseModule("lib/regions.rego", helpersModule)
+	assert.NoError(t, err)
+	b := bundle.Bundle{
+		Modules: []bundle.ModuleFile{{URL: "lib/regions.rego", Path: "lib/regions.rego", Raw: []byte(helpersModule), Parsed: module}},
+		Data:    map[string]interface{}{"regions": map[string]interface{}{"allowed": []interface{}{"eu-west-1"}}},
+	}
+	if signingKey != "" {
+		assert.NoError(t, b.GenerateSignature(bundle.NewSigningConfig(signingKey, "RS256", ""), "digger", false))
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, bundle.NewWriter(&buf).Write(b))
+	return buf.Bytes()
+}
+
+func newTestKeyPair(t *testing.T) (privatePem string, publicPem string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	privatePem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	publicPem = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	return privatePem, publicPem
+}
+
+func TestBundleLoaderEtagRevalidation(t *testing.T) {
+	data := buildTestBundle(t, "")
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(data)
+	}))
+	loader := BundleLoader{CacheDir: t.TempDir(), HttpClient: server.Client()}
+
+	b, err := loader.Load(server.URL + "/bundle.tar.gz")
+	assert.NoError(t, err)
+	assert.Len(t, b.Modules, 1)
+
+	b, err = loader.Load(server.URL + "/bundle.tar.gz")
+	assert.NoError(t, err)
+	assert.Len(t, b.Modules, 1)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+
+	// the cached copy is used when the server is unreachable
+	server.Close()
+	b, err = loader.Load(server.URL + "/bundle.tar.gz")
+	assert.NoError(t, err)
+	assert.Len(t, b.Modules, 1)
+}
+
+func TestBundleLoaderSignatureVerification(t *testing.T) {
+	privateKey, publicKey := newTestKeyPair(t)
+	_, otherPublicKey := newTestKeyPair(t)
+	signed := buildTestBundle(t, privateKey)
+	unsigned := buildTestBundle(t, "")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signed.tar.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(signed) })
+	mux.HandleFunc("/unsigned.tar.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(unsigned) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	loader := BundleLoader{CacheDir: t.TempDir(), HttpClient: server.Client(), PublicKey: publicKey}
+	_, err := loader.Load(server.URL + "/signed.tar.gz")
+	assert.NoError(t, err)
+
+	_, err = loader.Load(server.URL + "/unsigned.tar.gz")
+	assert.Error(t, err)
+
+	loader.PublicKey = otherPublicKey
+	_, err = loader.Load(server.URL + "/signed.tar.gz")
+	assert.Error(t, err)
+}
+
+func TestBundleLoaderOci(t *testing.T) {
+	data := buildTestBundle(t, "")
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repository:acme/policies:pull", r.URL.Query().Get("scope"))
+		json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"})
+	})
+	var server *httptest.Server
+	mux.HandleFunc("/v2/acme/policies/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:acme/policies:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/acme/policies/manifests/v1":
+			w.Header().Set("Docker-Content-Digest", "sha256:manifest")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"layers": []map[string]string{{"mediaType": ociBundleLayerMediaType, "digest": digest}},
+			})
+		case "/v2/acme/policies/blobs/" + digest:
+			w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	loader := BundleLoader{CacheDir: t.TempDir(), HttpClient: server.Client()}
+	b, err := loader.Load("oci://" + strings.TrimPrefix(server.URL, "https://") + "/acme/policies:v1")
+	assert.NoError(t, err)
+	assert.Len(t, b.Modules, 1)
+}
+
+func TestParseOciReference(t *testing.T) {
+	for reference, expected := range map[string][3]string{
+		"ghcr.io/acme/policies:v1":        {"ghcr.io", "acme/policies", "v1"},
+		"localhost:5000/policies":         {"localhost:5000", "policies", "latest"},
+		"ghcr.io/acme/policies@sha256:ab": {"ghcr.io", "acme/policies", "sha256:ab"},
+	} {
+		registry, repository, ref, err := parseOciReference(reference)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, [3]string{registry, repository, ref})
+	}
+	_, _, _, err := parseOciReference("policies")
+	assert.Error(t, err)
+}
+
+func TestPolicyCheckerUsesBundles(t *testing.T) {
+	b, err := bundle.NewReader(bytes.NewReader(buildTestBundle(t, ""))).Read()
+	assert.NoError(t, err)
+	checker := DiggerPolicyChecker{
+		PolicyProvider: &enforcementLevelsPolicyProvider{planPolicy: "package digger\n" +
+			"import data.lib.regions\n" +
+			"deny[msg] { r := input.terraform.resource_changes[_]; not regions.allowed(r.change.after.region); msg := sprintf(\"%v is not in an allowed region\", [r.address]) }\n"},
+		Bundles: []PolicyBundle{{Source: "test", Bundle: &b}},
+	}
+
+	planJson := `{"resource_changes":[{"address":"aws_s3_bucket.a","change":{"after":{"region":"eu-west-1"}}},{"address":"aws_s3_bucket.b","change":{"after":{"region":"us-east-1"}}}]}`
+	allowed, result, err := checker.CheckPlanPolicy("", "", "", "", planJson, PolicyContext{})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, []string{"aws_s3_bucket.b is not in an allowed region"}, result.Denials)
+
+	allowed, err = checker.CheckAccessPolicy(ci.MockPullRequestManager{}, nil, "", "", "", "", "digger plan", nil, "alice", []string{})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
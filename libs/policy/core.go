@@ -1,6 +1,10 @@
 package policy
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"github.com/diggerhq/digger/libs/ci"
 )
 
@@ -11,11 +15,99 @@ type Provider interface {
 	GetOrganisation() string // TODO: remove this method from here since out of place
 }
 
+// Policy layer names. Directory layers are named DirPolicyLayerPrefix followed by
+// the directory relative to the management repository root.
+const (
+	OrgPolicyLayer       = "org"
+	RepoPolicyLayer      = "repo"
+	ProjectPolicyLayer   = "project"
+	DefaultPolicyLayer   = "default"
+	DirPolicyLayerPrefix = "dir:"
+)
+
+// PolicyLayer is a policy together with the scope it was defined at
+type PolicyLayer struct {
+	Name   string
+	Policy string
+}
+
+// LayeredProvider is implemented by providers that return every policy that
+// applies to a project, ordered from the broadest scope to the most specific.
+// Every layer is evaluated so a narrower layer can add to but never weaken a
+// broader one: access policies are deny-overrides and plan policy messages
+// from all layers are combined.
+type LayeredProvider interface {
+	GetAccessPolicyLayers(organisation string, repository string, projectname string, projectDir string) ([]PolicyLayer, error)
+	GetPlanPolicyLayers(organisation string, repository string, projectname string, projectDir string) ([]PolicyLayer, error)
+}
+
+// AccessDeniedError is returned along with false by CheckAccessPolicy when a named
+// policy layer denies the action, so users know which policy to look at
+type AccessDeniedError struct {
+	Layer string
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("denied by the %v access policy", e.Layer)
+}
+
+// IsAccessDenied reports whether err is a denial by an access policy layer
+// rather than a failure to evaluate the policies
+func IsAccessDenied(err error) bool {
+	var denied *AccessDeniedError
+	return errors.As(err, &denied)
+}
+
 type Checker interface {
 	// TODO refactor arguments - use AccessPolicyContext
+	// CheckAccessPolicy returns an *AccessDeniedError with false when a named layer denies the action
 	CheckAccessPolicy(ciService ci.OrgService, prService *ci.PullRequestService, SCMOrganisation string, SCMrepository string, projectName string, projectDir string, command string, prNumber *int, requestedBy string, planPolicyViolations []string) (bool, error)
-	CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string) (bool, []string, error)
-	CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string) (bool, error)
+	// CheckPlanPolicy reports whether the plan passes with no deny or soft_deny
+	// results, along with everything the plan policy returned
+	CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string, policyContext PolicyContext) (bool, PlanPolicyResult, error)
+	CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string, policyContext PolicyContext) (bool, error)
+}
+
+// PlanPolicyResult holds plan policy messages by enforcement level
+type PlanPolicyResult struct {
+	// Denials come from data.digger.deny and always block
+	Denials []string
+	// SoftDenials come from data.digger.soft_deny and block unless overridden
+	SoftDenials []string
+	// Warnings come from data.digger.warn and are only reported
+	Warnings []string
+	// Layers holds the messages of each named policy layer that was evaluated
+	Layers []PlanPolicyLayerResult
+}
+
+// PlanPolicyLayerResult holds the plan policy messages produced by one layer
+type PlanPolicyLayerResult struct {
+	Layer       string
+	Denials     []string
+	SoftDenials []string
+	Warnings    []string
+}
+
+// LayersOf returns the names of the layers that produced a message
+func (r PlanPolicyResult) LayersOf(message string) []string {
+	var layers []string
+	for _, layer := range r.Layers {
+		if slices.Contains(layer.Denials, message) || slices.Contains(layer.SoftDenials, message) || slices.Contains(layer.Warnings, message) {
+			layers = append(layers, layer.Layer)
+		}
+	}
+	return layers
+}
+
+// Violations returns the messages that block the plan, leaving out soft
+// denials when they have been overridden
+func (r PlanPolicyResult) Violations(softDenialsOverridden bool) []string {
+	violations := make([]string, 0, len(r.Denials)+len(r.SoftDenials))
+	violations = append(violations, r.Denials...)
+	if !softDenialsOverridden {
+		violations = append(violations, r.SoftDenials...)
+	}
+	return violations
 }
 
 type PolicyCheckerProvider interface {
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PolicyInputVersion is the version of the input.context schema passed to plan
+// and drift policies. It is bumped whenever a field is renamed or removed, so
+// policies can guard on input.context.version.
+const PolicyInputVersion = 1
+
+// PolicyContext describes what a plan or drift policy is evaluated for. It is
+// passed to policies as input.context.
+type PolicyContext struct {
+	Version      int     `json:"version" yaml:"version"`
+	Organisation string  `json:"organisation" yaml:"organisation"`
+	Repository   string  `json:"repository" yaml:"repository"`
+	Project      Project `json:"project" yaml:"project"`
+	// PullRequest is nil outside of pull requests, e.g. for drift detection
+	PullRequest *PullRequest `json:"pull_request" yaml:"pull_request"`
+	// User is the user who requested the run
+	User string `json:"user" yaml:"user"`
+
+	// loadPullRequest sets PullRequest once the context is passed to a policy
+	loadPullRequest func() *PullRequest
+}
+
+// WithPullRequestLoader returns a copy of the context whose pull request is loaded only
+// when a policy is evaluated, so that no VCS lookups are made when there is no policy
+func (c PolicyContext) WithPullRequestLoader(load func() *PullRequest) PolicyContext {
+	c.loadPullRequest = load
+	return c
+}
+
+// Project is the digger project a policy is evaluated for
+type Project struct {
+	Name      string `json:"name" yaml:"name"`
+	Dir       string `json:"dir" yaml:"dir"`
+	Workspace string `json:"workspace" yaml:"workspace"`
+}
+
+// PullRequest is the pull request a policy is evaluated for
+type PullRequest struct {
+	Number       int      `json:"number" yaml:"number"`
+	Author       string   `json:"author" yaml:"author"`
+	Labels       []string `json:"labels" yaml:"labels"`
+	ChangedFiles []string `json:"changed_files" yaml:"changed_files"`
+	TargetBranch string   `json:"target_branch" yaml:"target_branch"`
+	Approvals    []string `json:"approvals" yaml:"approvals"`
+}
+
+// toInput converts the context into the generic form rego evaluates, stamped
+// with the current schema version
+func (c PolicyContext) toInput() (map[string]interface{}, error) {
+	c.Version = PolicyInputVersion
+	if c.loadPullRequest != nil {
+		c.PullRequest = c.loadPullRequest()
+	}
+	if c.PullRequest != nil {
+		pr := *c.PullRequest
+		pr.Labels = nonNil(pr.Labels)
+		pr.ChangedFiles = nonNil(pr.ChangedFiles)
+		pr.Approvals = nonNil(pr.Approvals)
+		c.PullRequest = &pr
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy context: %v", err)
+	}
+	var input map[string]interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy context: %v", err)
+	}
+	return input, nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WritePolicyTestJUnit writes the suite results as a JUnit XML report
+func WritePolicyTestJUnit(w io.Writer, suiteResults []PolicyTestSuiteResult) error {
+	report := junitTestSuites{}
+	for _, suiteResult := range suiteResults {
+		failures, errors := suiteResult.Counts()
+		suite := junitTestSuite{
+			Name:     suiteResult.Name,
+			Tests:    len(suiteResult.Results),
+			Failures: failures,
+			Errors:   errors,
+		}
+		var total time.Duration
+		for _, result := range suiteResult.Results {
+			total += result.Duration
+			testCase := junitTestCase{
+				Name:      result.Name,
+				ClassName: fmt.Sprintf("%v.%v", suiteResult.Name, result.Policy),
+				Time:      junitSeconds(result.Duration),
+			}
+			if result.Err != nil {
+				testCase.Error = &junitMessage{Message: "policy evaluation failed", Text: result.Err.Error()}
+			} else if len(result.Failures) > 0 {
+				testCase.Failure = &junitMessage{Message: result.Failures[0], Text: strings.Join(result.Failures, "\n")}
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		suite.Time = junitSeconds(total)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.TestSuites = append(report.TestSuites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode junit report: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
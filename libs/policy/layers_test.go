@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLayeredHttpPolicyProvider(t *testing.T, policies map[string]string) DiggerHttpPolicyProvider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := policies[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(policy))
+	}))
+	t.Cleanup(server.Close)
+	return DiggerHttpPolicyProvider{
+		DiggerHost:         server.URL,
+		DiggerOrganisation: "acme",
+		AuthToken:          "token",
+		HttpClient:         server.Client(),
+	}
+}
+
+func TestLayeredAccessPolicyDenyOverrides(t *testing.T) {
+	provider := newLayeredHttpPolicyProvider(t, map[string]string{
+		"/orgs/acme/access-policy": "package digger\n" +
+			"default allow = false\n" +
+			"allow { input.teams[_] == \"platform\" }\n",
+		// a project policy that tries to allow everyone
+		"/repos/acme-infra/projects/prod/access-policy": "package digger\n" +
+			"default allow = true\n",
+	})
+	checker := DiggerPolicyChecker{PolicyProvider: provider}
+
+	allowed, err := checker.CheckAccessPolicy(ci.MockPullRequestManager{Teams: []string{"developers"}}, nil, "acme", "infra", "prod", "prod", "digger apply", nil, "alice", []string{})
+	assert.False(t, allowed)
+	assert.True(t, IsAccessDenied(err))
+	assert.EqualError(t, err, "denied by the org access policy")
+
+	allowed, err = checker.CheckAccessPolicy(ci.MockPullRequestManager{Teams: []string{"platform"}}, nil, "acme", "infra", "prod", "prod", "digger apply", nil, "bob", []string{})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestLayeredAccessPolicyDefault(t *testing.T) {
+	provider := newLayeredHttpPolicyProvider(t, map[string]string{})
+
+	layers, err := provider.GetAccessPolicyLayers("acme", "infra", "prod", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []PolicyLayer{{Name: DefaultPolicyLayer, Policy: DefaultAccessPolicy}}, layers)
+}
+
+func TestLayeredPlanPolicyUnion(t *testing.T) {
+	provider := newLayeredHttpPolicyProvider(t, map[string]string{
+		"/orgs/acme/plan-policy": "package digger\n" +
+			"deny[msg] { input.terraform.resource_changes[_].change.actions[_] == \"delete\"; msg := \"deletes resources\" }\n",
+		"/repos/acme-infra/plan-policy": "package digger\n" +
+			"warn[msg] { input.terraform.resource_changes[_].type == \"aws_instance\"; msg := \"instance changes\" }\n",
+		"/repos/acme-infra/projects/prod/plan-policy": "package digger\n" +
+			"deny[msg] { input.terraform.resource_changes[_].change.actions[_] == \"delete\"; msg := \"deletes resources\" }\n" +
+			"soft_deny[msg] { input.terraform.resource_changes[_].type == \"aws_instance\"; msg := \"prod instances\" }\n",
+	})
+	checker := DiggerPolicyChecker{PolicyProvider: provider}
+
+	planJson := `{"resource_changes":[{"type":"aws_instance","change":{"actions":["delete"]}}]}`
+	allowed, result, err := checker.CheckPlanPolicy("infra", "acme", "prod", "prod", planJson, PolicyContext{})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, []string{"deletes resources"}, result.Denials)
+	assert.Equal(t, []string{"prod instances"}, result.SoftDenials)
+	assert.Equal(t, []string{"instance changes"}, result.Warnings)
+	assert.Equal(t, []string{OrgPolicyLayer, ProjectPolicyLayer}, result.LayersOf("deletes resources"))
+	assert.Equal(t, []string{RepoPolicyLayer}, result.LayersOf("instance changes"))
+}
+
+func TestLayeredPolicyFailsClosedOnUnreadableLayer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/access-policy" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the connection closes before the announced body is sent
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("package digger\n"))
+	}))
+	t.Cleanup(server.Close)
+	provider := DiggerHttpPolicyProvider{DiggerHost: server.URL, DiggerOrganisation: "acme", AuthToken: "token", HttpClient: server.Client()}
+	checker := DiggerPolicyChecker{PolicyProvider: provider}
+
+	allowed, err := checker.CheckAccessPolicy(ci.MockPullRequestManager{}, nil, "acme", "infra", "prod", "prod", "digger apply", nil, "alice", []string{})
+	assert.Error(t, err)
+	assert.False(t, allowed)
+	_, err = provider.GetAccessPolicy("acme", "infra", "prod", "prod")
+	assert.Error(t, err)
+}
+
+func TestHttpPolicyProviderMostSpecificPolicy(t *testing.T) {
+	provider := newLayeredHttpPolicyProvider(t, map[string]string{
+		"/orgs/acme/plan-policy":                      "org",
+		"/repos/acme-infra/projects/prod/plan-policy": "project",
+		"/orgs/acme/drift-policy":                     "drift",
+	})
+
+	policy, err := provider.GetPlanPolicy("acme", "infra", "prod", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "project", policy)
+	policy, err = provider.GetPlanPolicy("acme", "infra", "dev", "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "org", policy)
+	policy, err = provider.GetAccessPolicy("acme", "infra", "dev", "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAccessPolicy, policy)
+	policy, err = provider.GetDriftPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, "drift", policy)
+}
+
+func TestFindRepoPolicyLayers(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{
+		"policies/plan.rego",
+		"policies/infra/prod/plan.rego",
+		"plan.rego",
+		"dev/plan.rego",
+		"dev/vpc/plan.rego",
+	} {
+		writeTestFile(t, filepath.Join(dir, path), "package digger # "+path+"\n")
+	}
+
+	layers, err := FindRepoPolicyLayers(dir, "infra", "prod", "dev/vpc", "plan.rego")
+	assert.NoError(t, err)
+	var names []string
+	for _, layer := range layers {
+		names = append(names, layer.Name)
+	}
+	assert.Equal(t, []string{"org", "project", "dir:.", "dir:dev", "dir:dev/vpc"}, names)
+	assert.Equal(t, "package digger # dev/vpc/plan.rego\n", layers[4].Policy)
+
+	// project directories outside of the repository are ignored
+	layers, err = FindRepoPolicyLayers(filepath.Join(dir, "dev"), "", "", "../dev", "plan.rego")
+	assert.NoError(t, err)
+	assert.Len(t, layers, 1)
+	assert.Equal(t, "dir:.", layers[0].Name)
+}
@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LocalPolicyProvider serves policies from the local filesystem so they can be
+// tested without a backend. Explicit policy files take priority over RepoDir,
+// a checkout laid out like the management repository.
+type LocalPolicyProvider struct {
+	AccessPolicyPath string
+	PlanPolicyPath   string
+	DriftPolicyPath  string
+	RepoDir          string
+}
+
+func (p LocalPolicyProvider) GetAccessPolicy(organisation string, repository string, projectName string, projectDir string) (string, error) {
+	if p.AccessPolicyPath != "" {
+		return readPolicyFile(p.AccessPolicyPath)
+	}
+	if p.RepoDir == "" {
+		return "", nil
+	}
+	policy, err := FindRepoPolicyFile(p.RepoDir, repository, projectName, projectDir, "access.rego")
+	if err != nil {
+		return "", err
+	}
+	if policy == "" {
+		return DefaultAccessPolicy, nil
+	}
+	return policy, nil
+}
+
+func (p LocalPolicyProvider) GetPlanPolicy(organisation string, repository string, projectName string, projectDir string) (string, error) {
+	if p.PlanPolicyPath != "" {
+		return readPolicyFile(p.PlanPolicyPath)
+	}
+	if p.RepoDir == "" {
+		return "", nil
+	}
+	return FindRepoPolicyFile(p.RepoDir, repository, projectName, projectDir, "plan.rego")
+}
+
+func (p LocalPolicyProvider) GetDriftPolicy() (string, error) {
+	if p.DriftPolicyPath != "" {
+		return readPolicyFile(p.DriftPolicyPath)
+	}
+	if p.RepoDir == "" {
+		return "", nil
+	}
+	// drift policies are not project specific so only the org level file applies
+	policy, err := readPolicyFile(filepath.Join(p.RepoDir, "policies", "drift.rego"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return policy, err
+}
+
+func (p LocalPolicyProvider) GetOrganisation() string {
+	return ""
+}
+
+// GetAccessPolicyLayers returns the explicit access policy file as a single
+// layer, or every layer found in RepoDir
+func (p LocalPolicyProvider) GetAccessPolicyLayers(organisation string, repository string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	if p.AccessPolicyPath != "" || p.RepoDir == "" {
+		return p.singlePolicyLayer(p.GetAccessPolicy(organisation, repository, projectName, projectDir))
+	}
+	layers, err := FindRepoPolicyLayers(p.RepoDir, repository, projectName, projectDir, "access.rego")
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return []PolicyLayer{{Name: DefaultPolicyLayer, Policy: DefaultAccessPolicy}}, nil
+	}
+	return layers, nil
+}
+
+func (p LocalPolicyProvider) GetPlanPolicyLayers(organisation string, repository string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	if p.PlanPolicyPath != "" || p.RepoDir == "" {
+		return p.singlePolicyLayer(p.GetPlanPolicy(organisation, repository, projectName, projectDir))
+	}
+	return FindRepoPolicyLayers(p.RepoDir, repository, projectName, projectDir, "plan.rego")
+}
+
+func (p LocalPolicyProvider) singlePolicyLayer(policy string, err error) ([]PolicyLayer, error) {
+	if err != nil || policy == "" {
+		return nil, err
+	}
+	return []PolicyLayer{{Policy: policy}}, nil
+}
+
+func readPolicyFile(filePath string) (string, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return string(contents), nil
+}
+
+// FindRepoPolicyFile returns the contents of the first policy file named fileName
+// found in a management repository checked out at basePath, or "" if there is none.
+// The project directory and its parents take priority, followed by
+// policies/<repo>/<project>, policies/<repo> and finally policies/.
+func FindRepoPolicyFile(basePath string, repo string, projectName string, projectDir string, fileName string) (string, error) {
+	prefixes := GetPrefixesForPath(filepath.Join(basePath, projectDir), fileName)
+	prefixes = append(prefixes,
+		filepath.Join(basePath, "policies", repo, projectName, fileName),
+		filepath.Join(basePath, "policies", repo, fileName),
+		filepath.Join(basePath, "policies", fileName),
+	)
+
+	for _, pathPrefix := range prefixes {
+		contents, err := readPolicyFile(pathPrefix)
+		if err == nil {
+			return contents, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// FindRepoPolicyLayers returns every policy file named fileName that applies to
+// a project in a management repository checked out at basePath, from the
+// broadest scope to the most specific: policies/, policies/<repo>,
+// policies/<repo>/<project>, then the repository root and each directory down
+// to the project directory. Directories outside of basePath are never read.
+func FindRepoPolicyLayers(basePath string, repo string, projectName string, projectDir string, fileName string) ([]PolicyLayer, error) {
+	type candidate struct {
+		layer string
+		path  string
+	}
+	candidates := []candidate{{OrgPolicyLayer, filepath.Join(basePath, "policies", fileName)}}
+	if repo != "" {
+		candidates = append(candidates, candidate{RepoPolicyLayer, filepath.Join(basePath, "policies", repo, fileName)})
+		if projectName != "" {
+			candidates = append(candidates, candidate{ProjectPolicyLayer, filepath.Join(basePath, "policies", repo, projectName, fileName)})
+		}
+	}
+
+	candidates = append(candidates, candidate{DirPolicyLayerPrefix + ".", filepath.Join(basePath, fileName)})
+	dir := filepath.Clean(projectDir)
+	if dir != "." && !filepath.IsAbs(dir) && dir != ".." && !strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		prefixes := GetPrefixesForPath(dir, fileName)
+		slices.Reverse(prefixes)
+		for _, prefix := range prefixes {
+			candidates = append(candidates, candidate{DirPolicyLayerPrefix + filepath.ToSlash(filepath.Dir(prefix)), filepath.Join(basePath, prefix)})
+		}
+	}
+
+	var layers []PolicyLayer
+	for _, c := range candidates {
+		contents, err := readPolicyFile(c.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if contents != "" {
+			layers = append(layers, PolicyLayer{Name: c.layer, Policy: contents})
+		}
+	}
+	return layers, nil
+}
+
+// GetPrefixesForPath
+// @path is the total path example /dev/vpc/subnets
+// @filename is the name of the file to search for example access.rego
+// returns the list of prefixes in priority order example:
+// /dev/vpc/subnets/access.rego
+// /dev/vpc/access.rego
+// /dev/access.rego
+func GetPrefixesForPath(path string, fileName string) []string {
+	var prefixes []string
+	parts := strings.Split(filepath.Clean(path), string(filepath.Separator))
+	for i := range parts {
+		prefixes = append(prefixes, filepath.Join(parts[:i+1]...))
+	}
+
+	slices.Reverse(prefixes)
+	var result []string
+	for index, item := range prefixes {
+		// if input path was absolute then result should be absolute and ignore last item ""
+		if parts[0] == "" {
+			if index < len(prefixes)-1 {
+				result = append(result, string(filepath.Separator)+item+string(filepath.Separator)+fileName)
+			}
+		} else {
+			result = append(result, item+string(filepath.Separator)+fileName)
+		}
+	}
+	return result
+}
@@ -9,10 +9,10 @@ func (t MockPolicyChecker) CheckAccessPolicy(ciService ci.OrgService, prService
 	return false, nil
 }
 
-func (t MockPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string) (bool, []string, error) {
-	return false, nil, nil
+func (t MockPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string, policyContext PolicyContext) (bool, PlanPolicyResult, error) {
+	return false, PlanPolicyResult{}, nil
 }
 
-func (t MockPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string) (bool, error) {
+func (t MockPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string, policyContext PolicyContext) (bool, error) {
 	return true, nil
 }
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/diggerhq/digger/libs/ci"
+)
+
+// OverridePolicyCommand is the PR comment command that overrides soft plan
+// policy denials for a project at a head commit of the pull request:
+//
+//	digger override-policy -p <project> --sha <commit> --reason "..."
+//
+// It is authorised through the access policy with this command as the action.
+const OverridePolicyCommand = "digger override-policy"
+
+var overrideReasonPattern = regexp.MustCompile(`--reason\s+(?:"([^"]*)"|'([^']*)'|(\S+))`)
+var overrideShaPattern = regexp.MustCompile(`--sha\s+([0-9a-fA-F]+)`)
+
+// minOverrideShaLength is the shortest commit prefix an override may name
+const minOverrideShaLength = 7
+
+// PolicyOverride records who overrode the soft denials of a project's plan policy and why
+type PolicyOverride struct {
+	Project     string   `json:"project"`
+	User        string   `json:"user"`
+	Reason      string   `json:"reason"`
+	Sha         string   `json:"sha"`
+	SoftDenials []string `json:"soft_denials"`
+}
+
+// IsOverridePolicyComment reports whether a comment is an override-policy command
+func IsOverridePolicyComment(comment string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(comment)), OverridePolicyCommand)
+}
+
+// ParseOverridePolicyComment parses an override-policy command. The project,
+// the commit and a non-empty reason are required.
+func ParseOverridePolicyComment(comment string) (*PolicyOverride, error) {
+	if !IsOverridePolicyComment(comment) {
+		return nil, fmt.Errorf("not a %v command", OverridePolicyCommand)
+	}
+	project := ci.ParseProjectName(comment)
+	if project == "" {
+		return nil, fmt.Errorf("%v requires a project, use -p <project>", OverridePolicyCommand)
+	}
+	match := overrideReasonPattern.FindStringSubmatch(comment)
+	reason := ""
+	if match != nil {
+		reason = strings.TrimSpace(match[1] + match[2] + match[3])
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%v requires a reason, use --reason \"...\"", OverridePolicyCommand)
+	}
+	sha := ""
+	if match := overrideShaPattern.FindStringSubmatch(comment); match != nil {
+		sha = strings.ToLower(match[1])
+	}
+	if len(sha) < minOverrideShaLength {
+		return nil, fmt.Errorf("%v requires the commit it overrides, use --sha <commit> with at least %d characters", OverridePolicyCommand, minOverrideShaLength)
+	}
+	return &PolicyOverride{Project: project, Reason: reason, Sha: sha}, nil
+}
+
+// FindPolicyOverride returns the most recent override-policy comment on the
+// pull request for the project and headSha whose author the access policy
+// allows to override, or nil if there is none. Overrides of earlier commits
+// don't carry over to later pushes, whose denials nobody has seen. The soft
+// denials are passed to the access policy as plan policy violations, so the
+// default access policy refuses overrides and policies have to grant them
+// explicitly.
+func FindPolicyOverride(checker Checker, orgService ci.OrgService, prService ci.PullRequestService, SCMOrganisation string, SCMrepository string, projectName string, projectDir string, prNumber int, headSha string, softDenials []string) (*PolicyOverride, error) {
+	if headSha == "" {
+		slog.Warn("Head commit of the pull request is unknown, ignoring override-policy comments", "prNumber", prNumber, "project", projectName)
+		return nil, nil
+	}
+	comments, err := prService.GetComments(prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request comments: %v", err)
+	}
+
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if comment.Body == nil || !IsOverridePolicyComment(*comment.Body) {
+			continue
+		}
+		override, err := ParseOverridePolicyComment(*comment.Body)
+		if err != nil {
+			slog.Debug("Ignoring malformed override-policy comment", "commentId", comment.Id, "error", err)
+			continue
+		}
+		if override.Project != projectName {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(headSha), override.Sha) {
+			slog.Debug("Ignoring override-policy comment for another commit", "commentId", comment.Id, "sha", override.Sha, "headSha", headSha)
+			continue
+		}
+		if comment.Author == "" {
+			slog.Warn("Ignoring override-policy comment without a known author", "commentId", comment.Id)
+			continue
+		}
+
+		allowed, err := checker.CheckAccessPolicy(orgService, &prService, SCMOrganisation, SCMrepository, projectName, projectDir, OverridePolicyCommand, &prNumber, comment.Author, softDenials)
+		if err != nil && !IsAccessDenied(err) {
+			return nil, fmt.Errorf("failed to check access policy for override: %v", err)
+		}
+		if !allowed {
+			slog.Info("User is not allowed to override plan policy",
+				"user", comment.Author,
+				"project", projectName,
+				"commentId", comment.Id,
+				"reason", err)
+			continue
+		}
+
+		override.User = comment.Author
+		override.SoftDenials = softDenials
+		slog.Info("Plan policy overridden",
+			"user", override.User,
+			"project", projectName,
+			"reason", override.Reason)
+		return override, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOverridePolicyComment(t *testing.T) {
+	override, err := ParseOverridePolicyComment(`digger override-policy -p prod --sha 3F2A9C1 --reason "approved in CAB-12"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", override.Project)
+	assert.Equal(t, "approved in CAB-12", override.Reason)
+	assert.Equal(t, "3f2a9c1", override.Sha)
+
+	override, err = ParseOverridePolicyComment(`digger override-policy -p prod --reason hotfix --sha 3f2a9c1d`)
+	assert.NoError(t, err)
+	assert.Equal(t, "hotfix", override.Reason)
+
+	_, err = ParseOverridePolicyComment(`digger override-policy -p prod --sha 3f2a9c1`)
+	assert.Error(t, err)
+
+	_, err = ParseOverridePolicyComment(`digger override-policy --sha 3f2a9c1 --reason "no project"`)
+	assert.Error(t, err)
+
+	_, err = ParseOverridePolicyComment(`digger override-policy -p prod --reason "no commit"`)
+	assert.Error(t, err)
+
+	_, err = ParseOverridePolicyComment(`digger override-policy -p prod --sha 3f2a --reason "short commit"`)
+	assert.Error(t, err)
+
+	_, err = ParseOverridePolicyComment(`digger plan -p prod`)
+	assert.Error(t, err)
+}
+
+type overrideAccessPolicyProvider struct {
+	DiggerDefaultPolicyProvider
+}
+
+func (s *overrideAccessPolicyProvider) GetAccessPolicy(organisation string, repository string, projectname string, projectDir string) (string, error) {
+	return "package digger\n" +
+		"default allow = false\n" +
+		"allow { input.action == \"digger override-policy\"; input.user == \"lead\" }\n", nil
+}
+
+func TestFindPolicyOverride(t *testing.T) {
+	comment := func(author string, body string) ci.Comment {
+		return ci.Comment{Id: author, Author: author, Body: &body}
+	}
+	prService := ci.MockPullRequestManager{Comments: []ci.Comment{
+		comment("lead", `digger override-policy -p prod --sha aaaaaaa --reason "first"`),
+		comment("lead", `digger override-policy -p prod --sha bbbbbbb --reason "second"`),
+		comment("lead", `digger override-policy -p staging --sha bbbbbbb --reason "other project"`),
+		comment("dev", `digger override-policy -p prod --sha bbbbbbb --reason "not allowed"`),
+		comment("lead", `digger override-policy -p prod --sha bbbbbbb`),
+	}}
+	checker := DiggerPolicyChecker{PolicyProvider: &overrideAccessPolicyProvider{}}
+
+	override, err := FindPolicyOverride(checker, prService, prService, "org", "repo", "prod", "prod", 1, "aaaaaaa0123", []string{"deletes resources"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, override) {
+		assert.Equal(t, "lead", override.User)
+		assert.Equal(t, "first", override.Reason)
+		assert.Equal(t, []string{"deletes resources"}, override.SoftDenials)
+	}
+
+	// an override of an earlier commit doesn't carry over to a later push
+	override, err = FindPolicyOverride(checker, prService, prService, "org", "repo", "prod", "prod", 1, "bbbbbbb0123", []string{"deletes resources"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, override) {
+		assert.Equal(t, "second", override.Reason)
+	}
+	override, err = FindPolicyOverride(checker, prService, prService, "org", "repo", "prod", "prod", 1, "ccccccc0123", []string{"deletes resources"})
+	assert.NoError(t, err)
+	assert.Nil(t, override)
+
+	override, err = FindPolicyOverride(checker, prService, prService, "org", "repo", "prod", "prod", 1, "", []string{"deletes resources"})
+	assert.NoError(t, err)
+	assert.Nil(t, override)
+
+	override, err = FindPolicyOverride(checker, prService, prService, "org", "repo", "dev", "dev", 1, "aaaaaaa0123", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, override)
+}
+
+func TestFindPolicyOverride_DefaultAccessPolicyRefuses(t *testing.T) {
+	body := `digger override-policy -p prod --sha aaaaaaa --reason "urgent"`
+	prService := ci.MockPullRequestManager{Comments: []ci.Comment{{Id: "1", Author: "dev", Body: &body}}}
+	checker := DiggerPolicyChecker{PolicyProvider: &DiggerDefaultPolicyProvider{}}
+
+	override, err := FindPolicyOverride(checker, prService, prService, "org", "repo", "prod", "prod", 1, "aaaaaaa", []string{"deletes resources"})
+	assert.NoError(t, err)
+	assert.Nil(t, override)
+}
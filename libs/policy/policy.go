@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 
 	"github.com/diggerhq/digger/libs/ci"
 	"github.com/open-policy-agent/opa/rego"
@@ -35,153 +36,43 @@ func (p NoOpPolicyChecker) CheckAccessPolicy(ciService ci.OrgService, prService
 	return true, nil
 }
 
-func (p NoOpPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string) (bool, []string, error) {
-	return true, nil, nil
+func (p NoOpPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string, policyContext PolicyContext) (bool, PlanPolicyResult, error) {
+	return true, PlanPolicyResult{}, nil
 }
 
-func (p NoOpPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string) (bool, error) {
+func (p NoOpPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectname string, policyContext PolicyContext) (bool, error) {
 	return true, nil
 }
 
-func getAccessPolicyForOrganisation(p *DiggerHttpPolicyProvider) (string, *http.Response, error) {
-	organisation := p.DiggerOrganisation
-	u, err := url.Parse(p.DiggerHost)
-	if err != nil {
-		slog.Error("Failed to parse digger cloud URL", "url", p.DiggerHost, "error", err)
-		return "", nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
-	}
-	u.Path = "/orgs/" + organisation + "/access-policy"
-
-	slog.Debug("Fetching org access policy", "organisation", organisation, "url", u.String())
-
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return "", nil, err
-	}
-	req.Header.Add("Authorization", "Bearer "+p.AuthToken)
-
-	resp, err := p.HttpClient.Do(req)
-	if err != nil {
-		return "", nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", resp, nil
-	}
-	return string(body), resp, nil
-}
-
-func getPlanPolicyForOrganisation(p *DiggerHttpPolicyProvider) (string, *http.Response, error) {
-	organisation := p.DiggerOrganisation
-	u, err := url.Parse(p.DiggerHost)
-	if err != nil {
-		slog.Error("Failed to parse digger cloud URL", "url", p.DiggerHost, "error", err)
-		return "", nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
-	}
-	u.Path = "/orgs/" + organisation + "/plan-policy"
-
-	slog.Debug("Fetching org plan policy", "organisation", organisation, "url", u.String())
-
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return "", nil, err
-	}
-	req.Header.Add("Authorization", "Bearer "+p.AuthToken)
-
-	resp, err := p.HttpClient.Do(req)
-	if err != nil {
-		return "", nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", resp, nil
-	}
-	return string(body), resp, nil
-}
-
-func getDriftPolicyForOrganisation(p *DiggerHttpPolicyProvider) (string, *http.Response, error) {
-	organisation := p.DiggerOrganisation
-	u, err := url.Parse(p.DiggerHost)
-	if err != nil {
-		slog.Error("Failed to parse digger cloud URL", "url", p.DiggerHost, "error", err)
-		return "", nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
-	}
-	u.Path = "/orgs/" + organisation + "/drift-policy"
-
-	slog.Debug("Fetching org drift policy", "organisation", organisation, "url", u.String())
-
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return "", nil, err
-	}
-	req.Header.Add("Authorization", "Bearer "+p.AuthToken)
-
-	resp, err := p.HttpClient.Do(req)
-	if err != nil {
-		return "", nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+// GetAccessPolicy returns the most specific access policy set for the project, or the default policy
+func (p DiggerHttpPolicyProvider) GetAccessPolicy(organisation string, repo string, projectName string, projectDir string) (string, error) {
+	layers, err := p.GetAccessPolicyLayers(organisation, repo, projectName, projectDir)
 	if err != nil {
-		return "", resp, nil
+		return "", err
 	}
-	return string(body), resp, nil
+	return layers[len(layers)-1].Policy, nil
 }
 
-func getAccessPolicyForNamespace(p *DiggerHttpPolicyProvider, namespace string, projectName string) (string, *http.Response, error) {
-	// fetch RBAC policies for project from Digger API
-	u, err := url.Parse(p.DiggerHost)
-	if err != nil {
-		slog.Error("Failed to parse digger cloud URL", "url", p.DiggerHost, "error", err)
-		return "", nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
-	}
-	u.Path = "/repos/" + namespace + "/projects/" + projectName + "/access-policy"
-
-	slog.Debug("Fetching namespace access policy",
-		"namespace", namespace,
-		"projectName", projectName,
-		"url", u.String())
-
-	req, err := http.NewRequest("GET", u.String(), nil)
-
-	if err != nil {
-		return "", nil, err
-	}
-	req.Header.Add("Authorization", "Bearer "+p.AuthToken)
-
-	resp, err := p.HttpClient.Do(req)
-	if err != nil {
-		return "", resp, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", resp, nil
+// GetPlanPolicy returns the most specific plan policy set for the project, empty if there is none
+func (p DiggerHttpPolicyProvider) GetPlanPolicy(organisation string, repo string, projectName string, projectDir string) (string, error) {
+	layers, err := p.GetPlanPolicyLayers(organisation, repo, projectName, projectDir)
+	if err != nil || len(layers) == 0 {
+		return "", err
 	}
-	return string(body), resp, nil
+	return layers[len(layers)-1].Policy, nil
 }
 
-func getPlanPolicyForNamespace(p *DiggerHttpPolicyProvider, namespace string, projectName string) (string, *http.Response, error) {
+// fetchPolicy fetches a policy from the backend. A body that can't be read is an error rather
+// than an empty policy, so that a failed fetch never drops a layer.
+func fetchPolicy(p *DiggerHttpPolicyProvider, policyPath string) (string, *http.Response, error) {
 	u, err := url.Parse(p.DiggerHost)
 	if err != nil {
 		slog.Error("Failed to parse digger cloud URL", "url", p.DiggerHost, "error", err)
 		return "", nil, fmt.Errorf("not able to parse digger cloud url: %v", err)
 	}
-	u.Path = "/repos/" + namespace + "/projects/" + projectName + "/plan-policy"
-
-	slog.Debug("Fetching namespace plan policy",
-		"namespace", namespace,
-		"projectName", projectName,
-		"url", u.String())
+	u.Path = policyPath
 
 	req, err := http.NewRequest("GET", u.String(), nil)
-
 	if err != nil {
 		return "", nil, err
 	}
@@ -195,126 +86,69 @@ func getPlanPolicyForNamespace(p *DiggerHttpPolicyProvider, namespace string, pr
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", resp, nil
+		return "", resp, fmt.Errorf("error reading policy response: %v", err)
 	}
 	return string(body), resp, nil
 }
 
-// GetPolicy fetches policy for particular project,  if not found then it will fallback to org level policy
-func (p DiggerHttpPolicyProvider) GetAccessPolicy(organisation string, repo string, projectName string, projectDir string) (string, error) {
+// getPolicyLayers fetches the org, repo and project policies of a type, skipping layers that are not set
+func (p DiggerHttpPolicyProvider) getPolicyLayers(policyType string, organisation string, repo string, projectName string) ([]PolicyLayer, error) {
 	namespace := fmt.Sprintf("%v-%v", organisation, repo)
-
-	slog.Debug("Getting access policy",
-		"organisation", organisation,
-		"repo", repo,
-		"projectName", projectName,
-		"projectDir", projectDir)
-
-	content, resp, err := getAccessPolicyForNamespace(&p, namespace, projectName)
-	if err != nil {
-		slog.Error("Failed to fetch access policy for namespace",
-			"namespace", namespace,
-			"error", err)
-		return "", fmt.Errorf("error while fetching access policy for namespace: %v", err)
-	}
-
-	// project policy found
-	if resp.StatusCode == 200 && content != "" {
-		slog.Debug("Found project access policy", "namespace", namespace, "projectName", projectName)
-		return content, nil
-	}
-
-	// check if project policy was empty or not found (retrieve org policy if so)
-	if (resp.StatusCode == 200 && content == "") || resp.StatusCode == 404 {
-		slog.Debug("Project access policy not found, falling back to org policy",
-			"organisation", organisation)
-
-		content, resp, err := getAccessPolicyForOrganisation(&p)
+	scopes := []struct {
+		layer string
+		path  string
+	}{
+		{OrgPolicyLayer, "/orgs/" + p.DiggerOrganisation + "/" + policyType + "-policy"},
+		{RepoPolicyLayer, "/repos/" + namespace + "/" + policyType + "-policy"},
+		{ProjectPolicyLayer, "/repos/" + namespace + "/projects/" + projectName + "/" + policyType + "-policy"},
+	}
+
+	var layers []PolicyLayer
+	for _, scope := range scopes {
+		slog.Debug("Fetching policy layer", "layer", scope.layer, "policyType", policyType, "path", scope.path)
+		content, resp, err := fetchPolicy(&p, scope.path)
 		if err != nil {
-			slog.Error("Failed to fetch access policy for organisation",
-				"organisation", organisation,
-				"error", err)
-			return "", fmt.Errorf("error while fetching access policy for organisation: %v", err)
+			slog.Error("Failed to fetch policy layer", "layer", scope.layer, "policyType", policyType, "error", err)
+			return nil, fmt.Errorf("error while fetching %v %v policy: %v", scope.layer, policyType, err)
 		}
-		if resp.StatusCode == 200 {
-			slog.Debug("Found organisation access policy", "organisation", organisation)
-			return content, nil
-		} else if resp.StatusCode == 404 {
-			slog.Debug("Organisation access policy not found, using default", "organisation", organisation)
-			return DefaultAccessPolicy, nil
-		} else {
-			slog.Error("Unexpected response for organisation policy",
+		switch {
+		case resp.StatusCode == 200 && content != "":
+			layers = append(layers, PolicyLayer{Name: scope.layer, Policy: content})
+		case resp.StatusCode == 200 || resp.StatusCode == 404:
+			slog.Debug("Policy layer not set", "layer", scope.layer, "policyType", policyType)
+		default:
+			slog.Error("Unexpected response for policy layer",
+				"layer", scope.layer,
 				"statusCode", resp.StatusCode,
 				"response", content)
-			return "", errors.New(fmt.Sprintf("unexpected response while fetching organisation policy: %v, code %v", content, resp.StatusCode))
+			return nil, fmt.Errorf("unexpected response while fetching %v %v policy: %v code %v", scope.layer, policyType, content, resp.StatusCode)
 		}
-	} else {
-		slog.Error("Unexpected response for project policy",
-			"statusCode", resp.StatusCode,
-			"response", content)
-		return "", errors.New(fmt.Sprintf("unexpected response while fetching project policy: %v code %v", content, resp.StatusCode))
 	}
+	return layers, nil
 }
 
-func (p DiggerHttpPolicyProvider) GetPlanPolicy(organisation string, repo string, projectName string, projectDir string) (string, error) {
-	namespace := fmt.Sprintf("%v-%v", organisation, repo)
-
-	slog.Debug("Getting plan policy",
-		"organisation", organisation,
-		"repo", repo,
-		"projectName", projectName,
-		"projectDir", projectDir)
-
-	content, resp, err := getPlanPolicyForNamespace(&p, namespace, projectName)
+// GetAccessPolicyLayers returns every access policy set for the project, or the
+// default policy if there are none
+func (p DiggerHttpPolicyProvider) GetAccessPolicyLayers(organisation string, repo string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	layers, err := p.getPolicyLayers("access", organisation, repo, projectName)
 	if err != nil {
-		slog.Error("Failed to fetch plan policy for namespace",
-			"namespace", namespace,
-			"error", err)
-		return "", err
+		return nil, err
 	}
-
-	// project policy found
-	if resp.StatusCode == 200 && content != "" {
-		slog.Debug("Found project plan policy", "namespace", namespace, "projectName", projectName)
-		return content, nil
+	if len(layers) == 0 {
+		slog.Debug("No access policy layers found, using default", "organisation", organisation)
+		return []PolicyLayer{{Name: DefaultPolicyLayer, Policy: DefaultAccessPolicy}}, nil
 	}
+	return layers, nil
+}
 
-	// check if project policy was empty or not found (retrieve org policy if so)
-	if (resp.StatusCode == 200 && content == "") || resp.StatusCode == 404 {
-		slog.Debug("Project plan policy not found, falling back to org policy",
-			"organisation", organisation)
-
-		content, resp, err := getPlanPolicyForOrganisation(&p)
-		if err != nil {
-			slog.Error("Failed to fetch plan policy for organisation",
-				"organisation", organisation,
-				"error", err)
-			return "", err
-		}
-		if resp.StatusCode == 200 {
-			slog.Debug("Found organisation plan policy", "organisation", organisation)
-			return content, nil
-		} else if resp.StatusCode == 404 {
-			slog.Debug("Organisation plan policy not found", "organisation", organisation)
-			return "", nil
-		} else {
-			slog.Error("Unexpected response for organisation policy",
-				"statusCode", resp.StatusCode,
-				"response", content)
-			return "", errors.New(fmt.Sprintf("unexpected response while fetching organisation policy: %v, code %v", content, resp.StatusCode))
-		}
-	} else {
-		slog.Error("Unexpected response for project policy",
-			"statusCode", resp.StatusCode,
-			"response", content)
-		return "", errors.New(fmt.Sprintf("unexpected response while fetching project policy: %v code %v", content, resp.StatusCode))
-	}
+func (p DiggerHttpPolicyProvider) GetPlanPolicyLayers(organisation string, repo string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	return p.getPolicyLayers("plan", organisation, repo, projectName)
 }
 
 func (p DiggerHttpPolicyProvider) GetDriftPolicy() (string, error) {
 	slog.Debug("Getting drift policy", "organisation", p.DiggerOrganisation)
 
-	content, resp, err := getDriftPolicyForOrganisation(&p)
+	content, resp, err := fetchPolicy(&p, "/orgs/"+p.DiggerOrganisation+"/drift-policy")
 	if err != nil {
 		slog.Error("Failed to fetch drift policy",
 			"organisation", p.DiggerOrganisation,
@@ -341,6 +175,20 @@ func (p DiggerHttpPolicyProvider) GetOrganisation() string {
 
 type DiggerPolicyChecker struct {
 	PolicyProvider Provider
+	// Bundles are loaded alongside every policy so it can use their rego and data
+	Bundles []PolicyBundle
+}
+
+// prepareQuery compiles a policy together with the checker's bundles
+func (p DiggerPolicyChecker) prepareQuery(ctx context.Context, query string, policy string) (rego.PreparedEvalQuery, error) {
+	options := []func(*rego.Rego){
+		rego.Query(query),
+		rego.Module("digger", policy),
+	}
+	for _, b := range p.Bundles {
+		options = append(options, rego.ParsedBundle(b.Source, b.Bundle))
+	}
+	return rego.New(options...).PrepareForEval(ctx)
 }
 
 // TODO refactor to use AccessPolicyContext - too many arguments
@@ -352,7 +200,7 @@ func (p DiggerPolicyChecker) CheckAccessPolicy(ciService ci.OrgService, prServic
 		"command", command,
 		"requestedBy", requestedBy)
 
-	policy, err := p.PolicyProvider.GetAccessPolicy(SCMOrganisation, SCMrepository, projectName, projectDir)
+	layers, err := p.getAccessPolicyLayers(SCMOrganisation, SCMrepository, projectName, projectDir)
 
 	if err != nil {
 		slog.Error("Error fetching policy", "error", err)
@@ -390,20 +238,78 @@ func (p DiggerPolicyChecker) CheckAccessPolicy(ciService ci.OrgService, prServic
 		"project":              projectName,
 	}
 
-	if policy == "" {
+	if len(layers) == 0 {
 		slog.Debug("No access policy found, allowing action")
 		return true, nil
 	}
 
 	ctx := context.Background()
-	slog.Debug("Evaluating access policy",
-		"input", input,
-		"policy", policy)
+	// deny-overrides: every layer has to allow the action
+	for _, layer := range layers {
+		slog.Debug("Evaluating access policy",
+			"layer", layer.Name,
+			"input", input,
+			"policy", layer.Policy)
+
+		allowed, err := p.evalAccessPolicy(ctx, layer.Policy, input)
+		if err != nil {
+			return false, policyLayerError(layer, err)
+		}
+		if !allowed {
+			slog.Info("Access policy denied action",
+				"user", requestedBy,
+				"action", command,
+				"project", projectName,
+				"layer", layer.Name)
+			if layer.Name == "" {
+				return false, nil
+			}
+			return false, &AccessDeniedError{Layer: layer.Name}
+		}
+	}
 
-	query, err := rego.New(
-		rego.Query("data.digger.allow"),
-		rego.Module("digger", policy),
-	).PrepareForEval(ctx)
+	slog.Info("Access policy allowed action",
+		"user", requestedBy,
+		"action", command,
+		"project", projectName,
+		"layers", len(layers))
+	return true, nil
+}
+
+// getAccessPolicyLayers returns every access policy layer when the provider is
+// layered, or the single policy it resolves to otherwise
+func (p DiggerPolicyChecker) getAccessPolicyLayers(organisation string, repository string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	if layered, ok := p.PolicyProvider.(LayeredProvider); ok {
+		return layered.GetAccessPolicyLayers(organisation, repository, projectName, projectDir)
+	}
+	policy, err := p.PolicyProvider.GetAccessPolicy(organisation, repository, projectName, projectDir)
+	if err != nil || policy == "" {
+		return nil, err
+	}
+	return []PolicyLayer{{Policy: policy}}, nil
+}
+
+func (p DiggerPolicyChecker) getPlanPolicyLayers(organisation string, repository string, projectName string, projectDir string) ([]PolicyLayer, error) {
+	if layered, ok := p.PolicyProvider.(LayeredProvider); ok {
+		return layered.GetPlanPolicyLayers(organisation, repository, projectName, projectDir)
+	}
+	policy, err := p.PolicyProvider.GetPlanPolicy(organisation, repository, projectName, projectDir)
+	if err != nil || policy == "" {
+		return nil, err
+	}
+	return []PolicyLayer{{Policy: policy}}, nil
+}
+
+// policyLayerError names the layer that failed to evaluate, if it has a name
+func policyLayerError(layer PolicyLayer, err error) error {
+	if layer.Name == "" {
+		return err
+	}
+	return fmt.Errorf("%v policy: %w", layer.Name, err)
+}
+
+func (p DiggerPolicyChecker) evalAccessPolicy(ctx context.Context, policy string, input map[string]interface{}) (bool, error) {
+	query, err := p.prepareQuery(ctx, "data.digger.allow", policy)
 
 	if err != nil {
 		slog.Error("Failed to prepare policy evaluation", "error", err)
@@ -411,117 +317,173 @@ func (p DiggerPolicyChecker) CheckAccessPolicy(ciService ci.OrgService, prServic
 	}
 
 	results, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		slog.Error("Failed to evaluate policy", "error", err)
+		return false, err
+	}
 	if len(results) == 0 || len(results[0].Expressions) == 0 {
 		slog.Error("No result found from policy evaluation")
 		return false, fmt.Errorf("no result found")
 	}
 
-	expressions := results[0].Expressions
-
-	for _, expression := range expressions {
+	for _, expression := range results[0].Expressions {
 		decision, ok := expression.Value.(bool)
 		if !ok {
 			slog.Error("Policy decision is not a boolean")
 			return false, fmt.Errorf("decision is not a boolean")
 		}
 		if !decision {
-			slog.Info("Access policy denied action",
-				"user", requestedBy,
-				"action", command,
-				"project", projectName)
 			return false, nil
 		}
 	}
-
-	slog.Info("Access policy allowed action",
-		"user", requestedBy,
-		"action", command,
-		"project", projectName)
 	return true, nil
 }
 
-func (p DiggerPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string) (bool, []string, error) {
+func (p DiggerPolicyChecker) CheckPlanPolicy(SCMrepository string, SCMOrganisation string, projectname string, projectDir string, planOutput string, policyContext PolicyContext) (bool, PlanPolicyResult, error) {
 	slog.Debug("Checking plan policy",
 		"organisation", SCMOrganisation,
 		"repository", SCMrepository,
 		"project", projectname)
 
-	policy, err := p.PolicyProvider.GetPlanPolicy(SCMOrganisation, SCMrepository, projectname, projectDir)
+	var result PlanPolicyResult
+	layers, err := p.getPlanPolicyLayers(SCMOrganisation, SCMrepository, projectname, projectDir)
 	if err != nil {
 		slog.Error("Failed to get plan policy", "error", err)
-		return false, nil, fmt.Errorf("failed get plan policy: %v", err)
+		return false, result, fmt.Errorf("failed get plan policy: %v", err)
 	}
 	var parsedPlanOutput map[string]interface{}
 
 	err = json.Unmarshal([]byte(planOutput), &parsedPlanOutput)
 	if err != nil {
 		slog.Error("Failed to parse terraform plan output", "error", err)
-		return false, nil, fmt.Errorf("failed to parse json terraform output to map: %v", err)
-	}
-
-	input := map[string]interface{}{
-		"terraform": parsedPlanOutput,
+		return false, result, fmt.Errorf("failed to parse json terraform output to map: %v", err)
 	}
 
-	if policy == "" {
+	if len(layers) == 0 {
 		slog.Info("No plan policies found, succeeding")
-		return true, nil, nil
+		return true, result, nil
 	}
 
-	ctx := context.Background()
-	slog.Debug("Evaluating plan policy", "policy", policy)
-
-	query, err := rego.New(
-		rego.Query("data.digger.deny"),
-		rego.Module("digger", policy),
-	).PrepareForEval(ctx)
-
+	contextInput, err := policyContext.toInput()
 	if err != nil {
-		slog.Error("Failed to prepare plan policy evaluation", "error", err)
-		return false, nil, err
+		return false, result, err
 	}
-
-	results, err := query.Eval(ctx, rego.EvalInput(input))
-	if len(results) == 0 || len(results[0].Expressions) == 0 {
-		slog.Error("No result found from plan policy evaluation")
-		return false, nil, fmt.Errorf("no result found")
+	input := map[string]interface{}{
+		"terraform": parsedPlanOutput,
+		"context":   contextInput,
 	}
 
-	expressions := results[0].Expressions
-
-	decisionsResult := make([]string, 0)
-	for _, expression := range expressions {
-		decisions, ok := expression.Value.([]interface{})
-
-		if !ok {
-			slog.Error("Plan policy decision is not a slice of interfaces")
-			return false, nil, fmt.Errorf("decision is not a slice of interfaces")
-		}
-		if len(decisions) > 0 {
-			for _, d := range decisions {
-				decisionsResult = append(decisionsResult, d.(string))
-				slog.Info("Plan policy violation", "reason", d)
+	ctx := context.Background()
+	result = PlanPolicyResult{Denials: []string{}, SoftDenials: []string{}, Warnings: []string{}}
+	// messages from every layer are combined so no layer can drop another's violations
+	for _, layer := range layers {
+		slog.Debug("Evaluating plan policy", "layer", layer.Name, "policy", layer.Policy)
+
+		layerResult := PlanPolicyLayerResult{Layer: layer.Name}
+		anyDefined := false
+		for _, rule := range []struct {
+			query    string
+			messages *[]string
+		}{
+			{"data.digger.deny", &layerResult.Denials},
+			{"data.digger.soft_deny", &layerResult.SoftDenials},
+			{"data.digger.warn", &layerResult.Warnings},
+		} {
+			messages, defined, err := p.evalPlanPolicyRule(ctx, layer.Policy, rule.query, input)
+			if err != nil {
+				return false, result, policyLayerError(layer, err)
 			}
+			anyDefined = anyDefined || defined
+			*rule.messages = messages
+		}
+		if !anyDefined {
+			slog.Error("No result found from plan policy evaluation", "layer", layer.Name)
+			return false, result, policyLayerError(layer, fmt.Errorf("no result found"))
+		}
+
+		result.Denials = appendUnique(result.Denials, layerResult.Denials...)
+		result.SoftDenials = appendUnique(result.SoftDenials, layerResult.SoftDenials...)
+		result.Warnings = appendUnique(result.Warnings, layerResult.Warnings...)
+		if layer.Name != "" {
+			result.Layers = append(result.Layers, layerResult)
 		}
 	}
 
-	if len(decisionsResult) > 0 {
+	for _, warning := range result.Warnings {
+		slog.Info("Plan policy warning", "reason", warning)
+	}
+	for _, softDenial := range result.SoftDenials {
+		slog.Info("Plan policy soft violation", "reason", softDenial)
+	}
+	for _, denial := range result.Denials {
+		slog.Info("Plan policy violation", "reason", denial)
+	}
+
+	if len(result.Denials) > 0 || len(result.SoftDenials) > 0 {
 		slog.Info("Plan policy check failed",
-			"violations", len(decisionsResult),
+			"violations", len(result.Denials),
+			"softViolations", len(result.SoftDenials),
 			"organisation", SCMOrganisation,
 			"repository", SCMrepository,
 			"project", projectname)
-		return false, decisionsResult, nil
+		return false, result, nil
 	}
 
 	slog.Info("Plan policy check passed",
+		"warnings", len(result.Warnings),
 		"organisation", SCMOrganisation,
 		"repository", SCMrepository,
 		"project", projectname)
-	return true, []string{}, nil
+	return true, result, nil
+}
+
+func appendUnique(values []string, additions ...string) []string {
+	for _, addition := range additions {
+		if !slices.Contains(values, addition) {
+			values = append(values, addition)
+		}
+	}
+	return values
+}
+
+// evalPlanPolicyRule evaluates a set rule of the plan policy and returns its
+// messages. defined is false when the policy does not declare the rule.
+func (p DiggerPolicyChecker) evalPlanPolicyRule(ctx context.Context, policy string, queryString string, input map[string]interface{}) (messages []string, defined bool, err error) {
+	query, err := p.prepareQuery(ctx, queryString, policy)
+
+	if err != nil {
+		slog.Error("Failed to prepare plan policy evaluation", "query", queryString, "error", err)
+		return nil, false, err
+	}
+
+	results, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		slog.Error("Failed to evaluate plan policy", "query", queryString, "error", err)
+		return nil, false, err
+	}
+	messages = make([]string, 0)
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return messages, false, nil
+	}
+
+	for _, expression := range results[0].Expressions {
+		decisions, ok := expression.Value.([]interface{})
+		if !ok {
+			slog.Error("Plan policy decision is not a slice of interfaces", "query", queryString)
+			return nil, true, fmt.Errorf("decision is not a slice of interfaces")
+		}
+		for _, d := range decisions {
+			message, ok := d.(string)
+			if !ok {
+				message = fmt.Sprintf("%v", d)
+			}
+			messages = append(messages, message)
+		}
+	}
+	return messages, true, nil
 }
 
-func (p DiggerPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectName string) (bool, error) {
+func (p DiggerPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMrepository string, projectName string, policyContext PolicyContext) (bool, error) {
 	slog.Debug("Checking drift policy",
 		"organisation", SCMOrganisation,
 		"repository", SCMrepository,
@@ -535,25 +497,27 @@ func (p DiggerPolicyChecker) CheckDriftPolicy(SCMOrganisation string, SCMreposit
 		return false, err
 	}
 
-	input := map[string]interface{}{
-		"organisation": SCMOrganisation,
-		"project":      projectName,
-	}
-
 	if policy == "" {
 		slog.Debug("No drift policy found, allowing drift detection")
 		return true, nil
 	}
 
+	contextInput, err := policyContext.toInput()
+	if err != nil {
+		return false, err
+	}
+	input := map[string]interface{}{
+		"organisation": SCMOrganisation,
+		"project":      projectName,
+		"context":      contextInput,
+	}
+
 	ctx := context.Background()
 	slog.Debug("Evaluating drift policy",
 		"input", input,
 		"policy", policy)
 
-	query, err := rego.New(
-		rego.Query("data.digger.enable"),
-		rego.Module("digger", policy),
-	).PrepareForEval(ctx)
+	query, err := p.prepareQuery(ctx, "data.digger.enable", policy)
 
 	if err != nil {
 		slog.Error("Failed to prepare drift policy evaluation", "error", err)
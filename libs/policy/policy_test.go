@@ -2,6 +2,7 @@ package policy
 
 import (
 	"github.com/diggerhq/digger/libs/ci"
+	"reflect"
 	"testing"
 )
 
@@ -273,7 +274,7 @@ func TestDiggerPlanPolicyChecker_Check(t *testing.T) {
 			var p = &DiggerPolicyChecker{
 				PolicyProvider: tt.fields.PolicyProvider,
 			}
-			got, _, err := p.CheckPlanPolicy("", "", "", "", tt.planJsonOutput)
+			got, _, err := p.CheckPlanPolicy("", "", "", "", tt.planJsonOutput, PolicyContext{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DiggerPolicyChecker.CheckPlanPolicy() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -284,3 +285,147 @@ func TestDiggerPlanPolicyChecker_Check(t *testing.T) {
 		})
 	}
 }
+
+type enforcementLevelsPolicyProvider struct {
+	DiggerDefaultPolicyProvider
+	planPolicy string
+}
+
+func (s *enforcementLevelsPolicyProvider) GetPlanPolicy(organisation string, repository string, projectname string, projectDir string) (string, error) {
+	return s.planPolicy, nil
+}
+
+func TestDiggerPlanPolicyChecker_EnforcementLevels(t *testing.T) {
+	planJson := `{"resource_changes":[{"type":"aws_instance","change":{"actions":["delete"]}}]}`
+	tests := []struct {
+		name   string
+		policy string
+		want   bool
+		result PlanPolicyResult
+	}{
+		{
+			name: "warn does not block",
+			policy: "package digger\n" +
+				"warn[msg] { input.terraform.resource_changes[_].type == \"aws_instance\"; msg := \"instance changes\" }\n",
+			want:   true,
+			result: PlanPolicyResult{Denials: []string{}, SoftDenials: []string{}, Warnings: []string{"instance changes"}},
+		},
+		{
+			name: "soft deny blocks",
+			policy: "package digger\n" +
+				"soft_deny[msg] { input.terraform.resource_changes[_].change.actions[_] == \"delete\"; msg := \"deletes resources\" }\n" +
+				"deny[msg] { false; msg := \"never\" }\n",
+			want:   false,
+			result: PlanPolicyResult{Denials: []string{}, SoftDenials: []string{"deletes resources"}, Warnings: []string{}},
+		},
+		{
+			name: "deny blocks",
+			policy: "package digger\n" +
+				"deny[msg] { input.terraform.resource_changes[_].type == \"aws_instance\"; msg := \"no instances\" }\n" +
+				"warn[msg] { false; msg := \"never\" }\n",
+			want:   false,
+			result: PlanPolicyResult{Denials: []string{"no instances"}, SoftDenials: []string{}, Warnings: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DiggerPolicyChecker{PolicyProvider: &enforcementLevelsPolicyProvider{planPolicy: tt.policy}}
+			got, result, err := p.CheckPlanPolicy("", "", "", "", planJson, PolicyContext{})
+			if err != nil {
+				t.Fatalf("CheckPlanPolicy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckPlanPolicy() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(result, tt.result) {
+				t.Errorf("CheckPlanPolicy() result = %+v, want %+v", result, tt.result)
+			}
+		})
+	}
+}
+
+func TestDiggerPlanPolicyChecker_Context(t *testing.T) {
+	policy := "package digger\n" +
+		"deny[msg] { input.context.version == 1; input.context.pull_request.labels[_] == \"freeze\"; msg := sprintf(\"%v is frozen\", [input.context.project.name]) }\n" +
+		"deny[msg] { input.context.pull_request.target_branch == \"main\"; count(input.context.pull_request.approvals) == 0; msg := \"main needs an approval\" }\n"
+	p := DiggerPolicyChecker{PolicyProvider: &enforcementLevelsPolicyProvider{planPolicy: policy}}
+
+	tests := []struct {
+		name    string
+		context PolicyContext
+		want    bool
+		denials []string
+	}{
+		{
+			name:    "no pull request",
+			context: PolicyContext{Project: Project{Name: "prod"}, User: "alice"},
+			want:    true,
+			denials: []string{},
+		},
+		{
+			name: "frozen label",
+			context: PolicyContext{
+				Project:     Project{Name: "prod"},
+				PullRequest: &PullRequest{Number: 1, Labels: []string{"freeze"}, TargetBranch: "develop"},
+			},
+			want:    false,
+			denials: []string{"prod is frozen"},
+		},
+		{
+			name: "unapproved main",
+			context: PolicyContext{
+				Project:     Project{Name: "prod"},
+				PullRequest: &PullRequest{Number: 1, TargetBranch: "main"},
+			},
+			want:    false,
+			denials: []string{"main needs an approval"},
+		},
+		{
+			name: "approved main",
+			context: PolicyContext{
+				Project:     Project{Name: "prod"},
+				PullRequest: &PullRequest{Number: 1, TargetBranch: "main", Approvals: []string{"bob"}},
+			},
+			want:    true,
+			denials: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, result, err := p.CheckPlanPolicy("", "", "prod", "", "{}", tt.context)
+			if err != nil {
+				t.Fatalf("CheckPlanPolicy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckPlanPolicy() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(result.Denials, tt.denials) {
+				t.Errorf("CheckPlanPolicy() denials = %v, want %v", result.Denials, tt.denials)
+			}
+		})
+	}
+}
+
+func TestDiggerPlanPolicyChecker_LoadsPullRequestOnlyForPolicies(t *testing.T) {
+	loads := 0
+	policyContext := PolicyContext{Project: Project{Name: "prod"}}.WithPullRequestLoader(func() *PullRequest {
+		loads++
+		return &PullRequest{Number: 1, Labels: []string{"freeze"}}
+	})
+
+	p := DiggerPolicyChecker{PolicyProvider: &enforcementLevelsPolicyProvider{}}
+	allowed, _, err := p.CheckPlanPolicy("", "", "prod", "", "{}", policyContext)
+	if err != nil || !allowed || loads != 0 {
+		t.Fatalf("without a policy: allowed = %v, err = %v, pull request loaded %d times", allowed, err, loads)
+	}
+
+	policy := "package digger\n" +
+		"deny[msg] { input.context.pull_request.labels[_] == \"freeze\"; msg := \"frozen\" }\n"
+	p = DiggerPolicyChecker{PolicyProvider: &enforcementLevelsPolicyProvider{planPolicy: policy}}
+	allowed, _, err = p.CheckPlanPolicy("", "", "prod", "", "{}", policyContext)
+	if err != nil || allowed || loads != 1 {
+		t.Fatalf("with a policy: allowed = %v, err = %v, pull request loaded %d times", allowed, err, loads)
+	}
+}
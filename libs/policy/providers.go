@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -18,13 +19,19 @@ func (p PolicyCheckerProviderBasic) Get(hostname string, organisationName string
 			"hostname", hostname,
 			"organisation", organisationName)
 
+		bundles, err := LoadPolicyBundlesFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy bundles: %v", err)
+		}
 		policyChecker = DiggerPolicyChecker{
 			PolicyProvider: &DiggerHttpPolicyProvider{
 				DiggerHost:         hostname,
 				DiggerOrganisation: organisationName,
 				AuthToken:          authToken,
 				HttpClient:         http.DefaultClient,
-			}}
+			},
+			Bundles: bundles,
+		}
 	}
 	return policyChecker, nil
 }
@@ -0,0 +1,221 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diggerhq/digger/libs/ci"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AccessPolicyType = "access"
+	PlanPolicyType   = "plan"
+	DriftPolicyType  = "drift"
+)
+
+// PolicyTestSuite is a set of policy test cases loaded from a YAML file
+type PolicyTestSuite struct {
+	Name         string           `yaml:"name"`
+	Organisation string           `yaml:"organisation"`
+	Repository   string           `yaml:"repository"`
+	Tests        []PolicyTestCase `yaml:"tests"`
+	// dir is the directory plan fixtures are resolved against
+	dir string
+}
+
+// PolicyTestCase evaluates one policy against a fixture input
+type PolicyTestCase struct {
+	Name string `yaml:"name"`
+	// Policy is one of access, plan or drift
+	Policy  string  `yaml:"policy"`
+	Project Project `yaml:"project"`
+	// Plan is the path to a `terraform show -json` fixture, relative to the suite file
+	Plan    string             `yaml:"plan"`
+	Access  AccessPolicyInput  `yaml:"access"`
+	Context PolicyContext      `yaml:"context"`
+	Expect  PolicyExpectations `yaml:"expect"`
+}
+
+// AccessPolicyInput is the fixture passed to access policies
+type AccessPolicyInput struct {
+	User                 string   `yaml:"user"`
+	Action               string   `yaml:"action"`
+	Teams                []string `yaml:"teams"`
+	Approvals            []string `yaml:"approvals"`
+	PlanPolicyViolations []string `yaml:"plan_policy_violations"`
+}
+
+// PolicyExpectations are asserted against the policy decision. Fields that are
+// left out are not checked.
+type PolicyExpectations struct {
+	Allow       *bool     `yaml:"allow"`
+	Denials     *[]string `yaml:"denials"`
+	SoftDenials *[]string `yaml:"soft_denials"`
+	Warnings    *[]string `yaml:"warnings"`
+}
+
+// PolicyTestResult is the outcome of a single test case. Failures are unmet
+// expectations while Err is set when the policy could not be evaluated at all.
+type PolicyTestResult struct {
+	Name     string
+	Policy   string
+	Duration time.Duration
+	Failures []string
+	Err      error
+}
+
+func (r PolicyTestResult) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// PolicyTestSuiteResult holds the results of every test case in a suite
+type PolicyTestSuiteResult struct {
+	Name    string
+	Results []PolicyTestResult
+}
+
+func (r PolicyTestSuiteResult) Counts() (failures int, errors int) {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errors++
+		} else if len(result.Failures) > 0 {
+			failures++
+		}
+	}
+	return failures, errors
+}
+
+// LoadPolicyTestSuite reads a test suite file. The suite is named after the
+// file unless it sets a name.
+func LoadPolicyTestSuite(path string) (*PolicyTestSuite, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy test suite %v: %v", path, err)
+	}
+	var suite PolicyTestSuite
+	if err := yaml.Unmarshal(contents, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse policy test suite %v: %v", path, err)
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	suite.dir = filepath.Dir(path)
+	for i, test := range suite.Tests {
+		if test.Name == "" {
+			suite.Tests[i].Name = fmt.Sprintf("%v #%v", test.Policy, i+1)
+		}
+	}
+	return &suite, nil
+}
+
+// RunPolicyTestSuite evaluates every test case in the suite with the checker
+func RunPolicyTestSuite(checker Checker, suite PolicyTestSuite) PolicyTestSuiteResult {
+	suiteResult := PolicyTestSuiteResult{Name: suite.Name}
+	for _, test := range suite.Tests {
+		start := time.Now()
+		failures, err := runPolicyTest(checker, suite, test)
+		suiteResult.Results = append(suiteResult.Results, PolicyTestResult{
+			Name:     test.Name,
+			Policy:   test.Policy,
+			Duration: time.Since(start),
+			Failures: failures,
+			Err:      err,
+		})
+	}
+	return suiteResult
+}
+
+func runPolicyTest(checker Checker, suite PolicyTestSuite, test PolicyTestCase) ([]string, error) {
+	policyContext := test.Context
+	if policyContext.Organisation == "" {
+		policyContext.Organisation = suite.Organisation
+	}
+	if policyContext.Repository == "" {
+		policyContext.Repository = suite.Repository
+	}
+	if policyContext.Project == (Project{}) {
+		policyContext.Project = test.Project
+	}
+
+	switch test.Policy {
+	case AccessPolicyType:
+		// the mock serves the fixture teams and approvals to the checker
+		fixture := ci.MockPullRequestManager{Teams: test.Access.Teams, Approvals: test.Access.Approvals}
+		var prService ci.PullRequestService = fixture
+		prNumber := 0
+		if policyContext.PullRequest != nil {
+			prNumber = policyContext.PullRequest.Number
+		}
+		violations := test.Access.PlanPolicyViolations
+		if violations == nil {
+			violations = []string{}
+		}
+		allowed, err := checker.CheckAccessPolicy(fixture, &prService, suite.Organisation, suite.Repository, test.Project.Name, test.Project.Dir, test.Access.Action, &prNumber, test.Access.User, violations)
+		if err != nil && !IsAccessDenied(err) {
+			return nil, err
+		}
+		return checkPolicyExpectations(test.Expect, allowed, nil), nil
+	case PlanPolicyType:
+		if test.Plan == "" {
+			return nil, fmt.Errorf("plan test case has no plan fixture")
+		}
+		planPath := test.Plan
+		if !filepath.IsAbs(planPath) {
+			planPath = filepath.Join(suite.dir, planPath)
+		}
+		plan, err := os.ReadFile(planPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plan fixture: %v", err)
+		}
+		allowed, result, err := checker.CheckPlanPolicy(suite.Repository, suite.Organisation, test.Project.Name, test.Project.Dir, string(plan), policyContext)
+		if err != nil {
+			return nil, err
+		}
+		return checkPolicyExpectations(test.Expect, allowed, &result), nil
+	case DriftPolicyType:
+		enabled, err := checker.CheckDriftPolicy(suite.Organisation, suite.Repository, test.Project.Name, policyContext)
+		if err != nil {
+			return nil, err
+		}
+		return checkPolicyExpectations(test.Expect, enabled, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown policy type %q, expected one of %v, %v or %v", test.Policy, AccessPolicyType, PlanPolicyType, DriftPolicyType)
+	}
+}
+
+func checkPolicyExpectations(expect PolicyExpectations, allowed bool, result *PlanPolicyResult) []string {
+	var failures []string
+	if expect.Allow != nil && *expect.Allow != allowed {
+		failures = append(failures, fmt.Sprintf("expected allow to be %v but was %v", *expect.Allow, allowed))
+	}
+	if result == nil {
+		if expect.Denials != nil || expect.SoftDenials != nil || expect.Warnings != nil {
+			failures = append(failures, "messages can only be asserted for plan policies")
+		}
+		return failures
+	}
+	failures = append(failures, checkPolicyMessages("denials", expect.Denials, result.Denials)...)
+	failures = append(failures, checkPolicyMessages("soft_denials", expect.SoftDenials, result.SoftDenials)...)
+	failures = append(failures, checkPolicyMessages("warnings", expect.Warnings, result.Warnings)...)
+	return failures
+}
+
+// checkPolicyMessages compares messages regardless of order, since rego rules produce sets
+func checkPolicyMessages(kind string, expected *[]string, actual []string) []string {
+	if expected == nil {
+		return nil
+	}
+	want := slices.Clone(*expected)
+	got := slices.Clone(actual)
+	slices.Sort(want)
+	slices.Sort(got)
+	if slices.Equal(want, got) {
+		return nil
+	}
+	return []string{fmt.Sprintf("expected %v %q but got %q", kind, want, got)}
+}
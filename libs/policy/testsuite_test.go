@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, path string, contents string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+}
+
+func TestRunPolicyTestSuite(t *testing.T) {
+	dir := t.TempDir()
+	repoDir := filepath.Join(dir, "mgmt")
+	writeTestFile(t, filepath.Join(repoDir, "policies", "infra", "plan.rego"), "package digger\n"+
+		"deny[msg] { input.terraform.resource_changes[_].change.actions[_] == \"delete\"; msg := \"deletes resources\" }\n"+
+		"warn[msg] { input.context.pull_request.labels[_] == \"freeze\"; msg := \"frozen\" }\n")
+	writeTestFile(t, filepath.Join(repoDir, "policies", "access.rego"), "package digger\n"+
+		"default allow = false\n"+
+		"allow { input.teams[_] == \"platform\" }\n")
+	writeTestFile(t, filepath.Join(dir, "tests", "fixtures", "delete.json"), `{"resource_changes":[{"change":{"actions":["delete"]}}]}`)
+	writeTestFile(t, filepath.Join(dir, "tests", "policies.yaml"), `
+organisation: acme
+repository: infra
+tests:
+  - name: deletes are denied
+    policy: plan
+    project: {name: prod, dir: prod}
+    plan: fixtures/delete.json
+    context:
+      pull_request: {number: 1, labels: [freeze]}
+    expect:
+      allow: false
+      denials: [deletes resources]
+      warnings: [frozen]
+  - name: platform can apply
+    policy: access
+    project: {name: prod, dir: prod}
+    access: {user: alice, action: digger apply, teams: [platform]}
+    expect: {allow: true}
+  - name: wrong expectation
+    policy: access
+    project: {name: prod, dir: prod}
+    access: {user: bob, action: digger apply}
+    expect: {allow: true}
+  - name: missing fixture
+    policy: plan
+    plan: fixtures/missing.json
+`)
+
+	suite, err := LoadPolicyTestSuite(filepath.Join(dir, "tests", "policies.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "policies", suite.Name)
+
+	checker := DiggerPolicyChecker{PolicyProvider: LocalPolicyProvider{RepoDir: repoDir}}
+	suiteResult := RunPolicyTestSuite(checker, *suite)
+	assert.Len(t, suiteResult.Results, 4)
+	assert.True(t, suiteResult.Results[0].Passed(), "%v %v", suiteResult.Results[0].Failures, suiteResult.Results[0].Err)
+	assert.True(t, suiteResult.Results[1].Passed(), "%v %v", suiteResult.Results[1].Failures, suiteResult.Results[1].Err)
+	assert.Equal(t, []string{"expected allow to be true but was false"}, suiteResult.Results[2].Failures)
+	assert.Error(t, suiteResult.Results[3].Err)
+
+	failures, errors := suiteResult.Counts()
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, 1, errors)
+
+	var report bytes.Buffer
+	assert.NoError(t, WritePolicyTestJUnit(&report, []PolicyTestSuiteResult{suiteResult}))
+	assert.True(t, strings.HasPrefix(report.String(), "<?xml"))
+	assert.Contains(t, report.String(), `<testsuite name="policies" tests="4" failures="1" errors="1"`)
+	assert.Contains(t, report.String(), `<failure message="expected allow to be true but was false">`)
+}
+
+func TestLocalPolicyProviderFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "plan.rego"), "package digger\n")
+	writeTestFile(t, filepath.Join(dir, "mgmt", "policies", "plan.rego"), "package repo\n")
+
+	provider := LocalPolicyProvider{PlanPolicyPath: filepath.Join(dir, "plan.rego"), RepoDir: filepath.Join(dir, "mgmt")}
+	policy, err := provider.GetPlanPolicy("acme", "infra", "prod", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "package digger\n", policy)
+
+	// access falls back to the default policy like the management repo provider
+	policy, err = provider.GetAccessPolicy("acme", "infra", "prod", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAccessPolicy, policy)
+
+	policy, err = provider.GetDriftPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, "", policy)
+}
@@ -24,6 +24,9 @@ type Job struct {
 	Terragrunt         bool
 	OpenTofu           bool
 	Pulumi             bool
+	TerraformVersion   string
+	OpenTofuVersion    string
+	DependencyOutputs  []configuration.DependencyOutput
 	Commands           []string
 	ApplyStage         *Stage
 	PlanStage          *Stage
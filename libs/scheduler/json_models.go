@@ -20,37 +20,42 @@ type StageJson struct {
 
 type cognitoConfig = digger_config.AwsCognitoOidcConfig
 
+type dependencyOutput = digger_config.DependencyOutput
+
 type JobJson struct {
-	JobType                 string            `json:"job_type"`
-	ProjectName             string            `json:"projectName"`
-	ProjectAlias            string            `json:"projectAlias"`
-	ProjectDir              string            `json:"projectDir"`
-	ProjectWorkspace        string            `json:"projectWorkspace"`
-	Terragrunt              bool              `json:"terragrunt"`
-	OpenTofu                bool              `json:"opentofu"`
-	Pulumi                  bool              `json:"pulumi"`
-	Commands                []string          `json:"commands"`
-	ApplyStage              StageJson         `json:"applyStage"`
-	PlanStage               StageJson         `json:"planStage"`
-	PullRequestNumber       *int              `json:"pullRequestNumber"`
-	Commit                  string            `json:"commit"`
-	Branch                  string            `json:"branch"`
-	EventName               string            `json:"eventName"`
-	RequestedBy             string            `json:"requestedBy"`
-	Namespace               string            `json:"namespace"`
-	RunEnvVars              map[string]string `json:"runEnvVars"`
-	StateEnvVars            map[string]string `json:"stateEnvVars"`
-	CommandEnvVars          map[string]string `json:"commandEnvVars"`
-	AwsRoleRegion           string            `json:"aws_role_region"`
-	StateRoleName           string            `json:"state_role_name"`
-	CommandRoleName         string            `json:"command_role_name"`
-	BackendHostname         string            `json:"backend_hostname"`
-	BackendOrganisationName string            `json:"backend_organisation_hostname"`
-	BackendJobToken         string            `json:"backend_job_token"`
-	SkipMergeCheck          bool              `json:"skip_merge_check"`
-	CommandRoleArn          string            `json:"command_role_arn"`
-	StateRoleArn            string            `json:"state_role_arn"`
-	CognitoOidcConfig       *cognitoConfig    `json:"aws_cognito_oidc"`
+	JobType                 string             `json:"job_type"`
+	ProjectName             string             `json:"projectName"`
+	ProjectAlias            string             `json:"projectAlias"`
+	ProjectDir              string             `json:"projectDir"`
+	ProjectWorkspace        string             `json:"projectWorkspace"`
+	Terragrunt              bool               `json:"terragrunt"`
+	OpenTofu                bool               `json:"opentofu"`
+	Pulumi                  bool               `json:"pulumi"`
+	TerraformVersion        string             `json:"terraform_version,omitempty"`
+	OpenTofuVersion         string             `json:"opentofu_version,omitempty"`
+	DependencyOutputs       []dependencyOutput `json:"dependency_outputs,omitempty"`
+	Commands                []string           `json:"commands"`
+	ApplyStage              StageJson          `json:"applyStage"`
+	PlanStage               StageJson          `json:"planStage"`
+	PullRequestNumber       *int               `json:"pullRequestNumber"`
+	Commit                  string             `json:"commit"`
+	Branch                  string             `json:"branch"`
+	EventName               string             `json:"eventName"`
+	RequestedBy             string             `json:"requestedBy"`
+	Namespace               string             `json:"namespace"`
+	RunEnvVars              map[string]string  `json:"runEnvVars"`
+	StateEnvVars            map[string]string  `json:"stateEnvVars"`
+	CommandEnvVars          map[string]string  `json:"commandEnvVars"`
+	AwsRoleRegion           string             `json:"aws_role_region"`
+	StateRoleName           string             `json:"state_role_name"`
+	CommandRoleName         string             `json:"command_role_name"`
+	BackendHostname         string             `json:"backend_hostname"`
+	BackendOrganisationName string             `json:"backend_organisation_hostname"`
+	BackendJobToken         string             `json:"backend_job_token"`
+	SkipMergeCheck          bool               `json:"skip_merge_check"`
+	CommandRoleArn          string             `json:"command_role_arn"`
+	StateRoleArn            string             `json:"state_role_arn"`
+	CognitoOidcConfig       *cognitoConfig     `json:"aws_cognito_oidc"`
 }
 
 func (j *JobJson) IsPlan() bool {
@@ -84,6 +89,9 @@ func JobToJson(job Job, jobType DiggerCommand, organisationName string, branch s
 		ProjectWorkspace:        job.ProjectWorkspace,
 		OpenTofu:                job.OpenTofu,
 		Pulumi:                  job.Pulumi,
+		TerraformVersion:        job.TerraformVersion,
+		OpenTofuVersion:         job.OpenTofuVersion,
+		DependencyOutputs:       job.DependencyOutputs,
 		Terragrunt:              job.Terragrunt,
 		Commands:                job.Commands,
 		ApplyStage:              stageToJson(job.ApplyStage),
@@ -118,6 +126,9 @@ func JsonToJob(jobJson JobJson) Job {
 		ProjectWorkspace:   jobJson.ProjectWorkspace,
 		OpenTofu:           jobJson.OpenTofu,
 		Pulumi:             jobJson.Pulumi,
+		TerraformVersion:   jobJson.TerraformVersion,
+		OpenTofuVersion:    jobJson.OpenTofuVersion,
+		DependencyOutputs:  jobJson.DependencyOutputs,
 		Terragrunt:         jobJson.Terragrunt,
 		Commands:           jobJson.Commands,
 		ApplyStage:         jsonToStage(jobJson.ApplyStage),
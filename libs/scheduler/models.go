@@ -106,6 +106,9 @@ type SerializedJob struct {
 	ResourcesCreated uint            `json:"resources_created"`
 	ResourcesDeleted uint            `json:"resources_deleted"`
 	ResourcesUpdated uint            `json:"resources_updated"`
+	// PolicyOverrideBy and PolicyOverrideReason are set when soft plan policy denials were overridden
+	PolicyOverrideBy     *string `json:"policy_override_by,omitempty"`
+	PolicyOverrideReason *string `json:"policy_override_reason,omitempty"`
 }
 
 type SerializedBatch struct {
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/diggerhq/digger/libs/digger_config"
+)
+
+// TerraformOutput is an output of a project as printed by terraform output -json. The value of
+// sensitive outputs is never kept, so they can't be passed to other projects.
+type TerraformOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+}
+
+// TerraformOutputs are the outputs of a project by name
+type TerraformOutputs map[string]TerraformOutput
+
+// ParseTerraformOutputs reads the output of terraform output -json, dropping the values of sensitive outputs
+func ParseTerraformOutputs(outputJson string) (TerraformOutputs, error) {
+	outputs := TerraformOutputs{}
+	if err := json.Unmarshal([]byte(outputJson), &outputs); err != nil {
+		return nil, fmt.Errorf("could not parse terraform outputs: %v", err)
+	}
+	for name, output := range outputs {
+		if output.Sensitive {
+			output.Value = nil
+			outputs[name] = output
+		}
+	}
+	return outputs, nil
+}
+
+// DependencyVariable returns the name and value of the TF_VAR_ variable a dependency output is passed
+// as. Strings are passed as they are, other types as JSON, which terraform reads as an HCL expression.
+func (o TerraformOutputs) DependencyVariable(dependencyOutput digger_config.DependencyOutput) (string, string, error) {
+	name := "TF_VAR_" + dependencyOutput.Variable
+	output, ok := o[dependencyOutput.Output]
+	if !ok {
+		return name, "", fmt.Errorf("project %v has no output %v", dependencyOutput.Project, dependencyOutput.Output)
+	}
+	if output.Sensitive {
+		return name, "", fmt.Errorf("output %v of project %v is sensitive and can't be passed to other projects", dependencyOutput.Output, dependencyOutput.Project)
+	}
+	var value string
+	if err := json.Unmarshal(output.Value, &value); err == nil {
+		return name, value, nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, output.Value); err != nil {
+		return name, "", fmt.Errorf("invalid value of output %v of project %v: %v", dependencyOutput.Output, dependencyOutput.Project, err)
+	}
+	return name, compact.String(), nil
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/diggerhq/digger/libs/digger_config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTerraformOutputs(t *testing.T) {
+	outputs, err := ParseTerraformOutputs(`{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "subnet_ids": {"sensitive": false, "type": ["list", "string"], "value": [
+    "subnet-a",
+    "subnet-b"
+  ]},
+  "instances": {"sensitive": false, "type": "number", "value": 3},
+  "db_password": {"sensitive": true, "type": "string", "value": "hunter2"}
+}`)
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 4)
+	assert.True(t, outputs["db_password"].Sensitive)
+	assert.Nil(t, outputs["db_password"].Value)
+
+	for _, tc := range []struct {
+		output   string
+		variable string
+		value    string
+	}{
+		{"vpc_id", "vpc_id", "vpc-123"},
+		{"subnet_ids", "private_subnets", `["subnet-a","subnet-b"]`},
+		{"instances", "instances", "3"},
+	} {
+		name, value, err := outputs.DependencyVariable(digger_config.DependencyOutput{Project: "network", Output: tc.output, Variable: tc.variable})
+		assert.NoError(t, err)
+		assert.Equal(t, "TF_VAR_"+tc.variable, name)
+		assert.Equal(t, tc.value, value)
+	}
+
+	_, _, err = outputs.DependencyVariable(digger_config.DependencyOutput{Project: "network", Output: "db_password", Variable: "db_password"})
+	assert.ErrorContains(t, err, "sensitive")
+	_, _, err = outputs.DependencyVariable(digger_config.DependencyOutput{Project: "network", Output: "missing", Variable: "missing"})
+	assert.ErrorContains(t, err, "has no output missing")
+
+	_, err = ParseTerraformOutputs("Error: no state")
+	assert.Error(t, err)
+}
@@ -148,6 +148,10 @@ func (r ReporterProvider) GetReporter(title string, reporterSpec ReporterSpec, c
 			return reporting.LatestRunCommentStrategy{
 				TimeOfRun: time.Now(),
 			}
+		case "check_run":
+			return &reporting.CheckRunStrategy{
+				Title: title,
+			}
 		default:
 			return reporting.MultipleCommentsStrategy{}
 		}
@@ -329,6 +333,10 @@ func (p BasicPolicyProvider) GetPolicyProvider(policySpec PolicySpec, diggerHost
 	switch policySpec.PolicyType {
 	case "http":
 		slog.Debug("Using HTTP policy provider")
+		bundles, err := policy2.LoadPolicyBundlesFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy bundles: %v", err)
+		}
 		return policy2.DiggerPolicyChecker{
 			PolicyProvider: policy2.DiggerHttpPolicyProvider{
 				DiggerHost:         diggerHost,
@@ -336,6 +344,7 @@ func (p BasicPolicyProvider) GetPolicyProvider(policySpec PolicySpec, diggerHost
 				AuthToken:          token,
 				HttpClient:         http.DefaultClient,
 			},
+			Bundles: bundles,
 		}, nil
 	default:
 		slog.Error("Unknown policy type", "policyType", policySpec.PolicyType)
@@ -15,6 +15,8 @@ export interface SandboxRunPayload {
   workingDirectory?: string;
   configArchive: string;
   state?: string;
+  envVars?: Record<string, string>; // workspace environment variables
+  tfvars?: string; // rendered terraform variables, written as an auto.tfvars file
   metadata?: Record<string, string>;
 }
 
@@ -30,6 +30,8 @@ export function createRunRouter(
         workingDirectory: parsed.working_directory,
         configArchive: parsed.config_archive,
         state: parsed.state,
+        envVars: parsed.env_vars,
+        tfvars: parsed.tfvars,
         metadata: parsed.metadata,
       };
 
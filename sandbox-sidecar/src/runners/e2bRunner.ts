@@ -236,6 +236,13 @@ export class E2BSandboxRunner implements SandboxRunner {
       ? `${workDir}/${job.payload.workingDirectory}`
       : workDir;
 
+    // Workspace variables: terraform variables go into an auto.tfvars file that
+    // sorts after user files, env variables are passed to every IaC command
+    if (job.payload.tfvars) {
+      await sandbox.files.write(`${execDir}/zzz_opentaco.auto.tfvars`, job.payload.tfvars);
+    }
+    (sandbox as any)._jobEnvs = job.payload.envVars ?? {};
+
     // Write the state file if provided
     if (job.payload.state) {
       const statePath = `${execDir}/terraform.tfstate`;
@@ -292,6 +299,7 @@ export class E2BSandboxRunner implements SandboxRunner {
     const result = await sandbox.commands.run(cmdStr, {
       cwd,
       envs: {
+        ...((sandbox as any)._jobEnvs ?? {}),
         TF_IN_AUTOMATION: "1",
       },
       onStdout: pipeChunk,
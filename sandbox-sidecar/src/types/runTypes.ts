@@ -16,6 +16,8 @@ export const runRequestSchema = z.object({
   working_directory: z.string().optional(),
   config_archive: z.string().min(1),
   state: z.string().optional(),
+  env_vars: z.record(z.string()).optional(),
+  tfvars: z.string().optional(),
   metadata: z.record(z.string()).optional(),
 });
 
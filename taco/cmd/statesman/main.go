@@ -14,6 +14,8 @@ import (
 	"github.com/diggerhq/digger/opentaco/internal/api"
 	"github.com/diggerhq/digger/opentaco/internal/auth"
 	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/encryption"
+	"github.com/diggerhq/digger/opentaco/internal/kms"
 	"github.com/diggerhq/digger/opentaco/internal/logging"
 	"github.com/diggerhq/digger/opentaco/internal/query"
 	"github.com/diggerhq/digger/opentaco/internal/queryfactory"
@@ -35,6 +37,10 @@ func main() {
 		s3Bucket    = flag.String("s3-bucket", os.Getenv("OPENTACO_S3_BUCKET"), "S3 bucket for state storage")
 		s3Prefix    = flag.String("s3-prefix", os.Getenv("OPENTACO_S3_PREFIX"), "S3 key prefix (optional)")
 		s3Region    = flag.String("s3-region", os.Getenv("OPENTACO_S3_REGION"), "S3 region (optional; uses AWS defaults if empty)")
+		encKMS      = flag.String("encryption-kms", os.Getenv("OPENTACO_ENCRYPTION_KMS"), "Master key provider for state encryption at rest: local, awskms, gcpkms or vault (disabled if empty)")
+		encKey      = flag.String("encryption-key", os.Getenv("OPENTACO_ENCRYPTION_KEY"), "Master key reference: key file path, AWS KMS key ID, GCP CryptoKey name or Vault transit key")
+		encPrevKMS  = flag.String("encryption-previous-kms", os.Getenv("OPENTACO_ENCRYPTION_PREVIOUS_KMS"), "Previous master key provider; data keys wrapped by it are re-wrapped on startup")
+		encPrevKey  = flag.String("encryption-previous-key", os.Getenv("OPENTACO_ENCRYPTION_PREVIOUS_KEY"), "Previous master key reference")
 	)
 	flag.Parse()
 
@@ -89,6 +95,57 @@ func main() {
 		slog.Info("Using in-memory storage")
 	}
 
+	// Encryption keyring: per-org data keys wrapped by a master key. The master key is
+	// the configured KMS key, or one derived from OPENTACO_SECRET_KEY, which is then only
+	// used to seal TFE variables. State is encrypted at rest only when a KMS is configured.
+	var keyring *encryption.Keyring
+	if encDB := repositories.GetDBFromQueryStore(queryStore); encDB != nil {
+		var previous []kms.KeyWrapper
+		var master kms.KeyWrapper
+		secretKey, _ := kms.NewLocalKeyFromSecret(os.Getenv("OPENTACO_SECRET_KEY"))
+		if *encKMS != "" {
+			master, err = kms.New(context.Background(), *encKMS, *encKey)
+			if err != nil {
+				slog.Error("Failed to initialize encryption master key", "error", err)
+				os.Exit(1)
+			}
+			if *encPrevKMS != "" {
+				prev, err := kms.New(context.Background(), *encPrevKMS, *encPrevKey)
+				if err != nil {
+					slog.Error("Failed to initialize previous encryption master key", "error", err)
+					os.Exit(1)
+				}
+				previous = append(previous, prev)
+			}
+			// Keys created before a KMS was configured are wrapped by the secret-derived key
+			if secretKey != nil {
+				previous = append(previous, secretKey)
+			}
+		} else if secretKey != nil {
+			master = secretKey
+		}
+
+		if master != nil {
+			keyring = encryption.NewKeyring(repositories.NewDataKeyRepository(encDB), master, previous...)
+			if len(previous) > 0 {
+				n, err := keyring.Rewrap(context.Background())
+				if err != nil {
+					slog.Error("Failed to re-wrap data keys with new master key", "error", err)
+					os.Exit(1)
+				}
+				slog.Info("Re-wrapped data keys with current master key", "count", n, "master_key", master.ID())
+			}
+		}
+	}
+	if *encKMS != "" {
+		if keyring == nil {
+			slog.Error("State encryption requires a query store with database access")
+			os.Exit(1)
+		}
+		blobStore = encryption.NewStore(blobStore, keyring)
+		slog.Info("State encryption at rest enabled", "kms", *encKMS)
+	}
+
 	// sync units to query index 
 	existingUnits, err := queryStore.ListUnits(context.Background(), "")
 	if err != nil {
@@ -143,6 +200,14 @@ func main() {
 		slog.Error("Failed to create RBAC manager", "error", err)
 		os.Exit(1)
 	}
+
+	// Grant actions added to the admin permission since it was seeded to existing organizations
+	if updated, err := rbac.BackfillAdminActions(context.Background(), db); err != nil {
+		slog.Error("Failed to backfill admin permissions", "error", err)
+		os.Exit(1)
+	} else if updated > 0 {
+		slog.Info("Backfilled admin permissions", "rules", updated)
+	}
 	
 	// --- Create Domain Interfaces with Optional Authorization ---
 	// These interfaces are what handlers will use
@@ -232,6 +297,10 @@ func main() {
 	}
 
 	// Register routes with interface-based dependencies
+	var valueSealer domain.ValueSealer
+	if keyring != nil {
+		valueSealer = keyring
+	}
 	api.RegisterRoutes(e, api.Dependencies{
 		Repository:          fullRepo,      // RBAC-wrapped repository (used by authenticated routes)
 		UnwrappedRepository: repo,          // Unwrapped repository (for pre-authorized operations like signed URLs)
@@ -241,6 +310,7 @@ func main() {
 		Signer:              signer,        // JWT signing
 		AuthEnabled:         !*authDisable, // Auth flag
 		Sandbox:             sandboxProvider,
+		ValueSealer:         valueSealer,
 	})
 
 	// Start server
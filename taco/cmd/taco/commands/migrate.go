@@ -0,0 +1,233 @@
+package commands
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "os/signal"
+    "text/tabwriter"
+
+    "github.com/diggerhq/digger/opentaco/cmd/taco/migrate"
+    "github.com/diggerhq/digger/opentaco/internal/analytics"
+    "github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+    Use:   "migrate",
+    Short: "Bulk import or export Terraform state",
+    Long: `Move Terraform state between OpenTaco and other backends.
+
+Import reads every state from an S3 backend bucket, a Terraform Cloud organization
+or a local directory and uploads it as an OpenTaco unit, optionally replaying the
+full version history. Export writes the current state of every unit to a directory
+or tar.gz archive. Both print a checksum report.`,
+}
+
+var (
+    migrateFrom       string
+    migrateBucket     string
+    migratePrefix     string
+    migrateRegion     string
+    migrateTFCOrg     string
+    migrateTFCToken   string
+    migrateTFCHost    string
+    migrateDir        string
+    migrateUnitPrefix string
+    migrateHistory    bool
+    migrateDryRun     bool
+    migrateOverwrite  bool
+    migrateProgress   string
+    migrateReport     string
+    migrateFormat     string
+)
+
+func init() {
+    rootCmd.AddCommand(migrateCmd)
+    migrateCmd.AddCommand(migrateImportCmd)
+    migrateCmd.AddCommand(migrateExportCmd)
+
+    migrateImportCmd.Flags().StringVar(&migrateFrom, "from", "", "Source backend: s3, tfc or dir")
+    migrateImportCmd.Flags().StringVar(&migrateBucket, "bucket", "", "S3 bucket (--from s3)")
+    migrateImportCmd.Flags().StringVar(&migratePrefix, "prefix", "", "Key prefix within the S3 bucket (--from s3)")
+    migrateImportCmd.Flags().StringVar(&migrateRegion, "region", "", "AWS region (--from s3)")
+    migrateImportCmd.Flags().StringVar(&migrateTFCOrg, "tfc-org", "", "Terraform Cloud organization (--from tfc)")
+    migrateImportCmd.Flags().StringVar(&migrateTFCToken, "tfc-token", os.Getenv("TFE_TOKEN"), "Terraform Cloud API token (--from tfc, defaults to $TFE_TOKEN)")
+    migrateImportCmd.Flags().StringVar(&migrateTFCHost, "tfc-host", getEnvOrDefault("TFE_ADDRESS", "https://app.terraform.io"), "Terraform Cloud/Enterprise address (--from tfc)")
+    migrateImportCmd.Flags().StringVar(&migrateDir, "dir", "", "Directory containing *.tfstate files (--from dir)")
+    migrateImportCmd.Flags().StringVar(&migrateUnitPrefix, "unit-prefix", "", "Prefix prepended to every imported unit ID")
+    migrateImportCmd.Flags().BoolVar(&migrateHistory, "history", false, "Import every available version, not just the current state")
+    migrateImportCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show what would be imported without writing anything")
+    migrateImportCmd.Flags().BoolVar(&migrateOverwrite, "overwrite", false, "Replace the state of units that already exist instead of reporting a conflict")
+    migrateImportCmd.Flags().StringVar(&migrateProgress, "progress", "", "Progress file used to resume an interrupted import")
+    migrateImportCmd.Flags().StringVar(&migrateReport, "report", "", "Write the checksum report as JSON to this file")
+    migrateImportCmd.MarkFlagRequired("from")
+
+    migrateExportCmd.Flags().StringVar(&migratePrefix, "prefix", "", "Only export units under this prefix")
+    migrateExportCmd.Flags().StringVar(&migrateFormat, "format", "", "Output format: dir or tar (default: tar if the destination ends in .tar.gz/.tgz)")
+    migrateExportCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show what would be exported without writing anything")
+    migrateExportCmd.Flags().StringVar(&migrateReport, "report", "", "Write the checksum report as JSON to this file")
+}
+
+var migrateImportCmd = &cobra.Command{
+    Use:   "import",
+    Short: "Import states from S3, Terraform Cloud or a directory",
+    Example: `  taco migrate import --from s3 --bucket my-tf-states --prefix prod --history
+  taco migrate import --from tfc --tfc-org acme --progress .taco-migrate.json
+  taco migrate import --from dir --dir ./states --unit-prefix legacy --dry-run`,
+    Args: cobra.NoArgs,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        analytics.SendEssential("taco_migrate_import_started")
+
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        defer stop()
+
+        src, err := newMigrateSource(ctx)
+        if err != nil {
+            analytics.SendEssential("taco_migrate_import_failed")
+            return err
+        }
+
+        opts := migrate.ImportOptions{
+            UnitPrefix: migrateUnitPrefix,
+            History:    migrateHistory,
+            DryRun:     migrateDryRun,
+            Overwrite:  migrateOverwrite,
+            Log:        os.Stderr,
+        }
+        if migrateProgress != "" {
+            progress, err := migrate.LoadProgress(migrateProgress)
+            if err != nil {
+                analytics.SendEssential("taco_migrate_import_failed")
+                return err
+            }
+            printVerbose("Loaded %d completed units from %s", len(progress.Units), migrateProgress)
+            opts.Progress = progress
+        }
+
+        client := newAuthedClient()
+        report, err := migrate.Import(ctx, src, client, opts)
+        if report != nil {
+            if werr := finishMigrateReport(report); werr != nil && err == nil {
+                err = werr
+            }
+        }
+        if err != nil {
+            analytics.SendEssential("taco_migrate_import_failed")
+            return err
+        }
+        if report.Failed() {
+            analytics.SendEssential("taco_migrate_import_failed")
+            return fmt.Errorf("import finished with failures")
+        }
+
+        analytics.SendEssential("taco_migrate_import_completed")
+        return nil
+    },
+}
+
+var migrateExportCmd = &cobra.Command{
+    Use:   "export <destination>",
+    Short: "Export unit states to a directory or tar.gz archive",
+    Example: `  taco migrate export ./backup
+  taco migrate export backup.tar.gz --prefix prod`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        analytics.SendEssential("taco_migrate_export_started")
+
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        defer stop()
+
+        dest := args[0]
+        opts := migrate.ExportOptions{
+            Prefix: migratePrefix,
+            DryRun: migrateDryRun,
+            Log:    os.Stderr,
+        }
+        switch migrateFormat {
+        case "":
+            opts.Archive = migrate.IsArchivePath(dest)
+        case "tar":
+            opts.Archive = true
+        case "dir":
+        default:
+            analytics.SendEssential("taco_migrate_export_failed")
+            return fmt.Errorf("unknown format %q (expected dir or tar)", migrateFormat)
+        }
+
+        client := newAuthedClient()
+        report, err := migrate.Export(ctx, client, dest, opts)
+        if report != nil {
+            if werr := finishMigrateReport(report); werr != nil && err == nil {
+                err = werr
+            }
+        }
+        if err != nil {
+            analytics.SendEssential("taco_migrate_export_failed")
+            return err
+        }
+        if report.Failed() {
+            analytics.SendEssential("taco_migrate_export_failed")
+            return fmt.Errorf("export finished with failures")
+        }
+
+        analytics.SendEssential("taco_migrate_export_completed")
+        return nil
+    },
+}
+
+func newMigrateSource(ctx context.Context) (migrate.Source, error) {
+    switch migrateFrom {
+    case "s3":
+        return migrate.NewS3Source(ctx, migrateBucket, migratePrefix, migrateRegion)
+    case "tfc":
+        if migrateTFCOrg == "" {
+            return nil, fmt.Errorf("--tfc-org is required for --from tfc")
+        }
+        if migrateTFCToken == "" {
+            return nil, fmt.Errorf("--tfc-token or TFE_TOKEN is required for --from tfc")
+        }
+        return &migrate.TFCSource{Host: migrateTFCHost, Organization: migrateTFCOrg, Token: migrateTFCToken}, nil
+    case "dir":
+        if migrateDir == "" {
+            return nil, fmt.Errorf("--dir is required for --from dir")
+        }
+        if _, err := os.Stat(migrateDir); err != nil {
+            return nil, err
+        }
+        return &migrate.DirSource{Root: migrateDir}, nil
+    default:
+        return nil, fmt.Errorf("unknown source %q (expected s3, tfc or dir)", migrateFrom)
+    }
+}
+
+// finishMigrateReport prints the checksum table and writes the JSON report if requested
+func finishMigrateReport(report *migrate.Report) error {
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "UNIT\tSTATUS\tVERSIONS\tSHA256\tMATCH")
+    for _, u := range report.Units {
+        sum := u.SourceChecksum
+        if len(sum) > 12 { sum = sum[:12] }
+        match := ""
+        if u.Status == migrate.StatusImported || u.Status == migrate.StatusExported || u.Status == migrate.StatusSkipped {
+            match = "no"
+            if u.Match { match = "yes" }
+        }
+        fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", u.Unit, u.Status, u.Versions, sum, match)
+    }
+    w.Flush()
+
+    if migrateReport == "" {
+        return nil
+    }
+    data, err := json.MarshalIndent(report, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(migrateReport, data, 0o644); err != nil {
+        return fmt.Errorf("failed to write report: %w", err)
+    }
+    printVerbose("Report written to %s", migrateReport)
+    return nil
+}
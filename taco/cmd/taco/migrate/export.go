@@ -0,0 +1,182 @@
+package migrate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestName is the file written alongside exported states listing their checksums
+const ManifestName = "manifest.json"
+
+// ExportOptions controls an export run
+type ExportOptions struct {
+	// Prefix limits the export to units under this prefix
+	Prefix string
+	// Archive writes a single tar.gz to the destination instead of a directory
+	Archive bool
+	// DryRun lists what would be exported without writing anything
+	DryRun bool
+	// Log receives human-readable progress lines; optional
+	Log io.Writer
+}
+
+// IsArchivePath reports whether dest names a tar.gz archive
+func IsArchivePath(dest string) bool {
+	return strings.HasSuffix(dest, ".tar.gz") || strings.HasSuffix(dest, ".tgz")
+}
+
+// Export writes the current state of every unit in target to dest as
+// <unit>.tfstate files plus a manifest. The output layout is readable by
+// DirSource, so an export can be imported back unchanged.
+func Export(ctx context.Context, target Target, dest string, opts ExportOptions) (*Report, error) {
+	report := &Report{Source: "opentaco", DryRun: opts.DryRun, StartedAt: time.Now()}
+
+	list, err := target.ListUnits(ctx, opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list units: %w", err)
+	}
+	logf(opts.Log, "Found %d units", len(list.Units))
+
+	var w exportWriter
+	if !opts.DryRun {
+		if opts.Archive {
+			w, err = newTarWriter(dest)
+		} else {
+			w, err = newDirWriter(dest)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, unit := range list.Units {
+		if err := ctx.Err(); err != nil {
+			if w != nil {
+				w.Close()
+			}
+			return report, err
+		}
+		result := UnitResult{Source: unit.ID, Unit: unit.ID, Versions: 1}
+
+		if opts.DryRun {
+			result.Status = StatusPlanned
+			report.Units = append(report.Units, result)
+			logf(opts.Log, "  ~ %s: would export", unit.ID)
+			continue
+		}
+
+		data, err := target.DownloadUnit(ctx, unit.ID)
+		if err != nil {
+			result = failed(result, fmt.Errorf("failed to download: %w", err))
+			report.Units = append(report.Units, result)
+			logf(opts.Log, "  ✗ %s: %s", unit.ID, result.Error)
+			continue
+		}
+		result.SourceChecksum = Checksum(data)
+		if err := w.WriteFile(unit.ID+".tfstate", data); err != nil {
+			w.Close()
+			return report, fmt.Errorf("failed to write %s: %w", unit.ID, err)
+		}
+		result.TargetChecksum = result.SourceChecksum
+		result.Match = true
+		result.Status = StatusExported
+		report.Units = append(report.Units, result)
+		logf(opts.Log, "  ✓ %s", unit.ID)
+	}
+
+	report.FinishedAt = time.Now()
+	if w == nil {
+		return report, nil
+	}
+
+	manifest, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		w.Close()
+		return report, err
+	}
+	if err := w.WriteFile(ManifestName, manifest); err != nil {
+		w.Close()
+		return report, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return report, w.Close()
+}
+
+type exportWriter interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+type dirWriter struct {
+	root string
+}
+
+func newDirWriter(root string) (*dirWriter, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", root, err)
+	}
+	return &dirWriter{root: root}, nil
+}
+
+func (d *dirWriter) WriteFile(name string, data []byte) error {
+	path := filepath.Join(d.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (d *dirWriter) Close() error { return nil }
+
+type tarWriter struct {
+	f  *os.File
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarWriter(path string) (*tarWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	gz := gzip.NewWriter(f)
+	return &tarWriter{f: f, gz: gz, tw: tar.NewWriter(gz)}, nil
+}
+
+func (t *tarWriter) WriteFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(data)
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		t.f.Close()
+		return err
+	}
+	if err := t.gz.Close(); err != nil {
+		t.f.Close()
+		return err
+	}
+	return t.f.Close()
+}
@@ -0,0 +1,257 @@
+// Package migrate moves Terraform state between OpenTaco and other backends.
+//
+// Imports read units (and, where the source keeps it, their version history)
+// from a Source and replay them into OpenTaco oldest-first through the SDK, so
+// OpenTaco's own versioning reproduces the history. Exports write the current
+// state of every unit to a directory or tar.gz archive.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/pkg/sdk"
+)
+
+// VersionRef identifies one historical version of a unit in a source
+type VersionRef struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+// SourceUnit is a unit discovered in a source. Versions are ordered oldest
+// first; the last entry is the current state.
+type SourceUnit struct {
+	Name     string       `json:"name"`
+	Versions []VersionRef `json:"versions"`
+}
+
+// Current returns the newest version of the unit
+func (u SourceUnit) Current() VersionRef {
+	return u.Versions[len(u.Versions)-1]
+}
+
+// Source is a backend states can be imported from
+type Source interface {
+	// Name describes the source for logs and reports
+	Name() string
+	// ListUnits returns every unit in the source with at least one version
+	ListUnits(ctx context.Context) ([]SourceUnit, error)
+	// Fetch returns the raw state bytes of a single version
+	Fetch(ctx context.Context, unit SourceUnit, version VersionRef) ([]byte, error)
+}
+
+// Target is the subset of sdk.Client used by imports and exports
+type Target interface {
+	CreateUnit(ctx context.Context, unitID string) (*sdk.CreateUnitResponse, error)
+	GetUnit(ctx context.Context, unitID string) (*sdk.UnitMetadata, error)
+	ListUnits(ctx context.Context, prefix string) (*sdk.ListUnitsResponse, error)
+	UploadUnit(ctx context.Context, unitID string, data []byte, lockID string) error
+	DownloadUnit(ctx context.Context, unitID string) ([]byte, error)
+}
+
+var _ Target = (*sdk.Client)(nil)
+
+// ImportOptions controls an import run
+type ImportOptions struct {
+	// UnitPrefix is prepended to every source unit name
+	UnitPrefix string
+	// History replays every available version instead of only the current one
+	History bool
+	// DryRun lists what would be imported without writing anything
+	DryRun bool
+	// Overwrite replaces the state of units that already exist in OpenTaco.
+	// Without it such units are reported as conflicts, unless Progress records
+	// them as imported by an earlier run.
+	Overwrite bool
+	// Progress records completed units so an interrupted run can resume; optional
+	Progress *Progress
+	// Log receives human-readable progress lines; optional
+	Log io.Writer
+}
+
+// Status values used in reports
+const (
+	StatusImported = "imported"
+	StatusExported = "exported"
+	StatusSkipped  = "skipped"
+	StatusPlanned  = "planned"
+	StatusConflict = "conflict"
+	StatusFailed   = "failed"
+)
+
+// UnitResult is one line of the checksum report
+type UnitResult struct {
+	Source         string `json:"source"`
+	Unit           string `json:"unit"`
+	Status         string `json:"status"`
+	Versions       int    `json:"versions"`
+	SourceChecksum string `json:"source_sha256,omitempty"`
+	TargetChecksum string `json:"target_sha256,omitempty"`
+	Match          bool   `json:"match"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Report summarises an import or export
+type Report struct {
+	Source     string       `json:"source"`
+	DryRun     bool         `json:"dry_run"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Units      []UnitResult `json:"units"`
+}
+
+// Failed reports whether any unit failed, conflicted or did not round-trip byte-for-byte
+func (r *Report) Failed() bool {
+	for _, u := range r.Units {
+		if u.Status == StatusFailed || u.Status == StatusConflict {
+			return true
+		}
+		if (u.Status == StatusImported || u.Status == StatusExported) && !u.Match {
+			return true
+		}
+	}
+	return false
+}
+
+// Checksum returns the hex sha256 of state bytes
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Import copies every unit from src into target. Units already recorded as
+// complete in opts.Progress with an unchanged source checksum are skipped.
+// Per-unit errors are recorded in the report and do not stop the run.
+func Import(ctx context.Context, src Source, target Target, opts ImportOptions) (*Report, error) {
+	report := &Report{Source: src.Name(), DryRun: opts.DryRun, StartedAt: time.Now()}
+
+	units, err := src.ListUnits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list units in %s: %w", src.Name(), err)
+	}
+	logf(opts.Log, "Found %d units in %s", len(units), src.Name())
+
+	for _, unit := range units {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		result := importUnit(ctx, src, target, unit, opts)
+		report.Units = append(report.Units, result)
+
+		switch result.Status {
+		case StatusFailed:
+			logf(opts.Log, "  ✗ %s: %s", result.Unit, result.Error)
+		case StatusConflict:
+			logf(opts.Log, "  ! %s: %s", result.Unit, result.Error)
+		case StatusSkipped:
+			logf(opts.Log, "  - %s: already imported", result.Unit)
+		case StatusPlanned:
+			logf(opts.Log, "  ~ %s: would import %d version(s)", result.Unit, result.Versions)
+		default:
+			logf(opts.Log, "  ✓ %s: imported %d version(s)", result.Unit, result.Versions)
+		}
+
+		if opts.Progress != nil && !opts.DryRun && result.Status == StatusImported && result.Match {
+			if err := opts.Progress.MarkDone(result.Unit, result.SourceChecksum); err != nil {
+				return report, fmt.Errorf("failed to save progress: %w", err)
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func importUnit(ctx context.Context, src Source, target Target, unit SourceUnit, opts ImportOptions) UnitResult {
+	name := joinUnitName(opts.UnitPrefix, unit.Name)
+	result := UnitResult{Source: unit.Name, Unit: name}
+
+	versions := unit.Versions
+	if !opts.History {
+		versions = []VersionRef{unit.Current()}
+	}
+	result.Versions = len(versions)
+
+	current, err := src.Fetch(ctx, unit, unit.Current())
+	if err != nil {
+		return failed(result, fmt.Errorf("failed to fetch current state: %w", err))
+	}
+	result.SourceChecksum = Checksum(current)
+
+	if opts.Progress != nil && opts.Progress.IsDone(name, result.SourceChecksum) {
+		result.Status = StatusSkipped
+		result.TargetChecksum = result.SourceChecksum
+		result.Match = true
+		return result
+	}
+
+	existing, err := target.GetUnit(ctx, name)
+	exists := err == nil
+	// Units without state have nothing to lose, e.g. when an earlier run stopped after creating them
+	if exists && existing.Size > 0 && !opts.Overwrite && (opts.Progress == nil || !opts.Progress.Imported(name)) {
+		result.Status = StatusConflict
+		result.Error = "unit already exists, pass --overwrite to replace its state"
+		return result
+	}
+
+	if opts.DryRun {
+		result.Status = StatusPlanned
+		return result
+	}
+
+	if !exists {
+		if _, err := target.CreateUnit(ctx, name); err != nil {
+			return failed(result, fmt.Errorf("failed to create unit: %w", err))
+		}
+	}
+
+	// Replay oldest first so OpenTaco's version list mirrors the source history
+	for i, v := range versions {
+		data := current
+		if i < len(versions)-1 {
+			if data, err = src.Fetch(ctx, unit, v); err != nil {
+				return failed(result, fmt.Errorf("failed to fetch version %s: %w", v.ID, err))
+			}
+		}
+		if err := target.UploadUnit(ctx, name, data, ""); err != nil {
+			return failed(result, fmt.Errorf("failed to upload version %s: %w", v.ID, err))
+		}
+	}
+
+	uploaded, err := target.DownloadUnit(ctx, name)
+	if err != nil {
+		return failed(result, fmt.Errorf("failed to verify upload: %w", err))
+	}
+	result.TargetChecksum = Checksum(uploaded)
+	result.Match = result.TargetChecksum == result.SourceChecksum
+	result.Status = StatusImported
+	return result
+}
+
+func failed(result UnitResult, err error) UnitResult {
+	result.Status = StatusFailed
+	result.Error = err.Error()
+	return result
+}
+
+func joinUnitName(prefix, name string) string {
+	name = strings.Trim(name, "/")
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+func logf(w io.Writer, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	fmt.Fprintf(w, format+"\n", args...)
+}
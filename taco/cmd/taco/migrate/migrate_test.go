@@ -0,0 +1,381 @@
+package migrate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/pkg/sdk"
+)
+
+// fakeTarget is an in-memory Target that records every upload
+type fakeTarget struct {
+	units   map[string][]byte
+	uploads map[string]int
+}
+
+func newFakeTarget() *fakeTarget {
+	return &fakeTarget{units: map[string][]byte{}, uploads: map[string]int{}}
+}
+
+func (f *fakeTarget) CreateUnit(ctx context.Context, unitID string) (*sdk.CreateUnitResponse, error) {
+	f.units[unitID] = nil
+	return &sdk.CreateUnitResponse{ID: unitID}, nil
+}
+
+func (f *fakeTarget) GetUnit(ctx context.Context, unitID string) (*sdk.UnitMetadata, error) {
+	if _, ok := f.units[unitID]; !ok {
+		return nil, errors.New("not found")
+	}
+	return &sdk.UnitMetadata{ID: unitID, Size: int64(len(f.units[unitID]))}, nil
+}
+
+func (f *fakeTarget) ListUnits(ctx context.Context, prefix string) (*sdk.ListUnitsResponse, error) {
+	var ids []string
+	for id := range f.units {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	resp := &sdk.ListUnitsResponse{}
+	for _, id := range ids {
+		resp.Units = append(resp.Units, &sdk.UnitMetadata{ID: id})
+	}
+	resp.Count = len(resp.Units)
+	return resp, nil
+}
+
+func (f *fakeTarget) UploadUnit(ctx context.Context, unitID string, data []byte, lockID string) error {
+	if _, ok := f.units[unitID]; !ok {
+		return errors.New("not found")
+	}
+	f.units[unitID] = data
+	f.uploads[unitID]++
+	return nil
+}
+
+func (f *fakeTarget) DownloadUnit(ctx context.Context, unitID string) ([]byte, error) {
+	data, ok := f.units[unitID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSource_UnitNames(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app", "prod.tfstate"), `{"serial":1}`)
+	writeFile(t, filepath.Join(root, "network", "terraform.tfstate"), `{"serial":2}`)
+	writeFile(t, filepath.Join(root, "terraform.tfstate.d", "staging", "terraform.tfstate"), `{"serial":3}`)
+	writeFile(t, filepath.Join(root, "README.md"), "ignored")
+
+	units, err := (&DirSource{Root: root}).ListUnits(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+	}
+	want := []string{"app/prod", "network", "staging"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("expected units %v, got %v", want, names)
+	}
+}
+
+func TestDirSource_DuplicateUnitNames(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app.tfstate"), `{}`)
+	writeFile(t, filepath.Join(root, "app", "terraform.tfstate"), `{}`)
+
+	if _, err := (&DirSource{Root: root}).ListUnits(context.Background()); err == nil {
+		t.Error("expected an error for two files mapping to the same unit")
+	}
+}
+
+func TestImport_DryRunWritesNothing(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.tfstate"), `{"serial":1}`)
+
+	target := newFakeTarget()
+	report, err := Import(context.Background(), &DirSource{Root: root}, target, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Units) != 1 || report.Units[0].Status != StatusPlanned {
+		t.Fatalf("expected one planned unit, got %+v", report.Units)
+	}
+	if len(target.units) != 0 {
+		t.Errorf("dry run created units: %v", target.units)
+	}
+}
+
+func TestImport_ChecksumsAndResume(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.tfstate"), `{"serial":1}`)
+	writeFile(t, filepath.Join(root, "b.tfstate"), `{"serial":7}`)
+	progressPath := filepath.Join(t.TempDir(), "progress.json")
+
+	target := newFakeTarget()
+	progress, err := LoadProgress(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Import(context.Background(), &DirSource{Root: root}, target, ImportOptions{UnitPrefix: "legacy", Progress: progress})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed() {
+		t.Fatalf("expected success, got %+v", report.Units)
+	}
+	for _, u := range report.Units {
+		if u.Status != StatusImported || !u.Match || u.SourceChecksum != u.TargetChecksum {
+			t.Errorf("unexpected result: %+v", u)
+		}
+	}
+	if string(target.units["legacy/b"]) != `{"serial":7}` {
+		t.Errorf("unexpected uploaded state: %s", target.units["legacy/b"])
+	}
+
+	// Re-running with a reloaded progress file only re-imports the changed unit
+	writeFile(t, filepath.Join(root, "b.tfstate"), `{"serial":8}`)
+	progress, err = LoadProgress(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = Import(context.Background(), &DirSource{Root: root}, target, ImportOptions{UnitPrefix: "legacy", Progress: progress})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, u := range report.Units {
+		statuses[u.Unit] = u.Status
+	}
+	if statuses["legacy/a"] != StatusSkipped || statuses["legacy/b"] != StatusImported {
+		t.Errorf("unexpected statuses on resume: %v", statuses)
+	}
+	if target.uploads["legacy/a"] != 1 || target.uploads["legacy/b"] != 2 {
+		t.Errorf("unexpected upload counts: %v", target.uploads)
+	}
+}
+
+func TestImport_ExistingUnitConflicts(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.tfstate"), `{"serial":1}`)
+	writeFile(t, filepath.Join(root, "b.tfstate"), `{"serial":2}`)
+
+	target := newFakeTarget()
+	target.units["a"] = []byte(`{"serial":9}`)
+	target.units["b"] = nil
+
+	for _, dryRun := range []bool{true, false} {
+		report, err := Import(context.Background(), &DirSource{Root: root}, target, ImportOptions{DryRun: dryRun})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		statuses := map[string]string{}
+		for _, u := range report.Units {
+			statuses[u.Unit] = u.Status
+		}
+		want := StatusImported
+		if dryRun {
+			want = StatusPlanned
+		}
+		if statuses["a"] != StatusConflict || statuses["b"] != want || !report.Failed() {
+			t.Errorf("dry run %v: unexpected statuses %v", dryRun, statuses)
+		}
+	}
+	if string(target.units["a"]) != `{"serial":9}` {
+		t.Errorf("existing state was overwritten: %s", target.units["a"])
+	}
+
+	report, err := Import(context.Background(), &DirSource{Root: root}, target, ImportOptions{Overwrite: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed() || string(target.units["a"]) != `{"serial":1}` {
+		t.Errorf("expected the state to be replaced with --overwrite, got %s %+v", target.units["a"], report.Units)
+	}
+}
+
+// historySource serves fixed in-memory versions
+type historySource map[string][]string
+
+func (h historySource) Name() string { return "history" }
+
+func (h historySource) ListUnits(ctx context.Context) ([]SourceUnit, error) {
+	var units []SourceUnit
+	for name, versions := range h {
+		u := SourceUnit{Name: name}
+		for i := range versions {
+			u.Versions = append(u.Versions, VersionRef{ID: fmt.Sprint(i)})
+		}
+		units = append(units, u)
+	}
+	return units, nil
+}
+
+func (h historySource) Fetch(ctx context.Context, unit SourceUnit, version VersionRef) ([]byte, error) {
+	var i int
+	fmt.Sscan(version.ID, &i)
+	return []byte(h[unit.Name][i]), nil
+}
+
+func TestImport_History(t *testing.T) {
+	src := historySource{"app": {"v1", "v2", "v3"}}
+
+	target := newFakeTarget()
+	report, err := Import(context.Background(), src, target, ImportOptions{History: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Units[0].Versions != 3 || target.uploads["app"] != 3 {
+		t.Errorf("expected 3 versions replayed, got report %d uploads %d", report.Units[0].Versions, target.uploads["app"])
+	}
+	if string(target.units["app"]) != "v3" {
+		t.Errorf("expected newest version last, got %s", target.units["app"])
+	}
+
+	target = newFakeTarget()
+	if _, err := Import(context.Background(), src, target, ImportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.uploads["app"] != 1 || string(target.units["app"]) != "v3" {
+		t.Errorf("expected only the current version without --history, got %d uploads", target.uploads["app"])
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	target := newFakeTarget()
+	target.units["app/prod"] = []byte(`{"serial":3}`)
+	target.units["network"] = []byte(`{"serial":9}`)
+
+	dest := t.TempDir()
+	report, err := Export(context.Background(), target, dest, ExportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed() || len(report.Units) != 2 {
+		t.Fatalf("unexpected report: %+v", report.Units)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ManifestName)); err != nil {
+		t.Errorf("expected manifest: %v", err)
+	}
+
+	// The exported tree imports back into identical units
+	restored := newFakeTarget()
+	if _, err := Import(context.Background(), &DirSource{Root: dest}, restored, ImportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, data := range target.units {
+		if string(restored.units[id]) != string(data) {
+			t.Errorf("unit %s: expected %s, got %s", id, data, restored.units[id])
+		}
+	}
+}
+
+func TestExport_Archive(t *testing.T) {
+	target := newFakeTarget()
+	target.units["app"] = []byte(`{"serial":1}`)
+
+	dest := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if _, err := Export(context.Background(), target, dest, ExportOptions{Archive: IsArchivePath(dest)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(data)
+	}
+	if files["app.tfstate"] != `{"serial":1}` {
+		t.Errorf("unexpected archive contents: %v", files)
+	}
+	if _, ok := files[ManifestName]; !ok {
+		t.Error("expected manifest in archive")
+	}
+}
+
+func TestTFCSource(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/v2/organizations/acme/workspaces":
+			if r.URL.Query().Get("page[number]") == "1" {
+				fmt.Fprint(w, `{"data":[{"id":"ws-1","attributes":{"name":"app"}}],"meta":{"pagination":{"next-page":2}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"id":"ws-2","attributes":{"name":"empty"}}],"meta":{"pagination":{"next-page":null}}}`)
+		case r.URL.Path == "/api/v2/state-versions":
+			if r.URL.Query().Get("filter[workspace][name]") != "app" {
+				fmt.Fprint(w, `{"data":[],"meta":{"pagination":{}}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":[
+				{"id":"sv-2","attributes":{"created-at":"2024-02-01T00:00:00Z","hosted-state-download-url":"%[1]s/dl/2"}},
+				{"id":"sv-1","attributes":{"created-at":"2024-01-01T00:00:00Z","hosted-state-download-url":"%[1]s/dl/1"}}
+			],"meta":{"pagination":{}}}`, server.URL)
+		case strings.HasPrefix(r.URL.Path, "/dl/"):
+			fmt.Fprintf(w, `{"serial":%s}`, strings.TrimPrefix(r.URL.Path, "/dl/"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	src := &TFCSource{Host: server.URL, Organization: "acme", Token: "tok"}
+	target := newFakeTarget()
+	report, err := Import(context.Background(), src, target, ImportOptions{History: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Units) != 1 || report.Failed() {
+		t.Fatalf("unexpected report: %+v", report.Units)
+	}
+	if target.uploads["app"] != 2 || string(target.units["app"]) != `{"serial":2}` {
+		t.Errorf("expected history replayed oldest first, got %d uploads, current %s", target.uploads["app"], target.units["app"])
+	}
+}
@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Progress is a resumable record of completed units, persisted as JSON after
+// every unit so an interrupted migration can be re-run without re-uploading.
+type Progress struct {
+	mu    sync.Mutex
+	path  string
+	Units map[string]ProgressEntry `json:"units"`
+}
+
+// ProgressEntry records the source checksum a unit was imported with
+type ProgressEntry struct {
+	Checksum   string    `json:"sha256"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// LoadProgress reads the progress file at path, or starts an empty one if it does not exist
+func LoadProgress(path string) (*Progress, error) {
+	p := &Progress{path: path, Units: map[string]ProgressEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+		return nil, fmt.Errorf("failed to read progress file: %w", err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse progress file %s: %w", path, err)
+	}
+	if p.Units == nil {
+		p.Units = map[string]ProgressEntry{}
+	}
+	return p, nil
+}
+
+// IsDone reports whether unit was already imported from a source with the same checksum.
+// A changed checksum means the source moved on and the unit is imported again.
+func (p *Progress) IsDone(unit, checksum string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.Units[unit]
+	return ok && entry.Checksum == checksum
+}
+
+// Imported reports whether unit was imported by an earlier run, whatever the checksum
+func (p *Progress) Imported(unit string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.Units[unit]
+	return ok
+}
+
+// MarkDone records a completed unit and flushes the file
+func (p *Progress) MarkDone(unit, checksum string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Units[unit] = ProgressEntry{Checksum: checksum, ImportedAt: time.Now().UTC()}
+	return p.save()
+}
+
+func (p *Progress) save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(p.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	// Write-then-rename so a crash never leaves a truncated progress file
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
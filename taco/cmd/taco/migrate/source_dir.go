@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirSource reads *.tfstate files from a local directory tree.
+//
+// Unit names are the path relative to the root without the .tfstate
+// extension; a file named terraform.tfstate takes its directory's name, so
+// both exported trees (app/prod.tfstate) and local-backend workspace trees
+// (terraform.tfstate.d/prod/terraform.tfstate) map to sensible units.
+// Directory sources have no history: each unit has a single version.
+type DirSource struct {
+	Root string
+}
+
+func (s *DirSource) Name() string { return "dir:" + s.Root }
+
+func (s *DirSource) ListUnits(ctx context.Context) ([]SourceUnit, error) {
+	var units []SourceUnit
+	err := filepath.WalkDir(s.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".tfstate") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		units = append(units, SourceUnit{
+			Name:     dirUnitName(rel),
+			Versions: []VersionRef{{ID: rel, Created: info.ModTime()}},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+
+	seen := map[string]string{}
+	for _, u := range units {
+		if prev, ok := seen[u.Name]; ok {
+			return nil, fmt.Errorf("files %s and %s both map to unit %q", prev, u.Current().ID, u.Name)
+		}
+		seen[u.Name] = u.Current().ID
+	}
+	return units, nil
+}
+
+func (s *DirSource) Fetch(ctx context.Context, unit SourceUnit, version VersionRef) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Root, version.ID))
+}
+
+func dirUnitName(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if filepath.Base(rel) == "terraform.tfstate" && filepath.Dir(rel) != "." {
+		rel = filepath.ToSlash(filepath.Dir(rel))
+		rel = strings.TrimPrefix(rel, "terraform.tfstate.d/")
+		return rel
+	}
+	return strings.TrimSuffix(rel, ".tfstate")
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Source reads states written by Terraform's s3 backend.
+//
+// Every object under Prefix whose key ends in .tfstate is a unit named after
+// its key (minus the extension). Workspace states stored by the backend under
+// "env:/<workspace>/<key>" become "<workspace>/<key>". If the bucket has
+// versioning enabled, previous object versions are imported as history.
+type S3Source struct {
+	Bucket string
+	Prefix string
+	client *s3.Client
+}
+
+// NewS3Source builds an S3 source using the default AWS credential chain.
+// AWS_ENDPOINT is honoured for S3-compatible stores, matching the statesman S3 store.
+func NewS3Source(ctx context.Context, bucket, prefix, region string) (*S3Source, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("AWS_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return &S3Source{Bucket: bucket, Prefix: strings.Trim(prefix, "/"), client: client}, nil
+}
+
+func (s *S3Source) Name() string {
+	if s.Prefix == "" {
+		return "s3://" + s.Bucket
+	}
+	return "s3://" + s.Bucket + "/" + s.Prefix
+}
+
+func (s *S3Source) ListUnits(ctx context.Context) ([]SourceUnit, error) {
+	byKey := map[string]*SourceUnit{}
+	deleted := map[string]bool{}
+
+	input := &s3.ListObjectVersionsInput{Bucket: aws.String(s.Bucket)}
+	if s.Prefix != "" {
+		input.Prefix = aws.String(s.Prefix + "/")
+	}
+
+	paginator := s3.NewListObjectVersionsPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range page.DeleteMarkers {
+			if aws.ToBool(m.IsLatest) {
+				deleted[aws.ToString(m.Key)] = true
+			}
+		}
+		for _, v := range page.Versions {
+			key := aws.ToString(v.Key)
+			if !strings.HasSuffix(key, ".tfstate") {
+				continue
+			}
+			u, ok := byKey[key]
+			if !ok {
+				u = &SourceUnit{Name: s.unitName(key)}
+				byKey[key] = u
+			}
+			u.Versions = append(u.Versions, VersionRef{
+				ID:      key + "?versionId=" + aws.ToString(v.VersionId),
+				Created: aws.ToTime(v.LastModified),
+			})
+		}
+	}
+
+	units := make([]SourceUnit, 0, len(byKey))
+	for key, u := range byKey {
+		// Objects whose latest version is a delete marker no longer exist
+		if deleted[key] {
+			continue
+		}
+		sort.Slice(u.Versions, func(i, j int) bool { return u.Versions[i].Created.Before(u.Versions[j].Created) })
+		units = append(units, *u)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+	return units, nil
+}
+
+func (s *S3Source) Fetch(ctx context.Context, unit SourceUnit, version VersionRef) ([]byte, error) {
+	key, versionID, _ := strings.Cut(version.ID, "?versionId=")
+	input := &s3.GetObjectInput{Bucket: aws.String(s.Bucket), Key: aws.String(key)}
+	// Unversioned buckets report the version ID "null"
+	if versionID != "" && versionID != "null" {
+		input.VersionId = aws.String(versionID)
+	}
+	out, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (s *S3Source) unitName(key string) string {
+	if s.Prefix != "" {
+		key = strings.TrimPrefix(key, s.Prefix+"/")
+	}
+	key = strings.TrimPrefix(key, "env:/")
+	return strings.TrimSuffix(key, ".tfstate")
+}
@@ -0,0 +1,159 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TFCSource reads workspace states from a Terraform Cloud / Terraform
+// Enterprise organization through the v2 API. Each workspace becomes a unit
+// and its state versions are the unit's history.
+type TFCSource struct {
+	Host         string // e.g. https://app.terraform.io
+	Organization string
+	Token        string
+	HTTPClient   *http.Client
+}
+
+// tfcPageSize is the largest page size the TFC API accepts
+const tfcPageSize = 100
+
+func (s *TFCSource) Name() string { return "tfc:" + s.Organization }
+
+type tfcListResponse struct {
+	Data []struct {
+		ID         string          `json:"id"`
+		Attributes json.RawMessage `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Pagination struct {
+			NextPage *int `json:"next-page"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+type tfcWorkspaceAttributes struct {
+	Name string `json:"name"`
+}
+
+type tfcStateVersionAttributes struct {
+	CreatedAt   time.Time `json:"created-at"`
+	DownloadURL string    `json:"hosted-state-download-url"`
+}
+
+func (s *TFCSource) ListUnits(ctx context.Context) ([]SourceUnit, error) {
+	var names []string
+	path := fmt.Sprintf("/api/v2/organizations/%s/workspaces", url.PathEscape(s.Organization))
+	err := s.paginate(ctx, path, url.Values{}, func(id string, raw json.RawMessage) error {
+		var attrs tfcWorkspaceAttributes
+		if err := json.Unmarshal(raw, &attrs); err != nil {
+			return err
+		}
+		names = append(names, attrs.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	var units []SourceUnit
+	for _, name := range names {
+		unit := SourceUnit{Name: name}
+		query := url.Values{}
+		query.Set("filter[organization][name]", s.Organization)
+		query.Set("filter[workspace][name]", name)
+		err := s.paginate(ctx, "/api/v2/state-versions", query, func(id string, raw json.RawMessage) error {
+			var attrs tfcStateVersionAttributes
+			if err := json.Unmarshal(raw, &attrs); err != nil {
+				return err
+			}
+			if attrs.DownloadURL == "" {
+				// Versions still being processed have no downloadable state yet
+				return nil
+			}
+			unit.Versions = append(unit.Versions, VersionRef{ID: attrs.DownloadURL, Created: attrs.CreatedAt})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list state versions for %s: %w", name, err)
+		}
+		if len(unit.Versions) == 0 {
+			continue
+		}
+		// The API returns newest first
+		sort.Slice(unit.Versions, func(i, j int) bool { return unit.Versions[i].Created.Before(unit.Versions[j].Created) })
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+func (s *TFCSource) Fetch(ctx context.Context, unit SourceUnit, version VersionRef) ([]byte, error) {
+	target := version.ID
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimRight(s.Host, "/") + target
+	}
+	resp, err := s.get(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s *TFCSource) paginate(ctx context.Context, path string, query url.Values, fn func(id string, attrs json.RawMessage) error) error {
+	page := 1
+	for {
+		query.Set("page[number]", fmt.Sprint(page))
+		query.Set("page[size]", fmt.Sprint(tfcPageSize))
+		resp, err := s.get(ctx, strings.TrimRight(s.Host, "/")+path+"?"+query.Encode())
+		if err != nil {
+			return err
+		}
+		var body tfcListResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		for _, item := range body.Data {
+			if err := fn(item.ID, item.Attributes); err != nil {
+				return err
+			}
+		}
+		if body.Meta.Pagination.NextPage == nil {
+			return nil
+		}
+		page = *body.Meta.Pagination.NextPage
+	}
+}
+
+func (s *TFCSource) get(ctx context.Context, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GET %s: HTTP %d: %s", req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
@@ -149,6 +149,7 @@ func RegisterInternalRoutes(e *echo.Echo, deps Dependencies) {
 	var runRepo domain.TFERunRepository
 	var planRepo domain.TFEPlanRepository
 	var configVerRepo domain.TFEConfigurationVersionRepository
+	var varRepo domain.TFEVariableRepository
 	
 	if deps.QueryStore != nil {
 		if db := repositories.GetDBFromQueryStore(deps.QueryStore); db != nil {
@@ -157,6 +158,7 @@ func RegisterInternalRoutes(e *echo.Echo, deps Dependencies) {
 			runRepo = repositories.NewTFERunRepository(db)
 			planRepo = repositories.NewTFEPlanRepository(db)
 			configVerRepo = repositories.NewTFEConfigurationVersionRepository(db)
+			varRepo = repositories.NewTFEVariableRepository(db, deps.ValueSealer)
 			log.Println("TFE repositories initialized successfully (internal routes)")
 		}
 	}
@@ -175,6 +177,7 @@ func RegisterInternalRoutes(e *echo.Echo, deps Dependencies) {
 		configVerRepo,
 		deps.Sandbox,
 		remoteRunActivityRepo,
+		varRepo,
 	)
 	
 	// TFE group with webhook auth (for UI pass-through)
@@ -203,8 +206,13 @@ func RegisterInternalRoutes(e *echo.Echo, deps Dependencies) {
 	tfeInternal.POST("/workspaces/:workspace_name/configuration-versions", tfeHandler.CreateConfigurationVersions)
 	tfeInternal.GET("/configuration-versions/:id", tfeHandler.GetConfigurationVersion)
 	tfeInternal.POST("/runs", tfeHandler.CreateRun)
+	tfeInternal.GET("/workspaces/:workspace_id/runs", tfeHandler.ListWorkspaceRuns)
+	tfeInternal.GET("/organizations/:org_name/runs/queue", tfeHandler.GetRunQueue)
 	tfeInternal.GET("/runs/:id", tfeHandler.GetRun)
 	tfeInternal.POST("/runs/:id/actions/apply", tfeHandler.ApplyRun)
+	tfeInternal.POST("/runs/:id/actions/cancel", tfeHandler.CancelRun)
+	tfeInternal.POST("/runs/:id/actions/discard", tfeHandler.DiscardRun)
+	tfeInternal.POST("/runs/:id/actions/force-cancel", tfeHandler.ForceCancelRun)
 	tfeInternal.GET("/runs/:id/policy-checks", tfeHandler.GetPolicyChecks)
 	tfeInternal.GET("/runs/:id/task-stages", tfeHandler.GetTaskStages)
 	tfeInternal.GET("/runs/:id/cost-estimates", tfeHandler.GetCostEstimates)
@@ -213,6 +221,28 @@ func RegisterInternalRoutes(e *echo.Echo, deps Dependencies) {
 	tfeInternal.GET("/applies/:id", tfeHandler.GetApply)
 	tfeInternal.GET("/applies/:id/logs", tfeHandler.GetApplyLogs)
 
+	// Variable routes
+	tfeInternal.GET("/workspaces/:workspace_id/vars", tfeHandler.ListWorkspaceVariables)
+	tfeInternal.POST("/workspaces/:workspace_id/vars", tfeHandler.CreateWorkspaceVariable)
+	tfeInternal.GET("/workspaces/:workspace_id/vars/:var_id", tfeHandler.GetWorkspaceVariable)
+	tfeInternal.PATCH("/workspaces/:workspace_id/vars/:var_id", tfeHandler.UpdateWorkspaceVariable)
+	tfeInternal.DELETE("/workspaces/:workspace_id/vars/:var_id", tfeHandler.DeleteWorkspaceVariable)
+	tfeInternal.GET("/workspaces/:workspace_id/varsets", tfeHandler.ListWorkspaceVariableSets)
+
+	// Variable set routes
+	tfeInternal.GET("/organizations/:org_name/varsets", tfeHandler.ListVariableSets)
+	tfeInternal.POST("/organizations/:org_name/varsets", tfeHandler.CreateVariableSet)
+	tfeInternal.GET("/varsets/:varset_id", tfeHandler.GetVariableSet)
+	tfeInternal.PATCH("/varsets/:varset_id", tfeHandler.UpdateVariableSet)
+	tfeInternal.DELETE("/varsets/:varset_id", tfeHandler.DeleteVariableSet)
+	tfeInternal.GET("/varsets/:varset_id/relationships/vars", tfeHandler.ListVariableSetVariables)
+	tfeInternal.POST("/varsets/:varset_id/relationships/vars", tfeHandler.CreateVariableSetVariable)
+	tfeInternal.GET("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.GetVariableSetVariable)
+	tfeInternal.PATCH("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.UpdateVariableSetVariable)
+	tfeInternal.DELETE("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.DeleteVariableSetVariable)
+	tfeInternal.POST("/varsets/:varset_id/relationships/workspaces", tfeHandler.ApplyVariableSetToWorkspaces)
+	tfeInternal.DELETE("/varsets/:varset_id/relationships/workspaces", tfeHandler.RemoveVariableSetFromWorkspaces)
+
 	log.Println("TFE API endpoints registered at /internal/tfe/api/v2 with webhook auth")
 	
 	// ====================================================================================
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/diggerhq/digger/opentaco/internal/analytics"
+	"github.com/diggerhq/digger/opentaco/internal/audit"
 	"github.com/diggerhq/digger/opentaco/internal/tfe"
 
 	authpkg "github.com/diggerhq/digger/opentaco/internal/auth"
@@ -38,6 +39,7 @@ type Dependencies struct {
 	Signer              *authpkg.Signer       // JWT signing (auth, middleware)
 	AuthEnabled         bool                  // Whether auth is enabled
 	Sandbox             sandbox.Sandbox       // Optional sandbox provider for remote runs
+	ValueSealer         domain.ValueSealer    // Encrypts TFE variable values (nil disables variables)
 }
 
 // RegisterRoutes registers all API routes with interface-scoped dependencies.
@@ -54,6 +56,25 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 	stateOps := domain.StateOperations(deps.Repository)
 	unitMgmt := domain.UnitManagement(deps.Repository)
 	
+	// Audit log: records mutations across the management, backend, S3-compat, TFE and RBAC surfaces.
+	// Registered on the Echo instance so it sees the principal/org set by group middleware.
+	// Without auth no request carries an org, so events are recorded and served for the default org.
+	var auditRepo domain.AuditRepository
+	var auditDefaultOrgID string
+	if deps.QueryStore != nil {
+		if db := repositories.GetDBFromQueryStore(deps.QueryStore); db != nil {
+			auditRepo = repositories.NewAuditRepository(db)
+			if !deps.AuthEnabled {
+				orgID, err := repositories.GetDefaultOrgUUID(context.Background(), db)
+				if err != nil {
+					log.Printf("WARNING: default organization not found, audit log is unavailable without auth: %v", err)
+				}
+				auditDefaultOrgID = orgID
+			}
+			e.Use(audit.Middleware(audit.NewRecorder(auditRepo), auditDefaultOrgID))
+		}
+	}
+
 	// Health checks
 	health := observability.NewHealthHandler()
 	e.GET("/healthz", health.Healthz)
@@ -198,6 +219,18 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 		v1.POST("/units/:id/restore", unitHandler.RestoreVersion)
 	}
 
+	// Audit log query/export
+	if auditRepo != nil {
+		auditHandler := audit.NewHandler(auditRepo, auditDefaultOrgID)
+		if deps.AuthEnabled {
+			v1.GET("/audit", middleware.JWTOnlyRBACMiddleware(deps.RBACManager, deps.Signer, rbac.ActionAuditRead, "*")(auditHandler.ListEvents))
+			v1.GET("/audit/verify", middleware.JWTOnlyRBACMiddleware(deps.RBACManager, deps.Signer, rbac.ActionAuditRead, "*")(auditHandler.VerifyChain))
+		} else {
+			v1.GET("/audit", auditHandler.ListEvents)
+			v1.GET("/audit/verify", auditHandler.VerifyChain)
+		}
+	}
+
 	// Terraform HTTP backend proxy
 	// Uses StateOperations interface (6 methods)
 	backendHandler := backend.NewHandler(stateOps)
@@ -258,6 +291,7 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 	var planRepo domain.TFEPlanRepository
 	var configVerRepo domain.TFEConfigurationVersionRepository
 	var remoteRunActivityRepo domain.RemoteRunActivityRepository
+	var varRepo domain.TFEVariableRepository
 	
 	if deps.QueryStore != nil {
 		if db := repositories.GetDBFromQueryStore(deps.QueryStore); db != nil {
@@ -267,6 +301,7 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 			planRepo = repositories.NewTFEPlanRepository(db)
 			configVerRepo = repositories.NewTFEConfigurationVersionRepository(db)
 			remoteRunActivityRepo = repositories.NewRemoteRunActivityRepository(db)
+			varRepo = repositories.NewTFEVariableRepository(db, deps.ValueSealer)
 			log.Println("TFE repositories initialized successfully")
 		}
 	}
@@ -283,6 +318,7 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 		configVerRepo,
 		deps.Sandbox,
 		remoteRunActivityRepo,
+		varRepo,
 	)
 
 	// Create protected TFE group - opaque tokens only
@@ -300,6 +336,7 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 	tfeGroup.POST("/workspaces/:workspace_id/actions/lock", tfeHandler.LockWorkspace)
 	tfeGroup.POST("/workspaces/:workspace_id/actions/unlock", tfeHandler.UnlockWorkspace)
 	tfeGroup.POST("/workspaces/:workspace_id/actions/force-unlock", tfeHandler.ForceUnlockWorkspace)
+	tfeGroup.POST("/workspaces/:workspace_id/actions/vcs-push", tfeHandler.TriggerVCSRun)
 	tfeGroup.GET("/workspaces/:workspace_id/current-state-version", tfeHandler.GetCurrentStateVersion)
 	tfeGroup.POST("/workspaces/:workspace_id/state-versions", tfeHandler.CreateStateVersion)
 	tfeGroup.GET("/state-versions/:id", tfeHandler.ShowStateVersion)
@@ -310,8 +347,13 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 	
 	// Run routes
 	tfeGroup.POST("/runs", tfeHandler.CreateRun)
+	tfeGroup.GET("/workspaces/:workspace_id/runs", tfeHandler.ListWorkspaceRuns)
+	tfeGroup.GET("/organizations/:org_name/runs/queue", tfeHandler.GetRunQueue)
 	tfeGroup.GET("/runs/:id", tfeHandler.GetRun)
 	tfeGroup.POST("/runs/:id/actions/apply", tfeHandler.ApplyRun)
+	tfeGroup.POST("/runs/:id/actions/cancel", tfeHandler.CancelRun)
+	tfeGroup.POST("/runs/:id/actions/discard", tfeHandler.DiscardRun)
+	tfeGroup.POST("/runs/:id/actions/force-cancel", tfeHandler.ForceCancelRun)
 	tfeGroup.GET("/runs/:id/policy-checks", tfeHandler.GetPolicyChecks)
 	tfeGroup.GET("/runs/:id/task-stages", tfeHandler.GetTaskStages)
 	tfeGroup.GET("/runs/:id/cost-estimates", tfeHandler.GetCostEstimates)
@@ -325,6 +367,28 @@ func RegisterRoutes(e *echo.Echo, deps Dependencies) {
 	// Apply routes
 	tfeGroup.GET("/applies/:id", tfeHandler.GetApply)
 
+	// Variable routes
+	tfeGroup.GET("/workspaces/:workspace_id/vars", tfeHandler.ListWorkspaceVariables)
+	tfeGroup.POST("/workspaces/:workspace_id/vars", tfeHandler.CreateWorkspaceVariable)
+	tfeGroup.GET("/workspaces/:workspace_id/vars/:var_id", tfeHandler.GetWorkspaceVariable)
+	tfeGroup.PATCH("/workspaces/:workspace_id/vars/:var_id", tfeHandler.UpdateWorkspaceVariable)
+	tfeGroup.DELETE("/workspaces/:workspace_id/vars/:var_id", tfeHandler.DeleteWorkspaceVariable)
+	tfeGroup.GET("/workspaces/:workspace_id/varsets", tfeHandler.ListWorkspaceVariableSets)
+
+	// Variable set routes
+	tfeGroup.GET("/organizations/:org_name/varsets", tfeHandler.ListVariableSets)
+	tfeGroup.POST("/organizations/:org_name/varsets", tfeHandler.CreateVariableSet)
+	tfeGroup.GET("/varsets/:varset_id", tfeHandler.GetVariableSet)
+	tfeGroup.PATCH("/varsets/:varset_id", tfeHandler.UpdateVariableSet)
+	tfeGroup.DELETE("/varsets/:varset_id", tfeHandler.DeleteVariableSet)
+	tfeGroup.GET("/varsets/:varset_id/relationships/vars", tfeHandler.ListVariableSetVariables)
+	tfeGroup.POST("/varsets/:varset_id/relationships/vars", tfeHandler.CreateVariableSetVariable)
+	tfeGroup.GET("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.GetVariableSetVariable)
+	tfeGroup.PATCH("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.UpdateVariableSetVariable)
+	tfeGroup.DELETE("/varsets/:varset_id/relationships/vars/:var_id", tfeHandler.DeleteVariableSetVariable)
+	tfeGroup.POST("/varsets/:varset_id/relationships/workspaces", tfeHandler.ApplyVariableSetToWorkspaces)
+	tfeGroup.DELETE("/varsets/:varset_id/relationships/workspaces", tfeHandler.RemoveVariableSetFromWorkspaces)
+
 	// Upload endpoints exempt from auth middleware (Terraform doesn't send auth headers)
 	// Security: These validate lock ownership and have RBAC checks in handlers
 	// Upload URLs can only be obtained from authenticated CreateStateVersion calls
@@ -0,0 +1,213 @@
+// Package audit records a tamper-evident log of who did what to which
+// resource. Unlike analytics (anonymous product telemetry), audit events are
+// stored in the query store, scoped to an organization and hash-chained so
+// that removing or editing a row can be detected with Verify.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+)
+
+// Action names recorded in the audit log
+const (
+	ActionUnitCreate      = "unit.create"
+	ActionUnitUpdate      = "unit.update"
+	ActionUnitDelete      = "unit.delete"
+	ActionStateDownload   = "state.download"
+	ActionStateUpload     = "state.upload"
+	ActionUnitLock        = "unit.lock"
+	ActionUnitUnlock      = "unit.unlock"
+	ActionUnitForceUnlock = "unit.force_unlock"
+	ActionVersionRestore  = "unit.version_restore"
+	ActionRunCreate       = "run.create"
+	ActionRunApply        = "run.apply"
+	ActionRBACInit        = "rbac.init"
+	ActionRBACAssignRole  = "rbac.role_assign"
+	ActionRBACRevokeRole  = "rbac.role_revoke"
+	ActionRBACRoleCreate  = "rbac.role_create"
+	ActionRBACRoleDelete  = "rbac.role_delete"
+	ActionRBACPermCreate  = "rbac.permission_create"
+	ActionRBACPermDelete  = "rbac.permission_delete"
+	ActionRBACPermAssign  = "rbac.role_permission_assign"
+	ActionRBACPermRevoke  = "rbac.role_permission_revoke"
+)
+
+// Outcome values
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+// maxAppendAttempts bounds retries when another writer claims the same sequence number
+const maxAppendAttempts = 5
+
+// Recorder appends events to the per-org hash chain.
+type Recorder struct {
+	repo domain.AuditRepository
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewRecorder creates a recorder backed by the given repository
+func NewRecorder(repo domain.AuditRepository) *Recorder {
+	return &Recorder{repo: repo, now: time.Now}
+}
+
+// Record links the event to the end of its org's chain and persists it.
+// Seq, PrevHash, Hash and (if unset) Timestamp are filled in by the recorder.
+func (r *Recorder) Record(ctx context.Context, event *domain.AuditEvent) error {
+	if r == nil || r.repo == nil {
+		return nil
+	}
+	if event.OrgID == "" {
+		return errors.New("audit event requires an org ID")
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = r.now()
+	}
+	// Databases keep at most microsecond precision; hash what will be read back
+	event.Timestamp = event.Timestamp.UTC().Truncate(time.Microsecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		last, err := r.repo.Last(ctx, event.OrgID)
+		if err != nil {
+			return err
+		}
+		event.Seq = 1
+		event.PrevHash = ""
+		if last != nil {
+			event.Seq = last.Seq + 1
+			event.PrevHash = last.Hash
+		}
+		event.Hash = ComputeHash(event)
+
+		// Another instance may have appended in between; the unique index on
+		// (org_id, seq) rejects our write and we re-read the chain head.
+		if lastErr = r.repo.Append(ctx, event); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to append audit event after %d attempts: %w", maxAppendAttempts, lastErr)
+}
+
+// ComputeHash returns the chain hash of an event: sha256 over the previous
+// hash and every recorded field in a fixed order.
+func ComputeHash(event *domain.AuditEvent) string {
+	var b strings.Builder
+	fields := []string{
+		event.PrevHash,
+		event.OrgID,
+		strconv.FormatInt(event.Seq, 10),
+		event.Timestamp.UTC().Format(time.RFC3339Nano),
+		event.Actor,
+		event.Action,
+		event.Resource,
+		event.SourceIP,
+		event.Outcome,
+		strconv.Itoa(event.Status),
+		event.RequestID,
+	}
+	for _, f := range fields {
+		b.WriteString(strconv.Quote(f))
+		b.WriteByte('\n')
+	}
+
+	keys := make([]string, 0, len(event.Details))
+	for k := range event.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(strconv.Quote(k))
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(event.Details[k]))
+		b.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyResult describes the integrity of an org's audit chain
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	LastSeq  int64  `json:"last_seq"`
+	LastHash string `json:"last_hash,omitempty"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// verifyPageSize is how many events Verify reads per query
+const verifyPageSize = 500
+
+// Verify walks the whole chain for an org and reports the first gap,
+// edited event or broken link. Truncation of the newest events cannot be
+// detected from the chain alone; compare LastSeq/LastHash with an exported copy.
+func Verify(ctx context.Context, repo domain.AuditRepository, orgID string) (*VerifyResult, error) {
+	result := &VerifyResult{Valid: true}
+	var prevHash string
+	var prevSeq int64
+
+	for {
+		events, err := repo.List(ctx, domain.AuditFilter{OrgID: orgID, AfterSeq: prevSeq, Limit: verifyPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			switch {
+			case e.Seq != prevSeq+1:
+				return broken(result, prevSeq+1, fmt.Sprintf("missing events: expected seq %d, found %d", prevSeq+1, e.Seq)), nil
+			case e.PrevHash != prevHash:
+				return broken(result, e.Seq, "prev_hash does not match the preceding event"), nil
+			case ComputeHash(e) != e.Hash:
+				return broken(result, e.Seq, "event contents do not match its hash"), nil
+			}
+			prevHash = e.Hash
+			prevSeq = e.Seq
+			result.Checked++
+		}
+		if len(events) < verifyPageSize {
+			break
+		}
+	}
+
+	result.LastSeq = prevSeq
+	result.LastHash = prevHash
+	return result, nil
+}
+
+func broken(result *VerifyResult, seq int64, reason string) *VerifyResult {
+	result.Valid = false
+	result.BrokenAt = seq
+	result.Reason = reason
+	return result
+}
+
+// OutcomeForStatus maps an HTTP status code to an audit outcome
+func OutcomeForStatus(status int) string {
+	switch {
+	case status == 401 || status == 403:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
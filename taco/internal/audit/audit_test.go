@@ -0,0 +1,228 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/rbac"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memRepo is an in-memory AuditRepository that enforces (org_id, seq) uniqueness
+type memRepo struct {
+	mu     sync.Mutex
+	events []*domain.AuditEvent
+}
+
+func (m *memRepo) Append(ctx context.Context, event *domain.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.events {
+		if e.OrgID == event.OrgID && e.Seq == event.Seq {
+			return errors.New("duplicate seq")
+		}
+	}
+	copied := *event
+	m.events = append(m.events, &copied)
+	return nil
+}
+
+func (m *memRepo) Last(ctx context.Context, orgID string) (*domain.AuditEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var last *domain.AuditEvent
+	for _, e := range m.events {
+		if e.OrgID == orgID && (last == nil || e.Seq > last.Seq) {
+			last = e
+		}
+	}
+	return last, nil
+}
+
+func (m *memRepo) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.AuditEvent
+	for _, e := range m.events {
+		if e.OrgID != filter.OrgID || e.Seq <= filter.AfterSeq {
+			continue
+		}
+		if filter.Action != nil && e.Action != *filter.Action {
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+func recordN(t *testing.T, rec *Recorder, orgID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := rec.Record(context.Background(), &domain.AuditEvent{
+			OrgID:    orgID,
+			Actor:    "alice@example.com",
+			Action:   ActionStateUpload,
+			Resource: "myapp/prod",
+			Outcome:  OutcomeSuccess,
+			Status:   http.StatusOK,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestRecorder_ChainsEventsPerOrg(t *testing.T) {
+	repo := &memRepo{}
+	rec := NewRecorder(repo)
+
+	recordN(t, rec, "org-a", 3)
+	recordN(t, rec, "org-b", 2)
+
+	events, err := repo.List(context.Background(), domain.AuditFilter{OrgID: "org-a"})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, int64(1), events[0].Seq)
+	assert.Empty(t, events[0].PrevHash)
+	for i := 1; i < len(events); i++ {
+		assert.Equal(t, int64(i+1), events[i].Seq)
+		assert.Equal(t, events[i-1].Hash, events[i].PrevHash)
+	}
+
+	result, err := Verify(context.Background(), repo, "org-b")
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(2), result.Checked)
+}
+
+func TestVerify_DetectsDeletedEvent(t *testing.T) {
+	repo := &memRepo{}
+	recordN(t, NewRecorder(repo), "org-a", 4)
+
+	// Remove seq 2
+	repo.events = append(repo.events[:1], repo.events[2:]...)
+
+	result, err := Verify(context.Background(), repo, "org-a")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenAt)
+}
+
+func TestVerify_DetectsEditedEvent(t *testing.T) {
+	repo := &memRepo{}
+	recordN(t, NewRecorder(repo), "org-a", 3)
+
+	repo.events[1].Actor = "mallory@example.com"
+
+	result, err := Verify(context.Background(), repo, "org-a")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenAt)
+}
+
+func TestVerify_DetectsRehashedEvent(t *testing.T) {
+	repo := &memRepo{}
+	recordN(t, NewRecorder(repo), "org-a", 3)
+
+	// Editing an event and recomputing its own hash still breaks the next link
+	repo.events[0].Outcome = OutcomeFailure
+	repo.events[0].Hash = ComputeHash(repo.events[0])
+
+	result, err := Verify(context.Background(), repo, "org-a")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenAt)
+}
+
+func TestMiddleware_RecordsAuditedRoutes(t *testing.T) {
+	repo := &memRepo{}
+	e := echo.New()
+	e.Use(Middleware(NewRecorder(repo), ""))
+
+	setIdentity := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := rbac.ContextWithPrincipal(c.Request().Context(), rbac.Principal{Subject: "alice"})
+			ctx = domain.ContextWithOrg(ctx, "org-a")
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+
+	v1 := e.Group("/v1", setIdentity)
+	v1.POST("/units/:id/restore", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	v1.DELETE("/units/:id/unlock", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusForbidden, "nope")
+	})
+	v1.GET("/units/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, r := range []struct{ method, path string }{
+		{http.MethodPost, "/v1/units/u1/restore"},
+		{http.MethodDelete, "/v1/units/u1/unlock"},
+		{http.MethodGet, "/v1/units/u1"}, // not audited
+	} {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-1")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events, err := repo.List(context.Background(), domain.AuditFilter{OrgID: "org-a"})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, ActionVersionRestore, events[0].Action)
+	assert.Equal(t, "alice", events[0].Actor)
+	assert.Equal(t, "u1", events[0].Resource)
+	assert.Equal(t, OutcomeSuccess, events[0].Outcome)
+	assert.Equal(t, "req-1", events[0].RequestID)
+
+	assert.Equal(t, ActionUnitUnlock, events[1].Action)
+	assert.Equal(t, OutcomeDenied, events[1].Outcome)
+	assert.Equal(t, http.StatusForbidden, events[1].Status)
+}
+
+func TestHandler_DefaultOrgOnlyWhenConfigured(t *testing.T) {
+	repo := &memRepo{}
+	recorder := NewRecorder(repo)
+	require.NoError(t, recorder.Record(context.Background(), &domain.AuditEvent{OrgID: "org-default", Action: ActionUnitCreate, Outcome: OutcomeSuccess}))
+
+	list := func(h *Handler) *httptest.ResponseRecorder {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ListEvents(e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/audit", nil), rec)))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, list(NewHandler(repo, "")).Code)
+
+	rec := list(NewHandler(repo, "org-default"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"count":1`)
+}
+
+func TestLookupRoute_StripsSurfacePrefixes(t *testing.T) {
+	for path, want := range map[string]string{
+		"/tfe/api/v2/workspaces/:workspace_id/actions/force-unlock":          ActionUnitForceUnlock,
+		"/internal/tfe/api/v2/workspaces/:workspace_id/actions/force-unlock": ActionUnitForceUnlock,
+		"/internal/api/units/:id/restore":                                    ActionVersionRestore,
+		"/v1/rbac/roles":                                                     ActionRBACRoleCreate,
+	} {
+		rt, ok := lookupRoute(http.MethodPost, path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, rt.action, path)
+	}
+}
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/logging"
+	"github.com/labstack/echo/v4"
+)
+
+// maxListLimit caps a single page of the JSON listing
+const maxListLimit = 1000
+
+// exportPageSize is the page size used while streaming a JSON lines export
+const exportPageSize = 500
+
+// Handler serves the audit log query, export and verification API.
+type Handler struct {
+	repo         domain.AuditRepository
+	defaultOrgID string
+}
+
+// NewHandler creates the audit API handler. defaultOrgID is served to requests
+// without an org context, i.e. when auth is disabled; pass "" to reject them.
+func NewHandler(repo domain.AuditRepository, defaultOrgID string) *Handler {
+	return &Handler{repo: repo, defaultOrgID: defaultOrgID}
+}
+
+// orgID returns the org of the request, or the explicit default org
+func (h *Handler) orgID(c echo.Context) (string, bool) {
+	if orgCtx, ok := domain.OrgFromContext(c.Request().Context()); ok && orgCtx.OrgID != "" {
+		return orgCtx.OrgID, true
+	}
+	return h.defaultOrgID, h.defaultOrgID != ""
+}
+
+// ListEvents handles GET /v1/audit
+//
+// Query parameters: actor, action, resource, outcome, since, until (RFC3339),
+// limit, offset. With format=jsonl the full filtered log is streamed as JSON
+// lines (one event per line) for export; limit/offset are ignored.
+func (h *Handler) ListEvents(c echo.Context) error {
+	logger := logging.FromContext(c)
+
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization context missing"})
+	}
+
+	filter, err := parseFilter(c, orgID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if c.QueryParam("format") == "jsonl" {
+		return h.export(c, filter)
+	}
+
+	events, err := h.repo.List(c.Request().Context(), filter)
+	if err != nil {
+		logger.Error("Failed to list audit events",
+			"operation", "list_audit_events",
+			"org_id", filter.OrgID,
+			"error", err,
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list audit events"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+// export streams every matching event as JSON lines
+func (h *Handler) export(c echo.Context, filter domain.AuditFilter) error {
+	logger := logging.FromContext(c)
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	resp.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	resp.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(resp)
+	filter.Limit = exportPageSize
+	filter.Offset = 0
+	for {
+		events, err := h.repo.List(c.Request().Context(), filter)
+		if err != nil {
+			// Headers are already sent; the truncated stream is the only signal left
+			logger.Error("Failed to export audit events",
+				"operation", "export_audit_events",
+				"org_id", filter.OrgID,
+				"error", err,
+			)
+			return nil
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return nil
+			}
+		}
+		resp.Flush()
+		if len(events) < exportPageSize {
+			return nil
+		}
+		filter.AfterSeq = events[len(events)-1].Seq
+	}
+}
+
+// VerifyChain handles GET /v1/audit/verify
+func (h *Handler) VerifyChain(c echo.Context) error {
+	logger := logging.FromContext(c)
+
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization context missing"})
+	}
+
+	result, err := Verify(c.Request().Context(), h.repo, orgID)
+	if err != nil {
+		logger.Error("Failed to verify audit chain",
+			"operation", "verify_audit_chain",
+			"org_id", orgID,
+			"error", err,
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to verify audit chain"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func parseFilter(c echo.Context, orgID string) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{OrgID: orgID, Limit: 100}
+
+	for name, dst := range map[string]**string{
+		"actor":    &filter.Actor,
+		"action":   &filter.Action,
+		"resource": &filter.Resource,
+		"outcome":  &filter.Outcome,
+	} {
+		if v := c.QueryParam(name); v != "" {
+			value := v
+			*dst = &value
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"since": &filter.Since,
+		"until": &filter.Until,
+	} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: expected RFC3339 timestamp", name)
+			}
+			*dst = &t
+		}
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return filter, errors.New("invalid limit")
+		}
+		if n > maxListLimit {
+			n = maxListLimit
+		}
+		filter.Limit = n
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, errors.New("invalid offset")
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
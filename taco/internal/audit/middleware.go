@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/rbac"
+	"github.com/labstack/echo/v4"
+)
+
+// route describes how a matched route is recorded
+type route struct {
+	action string
+	param  string // Path parameter holding the resource identifier ("" for none)
+}
+
+// routePrefixes are stripped from the registered path before lookup so the same
+// table covers the public, internal (webhook) and TFE surfaces.
+var routePrefixes = []string{
+	"/internal/tfe/api/v2",
+	"/tfe/api/v2",
+	"/internal/api",
+	"/v1",
+}
+
+// auditedRoutes maps "METHOD path" (with surface prefix removed) to the audit action.
+// Read-only routes are not recorded, except state downloads which expose secrets.
+var auditedRoutes = map[string]route{
+	// Unit management
+	"POST /units":              {ActionUnitCreate, ""},
+	"PATCH /units/:id":         {ActionUnitUpdate, "id"},
+	"DELETE /units/:id":        {ActionUnitDelete, "id"},
+	"GET /units/:id/download":  {ActionStateDownload, "id"},
+	"POST /units/:id/upload":   {ActionStateUpload, "id"},
+	"POST /units/:id/lock":     {ActionUnitLock, "id"},
+	"DELETE /units/:id/unlock": {ActionUnitUnlock, "id"},
+	"POST /units/:id/restore":  {ActionVersionRestore, "id"},
+
+	// Terraform HTTP backend
+	"GET /backend/*":    {ActionStateDownload, "*"},
+	"POST /backend/*":   {ActionStateUpload, "*"},
+	"PUT /backend/*":    {ActionStateUpload, "*"},
+	"LOCK /backend/*":   {ActionUnitLock, "*"},
+	"UNLOCK /backend/*": {ActionUnitUnlock, "*"},
+
+	// S3-compatible surface
+	"GET /s3/*":    {ActionStateDownload, "*"},
+	"PUT /s3/*":    {ActionStateUpload, "*"},
+	"DELETE /s3/*": {ActionUnitUnlock, "*"},
+
+	// TFE surface
+	"POST /workspaces/:workspace_id/actions/lock":         {ActionUnitLock, "workspace_id"},
+	"POST /workspaces/:workspace_id/actions/unlock":       {ActionUnitUnlock, "workspace_id"},
+	"POST /workspaces/:workspace_id/actions/force-unlock": {ActionUnitForceUnlock, "workspace_id"},
+	"GET /state-versions/:id/download":                    {ActionStateDownload, "id"},
+	"PUT /state-versions/:id/upload":                      {ActionStateUpload, "id"},
+	"POST /runs":                                          {ActionRunCreate, ""},
+	"POST /runs/:id/actions/apply":                        {ActionRunApply, "id"},
+
+	// RBAC
+	"POST /rbac/init":                                  {ActionRBACInit, ""},
+	"POST /rbac/users/assign":                          {ActionRBACAssignRole, ""},
+	"POST /rbac/users/revoke":                          {ActionRBACRevokeRole, ""},
+	"POST /rbac/assign":                                {ActionRBACAssignRole, ""},
+	"POST /rbac/revoke":                                {ActionRBACRevokeRole, ""},
+	"POST /rbac/roles":                                 {ActionRBACRoleCreate, ""},
+	"DELETE /rbac/roles/:id":                           {ActionRBACRoleDelete, "id"},
+	"POST /rbac/roles/:id/permissions":                 {ActionRBACPermAssign, "id"},
+	"DELETE /rbac/roles/:id/permissions/:permissionId": {ActionRBACPermRevoke, "id"},
+	"POST /rbac/permissions":                           {ActionRBACPermCreate, ""},
+	"DELETE /rbac/permissions/:id":                     {ActionRBACPermDelete, "id"},
+}
+
+// lookupRoute finds the audit rule for a registered echo path
+func lookupRoute(method, path string) (route, bool) {
+	for _, prefix := range routePrefixes {
+		if strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	r, ok := auditedRoutes[method+" "+path]
+	return r, ok
+}
+
+// Middleware records an audit event for every audited route once the handler
+// has finished, so that the outcome reflects the actual response. It must be
+// registered on the Echo instance (not a group) and reads the principal and
+// org that the group-level auth middleware stored on the request. Requests
+// without an org are recorded under defaultOrgID, which is only set when auth
+// is disabled and every request belongs to the default organization.
+// Audit write failures are logged but never fail the request.
+func Middleware(recorder *Recorder, defaultOrgID string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if recorder == nil {
+				return next(c)
+			}
+
+			handlerErr := next(c)
+
+			rt, ok := lookupRoute(c.Request().Method, c.Path())
+			if !ok {
+				return handlerErr
+			}
+
+			status := c.Response().Status
+			if handlerErr != nil {
+				status = http.StatusInternalServerError
+				var httpErr *echo.HTTPError
+				if errors.As(handlerErr, &httpErr) {
+					status = httpErr.Code
+				}
+			}
+
+			event := &domain.AuditEvent{
+				OrgID:     orgFromRequest(c, defaultOrgID),
+				Actor:     actorFromRequest(c),
+				Action:    rt.action,
+				Resource:  resourceFromRequest(c, rt.param),
+				SourceIP:  c.RealIP(),
+				Outcome:   OutcomeForStatus(status),
+				Status:    status,
+				RequestID: requestIDFromRequest(c),
+				Details: map[string]string{
+					"method": c.Request().Method,
+					"path":   c.Request().URL.Path,
+				},
+			}
+
+			if err := recorder.Record(c.Request().Context(), event); err != nil {
+				slog.Error("Failed to record audit event",
+					"action", event.Action,
+					"resource", event.Resource,
+					"request_id", event.RequestID,
+					"error", err,
+				)
+			}
+
+			return handlerErr
+		}
+	}
+}
+
+func orgFromRequest(c echo.Context, defaultOrgID string) string {
+	if orgCtx, ok := domain.OrgFromContext(c.Request().Context()); ok && orgCtx.OrgID != "" {
+		return orgCtx.OrgID
+	}
+	if org, ok := c.Get("jwt_org").(string); ok && org != "" {
+		return org
+	}
+	if org, ok := c.Get("organization_id").(string); ok && org != "" {
+		return org
+	}
+	return defaultOrgID
+}
+
+func actorFromRequest(c echo.Context) string {
+	if p, ok := rbac.PrincipalFromContext(c.Request().Context()); ok {
+		if p.Subject != "" {
+			return p.Subject
+		}
+		if p.Email != "" {
+			return p.Email
+		}
+	}
+	if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return "anonymous"
+}
+
+func resourceFromRequest(c echo.Context, param string) string {
+	if param == "" {
+		return c.Request().URL.Path
+	}
+	if v := c.Param(param); v != "" {
+		return v
+	}
+	return c.Request().URL.Path
+}
+
+func requestIDFromRequest(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
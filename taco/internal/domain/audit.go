@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ============================================
+// Audit Log
+// ============================================
+
+// AuditEvent is a single tamper-evident record of a mutation (or sensitive read)
+// performed against OpenTaco. Events are chained per organization: each event
+// carries the hash of its predecessor so a deleted or edited row breaks the chain.
+type AuditEvent struct {
+	ID        string            `json:"id"`
+	OrgID     string            `json:"org_id"`
+	Seq       int64             `json:"seq"`
+	Timestamp time.Time         `json:"timestamp"`
+	Actor     string            `json:"actor"`
+	Action    string            `json:"action"`
+	Resource  string            `json:"resource"`
+	SourceIP  string            `json:"source_ip"`
+	Outcome   string            `json:"outcome"`
+	Status    int               `json:"status"`
+	RequestID string            `json:"request_id"`
+	Details   map[string]string `json:"details,omitempty"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// AuditFilter narrows down audit queries. OrgID is always required.
+type AuditFilter struct {
+	OrgID    string
+	Actor    *string
+	Action   *string
+	Resource *string
+	Outcome  *string
+	Since    *time.Time
+	Until    *time.Time
+	AfterSeq int64 // Only return events with seq > AfterSeq (used for chain verification paging)
+	Limit    int
+	Offset   int
+}
+
+// AuditRepository persists audit events in the query store.
+// Append must fail if an event with the same (org_id, seq) already exists
+// so concurrent writers cannot fork the hash chain.
+type AuditRepository interface {
+	Append(ctx context.Context, event *AuditEvent) error
+	Last(ctx context.Context, orgID string) (*AuditEvent, error) // Returns nil, nil when the org has no events
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+}
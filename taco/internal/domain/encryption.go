@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ============================================
+// State Encryption
+// ============================================
+
+// ErrDataKeyExists is returned when a data key version already exists for a scope
+var ErrDataKeyExists = errors.New("data key version already exists")
+
+// DataKey is a per-scope (normally per-org) AES-256 key used to encrypt state at
+// rest. Only the wrapped form is persisted; it is encrypted by the master key
+// identified by MasterKeyID. Rotating the master key re-wraps these rows and
+// never touches the encrypted state itself.
+type DataKey struct {
+	ID          string    `json:"id"`
+	Scope       string    `json:"scope"`
+	Version     int       `json:"version"`
+	WrappedKey  []byte    `json:"-"`
+	MasterKeyID string    `json:"master_key_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	RotatedAt   time.Time `json:"rotated_at"`
+}
+
+// DataKeyRepository persists wrapped data keys
+type DataKeyRepository interface {
+	// Create inserts a new key version; returns ErrDataKeyExists if another
+	// writer already created the same (scope, version)
+	Create(ctx context.Context, key *DataKey) error
+	// Get returns a key by ID
+	Get(ctx context.Context, id string) (*DataKey, error)
+	// Latest returns the highest version for a scope, or nil if the scope has no key yet
+	Latest(ctx context.Context, scope string) (*DataKey, error)
+	// List returns every key
+	List(ctx context.Context) ([]*DataKey, error)
+	// UpdateWrapped replaces the wrapped key material after a master key rotation
+	UpdateWrapped(ctx context.Context, id string, wrapped []byte, masterKeyID string) error
+}
+
+// ValueSealer encrypts small values (such as sensitive variables) for storage
+// in the database, using the same per-scope data keys as state encryption.
+type ValueSealer interface {
+	Seal(ctx context.Context, scope string, plaintext []byte) ([]byte, error)
+	Open(ctx context.Context, sealed []byte) ([]byte, error)
+}
@@ -3,6 +3,9 @@ package domain
 import (
 	"context"
 	"errors"
+	"net/url"
+	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -72,6 +75,9 @@ type TFERunRepository interface {
 	// List runs for a unit (workspace)
 	ListRunsForUnit(ctx context.Context, unitID string, limit int) ([]*TFERun, error)
 
+	// List runs for a unit in any of the given statuses, oldest first
+	ListRunsForUnitByStatus(ctx context.Context, unitID string, statuses []string) ([]*TFERun, error)
+
 	// Update run status
 	UpdateRunStatus(ctx context.Context, runID string, status string) error
 
@@ -83,6 +89,12 @@ type TFERunRepository interface {
 
 	// Update run with error message (when execution fails)
 	UpdateRunError(ctx context.Context, runID string, errorMessage string) error
+
+	// Update run status only if the current status is one of from; reports whether it changed
+	TransitionRunStatus(ctx context.Context, runID string, from []string, status string) (bool, error)
+
+	// Flag a run for cancellation if its current status is one of from; reports whether it was flagged
+	RequestRunCancel(ctx context.Context, runID string, from []string) (bool, error)
 }
 
 // TFEPlanRepository manages TFE plan lifecycle
@@ -183,6 +195,8 @@ type Unit struct {
 	TFEEngine           *string `json:"tfe_engine,omitempty"`
 	TFEWorkingDirectory *string `json:"tfe_working_directory,omitempty"`
 	TFEExecutionMode    *string `json:"tfe_execution_mode,omitempty"`
+	TFEVCSRepoURL       *string `json:"tfe_vcs_repo_url,omitempty"`
+	TFEVCSBranch        *string `json:"tfe_vcs_branch,omitempty"`
 }
 
 // Lock represents a Terraform state lock in API responses
@@ -237,6 +251,67 @@ func NormalizeUnitID(id string) string {
 	return s
 }
 
+// scpLikeRepoURL matches the scp-like syntax of ssh remotes, e.g. git@github.com:org/repo.git
+var scpLikeRepoURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^:]`)
+
+// AllowLocalVCSReposEnv lets operators bind units to repositories on the server itself, given as
+// absolute paths or file:// URLs, e.g. for mirrors. It is off by default, as anyone who can edit
+// a unit could then make the server read its own disk.
+const AllowLocalVCSReposEnv = "OPENTACO_VCS_ALLOW_LOCAL_REPOS"
+
+// LocalVCSReposAllowed reports whether the operator opted in to local repositories
+func LocalVCSReposAllowed() bool {
+	v := os.Getenv(AllowLocalVCSReposEnv)
+	return strings.EqualFold(v, "true") || v == "1"
+}
+
+// ValidateVCSRepoURL validates a repository a unit is bound to. Only https and ssh remotes are
+// allowed, the URL is passed to git on the server so local paths, file:// URLs and anything git
+// could read as an option are rejected. Local repositories are accepted when the operator sets
+// OPENTACO_VCS_ALLOW_LOCAL_REPOS. An empty URL removes the binding.
+func ValidateVCSRepoURL(repoURL string) error {
+	if repoURL == "" {
+		return nil
+	}
+	if strings.HasPrefix(repoURL, "-") {
+		return errors.New("VCS repository URL cannot start with '-'")
+	}
+	if strings.IndexFunc(repoURL, func(r rune) bool { return r <= ' ' || r == 0x7f }) >= 0 {
+		return errors.New("VCS repository URL cannot contain whitespace or control characters")
+	}
+	if scpLikeRepoURL.MatchString(repoURL) {
+		return nil
+	}
+	if strings.HasPrefix(repoURL, "/") || strings.HasPrefix(repoURL, "file://") {
+		if !LocalVCSReposAllowed() {
+			return errors.New("local VCS repositories are disabled, set " + AllowLocalVCSReposEnv + " to allow them")
+		}
+		if strings.HasPrefix(repoURL, "/") || strings.HasPrefix(repoURL, "file:///") {
+			return nil
+		}
+		return errors.New("invalid VCS repository URL")
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" || strings.HasPrefix(u.Host, "-") {
+		return errors.New("invalid VCS repository URL")
+	}
+	if u.Scheme != "https" && u.Scheme != "ssh" {
+		return errors.New("VCS repository URL must use https or ssh")
+	}
+	return nil
+}
+
+// ValidateVCSBranch validates the branch a unit is bound to, it is passed to git as well
+func ValidateVCSBranch(branch string) error {
+	if strings.HasPrefix(branch, "-") {
+		return errors.New("VCS branch cannot start with '-'")
+	}
+	if strings.IndexFunc(branch, func(r rune) bool { return r <= ' ' || r == 0x7f }) >= 0 {
+		return errors.New("VCS branch cannot contain whitespace or control characters")
+	}
+	return nil
+}
+
 // DecodeURLPath decodes a URL-encoded path parameter
 func DecodeURLPath(encoded string) (string, error) {
 	// URL-decode the path (handles %2F -> /)
@@ -275,6 +350,9 @@ type TFERun struct {
 	CreatedBy              string
 	ApplyLogBlobID         *string
 	ErrorMessage           *string // Stores error message if run fails
+	CommitSHA              *string // Commit that triggered a VCS run
+	CommitMessage          *string
+	CancelRequested        bool // Set by a cancel request, the replica executing the run stops it
 }
 
 // TFEPlan represents a Terraform plan execution
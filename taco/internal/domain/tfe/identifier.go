@@ -12,6 +12,8 @@ const (
 	OrganizationType ResourceType = "org"
 	WorkspaceType    ResourceType = "ws"
 	AgentPoolType    ResourceType = "apool"
+	VariableType     ResourceType = "var"
+	VariableSetType  ResourceType = "varset"
 )
 
 type TfeResourceIdentifier struct {
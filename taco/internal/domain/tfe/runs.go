@@ -4,15 +4,19 @@ type TFERun struct {
 	ID string `jsonapi:"primary,runs" json:"id"`
 
 	// ----- attributes -----
-	Status        string          `jsonapi:"attr,status" json:"status"`
-	HasChanges    bool            `jsonapi:"attr,has-changes" json:"has-changes"`
-	IsDestroy     bool            `jsonapi:"attr,is-destroy" json:"is-destroy"`
-	Message       string          `jsonapi:"attr,message" json:"message"`
-	PlanOnly      bool            `jsonapi:"attr,plan-only" json:"plan-only"`
-	AutoApply     bool            `jsonapi:"attr,auto-apply" json:"auto-apply"`
-	IsConfirmable bool            `jsonapi:"attr,is-confirmable" json:"is-confirmable"`
-	Actions       *RunActions     `jsonapi:"attr,actions" json:"actions"`
-	Permissions   *RunPermissions `jsonapi:"attr,permissions" json:"permissions"`
+	Status          string          `jsonapi:"attr,status" json:"status"`
+	HasChanges      bool            `jsonapi:"attr,has-changes" json:"has-changes"`
+	IsDestroy       bool            `jsonapi:"attr,is-destroy" json:"is-destroy"`
+	Message         string          `jsonapi:"attr,message" json:"message"`
+	PlanOnly        bool            `jsonapi:"attr,plan-only" json:"plan-only"`
+	AutoApply       bool            `jsonapi:"attr,auto-apply" json:"auto-apply"`
+	IsConfirmable   bool            `jsonapi:"attr,is-confirmable" json:"is-confirmable"`
+	PositionInQueue int             `jsonapi:"attr,position-in-queue" json:"position-in-queue"`
+	Actions         *RunActions     `jsonapi:"attr,actions" json:"actions"`
+	Permissions     *RunPermissions `jsonapi:"attr,permissions" json:"permissions"`
+	Source          string          `jsonapi:"attr,source,omitempty" json:"source,omitempty"`
+	CommitSHA       string          `jsonapi:"attr,commit-sha,omitempty" json:"commit-sha,omitempty"`
+	CommitMessage   string          `jsonapi:"attr,commit-message,omitempty" json:"commit-message,omitempty"`
 
 	// ----- relationships -----
 	Plan                 *PlanRef                 `jsonapi:"relation,plan" json:"plan"`
@@ -23,12 +27,17 @@ type TFERun struct {
 
 // Actions block Terraform likes to see on runs
 type RunActions struct {
-	IsCancelable  bool `json:"is-cancelable"`
-	IsConfirmable bool `json:"is-confirmable"`
+	IsCancelable      bool `json:"is-cancelable"`
+	IsConfirmable     bool `json:"is-confirmable"`
+	IsDiscardable     bool `json:"is-discardable"`
+	IsForceCancelable bool `json:"is-force-cancelable"`
 }
 
 type RunPermissions struct {
-	CanApply bool `json:"can-apply"`
+	CanApply       bool `json:"can-apply"`
+	CanCancel      bool `json:"can-cancel"`
+	CanDiscard     bool `json:"can-discard"`
+	CanForceCancel bool `json:"can-force-cancel"`
 }
 
 // Relationship: plan
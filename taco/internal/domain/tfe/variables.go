@@ -0,0 +1,95 @@
+package tfe
+
+import "time"
+
+// VariableRecord models a workspace or variable set variable for a JSON:API client.
+// Sensitive values are always returned empty.
+type VariableRecord struct {
+	ID          string `jsonapi:"primary,vars" json:"id"`
+	Key         string `jsonapi:"attr,key" json:"key"`
+	Value       string `jsonapi:"attr,value" json:"value"`
+	Description string `jsonapi:"attr,description" json:"description"`
+	Category    string `jsonapi:"attr,category" json:"category"`
+	HCL         bool   `jsonapi:"attr,hcl" json:"hcl"`
+	Sensitive   bool   `jsonapi:"attr,sensitive" json:"sensitive"`
+	VersionID   string `jsonapi:"attr,version-id" json:"version-id"`
+
+	// ----- relationships -----
+	Configurable *WorkspaceRef `jsonapi:"relation,configurable,omitempty" json:"configurable,omitempty"`
+	VariableSet  *VarsetRef    `jsonapi:"relation,varset,omitempty" json:"varset,omitempty"`
+}
+
+// VariableSetRecord models a variable set for a JSON:API client.
+type VariableSetRecord struct {
+	ID             string    `jsonapi:"primary,varsets" json:"id"`
+	Name           string    `jsonapi:"attr,name" json:"name"`
+	Description    string    `jsonapi:"attr,description" json:"description"`
+	Global         bool      `jsonapi:"attr,global" json:"global"`
+	Priority       bool      `jsonapi:"attr,priority" json:"priority"`
+	UpdatedAt      time.Time `jsonapi:"attr,updated-at,iso8601" json:"updated-at"`
+	VarCount       int       `jsonapi:"attr,var-count" json:"var-count"`
+	WorkspaceCount int       `jsonapi:"attr,workspace-count" json:"workspace-count"`
+
+	// ----- relationships -----
+	Workspaces []*WorkspaceRef `jsonapi:"relation,workspaces" json:"workspaces"`
+	Vars       []*VarRef       `jsonapi:"relation,vars" json:"vars"`
+}
+
+// Relationship: varset
+type VarsetRef struct {
+	ID string `jsonapi:"primary,varsets" json:"id"`
+}
+
+// Relationship: var
+type VarRef struct {
+	ID string `jsonapi:"primary,vars" json:"id"`
+}
+
+// VariableAttributes is the request body attributes block for creating or
+// updating a variable. Pointers distinguish omitted fields on PATCH.
+type VariableAttributes struct {
+	Key         *string `json:"key"`
+	Value       *string `json:"value"`
+	Description *string `json:"description"`
+	Category    *string `json:"category"`
+	HCL         *bool   `json:"hcl"`
+	Sensitive   *bool   `json:"sensitive"`
+}
+
+// VariableRequest is the JSON:API request document for a variable
+type VariableRequest struct {
+	Data struct {
+		Type       string             `json:"type"`
+		Attributes VariableAttributes `json:"attributes"`
+	} `json:"data"`
+}
+
+// VariableSetRequest is the JSON:API request document for a variable set
+type VariableSetRequest struct {
+	Data struct {
+		Type       string `json:"type"`
+		Attributes struct {
+			Name        *string `json:"name"`
+			Description *string `json:"description"`
+			Global      *bool   `json:"global"`
+			Priority    *bool   `json:"priority"`
+		} `json:"attributes"`
+		Relationships struct {
+			Workspaces struct {
+				Data []ResourceIdentifier `json:"data"`
+			} `json:"workspaces"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+// ResourceIdentifierList is the request document for relationship endpoints
+// such as POST /varsets/:id/relationships/workspaces
+type ResourceIdentifierList struct {
+	Data []ResourceIdentifier `json:"data"`
+}
+
+// ResourceIdentifier is a JSON:API resource identifier object
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
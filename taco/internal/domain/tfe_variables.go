@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ============================================
+// TFE Variables and Variable Sets
+// ============================================
+
+// Variable categories, matching the TFE API
+const (
+	VariableCategoryTerraform = "terraform"
+	VariableCategoryEnv       = "env"
+)
+
+var (
+	ErrVariableNotFound    = errors.New("variable not found")
+	ErrVariableSetNotFound = errors.New("variable set not found")
+	ErrVariableExists      = errors.New("variable already exists")
+)
+
+// TFEVariable is a workspace variable or a member of a variable set. Exactly
+// one of UnitID and VariableSetID is set. Value is always plaintext here; the
+// repository seals it before it reaches the database.
+type TFEVariable struct {
+	ID            string
+	OrgID         string
+	UnitID        *string
+	VariableSetID *string
+	Key           string
+	Value         string
+	Description   string
+	Category      string // "terraform" or "env"
+	HCL           bool
+	Sensitive     bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TFEVariableSet groups variables that can be applied to many workspaces, or
+// to every workspace in the org when Global is set. Priority sets override
+// workspace variables.
+type TFEVariableSet struct {
+	ID          string
+	OrgID       string
+	Name        string
+	Description string
+	Global      bool
+	Priority    bool
+	UnitIDs     []string // workspaces the set is applied to (ignored when Global)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TFEVariableRepository stores workspace variables and variable sets
+type TFEVariableRepository interface {
+	// Workspace and variable set members
+	ListWorkspaceVariables(ctx context.Context, unitID string) ([]*TFEVariable, error)
+	ListVariableSetVariables(ctx context.Context, setID string) ([]*TFEVariable, error)
+	GetVariable(ctx context.Context, id string) (*TFEVariable, error)
+	CreateVariable(ctx context.Context, v *TFEVariable) error
+	UpdateVariable(ctx context.Context, v *TFEVariable) error
+	DeleteVariable(ctx context.Context, id string) error
+
+	// Variable sets
+	ListVariableSets(ctx context.Context, orgID string) ([]*TFEVariableSet, error)
+	// ListVariableSetsForUnit returns global sets plus sets applied to the unit
+	ListVariableSetsForUnit(ctx context.Context, orgID, unitID string) ([]*TFEVariableSet, error)
+	GetVariableSet(ctx context.Context, id string) (*TFEVariableSet, error)
+	CreateVariableSet(ctx context.Context, set *TFEVariableSet) error
+	UpdateVariableSet(ctx context.Context, set *TFEVariableSet) error
+	DeleteVariableSet(ctx context.Context, id string) error
+	ApplyVariableSet(ctx context.Context, setID string, unitIDs []string) error
+	RemoveVariableSet(ctx context.Context, setID string, unitIDs []string) error
+}
@@ -46,3 +46,50 @@ func TestNormalizeUnitID(t *testing.T) {
 }
 
 
+
+func TestValidateVCSRepoURL(t *testing.T) {
+    tests := []struct{
+        name string
+        url string
+        wantErr bool
+    }{
+        {name: "empty removes the binding", url: "", wantErr: false},
+        {name: "https", url: "https://github.com/org/repo.git", wantErr: false},
+        {name: "ssh", url: "ssh://git@github.com/org/repo.git", wantErr: false},
+        {name: "scp-like ssh", url: "git@github.com:org/repo.git", wantErr: false},
+        {name: "option", url: "--upload-pack=touch /tmp/x;", wantErr: true},
+        {name: "local path", url: "/var/lib/repos/secret.git", wantErr: true},
+        {name: "relative path", url: "../repo", wantErr: true},
+        {name: "file url", url: "file:///var/lib/repos/secret.git", wantErr: true},
+        {name: "ext transport", url: "ext::sh -c touch% /tmp/x", wantErr: true},
+        {name: "plain http", url: "http://github.com/org/repo.git", wantErr: true},
+        {name: "host as option", url: "ssh://-oProxyCommand=touch/repo", wantErr: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := ValidateVCSRepoURL(tt.url)
+            if (err != nil) != tt.wantErr {
+                t.Errorf("ValidateVCSRepoURL() error = %v, wantErr %v", err, tt.wantErr)
+            }
+        })
+    }
+
+    t.Setenv(AllowLocalVCSReposEnv, "true")
+    for _, local := range []string{"/var/lib/repos/mirror.git", "file:///var/lib/repos/mirror.git"} {
+        if err := ValidateVCSRepoURL(local); err != nil {
+            t.Errorf("ValidateVCSRepoURL(%q) with local repos allowed: %v", local, err)
+        }
+    }
+    for _, rejected := range []string{"../repo", "file://host/repo.git", "ext::sh -c touch% /tmp/x", "-/repo"} {
+        if err := ValidateVCSRepoURL(rejected); err == nil {
+            t.Errorf("ValidateVCSRepoURL(%q) accepted with local repos allowed", rejected)
+        }
+    }
+
+    if err := ValidateVCSBranch("-x"); err == nil {
+        t.Error("ValidateVCSBranch() accepted a branch starting with '-'")
+    }
+    if err := ValidateVCSBranch("feature/vcs"); err != nil {
+        t.Errorf("ValidateVCSBranch() error = %v", err)
+    }
+}
@@ -0,0 +1,97 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Encrypted objects start with a fixed header so plaintext written before
+// encryption was enabled keeps working:
+//
+//	"OTENC1" | key ID length (1 byte) | key ID | nonce (12 bytes) | AES-256-GCM ciphertext
+//
+// The header is authenticated as additional data, so swapping the key ID fails decryption.
+var magic = []byte("OTENC1")
+
+const (
+	nonceSize = 12
+	tagSize   = 16
+)
+
+var errMalformed = errors.New("malformed encrypted object")
+
+// isEncrypted reports whether data carries the envelope header
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+func seal(keyID string, key, plaintext []byte) ([]byte, error) {
+	if len(keyID) > 255 {
+		return nil, fmt.Errorf("data key ID too long")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, len(magic)+1+len(keyID))
+	header = append(header, magic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+nonceSize+len(plaintext)+tagSize)
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// parseHeader returns the data key ID and the header length
+func parseHeader(data []byte) (string, int, error) {
+	if !isEncrypted(data) || len(data) < len(magic)+1 {
+		return "", 0, errMalformed
+	}
+	n := int(data[len(magic)])
+	end := len(magic) + 1 + n
+	if len(data) < end+nonceSize+tagSize {
+		return "", 0, errMalformed
+	}
+	return string(data[len(magic)+1 : end]), end, nil
+}
+
+// plaintextSize derives the decrypted length from the envelope without decrypting
+func plaintextSize(data []byte) (int64, error) {
+	_, headerLen, err := parseHeader(data)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data) - headerLen - nonceSize - tagSize), nil
+}
+
+func open(key, data []byte, headerLen int) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := data[:headerLen]
+	nonce := data[headerLen : headerLen+nonceSize]
+	plaintext, err := aead.Open(nil, nonce, data[headerLen+nonceSize:], header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt object: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,213 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/kms"
+)
+
+// Keyring manages per-scope data keys: it creates them on first use, unwraps
+// them with the configured master keys and caches the plaintext in memory.
+// It also seals small values (e.g. TFE variables) outside the blob store.
+type Keyring struct {
+	keys     domain.DataKeyRepository
+	primary  kms.KeyWrapper
+	wrappers map[string]kms.KeyWrapper
+
+	mu       sync.Mutex
+	dataKeys map[string][]byte // key ID -> unwrapped data key
+	active   map[string]string // scope -> key ID used for new writes
+}
+
+var _ domain.ValueSealer = (*Keyring)(nil)
+
+// NewKeyring creates a keyring. New data keys are wrapped with primary;
+// previous master keys are only used to unwrap keys that have not been
+// re-wrapped yet.
+func NewKeyring(keys domain.DataKeyRepository, primary kms.KeyWrapper, previous ...kms.KeyWrapper) *Keyring {
+	wrappers := map[string]kms.KeyWrapper{}
+	for _, w := range previous {
+		if w != nil {
+			wrappers[w.ID()] = w
+		}
+	}
+	wrappers[primary.ID()] = primary
+	return &Keyring{
+		keys:     keys,
+		primary:  primary,
+		wrappers: wrappers,
+		dataKeys: map[string][]byte{},
+		active:   map[string]string{},
+	}
+}
+
+// Seal encrypts plaintext with the active data key for scope
+func (k *Keyring) Seal(ctx context.Context, scope string, plaintext []byte) ([]byte, error) {
+	keyID, key, err := k.activeKey(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return seal(keyID, key, plaintext)
+}
+
+// Open decrypts a value produced by Seal. Data without the envelope header is
+// returned unchanged so values stored before encryption stay readable.
+func (k *Keyring) Open(ctx context.Context, data []byte) ([]byte, error) {
+	if !isEncrypted(data) {
+		return data, nil
+	}
+	keyID, headerLen, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := k.keyByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return open(key, data, headerLen)
+}
+
+// Rewrap re-wraps every data key that is not wrapped by the primary master
+// key. Encrypted data is not touched. Returns the number of keys re-wrapped.
+func (k *Keyring) Rewrap(ctx context.Context) (int, error) {
+	keys, err := k.keys.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, key := range keys {
+		if key.MasterKeyID == k.primary.ID() {
+			continue
+		}
+		plaintext, err := k.unwrap(ctx, key)
+		if err != nil {
+			return count, err
+		}
+		wrapped, err := k.primary.Wrap(ctx, plaintext)
+		if err != nil {
+			return count, fmt.Errorf("failed to wrap data key %s: %w", key.ID, err)
+		}
+		if err := k.keys.UpdateWrapped(ctx, key.ID, wrapped, k.primary.ID()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RotateDataKey creates a new data key version for scope. New writes use it;
+// data encrypted with earlier versions remains readable.
+func (k *Keyring) RotateDataKey(ctx context.Context, scope string) (*domain.DataKey, error) {
+	latest, err := k.keys.Latest(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+	key, _, err := k.createDataKey(ctx, scope, version)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	delete(k.active, scope)
+	k.mu.Unlock()
+	return key, nil
+}
+
+func (k *Keyring) activeKey(ctx context.Context, scope string) (string, []byte, error) {
+	k.mu.Lock()
+	if id, ok := k.active[scope]; ok {
+		key := k.dataKeys[id]
+		k.mu.Unlock()
+		return id, key, nil
+	}
+	k.mu.Unlock()
+
+	latest, err := k.keys.Latest(ctx, scope)
+	if err != nil {
+		return "", nil, err
+	}
+	var plaintext []byte
+	if latest == nil {
+		latest, plaintext, err = k.createDataKey(ctx, scope, 1)
+		if errors.Is(err, domain.ErrDataKeyExists) {
+			// Another replica created the first key concurrently; use theirs
+			return k.activeKey(ctx, scope)
+		}
+	} else {
+		plaintext, err = k.unwrap(ctx, latest)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.mu.Lock()
+	k.dataKeys[latest.ID] = plaintext
+	k.active[scope] = latest.ID
+	k.mu.Unlock()
+	return latest.ID, plaintext, nil
+}
+
+func (k *Keyring) keyByID(ctx context.Context, id string) ([]byte, error) {
+	k.mu.Lock()
+	key, ok := k.dataKeys[id]
+	k.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	record, err := k.keys.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	key, err = k.unwrap(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	k.dataKeys[id] = key
+	k.mu.Unlock()
+	return key, nil
+}
+
+func (k *Keyring) createDataKey(ctx context.Context, scope string, version int) (*domain.DataKey, []byte, error) {
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := k.primary.Wrap(ctx, plaintext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	key := &domain.DataKey{
+		Scope:       scope,
+		Version:     version,
+		WrappedKey:  wrapped,
+		MasterKeyID: k.primary.ID(),
+	}
+	if err := k.keys.Create(ctx, key); err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Created encryption data key", "scope", scope, "version", version, "master_key", k.primary.ID())
+	return key, plaintext, nil
+}
+
+func (k *Keyring) unwrap(ctx context.Context, key *domain.DataKey) ([]byte, error) {
+	wrapper, ok := k.wrappers[key.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("data key %s is wrapped by master key %s, which is not configured", key.ID, key.MasterKeyID)
+	}
+	plaintext, err := wrapper.Unwrap(ctx, key.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key %s: %w", key.ID, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,266 @@
+// Package encryption adds envelope encryption at rest to any storage.UnitStore
+// and seals small values such as TFE variables.
+//
+// State and blobs are encrypted with AES-256-GCM under a data key per scope.
+// The scope is the first segment of the object ID, which for units is the
+// organization UUID ({org-uuid}/{unit-uuid}), so every org gets its own key.
+// Blobs of TFE runs carry no org in their key; their writers name the owning
+// org with storage.WithBlobScope instead.
+// Data keys are stored wrapped by a master key from the kms package; rotating
+// the master key only re-wraps those rows. Callers see plaintext everywhere, so
+// the backend, S3-compat and TFE handlers are unaware of encryption.
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/storage"
+)
+
+// Store is a storage.UnitStore that encrypts data on the way in and decrypts
+// it on the way out. Objects written before encryption was enabled are
+// returned unchanged and encrypted on their next write.
+type Store struct {
+	inner   storage.UnitStore
+	keyring *Keyring
+
+	mu    sync.Mutex
+	sizes map[string]sizeEntry // object ID -> plaintext size for the current object
+}
+
+// plaintextSizeAttribute records the plaintext size of an object in stores that
+// keep attributes, so sizes are known without downloading the object
+const plaintextSizeAttribute = "plaintext-size"
+
+type sizeEntry struct {
+	updated time.Time
+	stored  int64
+	plain   int64
+}
+
+var _ storage.UnitStore = (*Store)(nil)
+
+// NewStore wraps inner, sealing objects with keys from keyring
+func NewStore(inner storage.UnitStore, keyring *Keyring) *Store {
+	return &Store{
+		inner:   inner,
+		keyring: keyring,
+		sizes:   map[string]sizeEntry{},
+	}
+}
+
+// --- Unit operations ---
+
+func (s *Store) Create(ctx context.Context, id string) (*storage.UnitMetadata, error) {
+	return s.inner.Create(ctx, id)
+}
+
+func (s *Store) Get(ctx context.Context, id string) (*storage.UnitMetadata, error) {
+	meta, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fixSize(ctx, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string) ([]*storage.UnitMetadata, error) {
+	units, err := s.inner.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range units {
+		if err := s.fixSize(ctx, meta); err != nil {
+			return nil, err
+		}
+	}
+	return units, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.sizes, id)
+	s.mu.Unlock()
+	return s.inner.Delete(ctx, id)
+}
+
+// --- Data operations ---
+
+func (s *Store) Download(ctx context.Context, id string) ([]byte, error) {
+	data, err := s.inner.Download(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.keyring.Open(ctx, data)
+}
+
+func (s *Store) Upload(ctx context.Context, id string, data []byte, lockID string) error {
+	scope, err := scopeFor(ctx, id)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.keyring.Seal(ctx, scope, data)
+	if err != nil {
+		return err
+	}
+	if attributeStore, ok := s.inner.(storage.AttributeStore); ok {
+		attributes := map[string]string{plaintextSizeAttribute: strconv.Itoa(len(data))}
+		return attributeStore.UploadWithAttributes(ctx, id, sealed, lockID, attributes)
+	}
+	return s.inner.Upload(ctx, id, sealed, lockID)
+}
+
+func (s *Store) DownloadBlob(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.inner.DownloadBlob(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.keyring.Open(ctx, data)
+}
+
+func (s *Store) UploadBlob(ctx context.Context, key string, data []byte) error {
+	scope, err := scopeFor(ctx, key)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.keyring.Seal(ctx, scope, data)
+	if err != nil {
+		return err
+	}
+	return s.inner.UploadBlob(ctx, key, sealed)
+}
+
+// --- Lock and version operations pass straight through ---
+
+func (s *Store) Lock(ctx context.Context, id string, info *storage.LockInfo) error {
+	return s.inner.Lock(ctx, id, info)
+}
+
+func (s *Store) Unlock(ctx context.Context, id string, lockID string) error {
+	return s.inner.Unlock(ctx, id, lockID)
+}
+
+func (s *Store) GetLock(ctx context.Context, id string) (*storage.LockInfo, error) {
+	return s.inner.GetLock(ctx, id)
+}
+
+func (s *Store) ListVersions(ctx context.Context, id string) ([]*storage.VersionInfo, error) {
+	return s.inner.ListVersions(ctx, id)
+}
+
+// RestoreVersion copies the stored ciphertext; the old version keeps its own
+// data key ID in its header, so it stays readable after data key rotation.
+func (s *Store) RestoreVersion(ctx context.Context, id string, versionTimestamp time.Time, lockID string) error {
+	return s.inner.RestoreVersion(ctx, id, versionTimestamp, lockID)
+}
+
+// fixSize replaces the stored (ciphertext) size with the plaintext size so
+// HEAD responses and metadata match what Download returns. The size comes from
+// the attribute recorded on upload, looked up with Get when a listing does not
+// carry attributes. Objects without it, written before encryption or to stores
+// without attributes, are read once. Results are cached per object revision.
+func (s *Store) fixSize(ctx context.Context, meta *storage.UnitMetadata) error {
+	if meta == nil || meta.Size == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	entry, ok := s.sizes[meta.ID]
+	s.mu.Unlock()
+	if ok && entry.stored == meta.Size && entry.updated.Equal(meta.Updated) {
+		meta.Size = entry.plain
+		return nil
+	}
+
+	plain, ok, err := s.recordedSize(ctx, meta)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		data, err := s.inner.Download(ctx, meta.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		plain = int64(len(data))
+		if isEncrypted(data) {
+			if plain, err = plaintextSize(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.sizes[meta.ID] = sizeEntry{updated: meta.Updated, stored: meta.Size, plain: plain}
+	s.mu.Unlock()
+	meta.Size = plain
+	return nil
+}
+
+// recordedSize returns the plaintext size recorded with the current object
+func (s *Store) recordedSize(ctx context.Context, meta *storage.UnitMetadata) (int64, bool, error) {
+	if _, ok := s.inner.(storage.AttributeStore); !ok {
+		return 0, false, nil
+	}
+	attributes := meta.Attributes
+	if attributes == nil {
+		current, err := s.inner.Get(ctx, meta.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		// the object changed since it was listed
+		if current.Size != meta.Size {
+			return 0, false, nil
+		}
+		attributes = current.Attributes
+	}
+	value, ok := attributes[plaintextSizeAttribute]
+	if !ok {
+		return 0, false, nil
+	}
+	plain, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, nil
+	}
+	return plain, true, nil
+}
+
+// orglessPrefixes are the blobs of TFE runs, whose keys don't name the organization
+var orglessPrefixes = []string{"config-versions/", "plans/", "applies/"}
+
+// scopeFor returns the key scope for writing an object: the organization
+// recorded in ctx, otherwise the first path segment of the ID. Blobs of TFE
+// runs must be written with an organization, as they would share a key else.
+func scopeFor(ctx context.Context, id string) (string, error) {
+	if orgID := storage.BlobScope(ctx); orgID != "" {
+		return orgID, nil
+	}
+	trimmed := strings.TrimPrefix(id, "/")
+	for _, prefix := range orglessPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return "", fmt.Errorf("no organization given for encrypting %s", id)
+		}
+	}
+	return scopeOf(id), nil
+}
+
+// scopeOf returns the key scope for an object ID: its first path segment
+func scopeOf(id string) string {
+	id = strings.TrimPrefix(id, "/")
+	if i := strings.Index(id, "/"); i > 0 {
+		return id[:i]
+	}
+	return id
+}
@@ -0,0 +1,349 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/kms"
+	"github.com/diggerhq/digger/opentaco/internal/storage"
+)
+
+// memKeys is an in-memory DataKeyRepository
+type memKeys struct {
+	mu   sync.Mutex
+	keys []*domain.DataKey
+}
+
+func (m *memKeys) Create(ctx context.Context, key *domain.DataKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range m.keys {
+		if k.Scope == key.Scope && k.Version == key.Version {
+			return domain.ErrDataKeyExists
+		}
+	}
+	key.ID = fmt.Sprintf("key-%d", len(m.keys)+1)
+	stored := *key
+	m.keys = append(m.keys, &stored)
+	return nil
+}
+
+func (m *memKeys) Get(ctx context.Context, id string) (*domain.DataKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range m.keys {
+		if k.ID == id {
+			copy := *k
+			return &copy, nil
+		}
+	}
+	return nil, fmt.Errorf("data key %s not found", id)
+}
+
+func (m *memKeys) Latest(ctx context.Context, scope string) (*domain.DataKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest *domain.DataKey
+	for _, k := range m.keys {
+		if k.Scope == scope && (latest == nil || k.Version > latest.Version) {
+			latest = k
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	copy := *latest
+	return &copy, nil
+}
+
+func (m *memKeys) List(ctx context.Context) ([]*domain.DataKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]*domain.DataKey, 0, len(m.keys))
+	for _, k := range m.keys {
+		copy := *k
+		out = append(out, &copy)
+	}
+	return out, nil
+}
+
+func (m *memKeys) UpdateWrapped(ctx context.Context, id string, wrapped []byte, masterKeyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range m.keys {
+		if k.ID == id {
+			k.WrappedKey = wrapped
+			k.MasterKeyID = masterKeyID
+			return nil
+		}
+	}
+	return fmt.Errorf("data key %s not found", id)
+}
+
+func newMasterKey(t *testing.T, b byte) kms.KeyWrapper {
+	t.Helper()
+	k, err := kms.NewLocalKey(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+const secretState = `{"version":4,"resources":[{"password":"hunter2"}]}`
+
+func TestStore_RoundTripIsTransparent(t *testing.T) {
+	ctx := context.Background()
+	inner := storage.NewMemStore()
+	store := NewStore(inner, NewKeyring(&memKeys{}, newMasterKey(t, 1)))
+
+	if _, err := store.Create(ctx, "org-a/unit-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upload(ctx, "org-a/unit-1", []byte(secretState), ""); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	raw, err := inner.Download(ctx, "org-a/unit-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("hunter2")) {
+		t.Error("state stored in plaintext")
+	}
+
+	data, err := store.Download(ctx, "org-a/unit-1")
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if string(data) != secretState {
+		t.Errorf("expected %s, got %s", secretState, data)
+	}
+
+	meta, err := store.Get(ctx, "org-a/unit-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Size != int64(len(secretState)) {
+		t.Errorf("expected plaintext size %d, got %d", len(secretState), meta.Size)
+	}
+
+	if err := store.UploadBlob(storage.WithBlobScope(ctx, "org-a"), "config-versions/cv-1/archive.tar.gz", []byte("archive")); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := store.DownloadBlob(ctx, "config-versions/cv-1/archive.tar.gz")
+	if err != nil || string(blob) != "archive" {
+		t.Errorf("blob round trip failed: %q %v", blob, err)
+	}
+}
+
+func TestStore_PerOrgDataKeys(t *testing.T) {
+	ctx := context.Background()
+	keys := &memKeys{}
+	store := NewStore(storage.NewMemStore(), NewKeyring(keys, newMasterKey(t, 1)))
+
+	for _, id := range []string{"org-a/u1", "org-a/u2", "org-b/u1"} {
+		store.Create(ctx, id)
+		if err := store.Upload(ctx, id, []byte(secretState), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, _ := keys.List(ctx)
+	if len(all) != 2 {
+		t.Fatalf("expected one data key per org, got %d", len(all))
+	}
+	if all[0].Scope == all[1].Scope {
+		t.Errorf("expected distinct scopes, got %s twice", all[0].Scope)
+	}
+}
+
+func TestStore_RunBlobsUseTheKeyOfTheirOrg(t *testing.T) {
+	ctx := context.Background()
+	keys := &memKeys{}
+	store := NewStore(storage.NewMemStore(), NewKeyring(keys, newMasterKey(t, 1)))
+
+	if err := store.UploadBlob(storage.WithBlobScope(ctx, "org-a"), "plans/plan-1/chunks/00000001.log", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UploadBlob(storage.WithBlobScope(ctx, "org-b"), "applies/run-2/chunks/00000001.log", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	all, _ := keys.List(ctx)
+	if len(all) != 2 || all[0].Scope != "org-a" || all[1].Scope != "org-b" {
+		t.Fatalf("expected a data key for org-a and org-b, got %+v", all)
+	}
+	if data, err := store.DownloadBlob(ctx, "applies/run-2/chunks/00000001.log"); err != nil || string(data) != "b" {
+		t.Errorf("expected blob readable without a scope, got %q %v", data, err)
+	}
+
+	// without an organization run blobs would share a key
+	if err := store.UploadBlob(ctx, "config-versions/cv-1/archive.tar.gz", []byte("archive")); err == nil {
+		t.Error("expected run blobs without an organization to be refused")
+	}
+}
+
+func TestStore_LegacyPlaintextPassesThrough(t *testing.T) {
+	ctx := context.Background()
+	inner := storage.NewMemStore()
+	inner.Create(ctx, "org-a/old")
+	inner.Upload(ctx, "org-a/old", []byte(secretState), "")
+
+	store := NewStore(inner, NewKeyring(&memKeys{}, newMasterKey(t, 1)))
+	data, err := store.Download(ctx, "org-a/old")
+	if err != nil || string(data) != secretState {
+		t.Fatalf("expected legacy plaintext, got %q %v", data, err)
+	}
+	meta, _ := store.Get(ctx, "org-a/old")
+	if meta.Size != int64(len(secretState)) {
+		t.Errorf("expected size %d, got %d", len(secretState), meta.Size)
+	}
+}
+
+// downloadCounter counts the state downloads of a store that keeps attributes
+type downloadCounter struct {
+	storage.UnitStore
+	downloads int
+}
+
+func (c *downloadCounter) Download(ctx context.Context, id string) ([]byte, error) {
+	c.downloads++
+	return c.UnitStore.Download(ctx, id)
+}
+
+func (c *downloadCounter) UploadWithAttributes(ctx context.Context, id string, data []byte, lockID string, attributes map[string]string) error {
+	return c.UnitStore.(storage.AttributeStore).UploadWithAttributes(ctx, id, data, lockID, attributes)
+}
+
+func TestStore_SizesFromAttributesWithoutDownloads(t *testing.T) {
+	ctx := context.Background()
+	inner := &downloadCounter{UnitStore: storage.NewMemStore()}
+	store := NewStore(inner, NewKeyring(&memKeys{}, newMasterKey(t, 1)))
+
+	for _, id := range []string{"org-a/u1", "org-a/u2"} {
+		store.Create(ctx, id)
+		if err := store.Upload(ctx, id, []byte(secretState), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a unit written before encryption has no recorded size
+	inner.UnitStore.Upload(ctx, "org-a/old", []byte("legacy"), "")
+
+	units, err := store.List(ctx, "org-a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]int64{}
+	for _, meta := range units {
+		sizes[meta.ID] = meta.Size
+	}
+	want := map[string]int64{"org-a/u1": int64(len(secretState)), "org-a/u2": int64(len(secretState)), "org-a/old": 6}
+	for id, size := range want {
+		if sizes[id] != size {
+			t.Errorf("expected %s size %d, got %d", id, size, sizes[id])
+		}
+	}
+	if inner.downloads != 1 {
+		t.Errorf("expected only the legacy unit to be downloaded, got %d downloads", inner.downloads)
+	}
+}
+
+func TestStore_MasterKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	inner := storage.NewMemStore()
+	keys := &memKeys{}
+	oldKey, newKey := newMasterKey(t, 1), newMasterKey(t, 2)
+
+	store := NewStore(inner, NewKeyring(keys, oldKey))
+	store.Create(ctx, "org-a/u1")
+	if err := store.Upload(ctx, "org-a/u1", []byte(secretState), ""); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := inner.Download(ctx, "org-a/u1")
+
+	// Restart with the new master key, keeping the old one for unwrapping
+	rotated := NewStore(inner, NewKeyring(keys, newKey, oldKey))
+	n, err := rotated.keyring.Rewrap(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 key re-wrapped, got %d %v", n, err)
+	}
+	after, _ := inner.Download(ctx, "org-a/u1")
+	if !bytes.Equal(before, after) {
+		t.Error("rotation rewrote encrypted state")
+	}
+
+	// The old master key is no longer needed
+	fresh := NewStore(inner, NewKeyring(keys, newKey))
+	data, err := fresh.Download(ctx, "org-a/u1")
+	if err != nil || string(data) != secretState {
+		t.Fatalf("expected state readable with new master key, got %q %v", data, err)
+	}
+
+	// Without the right master key nothing decrypts
+	wrong := NewStore(inner, NewKeyring(keys, newMasterKey(t, 3)))
+	if _, err := wrong.Download(ctx, "org-a/u1"); err == nil {
+		t.Error("expected failure with an unknown master key")
+	}
+}
+
+func TestStore_DataKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(storage.NewMemStore(), NewKeyring(&memKeys{}, newMasterKey(t, 1)))
+
+	store.Create(ctx, "org-a/u1")
+	store.Upload(ctx, "org-a/u1", []byte("v1"), "")
+	store.UploadBlob(ctx, "org-a/old-blob", []byte("old"))
+
+	key, err := store.keyring.RotateDataKey(ctx, "org-a")
+	if err != nil || key.Version != 2 {
+		t.Fatalf("expected version 2, got %+v %v", key, err)
+	}
+	store.Upload(ctx, "org-a/u1", []byte("v2"), "")
+
+	if data, err := store.DownloadBlob(ctx, "org-a/old-blob"); err != nil || string(data) != "old" {
+		t.Errorf("object under previous data key unreadable: %q %v", data, err)
+	}
+	if data, err := store.Download(ctx, "org-a/u1"); err != nil || string(data) != "v2" {
+		t.Errorf("unexpected current state: %q %v", data, err)
+	}
+}
+
+func TestEnvelope_TamperDetected(t *testing.T) {
+	key := bytes.Repeat([]byte{9}, 32)
+	sealed, err := seal("key-1", key, []byte(secretState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := plaintextSize(sealed)
+	if err != nil || size != int64(len(secretState)) {
+		t.Errorf("expected size %d, got %d %v", len(secretState), size, err)
+	}
+
+	sealed[len(sealed)-1] ^= 0xff
+	_, headerLen, _ := parseHeader(sealed)
+	if _, err := open(key, sealed, headerLen); err == nil {
+		t.Error("expected tampered ciphertext to fail")
+	}
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	ctx := context.Background()
+	keyring := NewKeyring(&memKeys{}, newMasterKey(t, 1))
+
+	sealed, err := keyring.Seal(ctx, "org-a", []byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("s3cr3t")) {
+		t.Error("value sealed in plaintext")
+	}
+	opened, err := keyring.Open(ctx, sealed)
+	if err != nil || string(opened) != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got %q %v", opened, err)
+	}
+	if plain, _ := keyring.Open(ctx, []byte("legacy")); string(plain) != "legacy" {
+		t.Errorf("expected unsealed values to pass through, got %q", plain)
+	}
+}
@@ -0,0 +1,111 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// AWSKMS wraps data keys with an AWS KMS symmetric key. Requests are signed
+// with the default AWS credential chain, the same one the S3 store uses.
+type AWSKMS struct {
+	keyID    string
+	endpoint string
+	cfg      aws.Config
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewAWSKMS builds an AWS KMS wrapper. AWS_ENDPOINT_URL_KMS overrides the
+// regional endpoint (e.g. for LocalStack).
+func NewAWSKMS(ctx context.Context, keyID string) (*AWSKMS, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_KMS")
+	if endpoint == "" {
+		if cfg.Region == "" {
+			return nil, fmt.Errorf("AWS region is required for awskms")
+		}
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", cfg.Region)
+	}
+	return &AWSKMS{
+		keyID:    keyID,
+		endpoint: endpoint,
+		cfg:      cfg,
+		signer:   v4.NewSigner(),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (k *AWSKMS) ID() string { return "awskms:" + k.keyID }
+
+func (k *AWSKMS) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var out struct {
+		CiphertextBlob []byte
+	}
+	if err := k.call(ctx, "TrentService.Encrypt", map[string]interface{}{"KeyId": k.keyID, "Plaintext": plaintext}, &out); err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (k *AWSKMS) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte
+	}
+	if err := k.call(ctx, "TrentService.Decrypt", map[string]interface{}{"KeyId": k.keyID, "CiphertextBlob": wrapped}, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// call performs a KMS JSON 1.1 API request. []byte fields round-trip as
+// base64, which is exactly how KMS encodes blobs.
+func (k *AWSKMS) call(ctx context.Context, target string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	creds, err := k.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if err := k.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "kms", k.cfg.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign KMS request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kms %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kms %s: HTTP %d: %s", target, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
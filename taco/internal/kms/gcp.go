@@ -0,0 +1,84 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+)
+
+const gcpKMSScope = "https://www.googleapis.com/auth/cloudkms"
+
+// GCPKMS wraps data keys with a Cloud KMS symmetric CryptoKey using
+// Application Default Credentials.
+type GCPKMS struct {
+	name     string
+	endpoint string
+	client   *http.Client
+}
+
+// NewGCPKMS builds a Cloud KMS wrapper for the CryptoKey resource name
+func NewGCPKMS(ctx context.Context, name string) (*GCPKMS, error) {
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/cryptoKeys/") {
+		return nil, fmt.Errorf("gcpkms key must be a CryptoKey resource name (projects/.../cryptoKeys/...), got %q", name)
+	}
+	client, err := google.DefaultClient(ctx, gcpKMSScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google credentials: %w", err)
+	}
+	return &GCPKMS{name: name, endpoint: "https://cloudkms.googleapis.com", client: client}, nil
+}
+
+func (k *GCPKMS) ID() string { return "gcpkms:" + k.name }
+
+func (k *GCPKMS) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var out struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := k.call(ctx, "encrypt", map[string][]byte{"plaintext": plaintext}, &out); err != nil {
+		return nil, err
+	}
+	return out.Ciphertext, nil
+}
+
+func (k *GCPKMS) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := k.call(ctx, "decrypt", map[string][]byte{"ciphertext": wrapped}, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+func (k *GCPKMS) call(ctx context.Context, method string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s:%s", k.endpoint, k.name, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cloud kms %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cloud kms %s: HTTP %d: %s", method, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
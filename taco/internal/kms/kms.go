@@ -0,0 +1,53 @@
+// Package kms provides master keys used to wrap OpenTaco state encryption keys.
+//
+// A master key never encrypts state directly: it only wraps and unwraps the
+// short per-org data keys managed by the encryption package, so rotating it
+// means re-wrapping a handful of keys rather than rewriting every state file.
+package kms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Provider names accepted by New
+const (
+	ProviderLocal = "local"
+	ProviderAWS   = "awskms"
+	ProviderGCP   = "gcpkms"
+	ProviderVault = "vault"
+)
+
+// KeyWrapper wraps and unwraps data keys with a master key
+type KeyWrapper interface {
+	// ID identifies the master key (provider-qualified). It is stored next to
+	// every wrapped data key so rotation can tell which keys still need re-wrapping.
+	ID() string
+	Wrap(ctx context.Context, plaintext []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// New builds a KeyWrapper for provider. keyRef is provider specific:
+//
+//	local   path to a file holding a 32-byte key (raw, hex or base64)
+//	awskms  key ID, ARN or alias
+//	gcpkms  projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>
+//	vault   transit key name, optionally prefixed with the mount ("transit/my-key")
+func New(ctx context.Context, provider, keyRef string) (KeyWrapper, error) {
+	if keyRef == "" {
+		return nil, fmt.Errorf("kms %q: key reference is required", provider)
+	}
+	switch strings.ToLower(provider) {
+	case ProviderLocal:
+		return NewLocalKeyFile(keyRef)
+	case ProviderAWS:
+		return NewAWSKMS(ctx, keyRef)
+	case ProviderGCP:
+		return NewGCPKMS(ctx, keyRef)
+	case ProviderVault:
+		return NewVaultTransit(keyRef)
+	default:
+		return nil, fmt.Errorf("unknown kms provider %q (expected %s, %s, %s or %s)", provider, ProviderLocal, ProviderAWS, ProviderGCP, ProviderVault)
+	}
+}
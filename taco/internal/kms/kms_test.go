@@ -0,0 +1,108 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalKeyFile(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	dir := t.TempDir()
+
+	for name, content := range map[string][]byte{
+		"raw": key,
+		"hex": []byte(hex.EncodeToString(key) + "\n"),
+		"b64": []byte(base64.StdEncoding.EncodeToString(key)),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		w, err := New(context.Background(), ProviderLocal, path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		wrapped, err := w.Wrap(context.Background(), []byte("data-key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := w.Unwrap(context.Background(), wrapped)
+		if err != nil || string(plain) != "data-key" {
+			t.Errorf("%s: round trip failed: %q %v", name, plain, err)
+		}
+	}
+
+	bad := filepath.Join(dir, "short")
+	os.WriteFile(bad, []byte("too short"), 0o600)
+	if _, err := NewLocalKeyFile(bad); err == nil {
+		t.Error("expected error for a short key")
+	}
+}
+
+func TestLocalKey_IDDiffersPerKey(t *testing.T) {
+	a, _ := NewLocalKey(bytes.Repeat([]byte{1}, 32))
+	b, _ := NewLocalKey(bytes.Repeat([]byte{2}, 32))
+	if a.ID() == b.ID() {
+		t.Error("expected different IDs for different keys")
+	}
+	wrapped, _ := a.Wrap(context.Background(), []byte("x"))
+	if _, err := b.Unwrap(context.Background(), wrapped); err == nil {
+		t.Error("expected unwrap with the wrong key to fail")
+	}
+}
+
+func TestVaultTransit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/secrets/encrypt/state":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/secrets/decrypt/state":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	w, err := New(context.Background(), ProviderVault, "secrets/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.ID() != "vault:secrets/state" {
+		t.Errorf("unexpected ID %s", w.ID())
+	}
+	wrapped, err := w.Wrap(context.Background(), []byte("data-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(wrapped), "vault:v1:") {
+		t.Errorf("expected transit ciphertext, got %s", wrapped)
+	}
+	plain, err := w.Unwrap(context.Background(), wrapped)
+	if err != nil || string(plain) != "data-key" {
+		t.Errorf("round trip failed: %q %v", plain, err)
+	}
+}
+
+func TestNew_UnknownProvider(t *testing.T) {
+	if _, err := New(context.Background(), "nope", "x"); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
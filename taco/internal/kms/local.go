@@ -0,0 +1,100 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LocalKey wraps data keys with AES-256-GCM using a key read from disk.
+// Intended for single-node and development deployments.
+type LocalKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalKeyFile loads a 32-byte master key from path. The file may hold the
+// raw bytes or their hex/base64 encoding.
+func NewLocalKeyFile(path string) (*LocalKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("master key file %s: %w", path, err)
+	}
+	return NewLocalKey(key)
+}
+
+// NewLocalKeyFromSecret derives a master key from a server secret such as
+// OPENTACO_SECRET_KEY. It is the fallback used to seal TFE variables when no
+// KMS provider is configured.
+func NewLocalKeyFromSecret(secret string) (*LocalKey, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("secret is empty")
+	}
+	sum := sha256.Sum256([]byte("opentaco-master-key:" + secret))
+	return NewLocalKey(sum[:])
+}
+
+// NewLocalKey builds a wrapper from a 32-byte key
+func NewLocalKey(key []byte) (*LocalKey, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The ID is a fingerprint so rotating the file is detected without storing the key
+	sum := sha256.Sum256(key)
+	return &LocalKey{id: "local:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+func (k *LocalKey) ID() string { return k.id }
+
+func (k *LocalKey) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (k *LocalKey) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	n := k.aead.NonceSize()
+	if len(wrapped) < n {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	plaintext, err := k.aead.Open(nil, wrapped[:n], wrapped[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", k.id, err)
+	}
+	return plaintext, nil
+}
+
+func decodeKey(data []byte) ([]byte, error) {
+	if len(data) == 32 {
+		return data, nil
+	}
+	text := strings.TrimSpace(string(data))
+	if b, err := hex.DecodeString(text); err == nil && len(b) == 32 {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(text); err == nil && len(b) == 32 {
+		return b, nil
+	}
+	return nil, fmt.Errorf("expected 32 raw bytes or their hex/base64 encoding")
+}
@@ -0,0 +1,108 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultTransit wraps data keys with a HashiCorp Vault transit key. The
+// address, token and namespace come from VAULT_ADDR, VAULT_TOKEN and
+// VAULT_NAMESPACE like the Vault CLI.
+type VaultTransit struct {
+	addr      string
+	token     string
+	namespace string
+	mount     string
+	key       string
+	client    *http.Client
+}
+
+// NewVaultTransit builds a transit wrapper for keyRef ("my-key" or "mount/my-key")
+func NewVaultTransit(keyRef string) (*VaultTransit, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is required for the vault kms provider")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is required for the vault kms provider")
+	}
+	mount, key := "transit", keyRef
+	if i := strings.LastIndex(keyRef, "/"); i >= 0 {
+		mount, key = keyRef[:i], keyRef[i+1:]
+	}
+	return &VaultTransit{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		mount:     strings.Trim(mount, "/"),
+		key:       key,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (v *VaultTransit) ID() string { return "vault:" + v.mount + "/" + v.key }
+
+func (v *VaultTransit) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if err := v.call(ctx, "encrypt", in, &out); err != nil {
+		return nil, err
+	}
+	// Transit ciphertext ("vault:v1:...") is already text; store it as-is
+	return []byte(out.Data.Ciphertext), nil
+}
+
+func (v *VaultTransit) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := v.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Data.Plaintext)
+}
+
+func (v *VaultTransit) call(ctx context.Context, op string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", v.addr, v.mount, op, v.key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault transit %s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault transit %s: HTTP %d: %s", op, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -279,6 +279,30 @@ func (s *SQLStore) UpdateUnitTFESettings(ctx context.Context, unitID string, aut
 		Updates(updates).Error
 }
 
+// UpdateUnitVCSSettings binds a unit to a VCS repository branch; an empty repo URL removes the binding
+func (s *SQLStore) UpdateUnitVCSSettings(ctx context.Context, unitID string, repoURL *string, branch *string) error {
+	updates := make(map[string]interface{})
+
+	if repoURL != nil {
+		if *repoURL == "" {
+			updates["tfe_vcs_repo_url"] = nil
+		} else {
+			updates["tfe_vcs_repo_url"] = *repoURL
+		}
+	}
+	if branch != nil {
+		updates["tfe_vcs_branch"] = *branch
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).Model(&types.Unit{}).
+		Where("id = ?", unitID).
+		Updates(updates).Error
+}
+
 func (s *SQLStore) SyncUnitLock(ctx context.Context, blobPath string, lockID, lockWho string, lockCreated time.Time) error {
 	orgUUID, unitUUID, err := s.parseBlobPath(ctx, blobPath)
 	if err != nil {
@@ -20,6 +20,7 @@ type UnitQuery interface {
 	SyncUnitUnlock(ctx context.Context, unitName string) error
 	SyncDeleteUnit(ctx context.Context, unitName string) error
 	UpdateUnitTFESettings(ctx context.Context, unitID string, autoApply *bool, executionMode *string, terraformVersion *string, engine *string, workingDirectory *string) error
+	UpdateUnitVCSSettings(ctx context.Context, unitID string, repoURL *string, branch *string) error
 }
 
 type RBACQuery interface {
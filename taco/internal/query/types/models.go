@@ -161,6 +161,10 @@ type Unit struct {
 	TFEEngine           *string `gorm:"type:varchar(20);default:'terraform'"` // 'terraform' or 'tofu'
 	TFEWorkingDirectory *string `gorm:"type:varchar(500);default:null"`
 	TFEExecutionMode    *string `gorm:"type:varchar(50);default:null"` // 'remote', 'local', 'agent'
+
+	// VCS binding: pushes to this repository branch queue runs
+	TFEVCSRepoURL *string `gorm:"column:tfe_vcs_repo_url;type:varchar(1000);default:null"`
+	TFEVCSBranch  *string `gorm:"column:tfe_vcs_branch;type:varchar(255);default:null"`
 }
 
 func (u *Unit) BeforeCreate(tx *gorm.DB) error {
@@ -247,10 +251,17 @@ type TFERun struct {
 	AutoApply bool   `gorm:"default:false"`                  // Whether to auto-trigger apply after successful plan
 	Source    string `gorm:"type:varchar(50);default:'cli'"` // 'cli', 'api', 'ui', 'vcs'
 
+	// Commit that triggered a VCS run
+	CommitSHA     *string `gorm:"type:varchar(64)"`
+	CommitMessage *string `gorm:"type:text"`
+
 	// Actions (stored as fields)
 	IsCancelable bool `gorm:"default:true"`
 	CanApply     bool `gorm:"default:false"`
 
+	// Set by a cancel request; the replica executing the run polls it
+	CancelRequested bool `gorm:"default:false"`
+
 	// Relationships (foreign keys)
 	ConfigurationVersionID string  `gorm:"type:varchar(36);not null;index"`
 	PlanID                 *string `gorm:"type:varchar(50);index"` // Nullable until plan is created 
@@ -398,6 +409,108 @@ func (rra *RemoteRunActivity) BeforeCreate(tx *gorm.DB) error {
 
 func (RemoteRunActivity) TableName() string { return "remote_run_activity" }
 
+// AuditEvent is an append-only, hash-chained audit record (one chain per org)
+type AuditEvent struct {
+	ID        string    `gorm:"type:varchar(36);primaryKey"`
+	OrgID     string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_audit_events_org_seq;index:idx_audit_events_org_timestamp"`
+	Seq       int64     `gorm:"not null;uniqueIndex:idx_audit_events_org_seq"`
+	Timestamp time.Time `gorm:"not null;index:idx_audit_events_org_timestamp"`
+	Actor     string    `gorm:"type:varchar(255);not null;index"`
+	Action    string    `gorm:"type:varchar(64);not null;index"`
+	Resource  string    `gorm:"type:varchar(500)"`
+	SourceIP  string    `gorm:"type:varchar(64)"`
+	Outcome   string    `gorm:"type:varchar(16);not null"`
+	Status    int       `gorm:"default:0"`
+	RequestID string    `gorm:"type:varchar(100)"`
+	Details   string    `gorm:"type:text"` // JSON-encoded map[string]string
+	PrevHash  string    `gorm:"type:varchar(64)"`
+	Hash      string    `gorm:"type:varchar(64);not null"`
+}
+
+func (a *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (AuditEvent) TableName() string { return "audit_events" }
+
+// DataKey is a wrapped per-scope state encryption key
+type DataKey struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey"`
+	Scope       string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_encryption_data_keys_scope_version"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_encryption_data_keys_scope_version"`
+	WrappedKey  []byte    `gorm:"not null"`
+	MasterKeyID string    `gorm:"type:varchar(500);not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	RotatedAt   time.Time
+}
+
+func (d *DataKey) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (DataKey) TableName() string { return "encryption_data_keys" }
+
+// TFEVariable stores a workspace variable or a variable set member. Value is
+// sealed with the encryption keyring before it is written.
+type TFEVariable struct {
+	ID            string    `gorm:"type:varchar(36);primaryKey"`
+	OrgID         string    `gorm:"type:varchar(36);not null;index"`
+	UnitID        *string   `gorm:"type:varchar(36);index"`
+	VariableSetID *string   `gorm:"type:varchar(36);index"`
+	Key           string    `gorm:"column:var_key;type:varchar(255);not null"` // "key" is reserved in MySQL
+	Value         []byte
+	Description   string    `gorm:"type:text"`
+	Category      string    `gorm:"type:varchar(20);not null;default:'terraform'"`
+	HCL           bool      `gorm:"column:hcl;default:false"`
+	Sensitive     bool      `gorm:"default:false"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (v *TFEVariable) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (TFEVariable) TableName() string { return "tfe_variables" }
+
+// TFEVariableSet groups variables shared between workspaces
+type TFEVariableSet struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey"`
+	OrgID       string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_tfe_variable_sets_org_name"`
+	Name        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_tfe_variable_sets_org_name"`
+	Description string    `gorm:"type:text"`
+	Global      bool      `gorm:"default:false"`
+	Priority    bool      `gorm:"default:false"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (s *TFEVariableSet) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (TFEVariableSet) TableName() string { return "tfe_variable_sets" }
+
+// TFEVariableSetUnit applies a variable set to a workspace
+type TFEVariableSetUnit struct {
+	VariableSetID string `gorm:"type:varchar(36);primaryKey"`
+	UnitID        string `gorm:"type:varchar(36);primaryKey;index"`
+}
+
+func (TFEVariableSetUnit) TableName() string { return "tfe_variable_set_units" }
+
 var DefaultModels = []any{
 	&Organization{},
 	&User{},
@@ -417,4 +530,9 @@ var DefaultModels = []any{
 	&TFEPlan{},
 	&TFEConfigurationVersion{},
 	&RemoteRunActivity{},
+	&AuditEvent{},
+	&DataKey{},
+	&TFEVariable{},
+	&TFEVariableSet{},
+	&TFEVariableSetUnit{},
 }
@@ -345,6 +345,45 @@ func (s *queryRBACStore) ListUserAssignments(ctx context.Context, orgID string)
 	return assignments, nil
 }
 
+// BackfillAdminActions grants the actions added to the admin permission since it was
+// seeded by InitializeRBAC to the admin permissions of existing organizations, so
+// that their admins are not locked out of new endpoints after an upgrade. The seeded
+// rule is recognized by its rbac.manage grant. It returns the number of rules updated.
+func BackfillAdminActions(ctx context.Context, db *gorm.DB) (int, error) {
+	var rules []types.Rule
+	err := db.WithContext(ctx).
+		Joins("JOIN permissions ON permissions.id = rules.permission_id").
+		Where("permissions.name = ? AND rules.effect = ? AND rules.wildcard_action = ?", "admin", "allow", false).
+		Where("rules.id IN (?)", db.Model(&types.RuleAction{}).Select("rule_id").Where("action = ?", string(ActionRBACManage))).
+		Preload("Actions").
+		Find(&rules).Error
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, rule := range rules {
+		granted := make(map[string]bool, len(rule.Actions))
+		for _, ra := range rule.Actions {
+			granted[ra.Action] = true
+		}
+		var missing []types.RuleAction
+		for _, action := range addedAdminActions {
+			if !granted[string(action)] {
+				missing = append(missing, types.RuleAction{RuleID: rule.ID, Action: string(action)})
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if err := db.WithContext(ctx).Create(&missing).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
 // ============================================
 // Conversion Helpers
 // ============================================
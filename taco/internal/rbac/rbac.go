@@ -26,8 +26,13 @@ const (
     ActionUnitLock    Action = "unit.lock"
     ActionUnitDelete  Action = "unit.delete"
     ActionRBACManage  Action = "rbac.manage"
+    ActionAuditRead   Action = "audit.read"
 )
 
+// addedAdminActions are the actions added to the seeded admin permission after its
+// first release. BackfillAdminActions grants them to organizations initialized earlier.
+var addedAdminActions = []Action{ActionAuditRead}
+
 // Principal captures the caller identity and roles/groups.
 type Principal struct {
 	Subject string
@@ -212,7 +217,7 @@ func (m *RBACManager) InitializeRBAC(ctx context.Context, orgID, initUser, initE
 		Description: "Admin permission allowing all actions on all resources",
 		Rules: []PermissionRule{
 			{
-				Actions:   []Action{ActionUnitRead, ActionUnitWrite, ActionUnitLock, ActionUnitDelete, ActionRBACManage},
+				Actions:   []Action{ActionUnitRead, ActionUnitWrite, ActionUnitLock, ActionUnitDelete, ActionRBACManage, ActionAuditRead},
 				Resources: []string{"*"},
 				Effect:    "allow",
 			},
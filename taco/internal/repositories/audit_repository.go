@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/query/types"
+	"gorm.io/gorm"
+)
+
+// AuditRepository stores hash-chained audit events using GORM
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append inserts an event. The unique (org_id, seq) index rejects a second
+// writer racing for the same position in the chain.
+func (r *AuditRepository) Append(ctx context.Context, event *domain.AuditEvent) error {
+	details := ""
+	if len(event.Details) > 0 {
+		b, err := json.Marshal(event.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		details = string(b)
+	}
+
+	record := &types.AuditEvent{
+		ID:        event.ID,
+		OrgID:     event.OrgID,
+		Seq:       event.Seq,
+		Timestamp: event.Timestamp,
+		Actor:     event.Actor,
+		Action:    event.Action,
+		Resource:  event.Resource,
+		SourceIP:  event.SourceIP,
+		Outcome:   event.Outcome,
+		Status:    event.Status,
+		RequestID: event.RequestID,
+		Details:   details,
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	}
+
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+
+	event.ID = record.ID
+	return nil
+}
+
+// Last returns the most recent event in the org's chain, or nil if the chain is empty
+func (r *AuditRepository) Last(ctx context.Context, orgID string) (*domain.AuditEvent, error) {
+	var record types.AuditEvent
+	err := r.db.WithContext(ctx).
+		Where("org_id = ?", orgID).
+		Order("seq DESC").
+		First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last audit event: %w", err)
+	}
+	return auditEventToDomain(&record), nil
+}
+
+// List returns events matching the filter in chain order (oldest first)
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	query := r.db.WithContext(ctx).Model(&types.AuditEvent{}).
+		Where("org_id = ?", filter.OrgID)
+
+	if filter.Actor != nil {
+		query = query.Where("actor = ?", *filter.Actor)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.Resource != nil {
+		query = query.Where("resource = ?", *filter.Resource)
+	}
+	if filter.Outcome != nil {
+		query = query.Where("outcome = ?", *filter.Outcome)
+	}
+	if filter.Since != nil {
+		query = query.Where("timestamp >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("timestamp <= ?", *filter.Until)
+	}
+	if filter.AfterSeq > 0 {
+		query = query.Where("seq > ?", filter.AfterSeq)
+	}
+
+	// Pagination
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	} else {
+		query = query.Limit(100) // Default limit
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	query = query.Order("seq ASC")
+
+	var records []types.AuditEvent
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	events := make([]*domain.AuditEvent, len(records))
+	for i := range records {
+		events[i] = auditEventToDomain(&records[i])
+	}
+	return events, nil
+}
+
+func auditEventToDomain(record *types.AuditEvent) *domain.AuditEvent {
+	event := &domain.AuditEvent{
+		ID:        record.ID,
+		OrgID:     record.OrgID,
+		Seq:       record.Seq,
+		Timestamp: record.Timestamp.UTC(),
+		Actor:     record.Actor,
+		Action:    record.Action,
+		Resource:  record.Resource,
+		SourceIP:  record.SourceIP,
+		Outcome:   record.Outcome,
+		Status:    record.Status,
+		RequestID: record.RequestID,
+		PrevHash:  record.PrevHash,
+		Hash:      record.Hash,
+	}
+	if record.Details != "" {
+		// Details were written by Append; a decode failure means the row was edited,
+		// which verification will report through the hash mismatch.
+		_ = json.Unmarshal([]byte(record.Details), &event.Details)
+	}
+	return event
+}
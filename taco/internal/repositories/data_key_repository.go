@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/query/types"
+	"gorm.io/gorm"
+)
+
+// DataKeyRepository stores wrapped state encryption keys using GORM
+type DataKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewDataKeyRepository creates a new data key repository
+func NewDataKeyRepository(db *gorm.DB) *DataKeyRepository {
+	return &DataKeyRepository{db: db}
+}
+
+// Create inserts a key version. The unique (scope, version) index makes
+// concurrent creators race safely: the loser gets ErrDataKeyExists and reloads.
+func (r *DataKeyRepository) Create(ctx context.Context, key *domain.DataKey) error {
+	record := &types.DataKey{
+		ID:          key.ID,
+		Scope:       key.Scope,
+		Version:     key.Version,
+		WrappedKey:  key.WrappedKey,
+		MasterKeyID: key.MasterKeyID,
+		RotatedAt:   key.RotatedAt,
+	}
+
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "duplicate") ||
+			strings.Contains(errMsg, "Duplicate") ||
+			strings.Contains(errMsg, "unique constraint") ||
+			strings.Contains(errMsg, "UNIQUE constraint") {
+			return domain.ErrDataKeyExists
+		}
+		return fmt.Errorf("failed to create data key: %w", err)
+	}
+
+	key.ID = record.ID
+	key.CreatedAt = record.CreatedAt
+	return nil
+}
+
+// Get returns a key by ID
+func (r *DataKeyRepository) Get(ctx context.Context, id string) (*domain.DataKey, error) {
+	var record types.DataKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("data key %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return dataKeyToDomain(&record), nil
+}
+
+// Latest returns the newest key version for a scope, or nil if none exists
+func (r *DataKeyRepository) Latest(ctx context.Context, scope string) (*domain.DataKey, error) {
+	var record types.DataKey
+	err := r.db.WithContext(ctx).
+		Where("scope = ?", scope).
+		Order("version DESC").
+		First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return dataKeyToDomain(&record), nil
+}
+
+// List returns every key ordered by scope and version
+func (r *DataKeyRepository) List(ctx context.Context) ([]*domain.DataKey, error) {
+	var records []types.DataKey
+	if err := r.db.WithContext(ctx).Order("scope ASC, version ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data keys: %w", err)
+	}
+	keys := make([]*domain.DataKey, 0, len(records))
+	for i := range records {
+		keys = append(keys, dataKeyToDomain(&records[i]))
+	}
+	return keys, nil
+}
+
+// UpdateWrapped replaces the wrapped key material after a master key rotation
+func (r *DataKeyRepository) UpdateWrapped(ctx context.Context, id string, wrapped []byte, masterKeyID string) error {
+	result := r.db.WithContext(ctx).Model(&types.DataKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"wrapped_key":   wrapped,
+			"master_key_id": masterKeyID,
+			"rotated_at":    time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update data key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("data key %s not found", id)
+	}
+	return nil
+}
+
+func dataKeyToDomain(record *types.DataKey) *domain.DataKey {
+	return &domain.DataKey{
+		ID:          record.ID,
+		Scope:       record.Scope,
+		Version:     record.Version,
+		WrappedKey:  record.WrappedKey,
+		MasterKeyID: record.MasterKeyID,
+		CreatedAt:   record.CreatedAt,
+		RotatedAt:   record.RotatedAt,
+	}
+}
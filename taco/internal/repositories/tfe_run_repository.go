@@ -39,6 +39,8 @@ func (r *TFERunRepository) CreateRun(ctx context.Context, run *domain.TFERun) er
 		ApplyID:                run.ApplyID,
 		ApplyLogBlobID:         run.ApplyLogBlobID,
 		CreatedBy:              run.CreatedBy,
+		CommitSHA:              run.CommitSHA,
+		CommitMessage:          run.CommitMessage,
 	}
 
 	// Create the run - but GORM may not respect false for boolean fields with database defaults
@@ -95,6 +97,9 @@ func (r *TFERunRepository) GetRun(ctx context.Context, runID string) (*domain.TF
 		ApplyLogBlobID:         dbRun.ApplyLogBlobID,
 		ErrorMessage:           dbRun.ErrorMessage,
 		CreatedBy:              dbRun.CreatedBy,
+		CommitSHA:              dbRun.CommitSHA,
+		CancelRequested:        dbRun.CancelRequested,
+		CommitMessage:          dbRun.CommitMessage,
 	}, nil
 }
 
@@ -131,6 +136,50 @@ func (r *TFERunRepository) ListRunsForUnit(ctx context.Context, unitID string, l
 			ApplyID:                dbRun.ApplyID,
 			ErrorMessage:           dbRun.ErrorMessage,
 			CreatedBy:              dbRun.CreatedBy,
+			CommitSHA:              dbRun.CommitSHA,
+			CancelRequested:        dbRun.CancelRequested,
+			CommitMessage:          dbRun.CommitMessage,
+		}
+	}
+
+	return runs, nil
+}
+
+// ListRunsForUnitByStatus retrieves runs for a unit in the given statuses, oldest first
+func (r *TFERunRepository) ListRunsForUnitByStatus(ctx context.Context, unitID string, statuses []string) ([]*domain.TFERun, error) {
+	var dbRuns []types.TFERun
+
+	if err := r.db.WithContext(ctx).
+		Where("unit_id = ? AND status IN ?", unitID, statuses).
+		Order("created_at ASC").
+		Find(&dbRuns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list runs for unit: %w", err)
+	}
+
+	runs := make([]*domain.TFERun, len(dbRuns))
+	for i, dbRun := range dbRuns {
+		runs[i] = &domain.TFERun{
+			ID:                     dbRun.ID,
+			OrgID:                  dbRun.OrgID,
+			UnitID:                 dbRun.UnitID,
+			CreatedAt:              dbRun.CreatedAt,
+			UpdatedAt:              dbRun.UpdatedAt,
+			Status:                 dbRun.Status,
+			IsDestroy:              dbRun.IsDestroy,
+			Message:                dbRun.Message,
+			PlanOnly:               dbRun.PlanOnly,
+			AutoApply:              dbRun.AutoApply,
+			Source:                 dbRun.Source,
+			IsCancelable:           dbRun.IsCancelable,
+			CanApply:               dbRun.CanApply,
+			ConfigurationVersionID: dbRun.ConfigurationVersionID,
+			PlanID:                 dbRun.PlanID,
+			ApplyID:                dbRun.ApplyID,
+			ErrorMessage:           dbRun.ErrorMessage,
+			CreatedBy:              dbRun.CreatedBy,
+			CommitSHA:              dbRun.CommitSHA,
+			CancelRequested:        dbRun.CancelRequested,
+			CommitMessage:          dbRun.CommitMessage,
 		}
 	}
 
@@ -225,3 +274,35 @@ func (r *TFERunRepository) UpdateRunError(ctx context.Context, runID string, err
 	return nil
 }
 
+
+// TransitionRunStatus moves a run to status only if its current status is one
+// of from. It reports whether the transition happened, which lets concurrent
+// callers (queue, executors, cancel requests) race safely on the same run.
+func (r *TFERunRepository) TransitionRunStatus(ctx context.Context, runID string, from []string, status string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&types.TFERun{}).
+		Where("id = ? AND status IN ?", runID, from).
+		Update("status", status)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to transition run status: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RequestRunCancel flags a run for cancellation if its current status is one of
+// from. The replica executing the run polls the flag, so a cancel request served
+// by any replica reaches the execution.
+func (r *TFERunRepository) RequestRunCancel(ctx context.Context, runID string, from []string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&types.TFERun{}).
+		Where("id = ? AND status IN ?", runID, from).
+		Update("cancel_requested", true)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to request run cancel: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,368 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/query/types"
+	"gorm.io/gorm"
+)
+
+// TFEVariableRepository stores workspace variables and variable sets using
+// GORM. Values are sealed with the org's data key before they are written.
+type TFEVariableRepository struct {
+	db     *gorm.DB
+	sealer domain.ValueSealer
+}
+
+// NewTFEVariableRepository creates a new variable repository. sealer may be
+// nil, in which case every write fails: variables are never stored in plaintext.
+func NewTFEVariableRepository(db *gorm.DB, sealer domain.ValueSealer) *TFEVariableRepository {
+	return &TFEVariableRepository{db: db, sealer: sealer}
+}
+
+// ListWorkspaceVariables returns the variables attached directly to a unit
+func (r *TFEVariableRepository) ListWorkspaceVariables(ctx context.Context, unitID string) ([]*domain.TFEVariable, error) {
+	return r.listVariables(ctx, "unit_id = ?", unitID)
+}
+
+// ListVariableSetVariables returns the members of a variable set
+func (r *TFEVariableRepository) ListVariableSetVariables(ctx context.Context, setID string) ([]*domain.TFEVariable, error) {
+	return r.listVariables(ctx, "variable_set_id = ?", setID)
+}
+
+func (r *TFEVariableRepository) listVariables(ctx context.Context, query string, arg string) ([]*domain.TFEVariable, error) {
+	var records []types.TFEVariable
+	if err := r.db.WithContext(ctx).Where(query, arg).Order("var_key ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list variables: %w", err)
+	}
+	vars := make([]*domain.TFEVariable, 0, len(records))
+	for i := range records {
+		v, err := r.variableToDomain(ctx, &records[i])
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// GetVariable returns a variable by ID
+func (r *TFEVariableRepository) GetVariable(ctx context.Context, id string) (*domain.TFEVariable, error) {
+	var record types.TFEVariable
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrVariableNotFound
+		}
+		return nil, fmt.Errorf("failed to get variable: %w", err)
+	}
+	return r.variableToDomain(ctx, &record)
+}
+
+// CreateVariable inserts a variable. Keys are unique per category within a
+// workspace or variable set.
+func (r *TFEVariableRepository) CreateVariable(ctx context.Context, v *domain.TFEVariable) error {
+	if err := r.checkDuplicate(ctx, v); err != nil {
+		return err
+	}
+	sealed, err := r.seal(ctx, v.OrgID, v.Value)
+	if err != nil {
+		return err
+	}
+	record := &types.TFEVariable{
+		ID:            v.ID,
+		OrgID:         v.OrgID,
+		UnitID:        v.UnitID,
+		VariableSetID: v.VariableSetID,
+		Key:           v.Key,
+		Value:         sealed,
+		Description:   v.Description,
+		Category:      v.Category,
+		HCL:           v.HCL,
+		Sensitive:     v.Sensitive,
+	}
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to create variable: %w", err)
+	}
+	v.ID = record.ID
+	v.CreatedAt = record.CreatedAt
+	v.UpdatedAt = record.UpdatedAt
+	return nil
+}
+
+// UpdateVariable saves every mutable field of a variable
+func (r *TFEVariableRepository) UpdateVariable(ctx context.Context, v *domain.TFEVariable) error {
+	if err := r.checkDuplicate(ctx, v); err != nil {
+		return err
+	}
+	sealed, err := r.seal(ctx, v.OrgID, v.Value)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&types.TFEVariable{}).
+		Where("id = ?", v.ID).
+		Updates(map[string]interface{}{
+			"var_key":     v.Key,
+			"value":       sealed,
+			"description": v.Description,
+			"category":    v.Category,
+			"hcl":         v.HCL,
+			"sensitive":   v.Sensitive,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update variable: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVariableNotFound
+	}
+	return nil
+}
+
+// DeleteVariable removes a variable
+func (r *TFEVariableRepository) DeleteVariable(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&types.TFEVariable{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete variable: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVariableNotFound
+	}
+	return nil
+}
+
+// ListVariableSets returns every variable set in an org
+func (r *TFEVariableRepository) ListVariableSets(ctx context.Context, orgID string) ([]*domain.TFEVariableSet, error) {
+	var records []types.TFEVariableSet
+	if err := r.db.WithContext(ctx).Where("org_id = ?", orgID).Order("name ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list variable sets: %w", err)
+	}
+	return r.setsToDomain(ctx, records)
+}
+
+// ListVariableSetsForUnit returns global sets in the org plus sets applied to the unit
+func (r *TFEVariableRepository) ListVariableSetsForUnit(ctx context.Context, orgID, unitID string) ([]*domain.TFEVariableSet, error) {
+	var records []types.TFEVariableSet
+	err := r.db.WithContext(ctx).
+		Where("org_id = ?", orgID).
+		Where("global = ? OR id IN (?)", true,
+			r.db.Model(&types.TFEVariableSetUnit{}).Select("variable_set_id").Where("unit_id = ?", unitID)).
+		Order("name ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variable sets: %w", err)
+	}
+	return r.setsToDomain(ctx, records)
+}
+
+// GetVariableSet returns a variable set by ID
+func (r *TFEVariableRepository) GetVariableSet(ctx context.Context, id string) (*domain.TFEVariableSet, error) {
+	var record types.TFEVariableSet
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrVariableSetNotFound
+		}
+		return nil, fmt.Errorf("failed to get variable set: %w", err)
+	}
+	sets, err := r.setsToDomain(ctx, []types.TFEVariableSet{record})
+	if err != nil {
+		return nil, err
+	}
+	return sets[0], nil
+}
+
+// CreateVariableSet inserts a variable set and applies it to set.UnitIDs
+func (r *TFEVariableRepository) CreateVariableSet(ctx context.Context, set *domain.TFEVariableSet) error {
+	record := &types.TFEVariableSet{
+		ID:          set.ID,
+		OrgID:       set.OrgID,
+		Name:        set.Name,
+		Description: set.Description,
+		Global:      set.Global,
+		Priority:    set.Priority,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrVariableExists
+			}
+			return fmt.Errorf("failed to create variable set: %w", err)
+		}
+		return applySet(tx, record.ID, set.UnitIDs)
+	})
+	if err != nil {
+		return err
+	}
+	set.ID = record.ID
+	set.CreatedAt = record.CreatedAt
+	set.UpdatedAt = record.UpdatedAt
+	return nil
+}
+
+// UpdateVariableSet saves the name, description and flags of a variable set
+func (r *TFEVariableRepository) UpdateVariableSet(ctx context.Context, set *domain.TFEVariableSet) error {
+	result := r.db.WithContext(ctx).Model(&types.TFEVariableSet{}).
+		Where("id = ?", set.ID).
+		Updates(map[string]interface{}{
+			"name":        set.Name,
+			"description": set.Description,
+			"global":      set.Global,
+			"priority":    set.Priority,
+		})
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			return domain.ErrVariableExists
+		}
+		return fmt.Errorf("failed to update variable set: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVariableSetNotFound
+	}
+	return nil
+}
+
+// DeleteVariableSet removes a variable set, its variables and its workspace links
+func (r *TFEVariableRepository) DeleteVariableSet(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("variable_set_id = ?", id).Delete(&types.TFEVariable{}).Error; err != nil {
+			return fmt.Errorf("failed to delete variable set variables: %w", err)
+		}
+		if err := tx.Where("variable_set_id = ?", id).Delete(&types.TFEVariableSetUnit{}).Error; err != nil {
+			return fmt.Errorf("failed to delete variable set workspaces: %w", err)
+		}
+		result := tx.Where("id = ?", id).Delete(&types.TFEVariableSet{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete variable set: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrVariableSetNotFound
+		}
+		return nil
+	})
+}
+
+// ApplyVariableSet links a variable set to units; already linked units are ignored
+func (r *TFEVariableRepository) ApplyVariableSet(ctx context.Context, setID string, unitIDs []string) error {
+	return applySet(r.db.WithContext(ctx), setID, unitIDs)
+}
+
+// RemoveVariableSet unlinks a variable set from units
+func (r *TFEVariableRepository) RemoveVariableSet(ctx context.Context, setID string, unitIDs []string) error {
+	if len(unitIDs) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("variable_set_id = ? AND unit_id IN ?", setID, unitIDs).
+		Delete(&types.TFEVariableSetUnit{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to remove variable set: %w", err)
+	}
+	return nil
+}
+
+func applySet(tx *gorm.DB, setID string, unitIDs []string) error {
+	for _, unitID := range unitIDs {
+		link := &types.TFEVariableSetUnit{VariableSetID: setID, UnitID: unitID}
+		if err := tx.Create(link).Error; err != nil {
+			if isUniqueViolation(err) {
+				continue
+			}
+			return fmt.Errorf("failed to apply variable set: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *TFEVariableRepository) checkDuplicate(ctx context.Context, v *domain.TFEVariable) error {
+	q := r.db.WithContext(ctx).Model(&types.TFEVariable{}).
+		Where("var_key = ? AND category = ?", v.Key, v.Category)
+	if v.UnitID != nil {
+		q = q.Where("unit_id = ?", *v.UnitID)
+	} else if v.VariableSetID != nil {
+		q = q.Where("variable_set_id = ?", *v.VariableSetID)
+	}
+	if v.ID != "" {
+		q = q.Where("id <> ?", v.ID)
+	}
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check variable: %w", err)
+	}
+	if count > 0 {
+		return domain.ErrVariableExists
+	}
+	return nil
+}
+
+func (r *TFEVariableRepository) seal(ctx context.Context, orgID, value string) ([]byte, error) {
+	if r.sealer == nil {
+		return nil, fmt.Errorf("variable encryption is not configured")
+	}
+	sealed, err := r.sealer.Seal(ctx, orgID, []byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt variable: %w", err)
+	}
+	return sealed, nil
+}
+
+func (r *TFEVariableRepository) variableToDomain(ctx context.Context, record *types.TFEVariable) (*domain.TFEVariable, error) {
+	value := record.Value
+	if r.sealer != nil && len(value) > 0 {
+		opened, err := r.sealer.Open(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt variable %s: %w", record.ID, err)
+		}
+		value = opened
+	}
+	return &domain.TFEVariable{
+		ID:            record.ID,
+		OrgID:         record.OrgID,
+		UnitID:        record.UnitID,
+		VariableSetID: record.VariableSetID,
+		Key:           record.Key,
+		Value:         string(value),
+		Description:   record.Description,
+		Category:      record.Category,
+		HCL:           record.HCL,
+		Sensitive:     record.Sensitive,
+		CreatedAt:     record.CreatedAt,
+		UpdatedAt:     record.UpdatedAt,
+	}, nil
+}
+
+func (r *TFEVariableRepository) setsToDomain(ctx context.Context, records []types.TFEVariableSet) ([]*domain.TFEVariableSet, error) {
+	sets := make([]*domain.TFEVariableSet, 0, len(records))
+	for i := range records {
+		var unitIDs []string
+		err := r.db.WithContext(ctx).Model(&types.TFEVariableSetUnit{}).
+			Where("variable_set_id = ?", records[i].ID).
+			Pluck("unit_id", &unitIDs).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variable set workspaces: %w", err)
+		}
+		sets = append(sets, &domain.TFEVariableSet{
+			ID:          records[i].ID,
+			OrgID:       records[i].OrgID,
+			Name:        records[i].Name,
+			Description: records[i].Description,
+			Global:      records[i].Global,
+			Priority:    records[i].Priority,
+			UnitIDs:     unitIDs,
+			CreatedAt:   records[i].CreatedAt,
+			UpdatedAt:   records[i].UpdatedAt,
+		})
+	}
+	return sets, nil
+}
+
+func isUniqueViolation(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate") ||
+		strings.Contains(errMsg, "Duplicate") ||
+		strings.Contains(errMsg, "unique constraint") ||
+		strings.Contains(errMsg, "UNIQUE constraint")
+}
+
+var _ domain.TFEVariableRepository = (*TFEVariableRepository)(nil)
@@ -155,6 +155,8 @@ func (r *UnitRepository) Get(ctx context.Context, uuid string) (*storage.UnitMet
 		TFEEngine:           unit.TFEEngine,
 		TFEWorkingDirectory: unit.TFEWorkingDirectory,
 		TFEExecutionMode:    unit.TFEExecutionMode,
+		TFEVCSRepoURL:       unit.TFEVCSRepoURL,
+		TFEVCSBranch:        unit.TFEVCSBranch,
 	}
 
 	// Use blob lock info if available
@@ -70,6 +70,8 @@ func (s *e2bSandbox) ExecutePlan(ctx context.Context, req *PlanRequest) (*PlanRe
 		WorkingDirectory:       req.WorkingDirectory,
 		ConfigArchive:          base64.StdEncoding.EncodeToString(req.ConfigArchive),
 		State:                  encodeOptional(req.State),
+		EnvVars:                req.EnvVars,
+		TFVars:                 req.TFVars,
 		Metadata:               req.Metadata,
 	})
 	if err != nil {
@@ -131,6 +133,8 @@ func (s *e2bSandbox) ExecuteApply(ctx context.Context, req *ApplyRequest) (*Appl
 		WorkingDirectory:       req.WorkingDirectory,
 		ConfigArchive:          base64.StdEncoding.EncodeToString(req.ConfigArchive),
 		State:                  encodeOptional(req.State),
+		EnvVars:                req.EnvVars,
+		TFVars:                 req.TFVars,
 		Metadata:               req.Metadata,
 	})
 	if err != nil {
@@ -334,6 +338,8 @@ type e2bRunRequest struct {
 	WorkingDirectory       string            `json:"working_directory,omitempty"`
 	ConfigArchive          string            `json:"config_archive"`
 	State                  string            `json:"state,omitempty"`
+	EnvVars                map[string]string `json:"env_vars,omitempty"`
+	TFVars                 string            `json:"tfvars,omitempty"`
 	Metadata               map[string]string `json:"metadata,omitempty"`
 }
 
@@ -15,7 +15,11 @@ type PlanRequest struct {
 	WorkingDirectory       string
 	ConfigArchive          []byte
 	State                  []byte
-	Metadata               map[string]string
+	// EnvVars and TFVars carry the resolved workspace variables: environment
+	// variables for the terraform process and the contents of an auto.tfvars file.
+	EnvVars  map[string]string
+	TFVars   string
+	Metadata map[string]string
 	// LogSink is an optional callback that receives incremental log chunks
 	// as they are observed while polling the sandbox run.
 	LogSink func(chunk string)
@@ -45,7 +49,11 @@ type ApplyRequest struct {
 	WorkingDirectory       string
 	ConfigArchive          []byte
 	State                  []byte
-	Metadata               map[string]string
+	// EnvVars and TFVars carry the resolved workspace variables: environment
+	// variables for the terraform process and the contents of an auto.tfvars file.
+	EnvVars  map[string]string
+	TFVars   string
+	Metadata map[string]string
 	// LogSink is an optional callback that receives incremental log chunks
 	// as they are observed while polling the sandbox run.
 	LogSink func(chunk string)
@@ -30,7 +30,12 @@ type UnitMetadata struct {
     TFEEngine           *string `json:"tfe_engine,omitempty"` // 'terraform' or 'tofu'
     TFEWorkingDirectory *string `json:"tfe_working_directory,omitempty"`
     TFEExecutionMode    *string `json:"tfe_execution_mode,omitempty"` // 'remote', 'local', 'agent'
+    TFEVCSRepoURL       *string `json:"tfe_vcs_repo_url,omitempty"`
+    TFEVCSBranch        *string `json:"tfe_vcs_branch,omitempty"`
     LockID              string  `json:"lock_id,omitempty"`
+
+    // Attributes kept with the current object by an AttributeStore, nil for other stores
+    Attributes map[string]string `json:"-"`
 }
 
 type VersionInfo struct {
@@ -69,3 +74,11 @@ type UnitStore interface {
     ListVersions(ctx context.Context, id string) ([]*VersionInfo, error)
     RestoreVersion(ctx context.Context, id string, versionTimestamp time.Time, lockID string) error
 }
+
+// AttributeStore is implemented by stores that keep small string attributes with
+// the current object of a unit, e.g. as S3 object metadata. Get returns them in
+// UnitMetadata.Attributes and versions keep the attributes they were written with.
+// Attribute names must be lower case.
+type AttributeStore interface {
+	UploadWithAttributes(ctx context.Context, id string, data []byte, lockID string, attributes map[string]string) error
+}
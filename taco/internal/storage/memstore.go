@@ -25,11 +25,14 @@ type unitData struct {
 }
 
 type versionData struct {
-	timestamp time.Time
-	hash      string
-	content   []byte
+	timestamp  time.Time
+	hash       string
+	content    []byte
+	attributes map[string]string
 }
 
+var _ AttributeStore = (*memStore)(nil)
+
 func NewMemStore() UnitStore {
     return &memStore{
         units: make(map[string]*unitData),
@@ -75,6 +78,7 @@ func (m *memStore) Get(ctx context.Context, id string) (*UnitMetadata, error) {
         Updated:  state.metadata.Updated,
         Locked:   state.metadata.Locked,
         LockInfo: state.metadata.LockInfo,
+        Attributes: copyAttributes(state.metadata.Attributes),
     }, nil
 }
 
@@ -92,6 +96,7 @@ func (m *memStore) List(ctx context.Context, prefix string) ([]*UnitMetadata, er
                 Updated:  unit.metadata.Updated,
                 Locked:   unit.metadata.Locked,
                 LockInfo: unit.metadata.LockInfo,
+                Attributes: copyAttributes(unit.metadata.Attributes),
             })
         }
     }
@@ -154,6 +159,11 @@ func (m *memStore) UploadBlob(ctx context.Context, key string, data []byte) erro
 }
 
 func (m *memStore) Upload(ctx context.Context, id string, data []byte, lockID string) error {
+	return m.UploadWithAttributes(ctx, id, data, lockID, nil)
+}
+
+// UploadWithAttributes is Upload keeping attributes with the new content
+func (m *memStore) UploadWithAttributes(ctx context.Context, id string, data []byte, lockID string, attributes map[string]string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
@@ -194,9 +204,10 @@ func (m *memStore) Upload(ctx context.Context, id string, data []byte, lockID st
 		copy(archivedContent, state.content)
 		
 		version := &versionData{
-			timestamp: time.Now().UTC(),
-			hash:      hashStr,
-			content:   archivedContent,
+			timestamp:  time.Now().UTC(),
+			hash:       hashStr,
+			content:    archivedContent,
+			attributes: state.metadata.Attributes,
 		}
 		
 		state.versions = append(state.versions, version)
@@ -212,6 +223,7 @@ func (m *memStore) Upload(ctx context.Context, id string, data []byte, lockID st
 	copy(state.content, data)
 	state.metadata.Size = int64(len(data))
 	state.metadata.Updated = time.Now()
+	state.metadata.Attributes = copyAttributes(attributes)
 	
 	return nil
 }
@@ -344,9 +356,10 @@ func (m *memStore) RestoreVersion(ctx context.Context, id string, versionTimesta
 		copy(archivedContent, state.content)
 		
 		version := &versionData{
-			timestamp: time.Now().UTC(),
-			hash:      hashStr,
-			content:   archivedContent,
+			timestamp:  time.Now().UTC(),
+			hash:       hashStr,
+			content:    archivedContent,
+			attributes: state.metadata.Attributes,
 		}
 		
 		state.versions = append(state.versions, version)
@@ -362,6 +375,7 @@ func (m *memStore) RestoreVersion(ctx context.Context, id string, versionTimesta
 	copy(state.content, targetVersion.content)
 	state.metadata.Size = int64(len(targetVersion.content))
 	state.metadata.Updated = time.Now()
+	state.metadata.Attributes = targetVersion.attributes
 	
 	return nil
 }
@@ -401,3 +415,14 @@ func (m *memStore) cleanupOldVersions(id string) error {
 	
 	return nil
 }
+
+func copyAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		copied[k] = v
+	}
+	return copied
+}
@@ -31,6 +31,7 @@ type s3Store struct {
     prefix string
 }
 
+var _ AttributeStore = (*s3Store)(nil)
 
 // NewS3Store creates a new S3-backed unit store.
 // Region can be empty to use the default AWS config chain.
@@ -193,6 +194,7 @@ func (s *s3Store) Get(ctx context.Context, id string) (*UnitMetadata, error) {
     } else {
         meta.Updated = time.Now()
     }
+    meta.Attributes = head.Metadata
     // Enrich with lock info if present
     if li, _ := s.GetLock(ctx, id); li != nil {
         meta.Locked = true
@@ -334,6 +336,11 @@ func (s *s3Store) UploadBlob(ctx context.Context, key string, data []byte) error
 }
 
 func (s *s3Store) Upload(ctx context.Context, id string, data []byte, lockID string) error {
+    return s.UploadWithAttributes(ctx, id, data, lockID, nil)
+}
+
+// UploadWithAttributes is Upload storing attributes as object metadata of the new state
+func (s *s3Store) UploadWithAttributes(ctx context.Context, id string, data []byte, lockID string, attributes map[string]string) error {
     fmt.Printf("[S3Store.Upload] START - id=%s, dataLen=%d, lockID=%s\n", id, len(data), lockID)
     
     meta, err := s.Get(ctx, id)
@@ -403,6 +410,7 @@ func (s *s3Store) Upload(ctx context.Context, id string, data []byte, lockID str
         Key:    aws.String(newKey),
         Body:   bytes.NewReader(data),
         ContentType: aws.String("application/json"),
+        Metadata: attributes,
     }); err != nil {
         fmt.Printf("[S3Store.Upload] Upload failed: %v\n", err)
         return err
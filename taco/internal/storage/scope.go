@@ -0,0 +1,19 @@
+package storage
+
+import "context"
+
+type blobScopeKey struct{}
+
+// WithBlobScope records the organization that owns the objects written with
+// ctx. Stores that partition data per organization, such as the encryption
+// store, use it for keys that don't start with the organization, e.g. the
+// config-versions/, plans/ and applies/ blobs of TFE runs.
+func WithBlobScope(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, blobScopeKey{}, orgID)
+}
+
+// BlobScope returns the organization recorded by WithBlobScope, or ""
+func BlobScope(ctx context.Context) string {
+	orgID, _ := ctx.Value(blobScopeKey{}).(string)
+	return orgID
+}
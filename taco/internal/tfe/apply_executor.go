@@ -3,6 +3,7 @@ package tfe
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -27,6 +28,7 @@ type ApplyExecutor struct {
 	unitRepo      domain.UnitRepository
 	sandbox       sandbox.Sandbox
 	activityRepo  domain.RemoteRunActivityRepository
+	varRepo       domain.TFEVariableRepository
 }
 
 // NewApplyExecutor creates a new apply executor
@@ -38,6 +40,7 @@ func NewApplyExecutor(
 	unitRepo domain.UnitRepository,
 	sandboxProvider sandbox.Sandbox,
 	activityRepo domain.RemoteRunActivityRepository,
+	varRepo domain.TFEVariableRepository,
 ) *ApplyExecutor {
 	return &ApplyExecutor{
 		runRepo:       runRepo,
@@ -47,11 +50,15 @@ func NewApplyExecutor(
 		unitRepo:      unitRepo,
 		sandbox:       sandboxProvider,
 		activityRepo:  activityRepo,
+		varRepo:       varRepo,
 	}
 }
 
 // ExecuteApply executes a Terraform apply for a run
 func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
+	execCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+
 	logger := slog.Default().With(
 		slog.String("operation", "execute_apply"),
 		slog.String("run_id", runID),
@@ -65,6 +72,8 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 		logger.Error("failed to get run", slog.String("error", err.Error()))
 		return fmt.Errorf("failed to get run: %w", err)
 	}
+	// log chunks are encrypted with the data key of the organization of the run
+	ctx = storage.WithBlobScope(ctx, run.OrgID)
 
 	unitMeta, err := e.unitRepo.Get(ctx, run.UnitID)
 	if err != nil {
@@ -222,11 +231,16 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 		_ = flushLogs()
 	}()
 
-	// Update run status to "applying"
-	if err := e.runRepo.UpdateRunStatus(ctx, runID, "applying"); err != nil {
+	// Update run status to "applying", unless the run was canceled or discarded meanwhile
+	started, err := e.runRepo.TransitionRunStatus(ctx, runID, []string{"planned", "confirmed", "apply_queued"}, "applying")
+	if err != nil {
 		logger.Error("failed to update run status", slog.String("error", err.Error()))
 		return e.handleApplyError(ctx, run.ID, logger, fmt.Sprintf("Failed to update run status: %v", err))
 	}
+	if !started {
+		logger.Info("run left the queue before apply started; skipping apply")
+		return nil
+	}
 
 	logger.Info("updated run status to applying")
 
@@ -302,6 +316,11 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 		updatedState []byte
 	)
 
+	vars, err := resolveRunVariables(ctx, e.varRepo, run.OrgID, run.UnitID)
+	if err != nil {
+		return e.handleApplyError(ctx, run.ID, logger, fmt.Sprintf("Failed to load workspace variables: %v", err))
+	}
+
 	if useSandbox {
 		appendLog("Starting remote execution environment...\n")
 		appendLog("Initializing terraform...\n")
@@ -325,14 +344,14 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 					appendLog(fmt.Sprintf("Remote apply in progress... (%s)\n", time.Now().Format("15:04:05")))
 				case <-heartbeatDone:
 					return
-				case <-ctx.Done():
+				case <-execCtx.Done():
 					return
 				}
 			}
 		}()
 		defer close(heartbeatDone)
 
-		result, execErr := e.executeApplyInSandbox(ctx, run, unitMeta, workspaceArchive, stateData, appendLog)
+		result, execErr := e.executeApplyInSandbox(execCtx, run, unitMeta, workspaceArchive, stateData, vars, appendLog)
 		applySandboxResult = result
 		applyErr = execErr
 		if result != nil {
@@ -343,10 +362,10 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 			logs = "remote sandbox did not return apply logs"
 		}
 	} else {
-		localLogs, execErr := e.runTerraformApply(ctx, workDir, run.IsDestroy)
+		localLogs, execErr := e.runTerraformApply(execCtx, workDir, run.IsDestroy, vars)
 		logs = localLogs
 		applyErr = execErr
-		if execErr == nil {
+		if execErr == nil || execCtx.Err() != nil {
 			statePath := filepath.Join(workDir, "terraform.tfstate")
 			if data, readErr := os.ReadFile(statePath); readErr != nil {
 				logger.Warn("failed to read updated state file", slog.String("error", readErr.Error()))
@@ -361,8 +380,12 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 		appendLog("\n" + logs)
 	}
 
+	canceled := execCtx.Err() != nil
+
 	// Store final status
-	if applyErr != nil {
+	if canceled {
+		appendLog("\n\nApply canceled\n")
+	} else if applyErr != nil {
 		appendLog("\n\nApply failed\n")
 	} else {
 		appendLog("\n\nApply complete\n")
@@ -370,14 +393,18 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 
 	// Update run status
 	runStatus := "applied"
-	if applyErr != nil {
+	if canceled {
+		runStatus = "canceled"
+	}
+	if applyErr != nil && !canceled {
 		runStatus = "errored"
 		logs = logs + "\n\nError: " + applyErr.Error()
 		// Error already logged via appendLog in the executor
 		if updateErr := e.runRepo.UpdateRunError(ctx, run.ID, applyErr.Error()); updateErr != nil {
 			logger.Error("failed to update run error", slog.String("error", updateErr.Error()))
 		}
-	} else {
+	} else if applyErr == nil || len(updatedState) > 0 {
+		// An interrupted apply may still have changed resources; keep whatever state it wrote
 		stateID := fmt.Sprintf("%s/%s", run.OrgID, run.UnitID)
 		if len(updatedState) == 0 {
 			logger.Warn("no updated state returned after apply; state upload skipped",
@@ -394,7 +421,7 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 				if updateErr := e.runRepo.UpdateRunError(ctx, run.ID, errMsg); updateErr != nil {
 					logger.Error("failed to update run error", slog.String("error", updateErr.Error()))
 				}
-				applyErr = fmt.Errorf(errMsg)
+				applyErr = errors.New(errMsg)
 			} else {
 				logger.Info("successfully uploaded updated state",
 					slog.String("state_id", stateID),
@@ -403,7 +430,13 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 		}
 	}
 
-	if err := e.runRepo.UpdateRunStatus(ctx, run.ID, runStatus); err != nil {
+	if canceled && runStatus == "canceled" {
+		// Don't overwrite force_canceled set by the canceller
+		if _, err := e.runRepo.TransitionRunStatus(ctx, run.ID, []string{"apply_queued", "applying"}, runStatus); err != nil {
+			logger.Error("failed to update run status", slog.String("error", err.Error()))
+			return fmt.Errorf("failed to update run status: %w", err)
+		}
+	} else if err := e.runRepo.UpdateRunStatus(ctx, run.ID, runStatus); err != nil {
 		logger.Error("failed to update run status", slog.String("error", err.Error()))
 		return fmt.Errorf("failed to update run status: %w", err)
 	}
@@ -438,7 +471,7 @@ func (e *ApplyExecutor) ExecuteApply(ctx context.Context, runID string) error {
 
 // runTerraformApply executes terraform init and apply using terraform-exec
 // This provides clean output without local execution indicators
-func (e *ApplyExecutor) runTerraformApply(ctx context.Context, workDir string, isDestroy bool) (logs string, err error) {
+func (e *ApplyExecutor) runTerraformApply(ctx context.Context, workDir string, isDestroy bool, vars *runVariables) (logs string, err error) {
 	logger := slog.Default().With(slog.String("work_dir", workDir))
 	var logBuffer bytes.Buffer
 
@@ -453,6 +486,9 @@ func (e *ApplyExecutor) runTerraformApply(ctx context.Context, workDir string, i
 	if err != nil {
 		return "", fmt.Errorf("failed to create terraform executor: %w", err)
 	}
+	if err := vars.applyLocal(workDir, tf); err != nil {
+		return "", err
+	}
 
 	// Capture all output to our log buffer
 	tf.SetStdout(&logBuffer)
@@ -502,7 +538,7 @@ func (e *ApplyExecutor) handleApplyError(ctx context.Context, runID string, logg
 	return fmt.Errorf("apply execution failed: %s", errorMsg)
 }
 
-func (e *ApplyExecutor) executeApplyInSandbox(ctx context.Context, run *domain.TFERun, unit *storage.UnitMetadata, archive []byte, stateData []byte, logSink func(string)) (*sandbox.ApplyResult, error) {
+func (e *ApplyExecutor) executeApplyInSandbox(ctx context.Context, run *domain.TFERun, unit *storage.UnitMetadata, archive []byte, stateData []byte, vars *runVariables, logSink func(string)) (*sandbox.ApplyResult, error) {
 	if e.sandbox == nil {
 		return nil, fmt.Errorf("sandbox provider not configured")
 	}
@@ -532,6 +568,8 @@ func (e *ApplyExecutor) executeApplyInSandbox(ctx context.Context, run *domain.T
 		WorkingDirectory:       workingDirectoryForUnit(unit),
 		ConfigArchive:          archive,
 		State:                  stateData,
+		EnvVars:                vars.Env,
+		TFVars:                 vars.TFVars,
 		Metadata:               metadata,
 		LogSink:                logSink,
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/diggerhq/digger/opentaco/internal/auth"
 	"github.com/diggerhq/digger/opentaco/internal/domain"
 	"github.com/diggerhq/digger/opentaco/internal/domain/tfe"
+	"github.com/diggerhq/digger/opentaco/internal/storage"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
 )
@@ -196,14 +197,14 @@ func (h *TfeHandler) UploadConfigurationArchive(c echo.Context) error {
 	fmt.Printf("Received %d bytes for configuration version %s\n", len(body), configVersionID)
 
 	// Get configuration version from database
-	_, err = h.configVerRepo.GetConfigurationVersion(ctx, configVersionID)
+	configVer, err := h.configVerRepo.GetConfigurationVersion(ctx, configVersionID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "configuration version not found"})
 	}
 
 	// Store archive in blob storage (use UploadBlob - no lock checks needed for archives)
 	archiveBlobID := fmt.Sprintf("config-versions/%s/archive.tar.gz", configVersionID)
-	if err := h.blobStore.UploadBlob(ctx, archiveBlobID, body); err != nil {
+	if err := h.blobStore.UploadBlob(storage.WithBlobScope(ctx, configVer.OrgID), archiveBlobID, body); err != nil {
 		fmt.Printf("Failed to store archive: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to store archive"})
 	}
@@ -31,6 +31,10 @@ type PlanExecutor struct {
 	unitRepo      domain.UnitRepository
 	sandbox       sandbox.Sandbox
 	activityRepo  domain.RemoteRunActivityRepository
+	varRepo       domain.TFEVariableRepository
+
+	// queue, when set, runs the auto-apply so it stays cancellable
+	queue *runQueue
 }
 
 // NewPlanExecutor creates a new plan executor
@@ -42,6 +46,7 @@ func NewPlanExecutor(
 	unitRepo domain.UnitRepository,
 	sandboxProvider sandbox.Sandbox,
 	activityRepo domain.RemoteRunActivityRepository,
+	varRepo domain.TFEVariableRepository,
 ) *PlanExecutor {
 	return &PlanExecutor{
 		runRepo:       runRepo,
@@ -51,11 +56,17 @@ func NewPlanExecutor(
 		unitRepo:      unitRepo,
 		sandbox:       sandboxProvider,
 		activityRepo:  activityRepo,
+		varRepo:       varRepo,
 	}
 }
 
-// ExecutePlan executes a Terraform plan for a run
+// ExecutePlan executes a Terraform plan for a run. Cancelling ctx stops the
+// terraform process or sandbox job and marks the run canceled; bookkeeping
+// (status updates, log flushes, unlock) still completes.
 func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
+	execCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+
 	logger := slog.Default().With(
 		slog.String("operation", "execute_plan"),
 		slog.String("run_id", runID),
@@ -69,6 +80,8 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		logger.Error("failed to get run", slog.String("error", err.Error()))
 		return fmt.Errorf("failed to get run: %w", err)
 	}
+	// log chunks are encrypted with the data key of the organization of the run
+	ctx = storage.WithBlobScope(ctx, run.OrgID)
 	logger.Info("retrieved run",
 		slog.String("config_version_id", run.ConfigurationVersionID),
 		slog.String("unit_id", run.UnitID))
@@ -262,11 +275,16 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		_ = flushLogs()
 	}()
 
-	// Update run status to "planning"
-	if err := e.runRepo.UpdateRunStatus(ctx, runID, "planning"); err != nil {
+	// Update run status to "planning", unless the run was canceled while queued
+	started, err := e.runRepo.TransitionRunStatus(ctx, runID, []string{"pending", "plan_queued"}, "planning")
+	if err != nil {
 		logger.Error("failed to update status to planning", slog.String("error", err.Error()))
 		return e.handlePlanError(ctx, run.ID, run.PlanID, logger, fmt.Sprintf("Failed to update run status: %v", err))
 	}
+	if !started {
+		logger.Info("run left the queue before planning started; skipping plan")
+		return nil
+	}
 	logger.Info("updated run status to planning")
 
 	// Note: We no longer set LogBlobID since we use chunked logging
@@ -360,6 +378,11 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		planErr    error
 	)
 
+	vars, err := resolveRunVariables(ctx, e.varRepo, run.OrgID, run.UnitID)
+	if err != nil {
+		return e.handlePlanError(ctx, run.ID, run.PlanID, logger, fmt.Sprintf("Failed to load workspace variables: %v", err))
+	}
+
 	if useSandbox {
 		appendLog("Starting remote execution environment...\n")
 		appendLog("Initializing terraform...\n")
@@ -392,14 +415,14 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 					appendLog(fmt.Sprintf("Remote plan in progress... (%s)\n", time.Now().Format("15:04:05")))
 				case <-heartbeatDone:
 					return
-				case <-ctx.Done():
+				case <-execCtx.Done():
 					return
 				}
 			}
 		}()
 		defer close(heartbeatDone)
 
-		result, execErr := e.executePlanInSandbox(ctx, run, unitMeta, workspaceArchive, stateData, appendLog)
+		result, execErr := e.executePlanInSandbox(execCtx, run, unitMeta, workspaceArchive, stateData, vars, appendLog)
 		planSandboxResult = result
 		planErr = execErr
 
@@ -430,7 +453,7 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 			slog.String("unit_id", run.UnitID),
 			slog.String("work_dir", workDir))
 
-		_, planLogs, planHasChanges, planAdds, planChanges, planDestroys, execErr := e.runTerraformPlan(ctx, workDir, run.IsDestroy)
+		_, planLogs, planHasChanges, planAdds, planChanges, planDestroys, execErr := e.runTerraformPlan(execCtx, workDir, run.IsDestroy, vars)
 		logs = planLogs
 		hasChanges = planHasChanges
 		adds = planAdds
@@ -473,8 +496,12 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		appendLog("\n" + logs)
 	}
 
+	canceled := execCtx.Err() != nil
+
 	// Store final status
-	if planErr != nil {
+	if canceled {
+		appendLog("\n\nPlan canceled\n")
+	} else if planErr != nil {
 		appendLog("\n\nPlan failed\n")
 	} else {
 		appendLog("\n\nPlan complete\n")
@@ -485,7 +512,9 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 
 	// Update plan with results
 	planStatus := "finished"
-	if planErr != nil {
+	if canceled {
+		planStatus = "canceled"
+	} else if planErr != nil {
 		planStatus = "errored"
 		appendLog("\nError: " + planErr.Error() + "\n")
 		// Store error in run for user visibility
@@ -512,6 +541,16 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		return fmt.Errorf("failed to update plan: %w", err)
 	}
 
+	if canceled {
+		// Don't overwrite force_canceled or any other final status set by the canceller
+		if _, err := e.runRepo.TransitionRunStatus(ctx, run.ID, []string{"plan_queued", "planning"}, "canceled"); err != nil {
+			logger.Error("failed to mark run canceled", slog.String("error", err.Error()))
+			return fmt.Errorf("failed to update run: %w", err)
+		}
+		logger.Info("plan execution canceled")
+		return nil
+	}
+
 	// Update run status and can_apply
 	// Use "planned" status (not "planned_and_finished") - this is what Terraform CLI expects
 	runStatus := "planned"
@@ -564,6 +603,12 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 		lockReleased = true // Mark as released to prevent defer from trying again
 		logger.Info("plan lock released, apply can now acquire it")
 
+		// Hand the apply to the run queue so it can be cancelled like any other run
+		if e.queue != nil {
+			e.queue.startApply(run)
+			return nil
+		}
+
 		// Trigger apply execution in background
 		// Use a new context to avoid cancellation propagation issues
 		applyCtx, cancel := context.WithCancel(context.Background())
@@ -574,7 +619,7 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 				slog.String("run_id", run.ID),
 			)
 			applyLogger.Info("starting async apply execution")
-			applyExecutor := NewApplyExecutor(e.runRepo, e.planRepo, e.configVerRepo, e.blobStore, e.unitRepo, e.sandbox, e.activityRepo)
+			applyExecutor := NewApplyExecutor(e.runRepo, e.planRepo, e.configVerRepo, e.blobStore, e.unitRepo, e.sandbox, e.activityRepo, e.varRepo)
 			if err := applyExecutor.ExecuteApply(applyCtx, run.ID); err != nil {
 				applyLogger.Error("apply execution failed", slog.String("error", err.Error()))
 			} else {
@@ -592,7 +637,7 @@ func (e *PlanExecutor) ExecutePlan(ctx context.Context, runID string) error {
 
 // runTerraformPlan executes terraform init and plan using terraform-exec
 // This provides clean, structured output without local execution indicators
-func (e *PlanExecutor) runTerraformPlan(ctx context.Context, workDir string, isDestroy bool) (output string, logs string, hasChanges bool, adds, changes, destroys int, err error) {
+func (e *PlanExecutor) runTerraformPlan(ctx context.Context, workDir string, isDestroy bool, vars *runVariables) (output string, logs string, hasChanges bool, adds, changes, destroys int, err error) {
 	logger := slog.Default().With(slog.String("work_dir", workDir))
 	var logBuffer bytes.Buffer
 
@@ -607,6 +652,9 @@ func (e *PlanExecutor) runTerraformPlan(ctx context.Context, workDir string, isD
 	if err != nil {
 		return "", "", false, 0, 0, 0, fmt.Errorf("failed to create terraform executor: %w", err)
 	}
+	if err := vars.applyLocal(workDir, tf); err != nil {
+		return "", "", false, 0, 0, 0, err
+	}
 
 	// Capture all output to our log buffer (this is clean output, no local indicators!)
 	tf.SetStdout(&logBuffer)
@@ -876,7 +924,7 @@ func createBackendOverride(workDir string) error {
 	return nil
 }
 
-func (e *PlanExecutor) executePlanInSandbox(ctx context.Context, run *domain.TFERun, unit *storage.UnitMetadata, archive []byte, stateData []byte, logSink func(string)) (*sandbox.PlanResult, error) {
+func (e *PlanExecutor) executePlanInSandbox(ctx context.Context, run *domain.TFERun, unit *storage.UnitMetadata, archive []byte, stateData []byte, vars *runVariables, logSink func(string)) (*sandbox.PlanResult, error) {
 	if e.sandbox == nil {
 		return nil, fmt.Errorf("sandbox provider not configured")
 	}
@@ -908,6 +956,8 @@ func (e *PlanExecutor) executePlanInSandbox(ctx context.Context, run *domain.TFE
 		WorkingDirectory:       workingDirectoryForUnit(unit),
 		ConfigArchive:          archive,
 		State:                  stateData,
+		EnvVars:                vars.Env,
+		TFVars:                 vars.TFVars,
 		Metadata:               metadata,
 		LogSink:                logSink,
 	}
@@ -0,0 +1,264 @@
+package tfe
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+)
+
+// Run statuses that occupy a workspace. A workspace runs one of these at a
+// time; newer runs wait in "pending" until it reaches a final status.
+var activeRunStatuses = []string{"plan_queued", "planning", "planned", "confirmed", "apply_queued", "applying"}
+
+// queuedRunStatuses are the statuses the queue looks at: active runs plus the
+// runs waiting behind them.
+var queuedRunStatuses = append([]string{"pending"}, activeRunStatuses...)
+
+// runExecutions tracks in-process plan and apply executions by run ID. It is
+// shared so a cancel request served by any handler instance reaches the run.
+var runExecutions = struct {
+	sync.Mutex
+	byRun map[string]*runExecution
+}{byRun: map[string]*runExecution{}}
+
+type runExecution struct {
+	cancel context.CancelFunc
+}
+
+// cancelPollInterval is how often an execution checks whether a cancel request
+// for its run was stored, e.g. by another replica
+var cancelPollInterval = 5 * time.Second
+
+// dispatchMu serialises queue promotion within the process; the conditional
+// pending -> plan_queued update guards against other replicas.
+var dispatchMu sync.Mutex
+
+// runQueue keeps one active run per workspace and starts the next pending run
+// when the active one finishes. Speculative (plan-only) runs bypass it.
+type runQueue struct {
+	runRepo  domain.TFERunRepository
+	planRepo domain.TFEPlanRepository
+
+	// plan and apply execute a run; cancelling ctx cancels the execution
+	plan  func(ctx context.Context, runID string) error
+	apply func(ctx context.Context, runID string) error
+}
+
+// newRunQueue creates a run queue backed by the handler's executors
+func newRunQueue(h *TfeHandler) *runQueue {
+	q := &runQueue{runRepo: h.runRepo, planRepo: h.planRepo}
+	q.plan = func(ctx context.Context, runID string) error {
+		executor := NewPlanExecutor(h.runRepo, h.planRepo, h.configVerRepo, h.blobStore, h.unitRepo, h.sandbox, h.runActivityRepo, h.varRepo)
+		executor.queue = q
+		return executor.ExecutePlan(ctx, runID)
+	}
+	q.apply = func(ctx context.Context, runID string) error {
+		executor := NewApplyExecutor(h.runRepo, h.planRepo, h.configVerRepo, h.blobStore, h.unitRepo, h.sandbox, h.runActivityRepo, h.varRepo)
+		return executor.ExecuteApply(ctx, runID)
+	}
+	return q
+}
+
+// enqueue starts a newly created run, or leaves it pending behind the
+// workspace's active run
+func (q *runQueue) enqueue(ctx context.Context, run *domain.TFERun) {
+	if !run.PlanOnly {
+		q.dispatch(ctx, run.UnitID)
+		return
+	}
+	started, err := q.runRepo.TransitionRunStatus(ctx, run.ID, []string{"pending"}, "plan_queued")
+	if err != nil {
+		slog.Default().Error("failed to queue speculative plan", slog.String("run_id", run.ID), slog.String("error", err.Error()))
+		return
+	}
+	if started {
+		q.startPlan(run)
+	}
+}
+
+// dispatch promotes the oldest pending run of a unit when nothing else holds it
+func (q *runQueue) dispatch(ctx context.Context, unitID string) {
+	logger := slog.Default().With(
+		slog.String("operation", "run_queue_dispatch"),
+		slog.String("unit_id", unitID),
+	)
+
+	dispatchMu.Lock()
+	defer dispatchMu.Unlock()
+
+	runs, err := q.runRepo.ListRunsForUnitByStatus(ctx, unitID, queuedRunStatuses)
+	if err != nil {
+		logger.Error("failed to list queued runs", slog.String("error", err.Error()))
+		return
+	}
+
+	var next *domain.TFERun
+	for _, run := range runs {
+		if q.holdsWorkspace(ctx, run) {
+			logger.Debug("workspace busy", slog.String("active_run_id", run.ID), slog.String("status", run.Status))
+			return
+		}
+		if next == nil && run.Status == "pending" && !run.PlanOnly {
+			next = run
+		}
+	}
+	if next == nil {
+		return
+	}
+
+	started, err := q.runRepo.TransitionRunStatus(ctx, next.ID, []string{"pending"}, "plan_queued")
+	if err != nil {
+		logger.Error("failed to queue run", slog.String("run_id", next.ID), slog.String("error", err.Error()))
+		return
+	}
+	if !started {
+		// Canceled or claimed elsewhere; whoever changed it dispatches again
+		return
+	}
+	logger.Info("run promoted from queue", slog.String("run_id", next.ID))
+	q.startPlan(next)
+}
+
+// holdsWorkspace reports whether run blocks other runs on its workspace. A
+// planned run only does while it waits for confirmation of real changes.
+func (q *runQueue) holdsWorkspace(ctx context.Context, run *domain.TFERun) bool {
+	if run.PlanOnly {
+		return false
+	}
+	switch run.Status {
+	case "plan_queued", "planning", "confirmed", "apply_queued", "applying":
+		return true
+	case "planned":
+		if !run.CanApply {
+			return false
+		}
+		if run.PlanID == nil {
+			return true
+		}
+		plan, err := q.planRepo.GetPlan(ctx, *run.PlanID)
+		if err != nil {
+			return true
+		}
+		return plan.HasChanges
+	}
+	return false
+}
+
+// currentRun returns the run holding the unit, if any
+func (q *runQueue) currentRun(ctx context.Context, unitID string) *domain.TFERun {
+	if q.runRepo == nil {
+		return nil
+	}
+	runs, err := q.runRepo.ListRunsForUnitByStatus(ctx, unitID, activeRunStatuses)
+	if err != nil {
+		return nil
+	}
+	for _, run := range runs {
+		if q.holdsWorkspace(ctx, run) {
+			return run
+		}
+	}
+	return nil
+}
+
+// position returns how many runs are ahead of a pending run
+func (q *runQueue) position(ctx context.Context, run *domain.TFERun) int {
+	if run.Status != "pending" || run.PlanOnly {
+		return 0
+	}
+	runs, err := q.runRepo.ListRunsForUnitByStatus(ctx, run.UnitID, queuedRunStatuses)
+	if err != nil {
+		return 0
+	}
+	ahead := 0
+	for _, other := range runs {
+		if other.ID == run.ID {
+			break
+		}
+		if (other.Status == "pending" && !other.PlanOnly) || q.holdsWorkspace(ctx, other) {
+			ahead++
+		}
+	}
+	return ahead
+}
+
+func (q *runQueue) startPlan(run *domain.TFERun) {
+	q.execute(run, "plan", q.plan)
+}
+
+func (q *runQueue) startApply(run *domain.TFERun) {
+	q.execute(run, "apply", q.apply)
+}
+
+// execute runs fn in the background under a cancellable context registered
+// for the run, then dispatches the next run for the workspace
+func (q *runQueue) execute(run *domain.TFERun, phase string, fn func(ctx context.Context, runID string) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := &runExecution{cancel: cancel}
+
+	runExecutions.Lock()
+	runExecutions.byRun[run.ID] = exec
+	runExecutions.Unlock()
+
+	go func() {
+		defer cancel()
+		logger := slog.Default().With(
+			slog.String("operation", "async_"+phase),
+			slog.String("run_id", run.ID),
+		)
+		go q.watchCancelRequest(ctx, cancel, run.ID)
+		logger.Info("starting async " + phase + " execution")
+		if err := fn(ctx, run.ID); err != nil {
+			logger.Error(phase+" execution failed", slog.String("error", err.Error()))
+		} else {
+			logger.Info(phase + " execution completed")
+		}
+
+		runExecutions.Lock()
+		// An auto-apply registers itself under the same run ID before the plan returns
+		if runExecutions.byRun[run.ID] == exec {
+			delete(runExecutions.byRun, run.ID)
+		}
+		runExecutions.Unlock()
+
+		q.dispatch(context.Background(), run.UnitID)
+	}()
+}
+
+// watchCancelRequest cancels an execution once a cancel request is stored for
+// its run, which is how cancels served by other replicas reach it. It returns
+// when ctx is done.
+func (q *runQueue) watchCancelRequest(ctx context.Context, cancel context.CancelFunc, runID string) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := q.runRepo.GetRun(ctx, runID)
+			if err != nil {
+				continue
+			}
+			if run.CancelRequested {
+				slog.Default().Info("cancel requested for run, stopping execution", slog.String("run_id", runID))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// cancel cancels the in-process execution of a run and reports whether one existed
+func (q *runQueue) cancel(runID string) bool {
+	runExecutions.Lock()
+	exec, ok := runExecutions.byRun[runID]
+	runExecutions.Unlock()
+	if ok {
+		exec.cancel()
+	}
+	return ok
+}
@@ -0,0 +1,245 @@
+package tfe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+)
+
+// memRunRepo is an in-memory TFERunRepository for queue tests
+type memRunRepo struct {
+	mu   sync.Mutex
+	runs []*domain.TFERun
+}
+
+func (r *memRunRepo) add(id, status string, planOnly bool) *domain.TFERun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := &domain.TFERun{ID: id, UnitID: "unit", Status: status, PlanOnly: planOnly, CanApply: true,
+		CreatedAt: time.Unix(int64(len(r.runs)), 0)}
+	r.runs = append(r.runs, run)
+	return run
+}
+
+func (r *memRunRepo) status(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == id {
+			return run.Status
+		}
+	}
+	return ""
+}
+
+func (r *memRunRepo) CreateRun(ctx context.Context, run *domain.TFERun) error { return nil }
+
+func (r *memRunRepo) GetRun(ctx context.Context, runID string) (*domain.TFERun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == runID {
+			copied := *run
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("run not found: %s", runID)
+}
+
+func (r *memRunRepo) ListRunsForUnit(ctx context.Context, unitID string, limit int) ([]*domain.TFERun, error) {
+	return nil, nil
+}
+
+func (r *memRunRepo) ListRunsForUnitByStatus(ctx context.Context, unitID string, statuses []string) ([]*domain.TFERun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.TFERun
+	for _, run := range r.runs {
+		for _, status := range statuses {
+			if run.UnitID == unitID && run.Status == status {
+				copied := *run
+				out = append(out, &copied)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *memRunRepo) UpdateRunStatus(ctx context.Context, runID string, status string) error {
+	_, err := r.TransitionRunStatus(ctx, runID, []string{r.status(runID)}, status)
+	return err
+}
+
+func (r *memRunRepo) UpdateRunPlanID(ctx context.Context, runID string, planID string) error {
+	return nil
+}
+
+func (r *memRunRepo) UpdateRunStatusAndCanApply(ctx context.Context, runID string, status string, canApply bool) error {
+	return r.UpdateRunStatus(ctx, runID, status)
+}
+
+func (r *memRunRepo) UpdateRunError(ctx context.Context, runID string, errorMessage string) error {
+	return r.UpdateRunStatus(ctx, runID, "errored")
+}
+
+func (r *memRunRepo) RequestRunCancel(ctx context.Context, runID string, from []string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == runID && slices.Contains(from, run.Status) {
+			run.CancelRequested = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memRunRepo) TransitionRunStatus(ctx context.Context, runID string, from []string, status string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID != runID {
+			continue
+		}
+		for _, f := range from {
+			if run.Status == f {
+				run.Status = status
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, nil
+}
+
+// newTestQueue returns a queue whose plans block until released and report
+// started run IDs on the returned channel
+func newTestQueue(repo *memRunRepo) (*runQueue, chan string) {
+	started := make(chan string, 10)
+	q := &runQueue{runRepo: repo}
+	q.plan = func(ctx context.Context, runID string) error {
+		started <- runID
+		<-ctx.Done()
+		_, err := repo.TransitionRunStatus(context.Background(), runID, []string{"plan_queued", "planning"}, "canceled")
+		return err
+	}
+	return q, started
+}
+
+func waitStarted(t *testing.T, started chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-started:
+		if got != want {
+			t.Fatalf("started %s, want %s", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("run %s did not start", want)
+	}
+}
+
+func TestRunQueue_OneActiveRunPerWorkspace(t *testing.T) {
+	repo := &memRunRepo{}
+	q, started := newTestQueue(repo)
+
+	first := repo.add("run-1", "pending", false)
+	q.enqueue(context.Background(), first)
+	waitStarted(t, started, "run-1")
+
+	second := repo.add("run-2", "pending", false)
+	q.enqueue(context.Background(), second)
+	if got := repo.status("run-2"); got != "pending" {
+		t.Fatalf("run-2 status = %s, want pending while run-1 is active", got)
+	}
+	if got := q.position(context.Background(), second); got != 1 {
+		t.Errorf("run-2 position = %d, want 1", got)
+	}
+
+	// Speculative plans don't wait for the workspace
+	speculative := repo.add("run-3", "pending", true)
+	q.enqueue(context.Background(), speculative)
+	waitStarted(t, started, "run-3")
+	q.cancel("run-3")
+
+	// Cancelling the active run lets the next one start
+	if !q.cancel("run-1") {
+		t.Fatal("run-1 had no registered execution")
+	}
+	waitStarted(t, started, "run-2")
+	if got := repo.status("run-1"); got != "canceled" {
+		t.Errorf("run-1 status = %s, want canceled", got)
+	}
+	q.cancel("run-2")
+}
+
+func TestRunQueue_StoredCancelRequestStopsExecution(t *testing.T) {
+	interval := cancelPollInterval
+	cancelPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { cancelPollInterval = interval })
+
+	repo := &memRunRepo{}
+	q, started := newTestQueue(repo)
+
+	run := repo.add("run-1", "pending", false)
+	q.enqueue(context.Background(), run)
+	waitStarted(t, started, "run-1")
+
+	// A cancel served by another replica only reaches the execution through the run row
+	if ok, _ := repo.RequestRunCancel(context.Background(), "run-1", []string{"plan_queued"}); !ok {
+		t.Fatal("cancel request was not stored")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.status("run-1") != "canceled" {
+		if time.Now().After(deadline) {
+			t.Fatalf("run-1 status = %s, want canceled", repo.status("run-1"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunQueue_PlannedRunHoldsWorkspaceOnlyWithChanges(t *testing.T) {
+	repo := &memRunRepo{}
+	plans := &memPlanRepo{plans: map[string]*domain.TFEPlan{}}
+	q := &runQueue{runRepo: repo, planRepo: plans}
+
+	planned := repo.add("run-1", "planned", false)
+	planID := "plan-1"
+	planned.PlanID = &planID
+
+	plans.plans[planID] = &domain.TFEPlan{ID: planID, HasChanges: true}
+	if !q.holdsWorkspace(context.Background(), planned) {
+		t.Error("planned run with changes should hold the workspace until confirmed or discarded")
+	}
+
+	plans.plans[planID] = &domain.TFEPlan{ID: planID, HasChanges: false}
+	if q.holdsWorkspace(context.Background(), planned) {
+		t.Error("planned run without changes should not hold the workspace")
+	}
+}
+
+type memPlanRepo struct {
+	plans map[string]*domain.TFEPlan
+}
+
+func (r *memPlanRepo) CreatePlan(ctx context.Context, plan *domain.TFEPlan) error { return nil }
+
+func (r *memPlanRepo) GetPlan(ctx context.Context, planID string) (*domain.TFEPlan, error) {
+	if plan, ok := r.plans[planID]; ok {
+		return plan, nil
+	}
+	return nil, fmt.Errorf("plan not found: %s", planID)
+}
+
+func (r *memPlanRepo) UpdatePlan(ctx context.Context, planID string, updates *domain.TFEPlanUpdate) error {
+	return nil
+}
+
+func (r *memPlanRepo) GetPlanByRunID(ctx context.Context, runID string) (*domain.TFEPlan, error) {
+	return nil, fmt.Errorf("plan not found for run: %s", runID)
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/diggerhq/digger/opentaco/internal/auth"
@@ -76,21 +77,15 @@ func (h *TfeHandler) GetRun(c echo.Context) error {
 
 	// Build response
 	response := tfe.TFERun{
-		ID:            run.ID,
-		Status:        run.Status,
-		HasChanges:    hasChanges,
-		IsDestroy:     run.IsDestroy,
-		Message:       run.Message,
-		PlanOnly:      run.PlanOnly,
-		AutoApply:     run.AutoApply,
-		IsConfirmable: isConfirmable,
-		Actions: &tfe.RunActions{
-			IsCancelable:  run.IsCancelable,
-			IsConfirmable: isConfirmable,
-		},
-		Permissions: &tfe.RunPermissions{
-			CanApply: run.CanApply,
-		},
+		ID:              run.ID,
+		Status:          run.Status,
+		HasChanges:      hasChanges,
+		IsDestroy:       run.IsDestroy,
+		Message:         run.Message,
+		PlanOnly:        run.PlanOnly,
+		AutoApply:       run.AutoApply,
+		IsConfirmable:   isConfirmable,
+		PositionInQueue: h.queue.position(ctx, run),
 		Workspace: &tfe.WorkspaceRef{
 			ID: workspaceID,
 		},
@@ -99,6 +94,9 @@ func (h *TfeHandler) GetRun(c echo.Context) error {
 		},
 	}
 
+	response.Actions, response.Permissions = runActions(run, isConfirmable)
+	setRunSource(&response, run)
+
 	if run.PlanID != nil {
 		response.Plan = &tfe.PlanRef{ID: *run.PlanID}
 	}
@@ -479,45 +477,24 @@ func (h *TfeHandler) CreateRun(c echo.Context) error {
 		// Non-fatal, continue
 	}
 
-	// Trigger real plan execution asynchronously
-	// Use a new context to avoid cancellation propagation
-	planCtx, cancel := context.WithCancel(context.Background())
-	go func() {
-		defer cancel()
-		planLogger := slog.Default().With(
-			slog.String("operation", "async_plan"),
-			slog.String("run_id", run.ID),
-		)
-		planLogger.Info("starting async plan execution")
-		// Create plan executor
-		executor := NewPlanExecutor(h.runRepo, h.planRepo, h.configVerRepo, h.blobStore, h.unitRepo, h.sandbox, h.runActivityRepo)
-
-		// Execute the plan (this will run terraform plan)
-		if err := executor.ExecutePlan(planCtx, run.ID); err != nil {
-			planLogger.Error("plan execution failed", slog.String("error", err.Error()))
-		} else {
-			planLogger.Info("plan execution completed successfully")
-		}
-	}()
+	// Start the plan now, or leave the run pending behind the workspace's active run
+	h.queue.enqueue(ctx, run)
+	if current, err := h.runRepo.GetRun(ctx, run.ID); err == nil {
+		run.Status = current.Status
+	}
 
 	// Return JSON:API response
 	// Terraform CLI expects workspace ID in the format "ws-{uuid}"
 	response := tfe.TFERun{
-		ID:            run.ID,
-		Status:        "planning", // Return as planning immediately
-		HasChanges:    false,
-		IsDestroy:     run.IsDestroy,
-		Message:       run.Message,
-		PlanOnly:      run.PlanOnly,
-		AutoApply:     run.AutoApply,
-		IsConfirmable: false,
-		Actions: &tfe.RunActions{
-			IsCancelable:  run.IsCancelable,
-			IsConfirmable: false,
-		},
-		Permissions: &tfe.RunPermissions{
-			CanApply: run.CanApply,
-		},
+		ID:              run.ID,
+		Status:          run.Status,
+		HasChanges:      false,
+		IsDestroy:       run.IsDestroy,
+		Message:         run.Message,
+		PlanOnly:        run.PlanOnly,
+		AutoApply:       run.AutoApply,
+		IsConfirmable:   false,
+		PositionInQueue: h.queue.position(ctx, run),
 		Plan: &tfe.PlanRef{
 			ID: plan.ID,
 		},
@@ -529,6 +506,8 @@ func (h *TfeHandler) CreateRun(c echo.Context) error {
 		},
 	}
 
+	response.Actions, response.Permissions = runActions(run, false)
+
 	// For auto-apply runs, include Apply reference immediately so Terraform CLI knows to expect it
 	if run.AutoApply {
 		response.Apply = &tfe.ApplyRef{ID: run.ID}
@@ -604,8 +583,9 @@ func (h *TfeHandler) ApplyRun(c echo.Context) error {
 
 	logger.Info("triggering apply")
 
-	// Update run status to apply_queued
-	if err := h.runRepo.UpdateRunStatus(ctx, runID, "apply_queued"); err != nil {
+	// Only one confirm wins, and a discard or cancel landing after the status was read keeps the run
+	queued, err := h.runRepo.TransitionRunStatus(ctx, runID, []string{"planned"}, "apply_queued")
+	if err != nil {
 		logger.Error("failed to update run status", slog.String("error", err.Error()))
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"errors": []map[string]string{{
@@ -615,30 +595,21 @@ func (h *TfeHandler) ApplyRun(c echo.Context) error {
 			}},
 		})
 	}
+	if !queued {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"errors": []map[string]string{{
+				"status": "409",
+				"title":  "conflict",
+				"detail": fmt.Sprintf("Run %s changed status; retry the request", runID),
+			}},
+		})
+	}
 
-	// Trigger real apply execution asynchronously
-	// Use a new context to avoid cancellation propagation
-	applyCtx, cancel := context.WithCancel(context.Background())
-	go func() {
-		defer cancel()
-		applyLogger := slog.Default().With(
-			slog.String("operation", "async_apply"),
-			slog.String("run_id", runID),
-		)
-		applyLogger.Info("starting async apply execution")
-		// Create apply executor
-		executor := NewApplyExecutor(h.runRepo, h.planRepo, h.configVerRepo, h.blobStore, h.unitRepo, h.sandbox, h.runActivityRepo)
-
-		// Execute the apply (this will run terraform apply)
-		if err := executor.ExecuteApply(applyCtx, runID); err != nil {
-			applyLogger.Error("apply execution failed", slog.String("error", err.Error()))
-		} else {
-			applyLogger.Info("apply execution completed successfully")
-		}
-	}()
+	// The run already holds the workspace queue, so the apply starts right away
+	run.Status = "apply_queued"
+	h.queue.startApply(run)
 
 	// Return updated run
-	run.Status = "apply_queued"
 	response := tfe.TFERun{
 		ID:            run.ID,
 		Status:        run.Status,
@@ -648,13 +619,6 @@ func (h *TfeHandler) ApplyRun(c echo.Context) error {
 		PlanOnly:      run.PlanOnly,
 		AutoApply:     run.AutoApply,
 		IsConfirmable: false,
-		Actions: &tfe.RunActions{
-			IsCancelable:  false,
-			IsConfirmable: false,
-		},
-		Permissions: &tfe.RunPermissions{
-			CanApply: false,
-		},
 		Workspace: &tfe.WorkspaceRef{
 			ID: "ws-" + run.UnitID, // Add ws- prefix
 		},
@@ -667,6 +631,8 @@ func (h *TfeHandler) ApplyRun(c echo.Context) error {
 		response.Plan = &tfe.PlanRef{ID: *run.PlanID}
 	}
 
+	response.Actions, response.Permissions = runActions(run, false)
+
 	// Include Apply reference so Terraform CLI knows to fetch apply logs
 	response.Apply = &tfe.ApplyRef{ID: run.ID}
 	logger.Debug("added apply reference", slog.String("apply_id", run.ID))
@@ -681,6 +647,291 @@ func (h *TfeHandler) ApplyRun(c echo.Context) error {
 	return nil
 }
 
+// CancelRun handles POST /runs/:id/actions/cancel. Queued runs are canceled
+// immediately; a running plan or apply is interrupted through its context and
+// marks itself canceled once terraform exits.
+func (h *TfeHandler) CancelRun(c echo.Context) error {
+	ctx := c.Request().Context()
+	runID := c.Param("id")
+
+	logger := slog.Default().With(
+		slog.String("operation", "cancel_run"),
+		slog.String("run_id", runID),
+	)
+
+	run, err := h.authorizeRunAction(c, runID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	switch run.Status {
+	case "pending", "plan_queued":
+		if h.queue.cancel(run.ID) {
+			break
+		}
+		if err := h.finishRun(ctx, run, []string{"pending", "plan_queued"}, "canceled"); err != nil {
+			return writeAPIError(c, err)
+		}
+	case "planning", "apply_queued", "applying":
+		if h.queue.cancel(run.ID) {
+			break
+		}
+		// Executing on another replica, which marks the run canceled once it stopped.
+		// A run whose execution is gone (e.g. after a restart) needs a force-cancel.
+		requested, err := h.runRepo.RequestRunCancel(ctx, run.ID, []string{run.Status})
+		if err != nil {
+			return writeAPIError(c, err)
+		}
+		if !requested {
+			return jsonAPIError(c, http.StatusConflict, "conflict", fmt.Sprintf("Run %s changed status; retry the request", run.ID))
+		}
+	default:
+		return jsonAPIError(c, http.StatusConflict, "conflict", fmt.Sprintf("Run cannot be canceled in status %s", run.Status))
+	}
+
+	logger.Info("run cancel requested", slog.String("status", run.Status))
+	return c.NoContent(http.StatusAccepted)
+}
+
+// DiscardRun handles POST /runs/:id/actions/discard for runs waiting on confirmation
+func (h *TfeHandler) DiscardRun(c echo.Context) error {
+	ctx := c.Request().Context()
+	runID := c.Param("id")
+
+	logger := slog.Default().With(
+		slog.String("operation", "discard_run"),
+		slog.String("run_id", runID),
+	)
+
+	run, err := h.authorizeRunAction(c, runID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	if !isDiscardable(run) {
+		return jsonAPIError(c, http.StatusConflict, "conflict", fmt.Sprintf("Run cannot be discarded in status %s", run.Status))
+	}
+	if err := h.finishRun(ctx, run, []string{"planned"}, "discarded"); err != nil {
+		return writeAPIError(c, err)
+	}
+
+	logger.Info("run discarded")
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ForceCancelRun handles POST /runs/:id/actions/force-cancel. The run is
+// marked force_canceled right away and any lock it holds is released, so the
+// workspace is usable even if the execution never reports back.
+func (h *TfeHandler) ForceCancelRun(c echo.Context) error {
+	ctx := c.Request().Context()
+	runID := c.Param("id")
+
+	logger := slog.Default().With(
+		slog.String("operation", "force_cancel_run"),
+		slog.String("run_id", runID),
+	)
+
+	run, err := h.authorizeRunAction(c, runID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	if !isForceCancelable(run) {
+		return jsonAPIError(c, http.StatusConflict, "conflict", fmt.Sprintf("Run cannot be force-canceled in status %s", run.Status))
+	}
+
+	h.queue.cancel(run.ID)
+	if err := h.finishRun(ctx, run, queuedRunStatuses, "force_canceled"); err != nil {
+		return writeAPIError(c, err)
+	}
+
+	for _, lockID := range []string{"tfe-plan-" + run.ID, "tfe-apply-" + run.ID} {
+		if err := h.unitRepo.Unlock(ctx, run.UnitID, lockID); err == nil {
+			logger.Info("released lock held by run", slog.String("lock_id", lockID))
+		}
+	}
+
+	logger.Info("run force-canceled", slog.String("previous_status", run.Status))
+	return c.NoContent(http.StatusAccepted)
+}
+
+// authorizeRunAction loads a run and checks the caller may write its workspace
+func (h *TfeHandler) authorizeRunAction(c echo.Context, runID string) (*domain.TFERun, error) {
+	run, err := h.runRepo.GetRun(c.Request().Context(), runID)
+	if err != nil {
+		return nil, newAPIError(http.StatusNotFound, "not found", fmt.Sprintf("Run %s not found", runID))
+	}
+	if err := h.checkWorkspacePermission(c, "unit.write", run.OrgID+"/"+run.UnitID); err != nil {
+		return nil, newAPIError(http.StatusForbidden, "forbidden", "insufficient permissions: unit.write required")
+	}
+	return run, nil
+}
+
+// finishRun moves a run into a final status and lets the next queued run start
+func (h *TfeHandler) finishRun(ctx context.Context, run *domain.TFERun, from []string, status string) error {
+	changed, err := h.runRepo.TransitionRunStatus(ctx, run.ID, from, status)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return newAPIError(http.StatusConflict, "conflict", fmt.Sprintf("Run %s changed status; retry the request", run.ID))
+	}
+	if run.PlanID != nil && status != "discarded" {
+		planStatus := "canceled"
+		if err := h.planRepo.UpdatePlan(ctx, *run.PlanID, &domain.TFEPlanUpdate{Status: &planStatus}); err != nil {
+			slog.Default().Warn("failed to mark plan canceled", slog.String("run_id", run.ID), slog.String("error", err.Error()))
+		}
+	}
+	h.queue.dispatch(context.WithoutCancel(ctx), run.UnitID)
+	return nil
+}
+
+func isDiscardable(run *domain.TFERun) bool {
+	return run.Status == "planned" && !run.PlanOnly
+}
+
+func isForceCancelable(run *domain.TFERun) bool {
+	for _, status := range queuedRunStatuses {
+		if run.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// runActions computes the actions and permissions Terraform reads to decide
+// whether it can confirm, discard or cancel a run
+func runActions(run *domain.TFERun, isConfirmable bool) (*tfe.RunActions, *tfe.RunPermissions) {
+	cancelable := false
+	switch run.Status {
+	case "pending", "plan_queued", "planning", "apply_queued", "applying":
+		cancelable = run.IsCancelable
+	}
+	discardable := isDiscardable(run)
+	forceCancelable := isForceCancelable(run)
+
+	return &tfe.RunActions{
+		IsCancelable:      cancelable,
+		IsConfirmable:     isConfirmable,
+		IsDiscardable:     discardable,
+		IsForceCancelable: forceCancelable,
+	}, &tfe.RunPermissions{
+		CanApply:       run.CanApply,
+		CanCancel:      true,
+		CanDiscard:     true,
+		CanForceCancel: true,
+	}
+}
+
+// setRunSource annotates a run resource with where it came from and, for VCS
+// runs, the commit that triggered it
+func setRunSource(response *tfe.TFERun, run *domain.TFERun) {
+	response.Source = run.Source
+	if run.CommitSHA != nil {
+		response.CommitSHA = *run.CommitSHA
+	}
+	if run.CommitMessage != nil {
+		response.CommitMessage = *run.CommitMessage
+	}
+}
+
+// ListWorkspaceRuns handles GET /workspaces/:workspace_id/runs, newest first.
+// Terraform pages through it to show a pending run's place in the queue.
+func (h *TfeHandler) ListWorkspaceRuns(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	_, unitUUID, err := h.resolveWorkspaceParam(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	runs, err := h.runRepo.ListRunsForUnit(ctx, unitUUID, 0)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	page, size := pageParams(c)
+	start := min((page-1)*size, len(runs))
+	end := min(start+size, len(runs))
+
+	records := make([]interface{}, 0, end-start)
+	for _, run := range runs[start:end] {
+		record := &tfe.TFERun{
+			ID:              run.ID,
+			Status:          run.Status,
+			IsDestroy:       run.IsDestroy,
+			Message:         run.Message,
+			PlanOnly:        run.PlanOnly,
+			AutoApply:       run.AutoApply,
+			PositionInQueue: h.queue.position(ctx, run),
+			Workspace:       &tfe.WorkspaceRef{ID: "ws-" + run.UnitID},
+			ConfigurationVersion: &tfe.ConfigurationVersionRef{
+				ID: run.ConfigurationVersionID,
+			},
+		}
+		record.Actions, record.Permissions = runActions(run, false)
+		setRunSource(record, run)
+		if run.PlanID != nil {
+			record.Plan = &tfe.PlanRef{ID: *run.PlanID}
+		}
+		records = append(records, record)
+	}
+
+	payload, err := jsonapi.Marshal(records)
+	if err != nil {
+		return err
+	}
+	many := payload.(*jsonapi.ManyPayload)
+	many.Meta = &jsonapi.Meta{"pagination": paginationMeta(page, size, len(runs))}
+
+	setJSONAPIHeaders(c)
+	c.Response().WriteHeader(http.StatusOK)
+	return json.NewEncoder(c.Response().Writer).Encode(many)
+}
+
+// GetRunQueue handles GET /organizations/:org_name/runs/queue. Runs start as
+// soon as their workspace is free, so there is never an org-level backlog.
+func (h *TfeHandler) GetRunQueue(c echo.Context) error {
+	page, size := pageParams(c)
+	setJSONAPIHeaders(c)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": []interface{}{},
+		"meta": map[string]interface{}{"pagination": paginationMeta(page, size, 0)},
+	})
+}
+
+// pageParams reads JSON:API page[number] and page[size], defaulting to 1 and 20
+func pageParams(c echo.Context) (int, int) {
+	page, err := strconv.Atoi(c.QueryParam("page[number]"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.QueryParam("page[size]"))
+	if err != nil || size < 1 {
+		size = 20
+	}
+	return page, min(size, 100)
+}
+
+func paginationMeta(page, size, total int) map[string]interface{} {
+	totalPages := max((total+size-1)/size, 1)
+	meta := map[string]interface{}{
+		"current-page": page,
+		"page-size":    size,
+		"prev-page":    nil,
+		"next-page":    nil,
+		"total-pages":  totalPages,
+		"total-count":  total,
+	}
+	if page > 1 {
+		meta["prev-page"] = page - 1
+	}
+	if page < totalPages {
+		meta["next-page"] = page + 1
+	}
+	return meta
+}
+
 // GetRunEvents returns timeline events for a run (used by Terraform CLI to track progress)
 func (h *TfeHandler) GetRunEvents(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -728,6 +979,11 @@ func (h *TfeHandler) GetRunEvents(c echo.Context) error {
 		addEvent("applying", "Apply is running")
 	case "errored":
 		addEvent("errored", "Run encountered an error")
+	case "canceled", "force_canceled":
+		addEvent("canceled", "Run was canceled")
+	case "discarded":
+		addEvent("planning", "Plan completed")
+		addEvent("discarded", "Run was discarded")
 	}
 
 	c.Response().Header().Set(echo.HeaderContentType, "application/vnd.api+json")
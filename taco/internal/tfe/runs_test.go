@@ -0,0 +1,91 @@
+package tfe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// staleRunRepo reads runs as planned whatever their stored status, like a read racing a discard
+type staleRunRepo struct {
+	*memRunRepo
+}
+
+func (r staleRunRepo) GetRun(ctx context.Context, runID string) (*domain.TFERun, error) {
+	run, err := r.memRunRepo.GetRun(ctx, runID)
+	if err == nil {
+		run.Status = "planned"
+	}
+	return run, err
+}
+
+func newApplyTestHandler(runRepo domain.TFERunRepository, applies *int32) *TfeHandler {
+	h := &TfeHandler{runRepo: runRepo, planRepo: &memPlanRepo{plans: map[string]*domain.TFEPlan{}}}
+	h.queue = &runQueue{runRepo: runRepo, planRepo: h.planRepo}
+	h.queue.apply = func(ctx context.Context, runID string) error {
+		atomic.AddInt32(applies, 1)
+		return nil
+	}
+	return h
+}
+
+func applyRun(h *TfeHandler, runID string) int {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/runs/"+runID+"/actions/apply", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues(runID)
+	if err := h.ApplyRun(c); err != nil {
+		return http.StatusInternalServerError
+	}
+	return rec.Code
+}
+
+func TestApplyRun_ConfirmsOnce(t *testing.T) {
+	repo := &memRunRepo{}
+	repo.add("run-1", "planned", false)
+	var applies int32
+	h := newApplyTestHandler(repo, &applies)
+
+	codes := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = applyRun(h, "run-1")
+		}(i)
+	}
+	wg.Wait()
+
+	if !(codes[0] == http.StatusOK && codes[1] == http.StatusConflict) && !(codes[0] == http.StatusConflict && codes[1] == http.StatusOK) {
+		t.Fatalf("confirm responses = %v, want one 200 and one 409", codes)
+	}
+	h.queue.cancel("run-1")
+	if got := atomic.LoadInt32(&applies); got > 1 {
+		t.Fatalf("apply started %d times", got)
+	}
+}
+
+func TestApplyRun_DoesNotReviveDiscardedRun(t *testing.T) {
+	repo := &memRunRepo{}
+	repo.add("run-1", "discarded", false)
+	var applies int32
+	h := newApplyTestHandler(staleRunRepo{repo}, &applies)
+
+	if code := applyRun(h, "run-1"); code != http.StatusConflict {
+		t.Fatalf("confirm response = %d, want 409", code)
+	}
+	if got := repo.status("run-1"); got != "discarded" {
+		t.Fatalf("run status = %s, want discarded", got)
+	}
+	if got := atomic.LoadInt32(&applies); got != 0 {
+		t.Fatalf("apply started %d times", got)
+	}
+}
@@ -26,6 +26,8 @@ type TfeHandler struct {
 	unitRepo        domain.UnitRepository // Direct access for locking during plan/apply
 	sandbox         sandbox.Sandbox
 	runActivityRepo domain.RemoteRunActivityRepository
+	varRepo         domain.TFEVariableRepository // Workspace variables and variable sets (nil without a DB)
+	queue           *runQueue                    // One active run per workspace
 }
 
 // NewTFETokenHandler creates a new TFE handler.
@@ -43,8 +45,9 @@ func NewTFETokenHandler(
 	configVerRepo domain.TFEConfigurationVersionRepository,
 	sandboxProvider sandbox.Sandbox,
 	runActivityRepo domain.RemoteRunActivityRepository,
+	varRepo domain.TFEVariableRepository,
 ) *TfeHandler {
-	return &TfeHandler{
+	h := &TfeHandler{
 		authHandler:        authHandler,
 		stateStore:         domain.TFEOperations(wrappedRepo),
 		directStateStore:   domain.TFEOperations(unwrappedRepo),
@@ -58,5 +61,8 @@ func NewTFETokenHandler(
 		unitRepo:           unwrappedRepo, // Use unwrapped repo for direct lock access
 		sandbox:            sandboxProvider,
 		runActivityRepo:    runActivityRepo,
+		varRepo:            varRepo,
 	}
+	h.queue = newRunQueue(h)
+	return h
 }
@@ -0,0 +1,191 @@
+package tfe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// runVariablesFileName is written next to the configuration so Terraform
+// loads it automatically; the zzz_ prefix makes it win over user *.auto.tfvars.
+const runVariablesFileName = "zzz_opentaco.auto.tfvars"
+
+// runEnvVariablesFileName holds TF_VAR_* environment variables in local
+// execution. It sorts before the user's *.auto.tfvars and the run variables
+// file, so like the environment it has the lowest precedence of the two.
+const runEnvVariablesFileName = "00_opentaco_env.auto.tfvars"
+
+// runVariables is the effective set of variables for a run
+type runVariables struct {
+	Env    map[string]string
+	TFVars string
+}
+
+// resolveRunVariables merges variable sets and workspace variables for a unit.
+// Precedence, lowest first: global sets, sets applied to the workspace,
+// workspace variables, priority sets. Within a tier, sets are applied in name
+// order so the result is deterministic.
+func resolveRunVariables(ctx context.Context, repo domain.TFEVariableRepository, orgID, unitID string) (*runVariables, error) {
+	if repo == nil {
+		return &runVariables{}, nil
+	}
+
+	sets, err := repo.ListVariableSetsForUnit(ctx, orgID, unitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variable sets: %w", err)
+	}
+	var global, scoped, priority [][]*domain.TFEVariable
+	for _, set := range sets {
+		vars, err := repo.ListVariableSetVariables(ctx, set.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variables for set %s: %w", set.Name, err)
+		}
+		switch {
+		case set.Priority:
+			priority = append(priority, vars)
+		case set.Global:
+			global = append(global, vars)
+		default:
+			scoped = append(scoped, vars)
+		}
+	}
+	workspace, err := repo.ListWorkspaceVariables(ctx, unitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace variables: %w", err)
+	}
+
+	layers := append(append(global, scoped...), workspace)
+	layers = append(layers, priority...)
+	return buildRunVariables(layers), nil
+}
+
+// buildRunVariables applies layers in order, later layers overriding earlier ones
+func buildRunVariables(layers [][]*domain.TFEVariable) *runVariables {
+	env := map[string]string{}
+	terraform := map[string]*domain.TFEVariable{}
+	for _, layer := range layers {
+		for _, v := range layer {
+			switch v.Category {
+			case domain.VariableCategoryEnv:
+				env[v.Key] = v.Value
+			case domain.VariableCategoryTerraform:
+				terraform[v.Key] = v
+			}
+		}
+	}
+
+	vars := make([]*domain.TFEVariable, 0, len(terraform))
+	for _, v := range terraform {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+	return &runVariables{Env: env, TFVars: renderTFVars(vars)}
+}
+
+// renderTFVars renders terraform-category variables as a tfvars file. HCL
+// values are written verbatim; everything else is a quoted string literal.
+func renderTFVars(vars []*domain.TFEVariable) string {
+	var b strings.Builder
+	for _, v := range vars {
+		value := v.Value
+		if !v.HCL {
+			value = quoteHCLString(value)
+		} else if strings.TrimSpace(value) == "" {
+			value = "null"
+		}
+		fmt.Fprintf(&b, "%s = %s\n", v.Key, value)
+	}
+	return b.String()
+}
+
+// renderEnvTFVars renders the TF_VAR_* environment variables as a tfvars file.
+// Terraform reads list and object values of TF_VAR_* as HCL, so values that
+// start like one are written verbatim and everything else as a string.
+func renderEnvTFVars(env map[string]string) string {
+	var keys []string
+	for k := range env {
+		if name := strings.TrimPrefix(k, "TF_VAR_"); name != k && name != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		value := env[k]
+		if trimmed := strings.TrimSpace(value); !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+			value = quoteHCLString(value)
+		}
+		fmt.Fprintf(&b, "%s = %s\n", strings.TrimPrefix(k, "TF_VAR_"), value)
+	}
+	return b.String()
+}
+
+// quoteHCLString produces an HCL string literal whose value is exactly s,
+// escaping template sequences so they are not interpolated.
+func quoteHCLString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch ch {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteByte(ch)
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte(ch)
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// applyLocal writes the tfvars file into workDir and sets environment
+// variables on tf. tfexec refuses TF_VAR_* and a few TF_* variables, so
+// TF_VAR_* variables are written to a tfvars file of their own instead, and
+// the run sees the same variables as in the sandbox.
+func (v *runVariables) applyLocal(workDir string, tf *tfexec.Terraform) error {
+	if v == nil {
+		return nil
+	}
+	if v.TFVars != "" {
+		if err := os.WriteFile(filepath.Join(workDir, runVariablesFileName), []byte(v.TFVars), 0o600); err != nil {
+			return fmt.Errorf("failed to write variables file: %w", err)
+		}
+	}
+	if envTFVars := renderEnvTFVars(v.Env); envTFVars != "" {
+		if err := os.WriteFile(filepath.Join(workDir, runEnvVariablesFileName), []byte(envTFVars), 0o600); err != nil {
+			return fmt.Errorf("failed to write environment variables file: %w", err)
+		}
+	}
+	if len(v.Env) == 0 {
+		return nil
+	}
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, val, ok := strings.Cut(kv, "="); ok {
+			env[k] = val
+		}
+	}
+	for k, val := range v.Env {
+		env[k] = val
+	}
+	return tf.SetEnv(tfexec.CleanEnv(env))
+}
@@ -0,0 +1,95 @@
+package tfe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+func tfVar(key, value string, hcl bool) *domain.TFEVariable {
+	return &domain.TFEVariable{Key: key, Value: value, Category: domain.VariableCategoryTerraform, HCL: hcl}
+}
+
+func envVar(key, value string) *domain.TFEVariable {
+	return &domain.TFEVariable{Key: key, Value: value, Category: domain.VariableCategoryEnv}
+}
+
+func TestBuildRunVariables_Precedence(t *testing.T) {
+	global := []*domain.TFEVariable{tfVar("region", "us-east-1", false), envVar("AWS_PROFILE", "global")}
+	workspace := []*domain.TFEVariable{tfVar("region", "eu-west-1", false), envVar("AWS_PROFILE", "workspace")}
+	priority := []*domain.TFEVariable{envVar("AWS_PROFILE", "priority")}
+
+	vars := buildRunVariables([][]*domain.TFEVariable{global, workspace, priority})
+
+	if got := vars.Env["AWS_PROFILE"]; got != "priority" {
+		t.Errorf("AWS_PROFILE = %q, want priority", got)
+	}
+	if want := "region = \"eu-west-1\"\n"; vars.TFVars != want {
+		t.Errorf("TFVars = %q, want %q", vars.TFVars, want)
+	}
+}
+
+func TestRenderTFVars(t *testing.T) {
+	vars := []*domain.TFEVariable{
+		tfVar("plain", "a \"quoted\" \\ value\nnext", false),
+		tfVar("template", "${var.x} and %{if true}", false),
+		tfVar("dollars", "$5 and 100%", false),
+		tfVar("tags", `{ env = "prod" }`, true),
+		tfVar("empty_hcl", "", true),
+	}
+	want := `plain = "a \"quoted\" \\ value\nnext"
+template = "$${var.x} and %%{if true}"
+dollars = "$5 and 100%"
+tags = { env = "prod" }
+empty_hcl = null
+`
+	if got := renderTFVars(vars); got != want {
+		t.Errorf("renderTFVars() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestApplyLocal_WritesEnvTFVars(t *testing.T) {
+	workDir := t.TempDir()
+	execPath := filepath.Join(t.TempDir(), "terraform")
+	if err := os.WriteFile(execPath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := tfexec.NewTerraform(workDir, execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := buildRunVariables([][]*domain.TFEVariable{{
+		tfVar("region", "eu-west-1", false),
+		envVar("TF_VAR_region", "us-east-1"),
+		envVar("TF_VAR_zones", `["a", "b"]`),
+		envVar("TF_VAR_name", "app ${x}"),
+		envVar("AWS_PROFILE", "prod"),
+	}})
+	if err := vars.applyLocal(workDir, tf); err != nil {
+		t.Fatalf("applyLocal: %v", err)
+	}
+
+	envFile, err := os.ReadFile(filepath.Join(workDir, runEnvVariablesFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `name = "app $${x}"
+region = "us-east-1"
+zones = ["a", "b"]
+`
+	if string(envFile) != want {
+		t.Errorf("environment tfvars =\n%s\nwant\n%s", envFile, want)
+	}
+	// the terraform-category variable is loaded later and wins
+	if runEnvVariablesFileName >= runVariablesFileName {
+		t.Errorf("%s must sort before %s", runEnvVariablesFileName, runVariablesFileName)
+	}
+	runFile, err := os.ReadFile(filepath.Join(workDir, runVariablesFileName))
+	if err != nil || string(runFile) != "region = \"eu-west-1\"\n" {
+		t.Errorf("run tfvars = %q %v", runFile, err)
+	}
+}
@@ -0,0 +1,777 @@
+package tfe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/domain/tfe"
+	"github.com/diggerhq/digger/opentaco/internal/logging"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	terraformVarKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	envVarKeyPattern       = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ListWorkspaceVariables handles GET /workspaces/:workspace_id/vars
+func (h *TfeHandler) ListWorkspaceVariables(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	_, unitUUID, err := h.resolveVariableWorkspace(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	vars, err := h.varRepo.ListWorkspaceVariables(c.Request().Context(), unitUUID)
+	if err != nil {
+		logger.Error("Failed to list workspace variables",
+			"operation", "tfe_list_vars",
+			"unit_uuid", unitUUID,
+			"error", err,
+		)
+		return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to list variables")
+	}
+
+	workspaceID := extractWorkspaceIDFromParam(c)
+	records := make([]interface{}, 0, len(vars))
+	for _, v := range vars {
+		records = append(records, toVariableRecord(v, workspaceID))
+	}
+	return marshalMany(c, http.StatusOK, records)
+}
+
+// CreateWorkspaceVariable handles POST /workspaces/:workspace_id/vars
+func (h *TfeHandler) CreateWorkspaceVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	orgUUID, unitUUID, err := h.resolveVariableWorkspace(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	v := &domain.TFEVariable{OrgID: orgUUID, UnitID: &unitUUID, Category: domain.VariableCategoryTerraform}
+	if err := bindVariable(c, v, true); err != nil {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", err.Error())
+	}
+	if err := h.varRepo.CreateVariable(c.Request().Context(), v); err != nil {
+		return h.variableWriteError(c, "tfe_create_var", err)
+	}
+
+	logger.Info("Created workspace variable",
+		"operation", "tfe_create_var",
+		"unit_uuid", unitUUID,
+		"var_id", v.ID,
+		"key", v.Key,
+		"category", v.Category,
+	)
+	return marshalOne(c, http.StatusCreated, toVariableRecord(v, extractWorkspaceIDFromParam(c)))
+}
+
+// GetWorkspaceVariable handles GET /workspaces/:workspace_id/vars/:var_id
+func (h *TfeHandler) GetWorkspaceVariable(c echo.Context) error {
+	setJSONAPIHeaders(c)
+
+	_, unitUUID, err := h.resolveVariableWorkspace(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.workspaceVariable(c, unitUUID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	return marshalOne(c, http.StatusOK, toVariableRecord(v, extractWorkspaceIDFromParam(c)))
+}
+
+// UpdateWorkspaceVariable handles PATCH /workspaces/:workspace_id/vars/:var_id
+func (h *TfeHandler) UpdateWorkspaceVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	_, unitUUID, err := h.resolveVariableWorkspace(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.workspaceVariable(c, unitUUID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	if err := bindVariable(c, v, false); err != nil {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", err.Error())
+	}
+	if err := h.varRepo.UpdateVariable(c.Request().Context(), v); err != nil {
+		return h.variableWriteError(c, "tfe_update_var", err)
+	}
+
+	logger.Info("Updated workspace variable",
+		"operation", "tfe_update_var",
+		"unit_uuid", unitUUID,
+		"var_id", v.ID,
+	)
+	return marshalOne(c, http.StatusOK, toVariableRecord(v, extractWorkspaceIDFromParam(c)))
+}
+
+// DeleteWorkspaceVariable handles DELETE /workspaces/:workspace_id/vars/:var_id
+func (h *TfeHandler) DeleteWorkspaceVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+
+	_, unitUUID, err := h.resolveVariableWorkspace(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.workspaceVariable(c, unitUUID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	if err := h.varRepo.DeleteVariable(c.Request().Context(), v.ID); err != nil {
+		return h.variableWriteError(c, "tfe_delete_var", err)
+	}
+
+	logger.Info("Deleted workspace variable",
+		"operation", "tfe_delete_var",
+		"unit_uuid", unitUUID,
+		"var_id", v.ID,
+	)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListVariableSets handles GET /organizations/:org_name/varsets
+func (h *TfeHandler) ListVariableSets(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	orgUUID, err := h.resolveVariableOrg(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	sets, err := h.varRepo.ListVariableSets(c.Request().Context(), orgUUID)
+	if err != nil {
+		logger.Error("Failed to list variable sets",
+			"operation", "tfe_list_varsets",
+			"org_uuid", orgUUID,
+			"error", err,
+		)
+		return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to list variable sets")
+	}
+	return h.marshalVariableSets(c, sets)
+}
+
+// ListWorkspaceVariableSets handles GET /workspaces/:workspace_id/varsets
+func (h *TfeHandler) ListWorkspaceVariableSets(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	orgUUID, unitUUID, err := h.resolveVariableWorkspace(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	sets, err := h.varRepo.ListVariableSetsForUnit(c.Request().Context(), orgUUID, unitUUID)
+	if err != nil {
+		logger.Error("Failed to list workspace variable sets",
+			"operation", "tfe_list_workspace_varsets",
+			"unit_uuid", unitUUID,
+			"error", err,
+		)
+		return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to list variable sets")
+	}
+	return h.marshalVariableSets(c, sets)
+}
+
+// CreateVariableSet handles POST /organizations/:org_name/varsets
+func (h *TfeHandler) CreateVariableSet(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	orgUUID, err := h.resolveVariableOrg(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	var req tfe.VariableSetRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return jsonAPIError(c, http.StatusBadRequest, "bad request", "Invalid request body")
+	}
+	attrs := req.Data.Attributes
+	if attrs.Name == nil || strings.TrimSpace(*attrs.Name) == "" {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", "name is required")
+	}
+
+	set := &domain.TFEVariableSet{OrgID: orgUUID, Name: strings.TrimSpace(*attrs.Name)}
+	if attrs.Description != nil {
+		set.Description = *attrs.Description
+	}
+	if attrs.Global != nil {
+		set.Global = *attrs.Global
+	}
+	if attrs.Priority != nil {
+		set.Priority = *attrs.Priority
+	}
+	for _, ws := range req.Data.Relationships.Workspaces.Data {
+		unitUUID, err := h.resolveWorkspaceRef(c, orgUUID, ws.ID)
+		if err != nil {
+			return jsonAPIError(c, http.StatusNotFound, "not found", err.Error())
+		}
+		set.UnitIDs = append(set.UnitIDs, unitUUID)
+	}
+
+	if err := h.varRepo.CreateVariableSet(c.Request().Context(), set); err != nil {
+		return h.variableWriteError(c, "tfe_create_varset", err)
+	}
+
+	logger.Info("Created variable set",
+		"operation", "tfe_create_varset",
+		"org_uuid", orgUUID,
+		"varset_id", set.ID,
+		"name", set.Name,
+	)
+	record, err := h.toVariableSetRecord(c, set)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	return marshalOne(c, http.StatusCreated, record)
+}
+
+// GetVariableSet handles GET /varsets/:varset_id
+func (h *TfeHandler) GetVariableSet(c echo.Context) error {
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	record, err := h.toVariableSetRecord(c, set)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	return marshalOne(c, http.StatusOK, record)
+}
+
+// UpdateVariableSet handles PATCH /varsets/:varset_id
+func (h *TfeHandler) UpdateVariableSet(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	var req tfe.VariableSetRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return jsonAPIError(c, http.StatusBadRequest, "bad request", "Invalid request body")
+	}
+	attrs := req.Data.Attributes
+	if attrs.Name != nil {
+		if strings.TrimSpace(*attrs.Name) == "" {
+			return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", "name cannot be empty")
+		}
+		set.Name = strings.TrimSpace(*attrs.Name)
+	}
+	if attrs.Description != nil {
+		set.Description = *attrs.Description
+	}
+	if attrs.Global != nil {
+		set.Global = *attrs.Global
+	}
+	if attrs.Priority != nil {
+		set.Priority = *attrs.Priority
+	}
+
+	if err := h.varRepo.UpdateVariableSet(c.Request().Context(), set); err != nil {
+		return h.variableWriteError(c, "tfe_update_varset", err)
+	}
+
+	logger.Info("Updated variable set",
+		"operation", "tfe_update_varset",
+		"varset_id", set.ID,
+	)
+	record, err := h.toVariableSetRecord(c, set)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	return marshalOne(c, http.StatusOK, record)
+}
+
+// DeleteVariableSet handles DELETE /varsets/:varset_id
+func (h *TfeHandler) DeleteVariableSet(c echo.Context) error {
+	logger := logging.FromContext(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	if err := h.varRepo.DeleteVariableSet(c.Request().Context(), set.ID); err != nil {
+		return h.variableWriteError(c, "tfe_delete_varset", err)
+	}
+
+	logger.Info("Deleted variable set",
+		"operation", "tfe_delete_varset",
+		"varset_id", set.ID,
+	)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListVariableSetVariables handles GET /varsets/:varset_id/relationships/vars
+func (h *TfeHandler) ListVariableSetVariables(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	vars, err := h.varRepo.ListVariableSetVariables(c.Request().Context(), set.ID)
+	if err != nil {
+		logger.Error("Failed to list variable set variables",
+			"operation", "tfe_list_varset_vars",
+			"varset_id", set.ID,
+			"error", err,
+		)
+		return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to list variables")
+	}
+	records := make([]interface{}, 0, len(vars))
+	for _, v := range vars {
+		records = append(records, toVariableRecord(v, ""))
+	}
+	return marshalMany(c, http.StatusOK, records)
+}
+
+// CreateVariableSetVariable handles POST /varsets/:varset_id/relationships/vars
+func (h *TfeHandler) CreateVariableSetVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	v := &domain.TFEVariable{OrgID: set.OrgID, VariableSetID: &set.ID, Category: domain.VariableCategoryTerraform}
+	if err := bindVariable(c, v, true); err != nil {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", err.Error())
+	}
+	if err := h.varRepo.CreateVariable(c.Request().Context(), v); err != nil {
+		return h.variableWriteError(c, "tfe_create_varset_var", err)
+	}
+
+	logger.Info("Created variable set variable",
+		"operation", "tfe_create_varset_var",
+		"varset_id", set.ID,
+		"var_id", v.ID,
+		"key", v.Key,
+	)
+	return marshalOne(c, http.StatusCreated, toVariableRecord(v, ""))
+}
+
+// GetVariableSetVariable handles GET /varsets/:varset_id/relationships/vars/:var_id
+func (h *TfeHandler) GetVariableSetVariable(c echo.Context) error {
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.read")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.variableSetVariable(c, set.ID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	return marshalOne(c, http.StatusOK, toVariableRecord(v, ""))
+}
+
+// UpdateVariableSetVariable handles PATCH /varsets/:varset_id/relationships/vars/:var_id
+func (h *TfeHandler) UpdateVariableSetVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+	setJSONAPIHeaders(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.variableSetVariable(c, set.ID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	if err := bindVariable(c, v, false); err != nil {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", err.Error())
+	}
+	if err := h.varRepo.UpdateVariable(c.Request().Context(), v); err != nil {
+		return h.variableWriteError(c, "tfe_update_varset_var", err)
+	}
+
+	logger.Info("Updated variable set variable",
+		"operation", "tfe_update_varset_var",
+		"varset_id", set.ID,
+		"var_id", v.ID,
+	)
+	return marshalOne(c, http.StatusOK, toVariableRecord(v, ""))
+}
+
+// DeleteVariableSetVariable handles DELETE /varsets/:varset_id/relationships/vars/:var_id
+func (h *TfeHandler) DeleteVariableSetVariable(c echo.Context) error {
+	logger := logging.FromContext(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	v, err := h.variableSetVariable(c, set.ID)
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	if err := h.varRepo.DeleteVariable(c.Request().Context(), v.ID); err != nil {
+		return h.variableWriteError(c, "tfe_delete_varset_var", err)
+	}
+
+	logger.Info("Deleted variable set variable",
+		"operation", "tfe_delete_varset_var",
+		"varset_id", set.ID,
+		"var_id", v.ID,
+	)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ApplyVariableSetToWorkspaces handles POST /varsets/:varset_id/relationships/workspaces
+func (h *TfeHandler) ApplyVariableSetToWorkspaces(c echo.Context) error {
+	return h.updateVariableSetWorkspaces(c, "tfe_apply_varset", h.varRepo.ApplyVariableSet)
+}
+
+// RemoveVariableSetFromWorkspaces handles DELETE /varsets/:varset_id/relationships/workspaces
+func (h *TfeHandler) RemoveVariableSetFromWorkspaces(c echo.Context) error {
+	return h.updateVariableSetWorkspaces(c, "tfe_remove_varset", h.varRepo.RemoveVariableSet)
+}
+
+func (h *TfeHandler) updateVariableSetWorkspaces(c echo.Context, operation string, update func(ctx context.Context, setID string, unitIDs []string) error) error {
+	logger := logging.FromContext(c)
+
+	set, err := h.variableSetFromParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+
+	var req tfe.ResourceIdentifierList
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return jsonAPIError(c, http.StatusBadRequest, "bad request", "Invalid request body")
+	}
+	unitIDs := make([]string, 0, len(req.Data))
+	for _, ws := range req.Data {
+		unitUUID, err := h.resolveWorkspaceRef(c, set.OrgID, ws.ID)
+		if err != nil {
+			return jsonAPIError(c, http.StatusNotFound, "not found", err.Error())
+		}
+		unitIDs = append(unitIDs, unitUUID)
+	}
+
+	if err := update(c.Request().Context(), set.ID, unitIDs); err != nil {
+		logger.Error("Failed to update variable set workspaces",
+			"operation", operation,
+			"varset_id", set.ID,
+			"error", err,
+		)
+		return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to update variable set workspaces")
+	}
+
+	logger.Info("Updated variable set workspaces",
+		"operation", operation,
+		"varset_id", set.ID,
+		"workspaces", len(unitIDs),
+	)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// resolveVariableWorkspace resolves :workspace_id for the variable endpoints,
+// which need a database-backed variable repository.
+func (h *TfeHandler) resolveVariableWorkspace(c echo.Context, action string) (string, string, error) {
+	if h.varRepo == nil {
+		return "", "", newAPIError(http.StatusNotImplemented, "not implemented", "Variables require a database-backed query store")
+	}
+	return h.resolveWorkspaceParam(c, action)
+}
+
+// resolveWorkspaceParam resolves :workspace_id to org and unit UUIDs and
+// checks RBAC. Errors are *apiError values for writeAPIError.
+func (h *TfeHandler) resolveWorkspaceParam(c echo.Context, action string) (string, string, error) {
+	logger := logging.FromContext(c)
+
+	workspaceID := extractWorkspaceIDFromParam(c)
+	if workspaceID == "" {
+		return "", "", newAPIError(http.StatusBadRequest, "bad request", "workspace_id required")
+	}
+	orgIdentifier, err := getOrgFromContext(c)
+	if err != nil {
+		return "", "", newAPIError(http.StatusUnauthorized, "unauthorized", "Organization context required")
+	}
+
+	stateID, err := h.convertWorkspaceToStateIDWithOrg(c.Request().Context(), orgIdentifier, convertWorkspaceToStateID(workspaceID))
+	if err != nil || !strings.Contains(stateID, "/") {
+		logger.Warn("Failed to resolve workspace",
+			"operation", "tfe_resolve_workspace",
+			"workspace_id", workspaceID,
+			"error", err,
+		)
+		return "", "", newAPIError(http.StatusNotFound, "not found", fmt.Sprintf("Workspace %s not found", workspaceID))
+	}
+
+	if err := h.checkWorkspacePermission(c, action, stateID); err != nil {
+		logger.Warn("Insufficient permissions for workspace",
+			"operation", "tfe_resolve_workspace",
+			"state_id", stateID,
+			"action", action,
+			"error", err,
+		)
+		return "", "", newAPIError(http.StatusForbidden, "forbidden", fmt.Sprintf("insufficient permissions: %s required", action))
+	}
+
+	orgUUID := strings.SplitN(stateID, "/", 2)[0]
+	return orgUUID, extractUnitUUID(stateID), nil
+}
+
+// resolveVariableOrg resolves the caller's org to a UUID and checks the action
+// against every unit in the org, since variable sets can apply to any of them.
+func (h *TfeHandler) resolveVariableOrg(c echo.Context, action string) (string, error) {
+	if h.varRepo == nil || h.identifierResolver == nil {
+		return "", newAPIError(http.StatusNotImplemented, "not implemented", "Variable sets require a database-backed query store")
+	}
+	orgIdentifier, err := getOrgFromContext(c)
+	if err != nil {
+		return "", newAPIError(http.StatusUnauthorized, "unauthorized", "Organization context required")
+	}
+	orgUUID, err := h.identifierResolver.ResolveOrganization(c.Request().Context(), orgIdentifier)
+	if err != nil {
+		return "", newAPIError(http.StatusNotFound, "not found", "Organization not found")
+	}
+	if err := h.checkWorkspacePermission(c, action, "*"); err != nil {
+		return "", newAPIError(http.StatusForbidden, "forbidden", fmt.Sprintf("insufficient permissions: %s on all units required", action))
+	}
+	return orgUUID, nil
+}
+
+func (h *TfeHandler) variableSetFromParam(c echo.Context, action string) (*domain.TFEVariableSet, error) {
+	orgUUID, err := h.resolveVariableOrg(c, action)
+	if err != nil {
+		return nil, err
+	}
+	setID := strings.TrimPrefix(c.Param("varset_id"), tfe.VariableSetType.String()+"-")
+	set, err := h.varRepo.GetVariableSet(c.Request().Context(), setID)
+	if err != nil || set.OrgID != orgUUID {
+		return nil, newAPIError(http.StatusNotFound, "not found", fmt.Sprintf("Variable set %s not found", c.Param("varset_id")))
+	}
+	return set, nil
+}
+
+func (h *TfeHandler) workspaceVariable(c echo.Context, unitUUID string) (*domain.TFEVariable, error) {
+	v, err := h.varRepo.GetVariable(c.Request().Context(), variableIDFromParam(c))
+	if err != nil || v.UnitID == nil || *v.UnitID != unitUUID {
+		return nil, newAPIError(http.StatusNotFound, "not found", fmt.Sprintf("Variable %s not found", c.Param("var_id")))
+	}
+	return v, nil
+}
+
+func (h *TfeHandler) variableSetVariable(c echo.Context, setID string) (*domain.TFEVariable, error) {
+	v, err := h.varRepo.GetVariable(c.Request().Context(), variableIDFromParam(c))
+	if err != nil || v.VariableSetID == nil || *v.VariableSetID != setID {
+		return nil, newAPIError(http.StatusNotFound, "not found", fmt.Sprintf("Variable %s not found", c.Param("var_id")))
+	}
+	return v, nil
+}
+
+// resolveWorkspaceRef resolves a "ws-<name>" identifier from a request body to a unit UUID
+func (h *TfeHandler) resolveWorkspaceRef(c echo.Context, orgUUID, workspaceID string) (string, error) {
+	stateID, err := h.convertWorkspaceToStateIDWithOrg(c.Request().Context(), orgUUID, workspaceID)
+	if err != nil || !strings.Contains(stateID, "/") {
+		return "", fmt.Errorf("workspace %s not found", workspaceID)
+	}
+	return extractUnitUUID(stateID), nil
+}
+
+func (h *TfeHandler) variableWriteError(c echo.Context, operation string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrVariableExists):
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "invalid attribute", "has already been taken")
+	case errors.Is(err, domain.ErrVariableNotFound), errors.Is(err, domain.ErrVariableSetNotFound):
+		return jsonAPIError(c, http.StatusNotFound, "not found", err.Error())
+	}
+	logging.FromContext(c).Error("Failed to save variable",
+		"operation", operation,
+		"error", err,
+	)
+	return jsonAPIError(c, http.StatusInternalServerError, "internal error", "Failed to save variable")
+}
+
+func (h *TfeHandler) marshalVariableSets(c echo.Context, sets []*domain.TFEVariableSet) error {
+	records := make([]interface{}, 0, len(sets))
+	for _, set := range sets {
+		record, err := h.toVariableSetRecord(c, set)
+		if err != nil {
+			return writeAPIError(c, err)
+		}
+		records = append(records, record)
+	}
+	return marshalMany(c, http.StatusOK, records)
+}
+
+func (h *TfeHandler) toVariableSetRecord(c echo.Context, set *domain.TFEVariableSet) (*tfe.VariableSetRecord, error) {
+	vars, err := h.varRepo.ListVariableSetVariables(c.Request().Context(), set.ID)
+	if err != nil {
+		return nil, newAPIError(http.StatusInternalServerError, "internal error", "Failed to list variables")
+	}
+	record := &tfe.VariableSetRecord{
+		ID:          tfe.NewTfeResourceIdentifier(tfe.VariableSetType, set.ID).String(),
+		Name:        set.Name,
+		Description: set.Description,
+		Global:      set.Global,
+		Priority:    set.Priority,
+		UpdatedAt:   set.UpdatedAt,
+		VarCount:    len(vars),
+		Workspaces:  []*tfe.WorkspaceRef{},
+		Vars:        make([]*tfe.VarRef, 0, len(vars)),
+	}
+	for _, v := range vars {
+		record.Vars = append(record.Vars, &tfe.VarRef{ID: tfe.NewTfeResourceIdentifier(tfe.VariableType, v.ID).String()})
+	}
+	for _, unitID := range set.UnitIDs {
+		unit, err := h.unitRepo.Get(c.Request().Context(), unitID)
+		if err != nil {
+			continue // unit was deleted; the link is cleaned up by the FK cascade
+		}
+		record.Workspaces = append(record.Workspaces, &tfe.WorkspaceRef{
+			ID: tfe.NewTfeResourceIdentifier(tfe.WorkspaceType, unit.Name).String(),
+		})
+	}
+	record.WorkspaceCount = len(record.Workspaces)
+	return record, nil
+}
+
+// bindVariable applies the request attributes to v. On create, key is required.
+func bindVariable(c echo.Context, v *domain.TFEVariable, create bool) error {
+	var req tfe.VariableRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return fmt.Errorf("invalid request body")
+	}
+	attrs := req.Data.Attributes
+
+	if attrs.Key != nil {
+		v.Key = strings.TrimSpace(*attrs.Key)
+	}
+	if attrs.Value != nil {
+		v.Value = *attrs.Value
+	}
+	if attrs.Description != nil {
+		v.Description = *attrs.Description
+	}
+	if attrs.Category != nil {
+		v.Category = *attrs.Category
+	}
+	if attrs.HCL != nil {
+		v.HCL = *attrs.HCL
+	}
+	if attrs.Sensitive != nil {
+		// Sensitive variables cannot be made non-sensitive again
+		if v.Sensitive && !*attrs.Sensitive && !create {
+			return fmt.Errorf("sensitive variables cannot be made non-sensitive")
+		}
+		v.Sensitive = *attrs.Sensitive
+	}
+
+	if create && v.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	switch v.Category {
+	case domain.VariableCategoryTerraform:
+		if !terraformVarKeyPattern.MatchString(v.Key) {
+			return fmt.Errorf("key %q is not a valid Terraform variable name", v.Key)
+		}
+	case domain.VariableCategoryEnv:
+		if !envVarKeyPattern.MatchString(v.Key) {
+			return fmt.Errorf("key %q is not a valid environment variable name", v.Key)
+		}
+		if v.HCL {
+			return fmt.Errorf("environment variables cannot be HCL")
+		}
+	default:
+		return fmt.Errorf("category must be %q or %q", domain.VariableCategoryTerraform, domain.VariableCategoryEnv)
+	}
+	return nil
+}
+
+func variableIDFromParam(c echo.Context) string {
+	return strings.TrimPrefix(c.Param("var_id"), tfe.VariableType.String()+"-")
+}
+
+func toVariableRecord(v *domain.TFEVariable, workspaceID string) *tfe.VariableRecord {
+	record := &tfe.VariableRecord{
+		ID:          tfe.NewTfeResourceIdentifier(tfe.VariableType, v.ID).String(),
+		Key:         v.Key,
+		Value:       v.Value,
+		Description: v.Description,
+		Category:    v.Category,
+		HCL:         v.HCL,
+		Sensitive:   v.Sensitive,
+		VersionID:   strconv.FormatInt(v.UpdatedAt.UnixNano(), 36),
+	}
+	if v.Sensitive {
+		record.Value = ""
+	}
+	if workspaceID != "" {
+		record.Configurable = &tfe.WorkspaceRef{ID: workspaceID}
+	}
+	if v.VariableSetID != nil {
+		record.VariableSet = &tfe.VarsetRef{ID: tfe.NewTfeResourceIdentifier(tfe.VariableSetType, *v.VariableSetID).String()}
+	}
+	return record
+}
+
+func setJSONAPIHeaders(c echo.Context) {
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+	c.Response().Header().Set("Tfp-Api-Version", "2.5")
+	c.Response().Header().Set("X-Terraform-Enterprise-App", "Terraform Enterprise")
+}
+
+func marshalOne(c echo.Context, status int, record interface{}) error {
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalPayload(c.Response().Writer, record)
+}
+
+func marshalMany(c echo.Context, status int, records []interface{}) error {
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalPayload(c.Response().Writer, records)
+}
+
+// apiError is returned by the lookup helpers and written as a JSON:API error document
+type apiError struct {
+	status int
+	title  string
+	detail string
+}
+
+func (e *apiError) Error() string { return e.detail }
+
+func newAPIError(status int, title, detail string) error {
+	return &apiError{status: status, title: title, detail: detail}
+}
+
+func writeAPIError(c echo.Context, err error) error {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = &apiError{status: http.StatusInternalServerError, title: "internal error", detail: err.Error()}
+	}
+	return jsonAPIError(c, apiErr.status, apiErr.title, apiErr.detail)
+}
+
+func jsonAPIError(c echo.Context, status int, title, detail string) error {
+	return c.JSON(status, map[string]interface{}{
+		"errors": []map[string]string{{
+			"status": strconv.Itoa(status),
+			"title":  title,
+			"detail": detail,
+		}},
+	})
+}
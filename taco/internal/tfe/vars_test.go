@@ -0,0 +1,288 @@
+package tfe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// memVarRepo keeps workspace variables in memory and rejects duplicate keys per
+// category like the database repository does
+type memVarRepo struct {
+	domain.TFEVariableRepository
+	vars   map[string]*domain.TFEVariable
+	nextID int
+}
+
+func (r *memVarRepo) checkDuplicate(v *domain.TFEVariable) error {
+	for _, other := range r.vars {
+		if other.ID != v.ID && other.Key == v.Key && other.Category == v.Category && *other.UnitID == *v.UnitID {
+			return domain.ErrVariableExists
+		}
+	}
+	return nil
+}
+
+func (r *memVarRepo) ListWorkspaceVariables(ctx context.Context, unitID string) ([]*domain.TFEVariable, error) {
+	var vars []*domain.TFEVariable
+	for _, v := range r.vars {
+		if *v.UnitID == unitID {
+			copied := *v
+			vars = append(vars, &copied)
+		}
+	}
+	return vars, nil
+}
+
+func (r *memVarRepo) GetVariable(ctx context.Context, id string) (*domain.TFEVariable, error) {
+	v, ok := r.vars[id]
+	if !ok {
+		return nil, domain.ErrVariableNotFound
+	}
+	copied := *v
+	return &copied, nil
+}
+
+func (r *memVarRepo) CreateVariable(ctx context.Context, v *domain.TFEVariable) error {
+	if err := r.checkDuplicate(v); err != nil {
+		return err
+	}
+	r.nextID++
+	v.ID = fmt.Sprintf("v%d", r.nextID)
+	copied := *v
+	r.vars[v.ID] = &copied
+	return nil
+}
+
+func (r *memVarRepo) UpdateVariable(ctx context.Context, v *domain.TFEVariable) error {
+	if _, ok := r.vars[v.ID]; !ok {
+		return domain.ErrVariableNotFound
+	}
+	if err := r.checkDuplicate(v); err != nil {
+		return err
+	}
+	copied := *v
+	r.vars[v.ID] = &copied
+	return nil
+}
+
+func (r *memVarRepo) DeleteVariable(ctx context.Context, id string) error {
+	if _, ok := r.vars[id]; !ok {
+		return domain.ErrVariableNotFound
+	}
+	delete(r.vars, id)
+	return nil
+}
+
+// memResolver resolves the org "acme" and its workspace "prod"
+type memResolver struct {
+	domain.IdentifierResolver
+}
+
+func (memResolver) ResolveOrganization(ctx context.Context, identifier string) (string, error) {
+	if identifier != "acme" {
+		return "", fmt.Errorf("organization %s not found", identifier)
+	}
+	return "org-1", nil
+}
+
+func (memResolver) ResolveUnit(ctx context.Context, identifier, orgID string) (string, error) {
+	if identifier != "prod" || orgID != "org-1" {
+		return "", fmt.Errorf("unit %s not found", identifier)
+	}
+	return "unit-1", nil
+}
+
+type varResponse struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Key       string `json:"key"`
+			Value     string `json:"value"`
+			Category  string `json:"category"`
+			Sensitive bool   `json:"sensitive"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+func newVarsTestHandler() (*TfeHandler, *memVarRepo) {
+	repo := &memVarRepo{vars: map[string]*domain.TFEVariable{}}
+	return &TfeHandler{varRepo: repo, identifierResolver: memResolver{}}, repo
+}
+
+// callVars calls a workspace variable handler for the workspace ws-prod of the org acme
+func callVars(t *testing.T, handler echo.HandlerFunc, method, varID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(method, "/workspaces/ws-prod/vars", strings.NewReader(body)), rec)
+	c.SetParamNames("workspace_id", "var_id")
+	c.SetParamValues("ws-prod", varID)
+	c.Set("jwt_org", "acme")
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func decodeVar(t *testing.T, rec *httptest.ResponseRecorder) varResponse {
+	t.Helper()
+	var resp varResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func varBody(attributes string) string {
+	return `{"data":{"type":"vars","attributes":` + attributes + `}}`
+}
+
+func TestWorkspaceVariables_CreateUpdateDelete(t *testing.T) {
+	h, repo := newVarsTestHandler()
+
+	rec := callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(`{"key":"region","value":"us-east-1","category":"terraform"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	created := decodeVar(t, rec)
+	if created.Data.ID != "var-v1" || created.Data.Attributes.Key != "region" || created.Data.Attributes.Value != "us-east-1" {
+		t.Fatalf("created variable = %+v", created.Data)
+	}
+	if v := repo.vars["v1"]; v.OrgID != "org-1" || *v.UnitID != "unit-1" {
+		t.Fatalf("stored variable org %s unit %s, want org-1 unit-1", v.OrgID, *v.UnitID)
+	}
+
+	rec = callVars(t, h.UpdateWorkspaceVariable, http.MethodPatch, created.Data.ID, varBody(`{"value":"eu-west-1"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := decodeVar(t, rec).Data.Attributes.Value; got != "eu-west-1" {
+		t.Fatalf("updated value = %q, want eu-west-1", got)
+	}
+	if got := repo.vars["v1"].Value; got != "eu-west-1" {
+		t.Fatalf("stored value = %q, want eu-west-1", got)
+	}
+
+	rec = callVars(t, h.DeleteWorkspaceVariable, http.MethodDelete, created.Data.ID, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := callVars(t, h.GetWorkspaceVariable, http.MethodGet, created.Data.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get after delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestWorkspaceVariables_VariableOfOtherWorkspaceNotFound(t *testing.T) {
+	h, repo := newVarsTestHandler()
+	other := "unit-2"
+	repo.vars["v9"] = &domain.TFEVariable{ID: "v9", OrgID: "org-1", UnitID: &other, Key: "region", Category: domain.VariableCategoryTerraform}
+
+	for name, call := range map[string]func() *httptest.ResponseRecorder{
+		"get": func() *httptest.ResponseRecorder {
+			return callVars(t, h.GetWorkspaceVariable, http.MethodGet, "var-v9", "")
+		},
+		"update": func() *httptest.ResponseRecorder {
+			return callVars(t, h.UpdateWorkspaceVariable, http.MethodPatch, "var-v9", varBody(`{"value":"x"}`))
+		},
+		"delete": func() *httptest.ResponseRecorder {
+			return callVars(t, h.DeleteWorkspaceVariable, http.MethodDelete, "var-v9", "")
+		},
+	} {
+		if rec := call(); rec.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404", name, rec.Code)
+		}
+	}
+	if _, ok := repo.vars["v9"]; !ok {
+		t.Fatal("variable of another workspace was deleted")
+	}
+}
+
+func TestWorkspaceVariables_SensitiveValuesBlanked(t *testing.T) {
+	h, repo := newVarsTestHandler()
+
+	rec := callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(`{"key":"TOKEN","value":"s3cret","category":"env","sensitive":true}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	created := decodeVar(t, rec)
+	if !created.Data.Attributes.Sensitive || created.Data.Attributes.Value != "" {
+		t.Fatalf("created sensitive variable = %+v, want blank value", created.Data.Attributes)
+	}
+	if got := repo.vars["v1"].Value; got != "s3cret" {
+		t.Fatalf("stored value = %q, want s3cret", got)
+	}
+
+	if got := decodeVar(t, callVars(t, h.GetWorkspaceVariable, http.MethodGet, created.Data.ID, "")).Data.Attributes.Value; got != "" {
+		t.Fatalf("get returned sensitive value %q", got)
+	}
+	rec = callVars(t, h.ListWorkspaceVariables, http.MethodGet, "", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "s3cret") {
+		t.Fatalf("list status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = callVars(t, h.UpdateWorkspaceVariable, http.MethodPatch, created.Data.ID, varBody(`{"value":"n3w"}`))
+	if got := decodeVar(t, rec).Data.Attributes.Value; rec.Code != http.StatusOK || got != "" {
+		t.Fatalf("update status = %d, value %q", rec.Code, got)
+	}
+	if got := repo.vars["v1"].Value; got != "n3w" {
+		t.Fatalf("stored value = %q, want n3w", got)
+	}
+
+	rec = callVars(t, h.UpdateWorkspaceVariable, http.MethodPatch, created.Data.ID, varBody(`{"sensitive":false}`))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("making a variable non-sensitive returned %d, want 422", rec.Code)
+	}
+	if !repo.vars["v1"].Sensitive {
+		t.Fatal("variable was made non-sensitive")
+	}
+}
+
+func TestWorkspaceVariables_RejectsDuplicatesAndInvalidCategories(t *testing.T) {
+	h, repo := newVarsTestHandler()
+
+	if rec := callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(`{"key":"region","value":"a","category":"terraform"}`)); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	rec := callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(`{"key":"region","value":"b","category":"terraform"}`))
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "has already been taken") {
+		t.Fatalf("duplicate create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	// The same key is allowed in the other category
+	rec = callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(`{"key":"region","value":"c","category":"env"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create in other category status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	envID := decodeVar(t, rec).Data.ID
+
+	// Moving it back into the terraform category collides with the first variable
+	rec = callVars(t, h.UpdateWorkspaceVariable, http.MethodPatch, envID, varBody(`{"category":"terraform"}`))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("duplicate update status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := repo.vars["v2"].Category; got != domain.VariableCategoryEnv {
+		t.Fatalf("stored category = %s, want env", got)
+	}
+
+	for _, attributes := range []string{
+		`{"key":"region","category":"secret"}`,
+		`{"key":"tags","value":"{}","category":"env","hcl":true}`,
+		`{"key":"1region","category":"terraform"}`,
+		`{"value":"no key","category":"terraform"}`,
+	} {
+		if rec := callVars(t, h.CreateWorkspaceVariable, http.MethodPost, "", varBody(attributes)); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("create %s status = %d, want 422", attributes, rec.Code)
+		}
+	}
+	if len(repo.vars) != 2 {
+		t.Fatalf("stored %d variables, want 2", len(repo.vars))
+	}
+}
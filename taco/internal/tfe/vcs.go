@@ -0,0 +1,291 @@
+package tfe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+	"github.com/diggerhq/digger/opentaco/internal/domain/tfe"
+	"github.com/diggerhq/digger/opentaco/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// vcsRunSource marks runs and configuration versions created from a VCS push
+const vcsRunSource = "vcs"
+
+// vcsCommit is a commit fetched from a workspace's bound repository
+type vcsCommit struct {
+	SHA     string
+	Message string
+	Archive []byte // tar.gz of the tree at SHA, as uploaded by the CLI
+}
+
+// vcsRepoForUnit returns the vcs-repo attribute of a workspace, nil when unbound
+func vcsRepoForUnit(unit *storage.UnitMetadata) *tfe.TFEVCSRepository {
+	if unit == nil || unit.TFEVCSRepoURL == nil || *unit.TFEVCSRepoURL == "" {
+		return nil
+	}
+	return &tfe.TFEVCSRepository{
+		Branch:            vcsBranchForUnit(unit),
+		DisplayIdentifier: *unit.TFEVCSRepoURL,
+		RepositoryHTTPURL: *unit.TFEVCSRepoURL,
+		ServiceProvider:   "git",
+	}
+}
+
+// vcsBranchForUnit returns the bound branch, empty for the repository's default branch
+func vcsBranchForUnit(unit *storage.UnitMetadata) string {
+	if unit.TFEVCSBranch == nil {
+		return ""
+	}
+	return strings.TrimSpace(*unit.TFEVCSBranch)
+}
+
+// TriggerVCSRun handles POST /workspaces/:workspace_id/actions/vcs-push.
+// Git servers call it from a push hook; when the bound branch has moved it
+// queues a plan for the new commit, or a plan-and-apply on auto-apply workspaces.
+func (h *TfeHandler) TriggerVCSRun(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	orgUUID, unitID, err := h.resolveWorkspaceParam(c, "unit.write")
+	if err != nil {
+		return writeAPIError(c, err)
+	}
+	logger := slog.Default().With(
+		slog.String("operation", "trigger_vcs_run"),
+		slog.String("unit_id", unitID),
+	)
+
+	unit, err := h.unitRepo.Get(ctx, unitID)
+	if err != nil {
+		return jsonAPIError(c, http.StatusNotFound, "not found", fmt.Sprintf("Workspace %s not found", unitID))
+	}
+	if vcsRepoForUnit(unit) == nil {
+		return jsonAPIError(c, http.StatusUnprocessableEntity, "unprocessable entity", "Workspace is not connected to a VCS repository")
+	}
+	if unit.TFEExecutionMode != nil && *unit.TFEExecutionMode == "remote" && h.sandbox == nil {
+		return jsonAPIError(c, http.StatusForbidden, "forbidden", "Remote execution mode requires configuring OPENTACO_SANDBOX_PROVIDER")
+	}
+
+	repoURL, branch := *unit.TFEVCSRepoURL, vcsBranchForUnit(unit)
+	sha, err := resolveBranchHead(ctx, repoURL, branch)
+	if err != nil {
+		logger.Error("failed to resolve branch head", slog.String("repo", repoURL), slog.String("error", err.Error()))
+		return jsonAPIError(c, http.StatusBadGateway, "bad gateway", fmt.Sprintf("Failed to read repository: %v", err))
+	}
+
+	// Repeated hooks for the same commit return the run that was already queued
+	if previous := h.latestVCSRun(ctx, unitID); previous != nil && previous.CommitSHA != nil && *previous.CommitSHA == sha {
+		logger.Info("branch has not moved since the last VCS run", slog.String("sha", sha), slog.String("run_id", previous.ID))
+		return h.writeVCSRun(c, http.StatusOK, previous)
+	}
+
+	commit, err := fetchCommit(ctx, repoURL, branch, sha)
+	if err != nil {
+		logger.Error("failed to fetch commit", slog.String("sha", sha), slog.String("error", err.Error()))
+		return jsonAPIError(c, http.StatusBadGateway, "bad gateway", fmt.Sprintf("Failed to fetch commit %s: %v", sha, err))
+	}
+
+	userID, _ := c.Get("user_id").(string)
+	if userID == "" {
+		userID = "system"
+	}
+	run, err := h.queueVCSRun(ctx, orgUUID, unit, commit, userID)
+	if err != nil {
+		logger.Error("failed to queue VCS run", slog.String("sha", sha), slog.String("error", err.Error()))
+		return writeAPIError(c, err)
+	}
+
+	logger.Info("queued VCS run",
+		slog.String("run_id", run.ID),
+		slog.String("sha", sha),
+		slog.Bool("auto_apply", run.AutoApply))
+	return h.writeVCSRun(c, http.StatusCreated, run)
+}
+
+// latestVCSRun returns the newest run of a unit that was triggered by a push
+func (h *TfeHandler) latestVCSRun(ctx context.Context, unitID string) *domain.TFERun {
+	runs, err := h.runRepo.ListRunsForUnit(ctx, unitID, 0)
+	if err != nil {
+		return nil
+	}
+	for _, run := range runs {
+		if run.Source == vcsRunSource {
+			return run
+		}
+	}
+	return nil
+}
+
+// queueVCSRun stores the commit as a configuration version and queues a run for it
+func (h *TfeHandler) queueVCSRun(ctx context.Context, orgUUID string, unit *storage.UnitMetadata, commit *vcsCommit, createdBy string) (*domain.TFERun, error) {
+	autoApply := unit.TFEAutoApply != nil && *unit.TFEAutoApply
+
+	configVer := &domain.TFEConfigurationVersion{
+		OrgID:            orgUUID,
+		UnitID:           unit.ID,
+		Status:           "pending",
+		Source:           vcsRunSource,
+		Speculative:      !autoApply,
+		AutoQueueRuns:    true,
+		StatusTimestamps: "{}",
+		CreatedBy:        createdBy,
+	}
+	if err := h.configVerRepo.CreateConfigurationVersion(ctx, configVer); err != nil {
+		return nil, fmt.Errorf("failed to create configuration version: %w", err)
+	}
+
+	archiveBlobID := fmt.Sprintf("config-versions/%s/archive.tar.gz", configVer.ID)
+	if err := h.blobStore.UploadBlob(storage.WithBlobScope(ctx, orgUUID), archiveBlobID, commit.Archive); err != nil {
+		return nil, fmt.Errorf("failed to store archive: %w", err)
+	}
+	uploadedAt := time.Now()
+	if err := h.configVerRepo.UpdateConfigurationVersionStatus(ctx, configVer.ID, "uploaded", &uploadedAt, &archiveBlobID); err != nil {
+		return nil, fmt.Errorf("failed to update configuration version status: %w", err)
+	}
+
+	sha, message := commit.SHA, commit.Message
+	run := &domain.TFERun{
+		OrgID:                  orgUUID,
+		UnitID:                 unit.ID,
+		Status:                 "pending",
+		Message:                strings.SplitN(message, "\n", 2)[0],
+		PlanOnly:               !autoApply,
+		AutoApply:              autoApply,
+		Source:                 vcsRunSource,
+		IsCancelable:           true,
+		ConfigurationVersionID: configVer.ID,
+		CreatedBy:              createdBy,
+		CommitSHA:              &sha,
+		CommitMessage:          &message,
+	}
+	if err := h.runRepo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	plan := &domain.TFEPlan{
+		OrgID:     orgUUID,
+		RunID:     run.ID,
+		Status:    "pending",
+		CreatedBy: createdBy,
+	}
+	if err := h.planRepo.CreatePlan(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to create plan: %w", err)
+	}
+	if err := h.runRepo.UpdateRunPlanID(ctx, run.ID, plan.ID); err != nil {
+		return nil, fmt.Errorf("failed to update run with plan ID: %w", err)
+	}
+	run.PlanID = &plan.ID
+
+	// The request context ends with the push hook, the run outlives it
+	h.queue.enqueue(context.Background(), run)
+	if current, err := h.runRepo.GetRun(ctx, run.ID); err == nil {
+		run.Status = current.Status
+	}
+	return run, nil
+}
+
+func (h *TfeHandler) writeVCSRun(c echo.Context, status int, run *domain.TFERun) error {
+	response := &tfe.TFERun{
+		ID:              run.ID,
+		Status:          run.Status,
+		IsDestroy:       run.IsDestroy,
+		Message:         run.Message,
+		PlanOnly:        run.PlanOnly,
+		AutoApply:       run.AutoApply,
+		PositionInQueue: h.queue.position(c.Request().Context(), run),
+		Workspace:       &tfe.WorkspaceRef{ID: "ws-" + run.UnitID},
+		ConfigurationVersion: &tfe.ConfigurationVersionRef{
+			ID: run.ConfigurationVersionID,
+		},
+	}
+	response.Actions, response.Permissions = runActions(run, false)
+	setRunSource(response, run)
+	if run.PlanID != nil {
+		response.Plan = &tfe.PlanRef{ID: *run.PlanID}
+	}
+
+	setJSONAPIHeaders(c)
+	return marshalOne(c, status, response)
+}
+
+// resolveBranchHead returns the commit a branch points at without cloning.
+// An empty branch resolves the repository's default branch.
+func resolveBranchHead(ctx context.Context, repoURL, branch string) (string, error) {
+	ref := "HEAD"
+	if branch != "" {
+		ref = "refs/heads/" + branch
+	}
+	out, err := runGit(ctx, "", "ls-remote", "--", repoURL, ref)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("ref %s not found in %s", ref, repoURL)
+}
+
+// fetchCommit clones a branch and archives the tree at sha
+func fetchCommit(ctx context.Context, repoURL, branch, sha string) (*vcsCommit, error) {
+	dir, err := os.MkdirTemp("", "opentaco-vcs-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"clone", "--quiet", "--no-checkout"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	if _, err := runGit(ctx, "", append(args, "--", repoURL, dir)...); err != nil {
+		return nil, err
+	}
+	message, err := runGit(ctx, dir, "log", "-1", "--format=%B", sha)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := runGit(ctx, dir, "archive", "--format=tar.gz", sha)
+	if err != nil {
+		return nil, err
+	}
+	return &vcsCommit{SHA: sha, Message: strings.TrimSpace(string(message)), Archive: archive}, nil
+}
+
+// gitAllowedProtocols are the transports git may use for unit repositories. Local paths, file://
+// and helpers such as ext:: would give unit writers access to the server, file is only added
+// when the operator opts in with OPENTACO_VCS_ALLOW_LOCAL_REPOS.
+const gitAllowedProtocols = "https:ssh"
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	protocols, fileAllow := gitAllowedProtocols, "never"
+	if domain.LocalVCSReposAllowed() {
+		protocols, fileAllow = gitAllowedProtocols+":file", "always"
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL="+protocols,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=protocol.file.allow",
+		"GIT_CONFIG_VALUE_0="+fileAllow,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
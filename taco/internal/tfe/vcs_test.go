@@ -0,0 +1,145 @@
+package tfe
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diggerhq/digger/opentaco/internal/domain"
+)
+
+// newBareRepo creates a bare repository with one commit on main and returns
+// its path and a working clone to push further commits from
+func newBareRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	bare, work := filepath.Join(root, "repo.git"), filepath.Join(root, "work")
+	git(t, root, "init", "--quiet", "--bare", "--initial-branch=main", bare)
+	git(t, root, "clone", "--quiet", bare, work)
+	commitFile(t, work, "main.tf", "resource \"null_resource\" \"a\" {}\n", "Add null resource\n\nWith a body")
+	git(t, work, "push", "--quiet", "origin", "HEAD:main")
+	return bare, work
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func commitFile(t *testing.T, work, name, contents, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(work, name), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, work, "add", name)
+	git(t, work, "commit", "--quiet", "-m", message)
+}
+
+func archiveFiles(t *testing.T, archive []byte) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			names = append(names, hdr.Name)
+		}
+	}
+}
+
+// allowLocalRepos lets git read the local test repositories for the duration of a test
+func allowLocalRepos(t *testing.T) {
+	t.Helper()
+	t.Setenv(domain.AllowLocalVCSReposEnv, "true")
+}
+
+func TestVCS_FetchBranchCommit(t *testing.T) {
+	bare, work := newBareRepo(t)
+	allowLocalRepos(t)
+	ctx := context.Background()
+
+	sha, err := resolveBranchHead(ctx, bare, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := git(t, work, "rev-parse", "HEAD"); sha != want {
+		t.Fatalf("head = %s, want %s", sha, want)
+	}
+	if defaultHead, err := resolveBranchHead(ctx, bare, ""); err != nil || defaultHead != sha {
+		t.Fatalf("default branch head = %s, %v", defaultHead, err)
+	}
+
+	commit, err := fetchCommit(ctx, bare, "main", sha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Message != "Add null resource\n\nWith a body" {
+		t.Fatalf("message = %q", commit.Message)
+	}
+	if files := archiveFiles(t, commit.Archive); len(files) != 1 || files[0] != "main.tf" {
+		t.Fatalf("archive files = %v", files)
+	}
+
+	// the branch moving is visible without cloning
+	commitFile(t, work, "variables.tf", "variable \"region\" {}\n", "Add region")
+	git(t, work, "push", "--quiet", "origin", "HEAD:main")
+	moved, err := resolveBranchHead(ctx, bare, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == sha {
+		t.Fatal("expected branch head to move")
+	}
+
+	if _, err := resolveBranchHead(ctx, bare, "missing"); err == nil {
+		t.Fatal("expected error for missing branch")
+	}
+}
+
+func TestVCS_RejectsLocalReposAndOptions(t *testing.T) {
+	bare, _ := newBareRepo(t)
+	ctx := context.Background()
+
+	if _, err := resolveBranchHead(ctx, bare, "main"); err == nil {
+		t.Fatal("expected local repository to be rejected")
+	}
+	if _, err := fetchCommit(ctx, "file://"+bare, "main", "HEAD"); err == nil {
+		t.Fatal("expected file:// repository to be rejected")
+	}
+
+	marker := filepath.Join(t.TempDir(), "marker")
+	if _, err := resolveBranchHead(ctx, "--upload-pack=touch "+marker+";", "main"); err == nil {
+		t.Fatal("expected option to be rejected")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran the command passed as repository URL")
+	}
+}
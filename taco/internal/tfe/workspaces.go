@@ -433,7 +433,10 @@ func (h *TfeHandler) GetWorkspace(c echo.Context) error {
 	// Check if unit is locked
 	locked := unit.Locked
 	var currentRun *tfe.TFERun
-	if locked && unit.LockID != "" {
+	if active := h.queue.currentRun(c.Request().Context(), unitUUID); active != nil {
+		// Terraform compares pending runs against the current run to show queue position
+		currentRun = &tfe.TFERun{ID: active.ID}
+	} else if locked && unit.LockID != "" {
 		currentRun = &tfe.TFERun{
 			ID: unit.LockID,
 		}
@@ -467,7 +470,7 @@ func (h *TfeHandler) GetWorkspace(c echo.Context) error {
 			CanUnlock:         true,
 			CanUpdate:         true,
 			CanReadSettings:   true,
-			CanUpdateVariable: false,
+			CanUpdateVariable: h.varRepo != nil,
 		},
 		QueueAllRuns:               false,
 		SpeculativeEnabled:         false, // False = allow confirmable applies, True = all runs are plan-only
@@ -477,7 +480,7 @@ func (h *TfeHandler) GetWorkspace(c echo.Context) error {
 		TerraformVersion:           "1.5.6",
 		TriggerPrefixes:            []string{},
 		TriggerPatterns:            []string{},
-		VCSRepo:                    nil,
+		VCSRepo:                    vcsRepoForUnit(unit),
 		WorkingDirectory:           "",
 		ResourceCount:              0,
 		ApplyDurationAverage:       0,
@@ -86,6 +86,8 @@ type CreateUnitRequest struct {
 	TFETerraformVersion  *string `json:"tfe_terraform_version"`
 	TFEEngine            *string `json:"tfe_engine"`
 	TFEWorkingDirectory  *string `json:"tfe_working_directory"`
+	TFEVCSRepoURL        *string `json:"tfe_vcs_repo_url"`
+	TFEVCSBranch         *string `json:"tfe_vcs_branch"`
 }
 
 type CreateUnitResponse struct {
@@ -115,6 +117,15 @@ func (h *Handler) CreateUnit(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	name := domain.NormalizeUnitID(req.Name)
+	if err := validateVCSSettings(req.TFEVCSRepoURL, req.TFEVCSBranch); err != nil {
+		logger.Error("Invalid VCS settings",
+			"operation", "create_unit",
+			"name", name,
+			"error", err,
+		)
+		analytics.SendEssential("unit_create_failed_invalid_vcs_settings")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	// Get org UUID from domain context (set by middleware for both JWT and webhook routes)
 	ctx := c.Request().Context()
@@ -182,6 +193,18 @@ func (h *Handler) CreateUnit(c echo.Context) error {
 		}
 	}
 
+	if req.TFEVCSRepoURL != nil || req.TFEVCSBranch != nil {
+		if h.queryStore != nil {
+			if err := h.queryStore.UpdateUnitVCSSettings(ctx, metadata.ID, req.TFEVCSRepoURL, req.TFEVCSBranch); err != nil {
+				logger.Warn("Failed to update VCS settings for unit",
+					"operation", "create_unit",
+					"unit_id", metadata.ID,
+					"error", err,
+				)
+			}
+		}
+	}
+
 	logger.Info("Unit created successfully",
 		"operation", "create_unit",
 		"name", name,
@@ -198,6 +221,24 @@ type UpdateUnitRequest struct {
 	TFETerraformVersion  *string `json:"tfe_terraform_version"`
 	TFEEngine            *string `json:"tfe_engine"`
 	TFEWorkingDirectory  *string `json:"tfe_working_directory"`
+	TFEVCSRepoURL        *string `json:"tfe_vcs_repo_url"`
+	TFEVCSBranch         *string `json:"tfe_vcs_branch"`
+}
+
+// validateVCSSettings checks the repository and branch a unit is bound to before they are stored,
+// both end up as arguments of git on the server
+func validateVCSSettings(repoURL *string, branch *string) error {
+	if repoURL != nil {
+		if err := domain.ValidateVCSRepoURL(*repoURL); err != nil {
+			return err
+		}
+	}
+	if branch != nil {
+		if err := domain.ValidateVCSBranch(*branch); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (h *Handler) UpdateUnit(c echo.Context) error {
@@ -249,6 +290,13 @@ func (h *Handler) UpdateUnit(c echo.Context) error {
 			"error", err)
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
+	if err := validateVCSSettings(req.TFEVCSRepoURL, req.TFEVCSBranch); err != nil {
+		logger.Error("Invalid VCS settings",
+			"operation", "update_unit",
+			"unit_id", unitID,
+			"error", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	// Update TFE settings if any are provided
 	if req.TFEAutoApply != nil || req.TFEExecutionMode != nil || req.TFETerraformVersion != nil || req.TFEWorkingDirectory != nil {
@@ -266,6 +314,21 @@ func (h *Handler) UpdateUnit(c echo.Context) error {
 		}
 	}
 
+	if req.TFEVCSRepoURL != nil || req.TFEVCSBranch != nil {
+		if h.queryStore != nil {
+			if err := h.queryStore.UpdateUnitVCSSettings(ctx, unitID, req.TFEVCSRepoURL, req.TFEVCSBranch); err != nil {
+				logger.Error("Failed to update VCS settings for unit",
+					"operation", "update_unit",
+					"unit_id", unitID,
+					"error", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "Failed to update unit settings",
+					"detail": err.Error(),
+				})
+			}
+		}
+	}
+
 	logger.Info("Unit updated successfully",
 		"operation", "update_unit",
 		"unit_id", unitID,
@@ -437,6 +500,8 @@ func (h *Handler) GetUnit(c echo.Context) error {
 		TFEEngine:           metadata.TFEEngine,
 		TFEWorkingDirectory: metadata.TFEWorkingDirectory,
 		TFEExecutionMode:    metadata.TFEExecutionMode,
+		TFEVCSRepoURL:       metadata.TFEVCSRepoURL,
+		TFEVCSBranch:        metadata.TFEVCSBranch,
 	})
 }
 
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `audit_events` (
+  `id` varchar(36) NOT NULL PRIMARY KEY,
+  `org_id` varchar(36) NOT NULL,
+  `seq` bigint NOT NULL,
+  `timestamp` datetime(6) NOT NULL,
+  `actor` varchar(255) NOT NULL,
+  `action` varchar(64) NOT NULL,
+  `resource` varchar(500) DEFAULT NULL,
+  `source_ip` varchar(64) DEFAULT NULL,
+  `outcome` varchar(16) NOT NULL,
+  `status` bigint DEFAULT 0,
+  `request_id` varchar(100) DEFAULT NULL,
+  `details` text,
+  `prev_hash` varchar(64) DEFAULT NULL,
+  `hash` varchar(64) NOT NULL,
+  UNIQUE INDEX `idx_audit_events_org_seq` (`org_id`, `seq`),
+  INDEX `idx_audit_events_org_timestamp` (`org_id`, `timestamp`),
+  INDEX `idx_audit_events_actor` (`actor`),
+  INDEX `idx_audit_events_action` (`action`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `encryption_data_keys` (
+  `id` varchar(36) NOT NULL PRIMARY KEY,
+  `scope` varchar(255) NOT NULL,
+  `version` bigint NOT NULL,
+  `wrapped_key` blob NOT NULL,
+  `master_key_id` varchar(500) NOT NULL,
+  `created_at` datetime(6) DEFAULT NULL,
+  `rotated_at` datetime(6) DEFAULT NULL,
+  UNIQUE INDEX `idx_encryption_data_keys_scope_version` (`scope`, `version`),
+  INDEX `idx_encryption_data_keys_master_key_id` (`master_key_id`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
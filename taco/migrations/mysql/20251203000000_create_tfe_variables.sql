@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS `tfe_variable_sets` (
+  `id` varchar(36) NOT NULL PRIMARY KEY,
+  `org_id` varchar(36) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `global` boolean DEFAULT false,
+  `priority` boolean DEFAULT false,
+  `created_at` datetime(6) DEFAULT NULL,
+  `updated_at` datetime(6) DEFAULT NULL,
+  UNIQUE INDEX `idx_tfe_variable_sets_org_name` (`org_id`, `name`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS `tfe_variables` (
+  `id` varchar(36) NOT NULL PRIMARY KEY,
+  `org_id` varchar(36) NOT NULL,
+  `unit_id` varchar(36) DEFAULT NULL,
+  `variable_set_id` varchar(36) DEFAULT NULL,
+  `var_key` varchar(255) NOT NULL,
+  `value` blob,
+  `description` text,
+  `category` varchar(20) NOT NULL DEFAULT 'terraform',
+  `hcl` boolean DEFAULT false,
+  `sensitive` boolean DEFAULT false,
+  `created_at` datetime(6) DEFAULT NULL,
+  `updated_at` datetime(6) DEFAULT NULL,
+  INDEX `idx_tfe_variables_org_id` (`org_id`),
+  INDEX `idx_tfe_variables_unit_id` (`unit_id`),
+  INDEX `idx_tfe_variables_variable_set_id` (`variable_set_id`),
+  CONSTRAINT `fk_tfe_variables_unit` FOREIGN KEY (`unit_id`) REFERENCES `units` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_tfe_variables_set` FOREIGN KEY (`variable_set_id`) REFERENCES `tfe_variable_sets` (`id`) ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS `tfe_variable_set_units` (
+  `variable_set_id` varchar(36) NOT NULL,
+  `unit_id` varchar(36) NOT NULL,
+  PRIMARY KEY (`variable_set_id`, `unit_id`),
+  INDEX `idx_tfe_variable_set_units_unit_id` (`unit_id`),
+  CONSTRAINT `fk_tfe_variable_set_units_set` FOREIGN KEY (`variable_set_id`) REFERENCES `tfe_variable_sets` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_tfe_variable_set_units_unit` FOREIGN KEY (`unit_id`) REFERENCES `units` (`id`) ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,5 @@
+-- Bind workspaces to a VCS repository branch
+ALTER TABLE `units` ADD COLUMN `tfe_vcs_repo_url` varchar(1000) NULL, ADD COLUMN `tfe_vcs_branch` varchar(255) NULL;
+
+-- Record the commit that triggered a VCS run
+ALTER TABLE `tfe_runs` ADD COLUMN `commit_sha` varchar(64) NULL, ADD COLUMN `commit_message` text NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS public.audit_events (
+    id varchar(36) PRIMARY KEY,
+    org_id varchar(36) NOT NULL,
+    seq bigint NOT NULL,
+    timestamp timestamptz NOT NULL,
+    actor varchar(255) NOT NULL,
+    action varchar(64) NOT NULL,
+    resource varchar(500),
+    source_ip varchar(64),
+    outcome varchar(16) NOT NULL,
+    status bigint DEFAULT 0,
+    request_id varchar(100),
+    details text,
+    prev_hash varchar(64),
+    hash varchar(64) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_org_seq ON public.audit_events (org_id, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_events_org_timestamp ON public.audit_events (org_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON public.audit_events (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON public.audit_events (action);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.encryption_data_keys (
+    id varchar(36) PRIMARY KEY,
+    scope varchar(255) NOT NULL,
+    version bigint NOT NULL,
+    wrapped_key bytea NOT NULL,
+    master_key_id varchar(500) NOT NULL,
+    created_at timestamptz,
+    rotated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_encryption_data_keys_scope_version ON public.encryption_data_keys (scope, version);
+CREATE INDEX IF NOT EXISTS idx_encryption_data_keys_master_key_id ON public.encryption_data_keys (master_key_id);
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS public.tfe_variable_sets (
+    id varchar(36) PRIMARY KEY,
+    org_id varchar(36) NOT NULL,
+    name varchar(255) NOT NULL,
+    description text,
+    global boolean DEFAULT false,
+    priority boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tfe_variable_sets_org_name ON public.tfe_variable_sets (org_id, name);
+
+CREATE TABLE IF NOT EXISTS public.tfe_variables (
+    id varchar(36) PRIMARY KEY,
+    org_id varchar(36) NOT NULL,
+    unit_id varchar(36) REFERENCES public.units (id) ON DELETE CASCADE,
+    variable_set_id varchar(36) REFERENCES public.tfe_variable_sets (id) ON DELETE CASCADE,
+    var_key varchar(255) NOT NULL,
+    value bytea,
+    description text,
+    category varchar(20) NOT NULL DEFAULT 'terraform',
+    hcl boolean DEFAULT false,
+    sensitive boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_org_id ON public.tfe_variables (org_id);
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_unit_id ON public.tfe_variables (unit_id);
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_variable_set_id ON public.tfe_variables (variable_set_id);
+
+CREATE TABLE IF NOT EXISTS public.tfe_variable_set_units (
+    variable_set_id varchar(36) NOT NULL REFERENCES public.tfe_variable_sets (id) ON DELETE CASCADE,
+    unit_id varchar(36) NOT NULL REFERENCES public.units (id) ON DELETE CASCADE,
+    PRIMARY KEY (variable_set_id, unit_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tfe_variable_set_units_unit_id ON public.tfe_variable_set_units (unit_id);
@@ -0,0 +1,5 @@
+-- Bind workspaces to a VCS repository branch
+ALTER TABLE "public"."units" ADD COLUMN "tfe_vcs_repo_url" character varying(1000) NULL, ADD COLUMN "tfe_vcs_branch" character varying(255) NULL;
+
+-- Record the commit that triggered a VCS run
+ALTER TABLE "public"."tfe_runs" ADD COLUMN "commit_sha" character varying(64) NULL, ADD COLUMN "commit_message" text NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  seq INTEGER NOT NULL,
+  timestamp DATETIME NOT NULL,
+  actor TEXT NOT NULL,
+  action TEXT NOT NULL,
+  resource TEXT,
+  source_ip TEXT,
+  outcome TEXT NOT NULL,
+  status INTEGER DEFAULT 0,
+  request_id TEXT,
+  details TEXT,
+  prev_hash TEXT,
+  hash TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_org_seq ON audit_events (org_id, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_events_org_timestamp ON audit_events (org_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
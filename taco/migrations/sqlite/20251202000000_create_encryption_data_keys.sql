@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS encryption_data_keys (
+  id TEXT PRIMARY KEY,
+  scope TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  wrapped_key BLOB NOT NULL,
+  master_key_id TEXT NOT NULL,
+  created_at DATETIME,
+  rotated_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_encryption_data_keys_scope_version ON encryption_data_keys (scope, version);
+CREATE INDEX IF NOT EXISTS idx_encryption_data_keys_master_key_id ON encryption_data_keys (master_key_id);
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS tfe_variable_sets (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  global INTEGER DEFAULT 0,
+  priority INTEGER DEFAULT 0,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tfe_variable_sets_org_name ON tfe_variable_sets (org_id, name);
+
+CREATE TABLE IF NOT EXISTS tfe_variables (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  unit_id TEXT,
+  variable_set_id TEXT,
+  var_key TEXT NOT NULL,
+  value BLOB,
+  description TEXT,
+  category TEXT NOT NULL DEFAULT 'terraform',
+  hcl INTEGER DEFAULT 0,
+  sensitive INTEGER DEFAULT 0,
+  created_at DATETIME,
+  updated_at DATETIME,
+  FOREIGN KEY (unit_id) REFERENCES units (id) ON DELETE CASCADE,
+  FOREIGN KEY (variable_set_id) REFERENCES tfe_variable_sets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_org_id ON tfe_variables (org_id);
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_unit_id ON tfe_variables (unit_id);
+CREATE INDEX IF NOT EXISTS idx_tfe_variables_variable_set_id ON tfe_variables (variable_set_id);
+
+CREATE TABLE IF NOT EXISTS tfe_variable_set_units (
+  variable_set_id TEXT NOT NULL,
+  unit_id TEXT NOT NULL,
+  PRIMARY KEY (variable_set_id, unit_id),
+  FOREIGN KEY (variable_set_id) REFERENCES tfe_variable_sets (id) ON DELETE CASCADE,
+  FOREIGN KEY (unit_id) REFERENCES units (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tfe_variable_set_units_unit_id ON tfe_variable_set_units (unit_id);
@@ -0,0 +1,7 @@
+-- Bind workspaces to a VCS repository branch
+ALTER TABLE units ADD COLUMN tfe_vcs_repo_url text NULL;
+ALTER TABLE units ADD COLUMN tfe_vcs_branch text NULL;
+
+-- Record the commit that triggered a VCS run
+ALTER TABLE tfe_runs ADD COLUMN commit_sha text NULL;
+ALTER TABLE tfe_runs ADD COLUMN commit_message text NULL;
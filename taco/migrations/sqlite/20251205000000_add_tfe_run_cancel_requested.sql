@@ -0,0 +1,2 @@
+-- Flag runs for cancellation so the replica executing them stops them
+ALTER TABLE tfe_runs ADD COLUMN cancel_requested BOOLEAN DEFAULT FALSE;